@@ -0,0 +1,138 @@
+package regen
+
+// UnreachableAlternative is an alternative of a OneOf that can never be part of a match, because every
+// string that it matches is also matched by earlier alternatives, which are preferred. For example,
+// foo is unreachable in fo+|foo. Although RE2 finds the same matches regardless, the submatches differ,
+// as do the matches of backtracking engines in other dialects.
+type UnreachableAlternative struct {
+	// OneOf is the alternation containing the alternative
+	OneOf Regexp
+	// Index is the index of the unreachable alternative
+	Index int
+	// Example is one of the shortest strings matched by the alternative
+	Example string
+	// ShadowedBy is the index of an earlier alternative that matches Example, or -1 if the alternative
+	// matches nothing
+	ShadowedBy int
+}
+
+// UnreachableAlternatives returns the alternatives of each OneOf in re that are shadowed by earlier
+// alternatives (see UnreachableAlternative), in the order that they appear. Alternatives are compared
+// as described by Equivalent, taking into account the flags of enclosing groups.
+func UnreachableAlternatives(re Regexp) []UnreachableAlternative {
+	var unreachable []UnreachableAlternative
+	walkAlternations(re, 0, func(m multiRegexp, automata []*automaton) {
+		for i, a := range automata {
+			if a == nil {
+				continue
+			}
+			earlier := append([]*automaton{a}, automata[:i]...)
+			if _, reachable := findString(func(accepted []bool) bool {
+				if !accepted[0] {
+					return false
+				}
+				for _, other := range accepted[1:] {
+					if other {
+						return false
+					}
+				}
+				return true
+			}, nonNil(earlier)...); reachable {
+				continue
+			}
+			u := UnreachableAlternative{OneOf: m, Index: i, ShadowedBy: -1}
+			example, ok := findString(func(accepted []bool) bool { return accepted[0] }, a)
+			if ok {
+				u.Example = example
+				for j, other := range automata[:i] {
+					if other != nil && accepts(other, example) {
+						u.ShadowedBy = j
+						break
+					}
+				}
+			}
+			unreachable = append(unreachable, u)
+		}
+	})
+	return unreachable
+}
+
+// walkAlternations calls fn with each alternation in re, along with an automaton for each of its
+// alternatives (or nil if one fails to compile), where active are the flags set by enclosing groups
+func walkAlternations(re Regexp, active Flag, fn func(m multiRegexp, automata []*automaton)) {
+	switch node := re.(type) {
+	case groupedRegexp:
+		active = active&^node.unsetFlags | node.setFlags
+	case multiRegexp:
+		if node.separator != "" {
+			automata := make([]*automaton, len(node.res))
+			for i, choice := range node.res {
+				if active != 0 {
+					choice = groupedRegexp{re: choice, noCapture: true, setFlags: active}
+				}
+				automata[i], _ = newAutomaton(choice)
+			}
+			fn(node, automata)
+		}
+	case CharClass:
+		return
+	}
+	for _, child := range Children(re) {
+		walkAlternations(child, active, fn)
+	}
+}
+
+// accepts returns true if the automaton matches text in its entirety
+func accepts(a *automaton, text string) bool {
+	s := a.start()
+	for _, r := range text {
+		if s = a.step(s, r); s == nil {
+			return false
+		}
+	}
+	return a.accepts(s)
+}
+
+func nonNil(automata []*automaton) []*automaton {
+	var result []*automaton
+	for _, a := range automata {
+		if a != nil {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// Overlap is a pair of alternatives of a OneOf that both match the same string
+type Overlap struct {
+	// OneOf is the alternation containing the alternatives
+	OneOf Regexp
+	// First and Second are the indexes of the alternatives, where First < Second
+	First, Second int
+	// Example is one of the shortest strings matched by both alternatives
+	Example string
+}
+
+// Overlaps returns each pair of alternatives of each OneOf in re that match a common string, which is
+// useful when alternatives are meant to be mutually exclusive, such as the classes of tokens in a
+// lexer. Alternatives are compared as described by Equivalent, taking into account the flags of
+// enclosing groups.
+func Overlaps(re Regexp) []Overlap {
+	var overlaps []Overlap
+	walkAlternations(re, 0, func(m multiRegexp, automata []*automaton) {
+		for i, a := range automata {
+			for j := i + 1; j < len(automata); j++ {
+				if a == nil || automata[j] == nil {
+					continue
+				}
+				example, ok := findString(func(accepted []bool) bool {
+					return accepted[0] && accepted[1]
+				}, a, automata[j])
+				if ok {
+					overlaps = append(overlaps, Overlap{OneOf: m, First: i, Second: j, Example: example})
+				}
+			}
+		}
+	})
+	return overlaps
+}
@@ -0,0 +1,115 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestUnreachableAlternatives(t *testing.T) {
+	type unreachable struct {
+		index      int
+		example    string
+		shadowedBy int
+	}
+	for _, tt := range []struct {
+		desc     string
+		re       regen.Regexp
+		expected []unreachable
+	}{
+		{
+			desc:     "shadowed by a repetition",
+			re:       regen.OneOf(regen.Sequence(regen.String("f"), regen.String("o").Repeat().Min(1)), regen.String("foo")),
+			expected: []unreachable{{index: 1, example: "foo", shadowedBy: 0}},
+		},
+		{
+			desc: "shadowed by a combination of alternatives",
+			re: regen.Sequence(
+				regen.String("id="),
+				regen.OneOf(regen.String("a"), regen.Raw(`b`), regen.CharSet('a', 'b'), regen.String("x")),
+			),
+			expected: []unreachable{{index: 2, example: "a", shadowedBy: 0}},
+		},
+		{
+			desc:     "later alternatives that are prefixes are reachable",
+			re:       regen.OneOfStrings("foo", "fo", "f"),
+			expected: nil,
+		},
+		{
+			desc: "flags of enclosing groups",
+			re: regen.OneOf(regen.String("get"), regen.String("GET")).
+				Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+			expected: []unreachable{{index: 1, example: "GET", shadowedBy: 0}},
+		},
+		{
+			desc:     "alternatives that never match",
+			re:       regen.OneOf(regen.String("a"), regen.Sequence(regen.String("a"), regen.TextStart)),
+			expected: []unreachable{{index: 1, shadowedBy: -1}},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			var actual []unreachable
+			for _, u := range regen.UnreachableAlternatives(tt.re) {
+				actual = append(actual, unreachable{index: u.Index, example: u.Example, shadowedBy: u.ShadowedBy})
+			}
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("expected %+v, got %+v", tt.expected, actual)
+			}
+			for i := range actual {
+				if actual[i] != tt.expected[i] {
+					t.Errorf("expected %+v, got %+v", tt.expected[i], actual[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	type overlap struct {
+		first, second int
+		example       string
+	}
+	for _, tt := range []struct {
+		desc     string
+		re       regen.Regexp
+		expected []overlap
+	}{
+		{
+			desc: "token classes",
+			re: regen.OneOf(
+				regen.Sequence(regen.ASCIICharClass("alpha"), regen.WordCharacter.Repeat()),
+				regen.Digit.Repeat().Min(1),
+				regen.OneOfStrings("if", "else"),
+			),
+			expected: []overlap{{first: 0, second: 2, example: "if"}},
+		},
+		{
+			desc:     "disjoint alternatives",
+			re:       regen.OneOfStrings("GET", "POST", "PUT"),
+			expected: nil,
+		},
+		{
+			desc: "nested alternations",
+			re: regen.Sequence(
+				regen.OneOfStrings("a", "b"),
+				regen.OneOf(regen.Digit, regen.CharRange('5', '9'), regen.CharSet('7')),
+			),
+			expected: []overlap{{first: 0, second: 1, example: "5"}, {first: 0, second: 2, example: "7"}, {first: 1, second: 2, example: "7"}},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			var actual []overlap
+			for _, o := range regen.Overlaps(tt.re) {
+				actual = append(actual, overlap{first: o.First, second: o.Second, example: o.Example})
+			}
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("expected %+v, got %+v", tt.expected, actual)
+			}
+			for i := range actual {
+				if actual[i] != tt.expected[i] {
+					t.Errorf("expected %+v, got %+v", tt.expected[i], actual[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package regen
+
+import (
+	"regexp/syntax"
+	"strconv"
+)
+
+// Anchoring describes where every match of an expression must begin or end
+type Anchoring int
+
+const (
+	// Unanchored expressions can match anywhere in the text
+	Unanchored Anchoring = iota
+	// AnchoredAtLine expressions can only match at line boundaries, e.g. ^ and $ with FlagMultiLine
+	AnchoredAtLine
+	// AnchoredAtText expressions can only match at the start (or end) of the text, e.g. \A and \z,
+	// or ^ and $ without FlagMultiLine
+	AnchoredAtText
+)
+
+func (a Anchoring) String() string {
+	switch a {
+	case Unanchored:
+		return "unanchored"
+	case AnchoredAtLine:
+		return "anchored at line"
+	case AnchoredAtText:
+		return "anchored at text"
+	}
+	return "Anchoring(" + strconv.Itoa(int(a)) + ")"
+}
+
+// IsAnchoredStart reports where every match of re must begin. For instance, validation code can warn
+// if a pattern that is meant to match an entire string is not AnchoredAtText.
+// An expression is anchored if every alternative begins with an anchor, ignoring word boundaries
+// before it, so (?:\Aa|\Ab) is anchored but (?:\Aa|b) is not.
+func IsAnchoredStart(re Regexp) Anchoring {
+	return anchoring(re, false)
+}
+
+// IsAnchoredEnd reports where every match of re must end. See IsAnchoredStart
+func IsAnchoredEnd(re Regexp) Anchoring {
+	return anchoring(re, true)
+}
+
+func anchoring(re Regexp, end bool) Anchoring {
+	parsed, err := syntax.Parse(re.Regexp(), syntax.Perl)
+	if err != nil {
+		return Unanchored
+	}
+	return syntaxAnchoring(parsed, end)
+}
+
+// syntaxAnchoring returns where every match of re must begin, or end if end is true
+func syntaxAnchoring(re *syntax.Regexp, end bool) Anchoring {
+	switch re.Op {
+	case syntax.OpBeginText:
+		if !end {
+			return AnchoredAtText
+		}
+	case syntax.OpEndText:
+		if end {
+			return AnchoredAtText
+		}
+	case syntax.OpBeginLine:
+		if !end {
+			return AnchoredAtLine
+		}
+	case syntax.OpEndLine:
+		if end {
+			return AnchoredAtLine
+		}
+	case syntax.OpCapture:
+		return syntaxAnchoring(re.Sub[0], end)
+	case syntax.OpPlus:
+		return syntaxAnchoring(re.Sub[0], end)
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return syntaxAnchoring(re.Sub[0], end)
+		}
+	case syntax.OpConcat:
+		// Zero-width assertions may come before the anchor, e.g. \b\A
+		anchoring := Unanchored
+		for i := range re.Sub {
+			sub := re.Sub[i]
+			if end {
+				sub = re.Sub[len(re.Sub)-1-i]
+			}
+			if a := syntaxAnchoring(sub, end); a > anchoring {
+				anchoring = a
+			}
+			if !zeroWidth(sub) || anchoring == AnchoredAtText {
+				break
+			}
+		}
+		return anchoring
+	case syntax.OpAlternate:
+		anchoring := AnchoredAtText
+		for _, sub := range re.Sub {
+			if a := syntaxAnchoring(sub, end); a < anchoring {
+				anchoring = a
+			}
+		}
+		return anchoring
+	}
+	return Unanchored
+}
+
+// zeroWidth returns true if re is an assertion that never consumes text
+func zeroWidth(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpBeginText, syntax.OpEndText, syntax.OpBeginLine, syntax.OpEndLine,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary, syntax.OpEmptyMatch:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestIsAnchored(t *testing.T) {
+	multiLine := func(re regen.Regexp) regen.Regexp {
+		return re.Group().NoCapture().SetFlags(regen.FlagMultiLine)
+	}
+	for _, tt := range []struct {
+		desc  string
+		re    regen.Regexp
+		start regen.Anchoring
+		end   regen.Anchoring
+	}{
+		{
+			desc:  "text anchors",
+			re:    regen.Sequence(regen.TextStart, regen.Digit.Repeat(), regen.TextEnd),
+			start: regen.AnchoredAtText,
+			end:   regen.AnchoredAtText,
+		},
+		{
+			desc:  "^ and $ without multi-line mode",
+			re:    regen.Sequence(regen.LineStart, regen.Digit, regen.LineEnd),
+			start: regen.AnchoredAtText,
+			end:   regen.AnchoredAtText,
+		},
+		{
+			desc:  "line anchors",
+			re:    multiLine(regen.Sequence(regen.LineStart, regen.Digit, regen.LineEnd)),
+			start: regen.AnchoredAtLine,
+			end:   regen.AnchoredAtLine,
+		},
+		{
+			desc:  "missing end anchor",
+			re:    regen.Sequence(regen.TextStart, regen.Digit),
+			start: regen.AnchoredAtText,
+			end:   regen.Unanchored,
+		},
+		{
+			desc:  "anchors within groups and after word boundaries",
+			re:    regen.Sequence(regen.ASCIIBoundary, regen.Sequence(regen.TextStart, regen.String("a")).Group(), regen.TextEnd.Group().Repeat().Min(1)),
+			start: regen.AnchoredAtText,
+			end:   regen.AnchoredAtText,
+		},
+		{
+			desc:  "every alternative is anchored",
+			re:    regen.OneOf(regen.Sequence(regen.TextStart, regen.String("a")), multiLine(regen.Sequence(regen.LineStart, regen.String("b")))),
+			start: regen.AnchoredAtLine,
+			end:   regen.Unanchored,
+		},
+		{
+			desc:  "an alternative is unanchored",
+			re:    regen.OneOf(regen.Sequence(regen.TextStart, regen.String("a")), regen.String("b")),
+			start: regen.Unanchored,
+			end:   regen.Unanchored,
+		},
+		{
+			desc:  "optional anchor",
+			re:    regen.Sequence(regen.TextStart.Optional(), regen.String("a")),
+			start: regen.Unanchored,
+			end:   regen.Unanchored,
+		},
+		{
+			desc:  "raw",
+			re:    regen.Raw(`^\d+\z`),
+			start: regen.AnchoredAtText,
+			end:   regen.AnchoredAtText,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			if actual := regen.IsAnchoredStart(tt.re); actual != tt.start {
+				t.Errorf("expected start to be %s, got %s", tt.start, actual)
+			}
+			if actual := regen.IsAnchoredEnd(tt.re); actual != tt.end {
+				t.Errorf("expected end to be %s, got %s", tt.end, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package regen
+
+import (
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// automaton lazily constructs a DFA for the set of strings that an expression matches in their
+// entirety, by simulating the compiled program of the expression on a representative rune for each
+// class of runes that the program can't distinguish between. Zero-width assertions are evaluated
+// using the previous rune and the next rune (or the end of the text).
+type automaton struct {
+	prog   *syntax.Prog
+	states map[string]*dfaState
+}
+
+// dfaState is a state of the DFA: the instructions reached after consuming a rune (before following
+// empty transitions), along with the rune that was consumed
+type dfaState struct {
+	id   int
+	pcs  []uint32
+	prev rune
+	// next contains the following state for each rune that has been consumed, once computed
+	next map[rune]*dfaState
+	// accepting is 1 if the text may end in this state, -1 if it may not, or 0 if not yet computed
+	accepting int
+}
+
+func newAutomaton(re Regexp) (*automaton, error) {
+	parsed, err := syntax.Parse(re.Regexp(), syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, err
+	}
+	return &automaton{prog: prog, states: make(map[string]*dfaState)}, nil
+}
+
+// start returns the initial state
+func (a *automaton) start() *dfaState {
+	return a.startAfter(-1)
+}
+
+// startAfter returns the initial state for text that follows prev (or -1 at the start of the text)
+func (a *automaton) startAfter(prev rune) *dfaState {
+	return a.state([]uint32{uint32(a.prog.Start)}, prev)
+}
+
+// state returns the canonical state for the given instructions and previous rune
+func (a *automaton) state(pcs []uint32, prev rune) *dfaState {
+	// Only the kind of the previous rune affects zero-width assertions
+	switch {
+	case prev == -1 || prev == '\n':
+	case syntax.IsWordChar(prev):
+		prev = 'a'
+	default:
+		prev = ' '
+	}
+	sort.Slice(pcs, func(i, j int) bool { return pcs[i] < pcs[j] })
+	var key strings.Builder
+	key.WriteString(strconv.Itoa(int(prev)))
+	for _, pc := range pcs {
+		key.WriteByte(',')
+		key.WriteString(strconv.Itoa(int(pc)))
+	}
+	if s, ok := a.states[key.String()]; ok {
+		return s
+	}
+	s := &dfaState{id: len(a.states), pcs: pcs, prev: prev, next: make(map[rune]*dfaState)}
+	a.states[key.String()] = s
+	return s
+}
+
+// closure returns the instructions reachable from s by following empty transitions, given the next
+// rune (or -1 at the end of the text)
+func (a *automaton) closure(s *dfaState, next rune) []*syntax.Inst {
+	context := syntax.EmptyOpContext(s.prev, next)
+	seen := make(map[uint32]bool)
+	var insts []*syntax.Inst
+	var visit func(pc uint32)
+	visit = func(pc uint32) {
+		if seen[pc] {
+			return
+		}
+		seen[pc] = true
+		inst := &a.prog.Inst[pc]
+		switch inst.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			visit(inst.Out)
+			visit(inst.Arg)
+		case syntax.InstCapture, syntax.InstNop:
+			visit(inst.Out)
+		case syntax.InstEmptyWidth:
+			if syntax.EmptyOp(inst.Arg)&^context == 0 {
+				visit(inst.Out)
+			}
+		case syntax.InstFail:
+		default:
+			insts = append(insts, inst)
+		}
+	}
+	for _, pc := range s.pcs {
+		visit(pc)
+	}
+	return insts
+}
+
+// step returns the state after consuming r, or nil if no instructions remain
+func (a *automaton) step(s *dfaState, r rune) *dfaState {
+	if next, ok := s.next[r]; ok {
+		return next
+	}
+	var pcs []uint32
+	for _, inst := range a.closure(s, r) {
+		switch inst.Op {
+		case syntax.InstRune, syntax.InstRune1:
+			if inst.MatchRune(r) {
+				pcs = append(pcs, inst.Out)
+			}
+		case syntax.InstRuneAny:
+			pcs = append(pcs, inst.Out)
+		case syntax.InstRuneAnyNotNL:
+			if r != '\n' {
+				pcs = append(pcs, inst.Out)
+			}
+		}
+	}
+	var next *dfaState
+	if len(pcs) > 0 {
+		next = a.state(pcs, r)
+	}
+	s.next[r] = next
+	return next
+}
+
+// accepts returns true if the text may end in state s
+func (a *automaton) accepts(s *dfaState) bool {
+	if s == nil {
+		return false
+	}
+	if s.accepting == 0 {
+		s.accepting = -1
+		for _, inst := range a.closure(s, -1) {
+			if inst.Op == syntax.InstMatch {
+				s.accepting = 1
+			}
+		}
+	}
+	return s.accepting == 1
+}
+
+// boundaries adds the runes at which the program's behavior may change to the set
+func (a *automaton) boundaries(set map[rune]bool) {
+	for _, inst := range a.prog.Inst {
+		if inst.Op != syntax.InstRune && inst.Op != syntax.InstRune1 {
+			continue
+		}
+		if syntax.Flags(inst.Arg)&syntax.FoldCase != 0 && len(inst.Rune) == 1 {
+			for _, r := range foldRunes(inst.Rune) {
+				set[r], set[r+1] = true, true
+			}
+			continue
+		}
+		runes := inst.Rune
+		if len(runes) == 1 {
+			runes = []rune{runes[0], runes[0]}
+		}
+		for i := 0; i+1 < len(runes); i += 2 {
+			set[runes[i]], set[runes[i+1]+1] = true, true
+		}
+	}
+}
+
+// alphabet returns a representative rune for each class of runes that the automata can't distinguish
+// between
+func alphabet(automata ...*automaton) []rune {
+	set := map[rune]bool{0: true, '\n': true, '\n' + 1: true, 0xD800: true, 0xE000: true}
+	// the kind of rune that zero-width assertions depend on
+	for _, r := range []rune{'0', '9' + 1, 'A', 'Z' + 1, '_', '_' + 1, 'a', 'z' + 1} {
+		set[r] = true
+	}
+	for _, a := range automata {
+		a.boundaries(set)
+	}
+	var bounds []rune
+	for r := range set {
+		if r <= unicode.MaxRune {
+			bounds = append(bounds, r)
+		}
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+	var runes []rune
+	for i, lo := range bounds {
+		hi := rune(unicode.MaxRune)
+		if i+1 < len(bounds) {
+			hi = bounds[i+1] - 1
+		}
+		if lo >= 0xD800 && hi <= 0xDFFF {
+			// surrogates are never matched
+			continue
+		}
+		runes = append(runes, representative(lo, hi))
+	}
+	return runes
+}
+
+// representative returns a rune in [lo, hi], preferring ones that are easy to read
+func representative(lo, hi rune) rune {
+	for _, r := range []rune{'a', 'A', '0', ' ', '!', '\t'} {
+		if lo <= r && r <= hi {
+			return r
+		}
+	}
+	for r := lo; r <= hi && r < lo+256; r++ {
+		if unicode.IsPrint(r) {
+			return r
+		}
+	}
+	return lo
+}
+
+// findString returns the shortest string (built from representative runes) that satisfies found,
+// given whether each automaton accepts it, or false if there is no such string
+func findString(found func(accepted []bool) bool, automata ...*automaton) (string, bool) {
+	runes := alphabet(automata...)
+	type node struct {
+		states []*dfaState
+		parent *node
+		r      rune
+	}
+	key := func(states []*dfaState) string {
+		var sb strings.Builder
+		for _, s := range states {
+			if s == nil {
+				sb.WriteString("-,")
+				continue
+			}
+			sb.WriteString(strconv.Itoa(s.id) + ",")
+		}
+		return sb.String()
+	}
+	start := &node{states: make([]*dfaState, len(automata))}
+	for i, a := range automata {
+		start.states[i] = a.start()
+	}
+	seen := map[string]bool{key(start.states): true}
+	queue := []*node{start}
+	accepted := make([]bool, len(automata))
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		alive := false
+		for i, a := range automata {
+			accepted[i] = a.accepts(n.states[i])
+			alive = alive || n.states[i] != nil
+		}
+		if found(accepted) {
+			var text []rune
+			for ; n.parent != nil; n = n.parent {
+				text = append([]rune{n.r}, text...)
+			}
+			return string(text), true
+		}
+		if !alive {
+			continue
+		}
+		for _, r := range runes {
+			next := &node{states: make([]*dfaState, len(automata)), parent: n, r: r}
+			for i, a := range automata {
+				if n.states[i] != nil {
+					next.states[i] = a.step(n.states[i], r)
+				}
+			}
+			k := key(next.states)
+			if !seen[k] {
+				seen[k] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return "", false
+}
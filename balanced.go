@@ -0,0 +1,30 @@
+package regen
+
+import (
+	"fmt"
+)
+
+// BalancedUpTo returns a Regexp that matches text enclosed by open and close, in which the delimiters
+// are balanced and nested at most depth levels deep (counting the outer pair), e.g. for depth 2:
+//
+//	regen.BalancedUpTo('(', ')', 2)
+//	// \((?:[^()]|\([^()]*\))*\)
+//
+// RE2 can't match arbitrarily deep nesting, which requires recursion, so the pattern is unrolled for
+// each level, and its length grows linearly with depth. BalancedUpTo panics if depth is less than 1, or
+// open and close are the same character.
+func BalancedUpTo(open, close rune, depth int) Regexp {
+	if depth < 1 {
+		panic(fmt.Sprintf("regen: BalancedUpTo requires a depth of at least 1, got %d", depth))
+	}
+	if open == close {
+		panic(fmt.Sprintf("regen: BalancedUpTo requires different delimiters, got %q twice", open))
+	}
+	o, c := String(string(open)), String(string(close))
+	other := CharSet(open, close).Negate()
+	re := Sequence(o, other.Repeat(), c)
+	for i := 1; i < depth; i++ {
+		re = Sequence(o, OneOf(other, re).Group().NoCapture().Repeat(), c)
+	}
+	return re
+}
@@ -0,0 +1,70 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestBalancedUpTo(t *testing.T) {
+	tests := []struct {
+		description string
+		re          regen.Regexp
+		expected    string
+		matches     []string
+		nonMatches  []string
+	}{
+		{
+			description: "depth 1 does not allow nesting",
+			re:          regen.BalancedUpTo('(', ')', 1),
+			expected:    `\([^()]*\)`,
+			matches:     []string{"()", "(a b)"},
+			nonMatches:  []string{"(()", "(a(b))"},
+		},
+		{
+			description: "depth 3 allows two nested levels",
+			re:          regen.BalancedUpTo('(', ')', 3),
+			expected:    `\((?:[^()]|\((?:[^()]|\([^()]*\))*\))*\)`,
+			matches:     []string{"(a(b)(c(d)))", "(()())"},
+			nonMatches:  []string{"(((())))", "(a(b)", "(a))"},
+		},
+		{
+			description: "other delimiters",
+			re:          regen.BalancedUpTo('{', '}', 2),
+			matches:     []string{"{a: {b: 1}}"},
+			nonMatches:  []string{"{a: {b: {c: 1}}}"},
+		},
+	}
+	for _, tt := range tests {
+		if tt.expected != "" && tt.re.Regexp() != tt.expected {
+			t.Errorf(`balanced test "%s" failed: got %s, expected %s`, tt.description, tt.re.Regexp(), tt.expected)
+		}
+		m := regen.MustCompile(regen.Sequence(regen.TextStart, tt.re, regen.TextEnd))
+		for _, s := range tt.matches {
+			if !m.MatchString(s) {
+				t.Errorf(`balanced test "%s" failed: expected %s to match %q`, tt.description, tt.re.Regexp(), s)
+			}
+		}
+		for _, s := range tt.nonMatches {
+			if m.MatchString(s) {
+				t.Errorf(`balanced test "%s" failed: expected %s not to match %q`, tt.description, tt.re.Regexp(), s)
+			}
+		}
+	}
+}
+
+func TestBalancedUpTo_Panics(t *testing.T) {
+	for _, fn := range []func(){
+		func() { regen.BalancedUpTo('(', ')', 0) },
+		func() { regen.BalancedUpTo('|', '|', 2) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected BalancedUpTo to panic")
+				}
+			}()
+			fn()
+		}()
+	}
+}
@@ -0,0 +1,123 @@
+package regen
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// Finding is a Warning produced by linting an entry in a Registry (see LintRegistry)
+type Finding struct {
+	// Entry is the name of the registry entry
+	Entry string `json:"entry"`
+	// Owner is the author of the entry, according to its Provenance
+	Owner string `json:"owner,omitempty"`
+	Rule  string `json:"rule"`
+	// Hash identifies the structure of the sub-expression that the warning refers to, so that a
+	// finding can be recognized even if the entry is renamed or other parts of it change
+	Hash    string `json:"hash"`
+	Message string `json:"message"`
+}
+
+type findingKey struct {
+	rule string
+	hash string
+}
+
+func (f Finding) key() findingKey {
+	return findingKey{rule: f.Rule, hash: f.Hash}
+}
+
+// LintRegistry lints every entry in the registry (see Lint), returning the findings sorted by entry name
+func LintRegistry(r *Registry) []Finding {
+	var findings []Finding
+	for _, entry := range r.Entries() {
+		for _, w := range Lint(entry.Regexp) {
+			findings = append(findings, Finding{
+				Entry:   entry.Name,
+				Owner:   entry.Provenance.Author,
+				Rule:    w.Rule,
+				Hash:    structuralHash(w.Node),
+				Message: w.Message,
+			})
+		}
+	}
+	return findings
+}
+
+// Baseline is a set of accepted findings, allowing lint rules to be adopted for an existing pattern
+// corpus: findings in the baseline are grandfathered, while new findings can fail the build.
+// Findings are identified by their rule and hash, so they remain grandfathered if the entry is
+// renamed or moved to another owner, but not if the offending sub-expression changes.
+type Baseline struct {
+	Findings []Finding `json:"findings"`
+}
+
+// NewBaseline returns a Baseline that accepts the given findings
+func NewBaseline(findings []Finding) *Baseline {
+	b := &Baseline{Findings: []Finding{}}
+	seen := make(map[findingKey]bool)
+	for _, f := range findings {
+		if !seen[f.key()] {
+			seen[f.key()] = true
+			b.Findings = append(b.Findings, f)
+		}
+	}
+	sort.Slice(b.Findings, func(i, j int) bool {
+		x, y := b.Findings[i], b.Findings[j]
+		if x.Entry != y.Entry {
+			return x.Entry < y.Entry
+		}
+		if x.Rule != y.Rule {
+			return x.Rule < y.Rule
+		}
+		return x.Hash < y.Hash
+	})
+	return b
+}
+
+// ReadBaseline reads a Baseline that was written using Write
+func ReadBaseline(r io.Reader) (*Baseline, error) {
+	var b Baseline
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Write writes the baseline to w as JSON, which is intended to be committed alongside the patterns
+func (b *Baseline) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// New returns the findings that are not accepted by the baseline
+func (b *Baseline) New(findings []Finding) []Finding {
+	accepted := make(map[findingKey]bool)
+	for _, f := range b.Findings {
+		accepted[f.key()] = true
+	}
+	var result []Finding
+	for _, f := range findings {
+		if !accepted[f.key()] {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// Fixed returns the findings in the baseline that no longer occur, which can be removed from it
+func (b *Baseline) Fixed(findings []Finding) []Finding {
+	current := make(map[findingKey]bool)
+	for _, f := range findings {
+		current[f.key()] = true
+	}
+	var result []Finding
+	for _, f := range b.Findings {
+		if !current[f.key()] {
+			result = append(result, f)
+		}
+	}
+	return result
+}
@@ -0,0 +1,47 @@
+package regen_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestBaseline(t *testing.T) {
+	registry := regen.NewRegistry()
+	registry.MustRegister("legacy", regen.Sequence(regen.String("id="), regen.Digit.Repeat().Group()),
+		regen.WithProvenance(regen.Provenance{Author: "team-a"}))
+
+	findings := regen.LintRegistry(registry)
+	if len(findings) != 1 || findings[0].Entry != "legacy" || findings[0].Owner != "team-a" || findings[0].Rule != "unnamed-capture" {
+		t.Fatalf("unexpected findings %+v", findings)
+	}
+
+	var buf bytes.Buffer
+	if err := regen.NewBaseline(findings).Write(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	baseline, err := regen.ReadBaseline(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The same violation in another entry is grandfathered, since it has the same structure
+	registry.MustRegister("copy", regen.Sequence(regen.String("key="), regen.Digit.Repeat().Group()))
+	registry.MustRegister("new", regen.Sequence(regen.String("x"), regen.WordCharacter.Group()))
+	findings = regen.LintRegistry(registry)
+	if len(findings) != 3 {
+		t.Fatalf("expected 3 findings, got %+v", findings)
+	}
+	newFindings := baseline.New(findings)
+	if len(newFindings) != 1 || newFindings[0].Entry != "new" {
+		t.Errorf("expected only the new entry's finding, got %+v", newFindings)
+	}
+	if fixed := baseline.Fixed(findings); len(fixed) != 0 {
+		t.Errorf("expected no fixed findings, got %+v", fixed)
+	}
+
+	if fixed := baseline.Fixed(nil); len(fixed) != 1 || fixed[0].Entry != "legacy" {
+		t.Errorf("expected the legacy finding to be fixed, got %+v", fixed)
+	}
+}
@@ -0,0 +1,380 @@
+package regen
+
+import (
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// binaryVersion is the first byte of the binary encoding of an expression, so that the format can
+// evolve
+const binaryVersion = 1
+
+// The fields of a node in the binary encoding, which are written (in this order) if their bit is set in
+// the node's field mask. Booleans are only represented by their bit.
+const (
+	binaryValue = 1 << iota
+	binaryName
+	binaryBalance
+	binaryNoCapture
+	binarySetFlags
+	binaryUnsetFlags
+	binaryMin
+	binaryMax
+	binaryUngreedy
+	binaryChars
+	binaryStart
+	binaryEnd
+	binaryClass
+	binaryNegated
+	binaryComment
+	binaryChildren
+)
+
+var errBinaryTruncated = errors.New("regen: the binary encoding is truncated")
+
+func init() {
+	// expressions can be encoded by gob as the values of Regexp interfaces
+	for _, re := range []Regexp{
+		literalRegexp{}, anyRegexp{}, anchorRegexp{}, multiRegexp{}, groupedRegexp{}, repeatedRegexp{},
+		annotatedRegexp{}, charSetRegexp{}, charRangeRegexp{}, asciiCharClassRegexp{},
+		unicodeCharClassRegexp{}, perlCharClassRegexp{}, unionCharClassRegexp{},
+	} {
+		gob.Register(re)
+	}
+}
+
+// MarshalBinary encodes the structure of re in a compact binary form, which UnmarshalBinary decodes.
+// This is suitable for caching composed expressions on disk, or sending them between services, without
+// parsing them again. The expressions in this package also implement encoding.BinaryMarshaler, so they
+// can be encoded by encoding/gob (including as the values of Regexp fields).
+func MarshalBinary(re Regexp) ([]byte, error) {
+	return appendBinaryNode([]byte{binaryVersion}, toJSONNode(re)), nil
+}
+
+// UnmarshalBinary decodes an expression encoded by MarshalBinary
+func UnmarshalBinary(data []byte) (Regexp, error) {
+	if len(data) == 0 {
+		return nil, errBinaryTruncated
+	}
+	if data[0] != binaryVersion {
+		return nil, fmt.Errorf("regen: unsupported binary encoding version %d", data[0])
+	}
+	d := binaryDecoder{data: data[1:]}
+	n, err := d.node()
+	if err != nil {
+		return nil, err
+	}
+	if len(d.data) != 0 {
+		return nil, fmt.Errorf("regen: %d unexpected bytes after the encoded expression", len(d.data))
+	}
+	return n.regexp()
+}
+
+// appendBinaryNode appends the binary encoding of n to b
+func appendBinaryNode(b []byte, n *jsonNode) []byte {
+	kind := 0
+	for k, name := range nodeKindNames {
+		if name == n.Kind {
+			kind = k
+		}
+	}
+	var mask uint64
+	setIf := func(bit uint64, set bool) {
+		if set {
+			mask |= bit
+		}
+	}
+	setIf(binaryValue, n.Value != "")
+	setIf(binaryName, n.Name != "")
+	setIf(binaryBalance, n.Balance != "")
+	setIf(binaryNoCapture, n.NoCapture)
+	setIf(binarySetFlags, n.SetFlags != "")
+	setIf(binaryUnsetFlags, n.UnsetFlags != "")
+	setIf(binaryMin, n.Min != nil)
+	setIf(binaryMax, n.Max != nil)
+	setIf(binaryUngreedy, n.Ungreedy)
+	setIf(binaryChars, n.Chars != "")
+	setIf(binaryStart, n.Start != "")
+	setIf(binaryEnd, n.End != "")
+	setIf(binaryClass, n.Class != "")
+	setIf(binaryNegated, n.Negated)
+	setIf(binaryComment, n.Comment != "")
+	setIf(binaryChildren, len(n.Children) > 0)
+
+	b = append(b, byte(kind))
+	b = appendUvarint(b, mask)
+	for _, field := range []struct {
+		bit uint64
+		s   string
+	}{
+		{binaryValue, n.Value}, {binaryName, n.Name}, {binaryBalance, n.Balance},
+		{binarySetFlags, n.SetFlags}, {binaryUnsetFlags, n.UnsetFlags},
+	} {
+		if mask&field.bit != 0 {
+			b = appendBinaryString(b, field.s)
+		}
+	}
+	if n.Min != nil {
+		b = appendUvarint(b, uint64(*n.Min))
+	}
+	if n.Max != nil {
+		b = appendUvarint(b, uint64(*n.Max))
+	}
+	for _, field := range []struct {
+		bit uint64
+		s   string
+	}{
+		{binaryChars, n.Chars}, {binaryStart, n.Start}, {binaryEnd, n.End},
+		{binaryClass, n.Class}, {binaryComment, n.Comment},
+	} {
+		if mask&field.bit != 0 {
+			b = appendBinaryString(b, field.s)
+		}
+	}
+	if len(n.Children) > 0 {
+		b = appendUvarint(b, uint64(len(n.Children)))
+		for _, child := range n.Children {
+			b = appendBinaryNode(b, child)
+		}
+	}
+	return b
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func appendBinaryString(b []byte, s string) []byte {
+	b = appendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// binaryDecoder decodes the nodes of the binary encoding of an expression
+type binaryDecoder struct {
+	data []byte
+}
+
+func (d *binaryDecoder) node() (*jsonNode, error) {
+	if len(d.data) == 0 {
+		return nil, errBinaryTruncated
+	}
+	kind := int(d.data[0])
+	d.data = d.data[1:]
+	if kind >= len(nodeKindNames) {
+		return nil, fmt.Errorf("regen: unknown kind of node %d in the binary encoding", kind)
+	}
+	mask, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	n := &jsonNode{
+		Kind:      nodeKindNames[kind],
+		NoCapture: mask&binaryNoCapture != 0,
+		Ungreedy:  mask&binaryUngreedy != 0,
+		Negated:   mask&binaryNegated != 0,
+	}
+	for _, field := range []struct {
+		bit uint64
+		s   *string
+	}{
+		{binaryValue, &n.Value}, {binaryName, &n.Name}, {binaryBalance, &n.Balance},
+		{binarySetFlags, &n.SetFlags}, {binaryUnsetFlags, &n.UnsetFlags},
+	} {
+		if mask&field.bit != 0 {
+			if *field.s, err = d.string(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, field := range []struct {
+		bit uint64
+		n   **uint
+	}{
+		{binaryMin, &n.Min}, {binaryMax, &n.Max},
+	} {
+		if mask&field.bit != 0 {
+			v, err := d.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			count := uint(v)
+			*field.n = &count
+		}
+	}
+	for _, field := range []struct {
+		bit uint64
+		s   *string
+	}{
+		{binaryChars, &n.Chars}, {binaryStart, &n.Start}, {binaryEnd, &n.End},
+		{binaryClass, &n.Class}, {binaryComment, &n.Comment},
+	} {
+		if mask&field.bit != 0 {
+			if *field.s, err = d.string(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if mask&binaryChildren != 0 {
+		count, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		// each child takes at least two bytes
+		if count > uint64(len(d.data))/2 {
+			return nil, errBinaryTruncated
+		}
+		n.Children = make([]*jsonNode, count)
+		for i := range n.Children {
+			if n.Children[i], err = d.node(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (d *binaryDecoder) uvarint() (uint64, error) {
+	v, size := binary.Uvarint(d.data)
+	if size <= 0 {
+		return 0, errBinaryTruncated
+	}
+	d.data = d.data[size:]
+	return v, nil
+}
+
+func (d *binaryDecoder) string() (string, error) {
+	size, err := d.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if size > uint64(len(d.data)) {
+		return "", errBinaryTruncated
+	}
+	s := string(d.data[:size])
+	d.data = d.data[size:]
+	return s, nil
+}
+
+// unmarshalBinaryInto decodes an expression into dst, a pointer to one of the expression types of this
+// package, which implements encoding.BinaryUnmarshaler for them (so that gob can decode Regexp values)
+func unmarshalBinaryInto(dst interface{}, data []byte) error {
+	re, err := UnmarshalBinary(data)
+	if err != nil {
+		return err
+	}
+	v := reflect.ValueOf(dst).Elem()
+	decoded := reflect.ValueOf(re)
+	if decoded.Type() != v.Type() {
+		return fmt.Errorf("regen: cannot decode a %s into a %s", Kind(re), v.Type())
+	}
+	v.Set(decoded)
+	return nil
+}
+
+func (l literalRegexp) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(l)
+}
+
+func (l *literalRegexp) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto(l, data)
+}
+
+func (a anyRegexp) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(a)
+}
+
+func (a *anyRegexp) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto(a, data)
+}
+
+func (a anchorRegexp) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(a)
+}
+
+func (a *anchorRegexp) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto(a, data)
+}
+
+func (m multiRegexp) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(m)
+}
+
+func (m *multiRegexp) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto(m, data)
+}
+
+func (g groupedRegexp) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(g)
+}
+
+func (g *groupedRegexp) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto(g, data)
+}
+
+func (r repeatedRegexp) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(r)
+}
+
+func (r *repeatedRegexp) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto(r, data)
+}
+
+func (a annotatedRegexp) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(a)
+}
+
+func (a *annotatedRegexp) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto(a, data)
+}
+
+func (c charSetRegexp) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(c)
+}
+
+func (c *charSetRegexp) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto(c, data)
+}
+
+func (c charRangeRegexp) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(c)
+}
+
+func (c *charRangeRegexp) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto(c, data)
+}
+
+func (a asciiCharClassRegexp) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(a)
+}
+
+func (a *asciiCharClassRegexp) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto(a, data)
+}
+
+func (u unicodeCharClassRegexp) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(u)
+}
+
+func (u *unicodeCharClassRegexp) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto(u, data)
+}
+
+func (p perlCharClassRegexp) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(p)
+}
+
+func (p *perlCharClassRegexp) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto(p, data)
+}
+
+func (u unionCharClassRegexp) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(u)
+}
+
+func (u *unionCharClassRegexp) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto(u, data)
+}
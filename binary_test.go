@@ -0,0 +1,96 @@
+package regen_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestBinary(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		re          regen.Regexp
+	}{
+		{
+			description: "literals and classes",
+			re:          regen.Sequence(regen.String("a.b"), regen.Raw(`\pL`), regen.Digit.Negate(), regen.Any, regen.LineEnd),
+		},
+		{
+			description: "groups and repetitions",
+			re:          regen.String("ab").Group().CaptureAs("x").SetFlags(regen.FlagCaseInsensitive).Repeat().Min(1).Max(300).Ungreedy(),
+		},
+		{
+			description: "alternations",
+			re:          regen.OneOf(regen.LineStart, regen.String("x")).Group().NoCapture(),
+		},
+		{
+			description: "unions of classes",
+			re:          regen.Union(regen.CharSet('_', 'é'), regen.CharRange('a', 'z'), regen.ASCIICharClass("digit"), regen.UnicodeCharClass("Greek").Negate()),
+		},
+		{
+			description: "annotations and balancing groups",
+			re:          regen.Annotate(regen.String(")").Group().CaptureAs("inner").Balance("open").UnsetFlags(regen.FlagMultiLine), "close"),
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			data, err := regen.MarshalBinary(tt.re)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decoded, err := regen.UnmarshalBinary(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !regen.Equal(decoded, tt.re) || decoded.Regexp() != tt.re.Regexp() {
+				t.Errorf("expected %s, got %s", tt.re.Regexp(), decoded.Regexp())
+			}
+
+			// expressions can be sent through gob as the values of Regexp fields
+			type rule struct {
+				Name    string
+				Pattern regen.Regexp
+			}
+			var buf bytes.Buffer
+			if err := gob.NewEncoder(&buf).Encode(rule{Name: "r", Pattern: tt.re}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var r rule
+			if err := gob.NewDecoder(&buf).Decode(&r); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !regen.Equal(r.Pattern, tt.re) {
+				t.Errorf("expected %s, got %s", tt.re.Regexp(), r.Pattern.Regexp())
+			}
+		})
+	}
+}
+
+func TestUnmarshalBinary_Invalid(t *testing.T) {
+	valid, err := regen.MarshalBinary(regen.Sequence(regen.String("ab"), regen.Any))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tt := range []struct {
+		description string
+		data        []byte
+		expected    string
+	}{
+		{"empty", nil, "regen: the binary encoding is truncated"},
+		{"version", []byte{9}, "regen: unsupported binary encoding version 9"},
+		{"truncated", valid[:len(valid)-3], "regen: the binary encoding is truncated"},
+		{"trailing data", append(valid[:len(valid):len(valid)], 0), "regen: 1 unexpected bytes after the encoded expression"},
+		{"kind", []byte{1, 200, 0}, "regen: unknown kind of node 200 in the binary encoding"},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			_, err := regen.UnmarshalBinary(tt.data)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %q", tt.expected, err)
+			}
+		})
+	}
+}
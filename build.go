@@ -0,0 +1,93 @@
+package regen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Deprecation describes when and why a registered Regexp is being retired
+type Deprecation struct {
+	// Sunset is the time after which Build fails if the Regexp is still registered
+	Sunset time.Time `json:"sunset"`
+	// Reason describes why the Regexp is deprecated, and what should be used instead
+	Reason string `json:"reason,omitempty"`
+}
+
+// WithSunset deprecates an Entry, which will no longer be allowed by Build after the sunset time
+func WithSunset(sunset time.Time, reason string) EntryOption {
+	return func(e *Entry) {
+		e.Deprecation = &Deprecation{Sunset: sunset, Reason: reason}
+	}
+}
+
+// BuildOption configures Registry.Build
+type BuildOption func(*buildConfig)
+
+type buildConfig struct {
+	now     time.Time
+	warn    func(Entry)
+	allowed map[string]bool
+}
+
+// AllowSunset allows Build to succeed even though the named entries are past their sunset time.
+// A warning is emitted for them instead (see WithWarningHandler).
+func AllowSunset(names ...string) BuildOption {
+	return func(c *buildConfig) {
+		for _, name := range names {
+			c.allowed[name] = true
+		}
+	}
+}
+
+// WithWarningHandler sets the function that is called by Build for each deprecated entry that is
+// allowed. By default, no warnings are emitted.
+func WithWarningHandler(warn func(Entry)) BuildOption {
+	return func(c *buildConfig) {
+		c.warn = warn
+	}
+}
+
+// WithBuildTime sets the time that sunset times are compared against, which defaults to the current time
+func WithBuildTime(now time.Time) BuildOption {
+	return func(c *buildConfig) {
+		c.now = now
+	}
+}
+
+// Build compiles every registered Regexp using the standard library's regexp package, returning them
+// by name. For deprecated entries (see WithSunset), a warning is emitted if the sunset time has not
+// yet passed; otherwise, an error is returned unless the entry is allowed using AllowSunset.
+func (r *Registry) Build(opts ...BuildOption) (map[string]*regexp.Regexp, error) {
+	c := buildConfig{
+		now:     time.Now(),
+		allowed: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	compiled := make(map[string]*regexp.Regexp)
+	var expired []string
+	for _, entry := range r.Entries() {
+		if entry.Deprecation != nil {
+			if c.now.Before(entry.Deprecation.Sunset) || c.allowed[entry.Name] {
+				if c.warn != nil {
+					c.warn(entry)
+				}
+			} else {
+				expired = append(expired, entry.Name)
+			}
+		}
+		re, err := regexp.Compile(entry.Regexp.Regexp())
+		if err != nil {
+			return nil, fmt.Errorf("regen: compiling %q: %v", entry.Name, err)
+		}
+		compiled[entry.Name] = re
+	}
+	if len(expired) > 0 {
+		return nil, fmt.Errorf("regen: entries are past their sunset time: %s", strings.Join(expired, ", "))
+	}
+	return compiled, nil
+}
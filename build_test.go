@@ -0,0 +1,74 @@
+package regen_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestBuild(t *testing.T) {
+	registry := regen.NewRegistry()
+	registry.MustRegister("current", regen.String("a"))
+	registry.MustRegister("legacy", regen.String("b"),
+		regen.WithSunset(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC), "use current instead"))
+
+	var warned []string
+	warn := regen.WithWarningHandler(func(entry regen.Entry) {
+		warned = append(warned, entry.Name)
+	})
+
+	compiled, err := registry.Build(warn, regen.WithBuildTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("unexpected error before the sunset time: %v", err)
+	}
+	if len(compiled) != 2 || !compiled["legacy"].MatchString("b") {
+		t.Errorf("unexpected compiled patterns %v", compiled)
+	}
+	if len(warned) != 1 || warned[0] != "legacy" {
+		t.Errorf("expected a warning for legacy, got %v", warned)
+	}
+
+	afterSunset := regen.WithBuildTime(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+	_, err = registry.Build(warn, afterSunset)
+	if err == nil || !strings.Contains(err.Error(), "legacy") {
+		t.Errorf("expected an error after the sunset time, got %v", err)
+	}
+
+	warned = nil
+	if _, err := registry.Build(warn, afterSunset, regen.AllowSunset("legacy")); err != nil {
+		t.Errorf("unexpected error when allowing the sunset: %v", err)
+	}
+	if len(warned) != 1 {
+		t.Errorf("expected a warning when allowing the sunset, got %v", warned)
+	}
+}
+
+func TestBuildWithoutWarningHandler(t *testing.T) {
+	registry := regen.NewRegistry()
+	registry.MustRegister("legacy", regen.String("b"),
+		regen.WithSunset(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC), "use current instead"))
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	if _, err := registry.Build(regen.WithBuildTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if logged.Len() > 0 {
+		t.Errorf("expected no warnings by default, got %q", logged.String())
+	}
+}
+
+func TestBuildInvalidRegexp(t *testing.T) {
+	registry := regen.NewRegistry()
+	registry.MustRegister("invalid", regen.Raw("("))
+	if _, err := registry.Build(); err == nil {
+		t.Errorf("expected an error")
+	}
+}
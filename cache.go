@@ -0,0 +1,47 @@
+package regen
+
+import (
+	"regexp"
+	"sync"
+)
+
+// compileCache contains the expressions compiled by CompileCached, keyed by their canonical rendering
+// (see Canonicalize) and by the renderings that they've been looked up by
+var compileCache sync.Map
+
+// CompileCached compiles re using the standard library's regexp package, returning the same
+// *regexp.Regexp for every expression with the same canonical form (see Canonicalize), no matter where
+// it was built. This avoids compiling the same pattern more than once when equivalent expressions are
+// built in several code paths. A *regexp.Regexp is safe for concurrent use, but note that the String
+// method of the result returns the canonical rendering, which can differ from re.Regexp().
+//
+// The cache is shared by the whole process and is never evicted, so it is only suitable for a bounded
+// set of patterns.
+func CompileCached(re Regexp) (*regexp.Regexp, error) {
+	pattern := re.Regexp()
+	if compiled, ok := compileCache.Load(pattern); ok {
+		return compiled.(*regexp.Regexp), nil
+	}
+	canonical := Canonicalize(re).Regexp()
+	compiled, ok := compileCache.Load(canonical)
+	if !ok {
+		c, err := regexp.Compile(canonical)
+		if err != nil {
+			return nil, err
+		}
+		compiled, _ = compileCache.LoadOrStore(canonical, c)
+	}
+	// later lookups by the same rendering don't need to canonicalize the expression
+	compileCache.Store(pattern, compiled)
+	return compiled.(*regexp.Regexp), nil
+}
+
+// MustCompileCached is like CompileCached, but panics if re cannot be compiled. It is intended for use
+// in package-level variable initialization.
+func MustCompileCached(re Regexp) *regexp.Regexp {
+	compiled, err := CompileCached(re)
+	if err != nil {
+		panic(err)
+	}
+	return compiled
+}
@@ -0,0 +1,78 @@
+package regen_test
+
+import (
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestCompileCached(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		a, b        regen.Regexp
+		shared      bool
+	}{
+		{
+			description: "the same expression built twice is compiled once",
+			a:           regen.Sequence(regen.String("id-"), regen.Digit.Repeat().Min(1)),
+			b:           regen.Sequence(regen.String("id-"), regen.Digit.Repeat().Min(1)),
+			shared:      true,
+		},
+		{
+			description: "expressions with the same canonical form are compiled once",
+			a:           regen.Sequence(regen.String("cache-"), regen.CharSet('b', 'a').Repeat()),
+			b:           regen.Sequence(regen.String("cache-"), regen.CharRange('a', 'b').Repeat()),
+			shared:      true,
+		},
+		{
+			description: "expressions with different groups are compiled separately",
+			a:           regen.Sequence(regen.String("group-"), regen.Digit.Group().CaptureAs("a")),
+			b:           regen.Sequence(regen.String("group-"), regen.Digit.Group().CaptureAs("b")),
+			shared:      false,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			a, err := regen.CompileCached(tt.a)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b, err := regen.CompileCached(tt.b)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if shared := a == b; shared != tt.shared {
+				t.Errorf("expected shared to be %v, got %v (%s and %s)", tt.shared, shared, a, b)
+			}
+			if again := regen.MustCompileCached(tt.a); again != a {
+				t.Errorf("expected compiling %s again to return the cached expression", tt.a.Regexp())
+			}
+		})
+	}
+}
+
+func TestCompileCached_Invalid(t *testing.T) {
+	if _, err := regen.CompileCached(regen.Raw("(")); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestCompileCached_Concurrent(t *testing.T) {
+	const n = 8
+	compiled := make([]*regexp.Regexp, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			compiled[i] = regen.MustCompileCached(regen.Sequence(regen.String("concurrent"), regen.Any))
+		}(i)
+	}
+	wg.Wait()
+	for i := 1; i < n; i++ {
+		if compiled[i] != compiled[0] {
+			t.Fatalf("expected every goroutine to get the same expression")
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package regen
+
+import "regexp/syntax"
+
+// Canonicalize returns an equivalent Regexp in a canonical form, so that expressions that are built
+// differently but are equivalent in the ways described below render identically. This makes rendered
+// patterns suitable for diffing and for use as cache keys. In addition to the rewrites made by
+// Simplify:
+//
+//   - character classes are rewritten as their sorted ranges, using predefined classes where
+//     possible, e.g. [ba0-9] becomes [ab\d] and [a-zA-Z] becomes [[:alpha:]]
+//   - ungreedy repetitions with a fixed count are made greedy, e.g. a{3}? becomes a{3}
+//
+// Flags and quantifiers are always rendered in a fixed order and form. Raw expressions and the order
+// of alternatives (which affects which one matches) are not changed.
+func Canonicalize(re Regexp) Regexp {
+	// Simplify can merge alternatives into classes, which are canonicalized again
+	return canonicalize(Simplify(canonicalize(re)))
+}
+
+// RenderCanonical renders the canonical form of re (see Canonicalize)
+func (d Dialect) RenderCanonical(re Regexp) (string, error) {
+	return d.Render(Canonicalize(re))
+}
+
+func canonicalize(re Regexp) Regexp {
+	switch node := re.(type) {
+	case CharClass:
+		return canonicalClass(node)
+	case repeatedRegexp:
+		if node.hasMax && node.hasMin && node.min == node.max {
+			node.ungreedy, node.cache = false, nil
+		}
+		re = node
+	}
+	kids := Children(re)
+	if len(kids) == 0 {
+		return re
+	}
+	canonical := make([]Regexp, len(kids))
+	for i, kid := range kids {
+		canonical[i] = canonicalize(kid)
+	}
+	return withChildren(re, canonical)
+}
+
+// canonicalClass returns the class matching the same runes as c, built from its sorted ranges
+func canonicalClass(c CharClass) Regexp {
+	parsed, err := syntax.Parse(c.Regexp(), syntax.Perl)
+	if err != nil {
+		return c
+	}
+	switch {
+	case parsed.Op == syntax.OpCharClass:
+		if len(parsed.Rune) == 0 {
+			return noMatch
+		}
+		return classFromRanges(parsed.Rune)
+	case parsed.Op == syntax.OpLiteral && len(parsed.Rune) == 1 && parsed.Flags&syntax.FoldCase == 0:
+		return CharSet(parsed.Rune[0])
+	}
+	return c
+}
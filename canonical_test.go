@@ -0,0 +1,86 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestCanonicalize(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		res      []regen.Regexp
+		expected string
+	}{
+		{
+			desc:     "class order",
+			res:      []regen.Regexp{regen.CharSet('b', 'a', 'c'), regen.CharSet('a', 'b', 'c'), regen.CharRange('a', 'c')},
+			expected: `[a-c]`,
+		},
+		{
+			desc: "predefined classes",
+			res: []regen.Regexp{
+				regen.Digit,
+				regen.CharRange('0', '9'),
+				regen.Union(regen.CharRange('5', '9'), regen.CharRange('0', '4')),
+				regen.ASCIICharClass("digit"),
+			},
+			expected: `\d`,
+		},
+		{
+			desc: "negated classes",
+			res: []regen.Regexp{
+				regen.Union(regen.CharSet('x'), regen.Digit).(regen.CharClass).Negate(),
+				regen.Union(regen.Digit, regen.CharSet('x')).(regen.CharClass).Negate(),
+			},
+			expected: `[^x\d]`,
+		},
+		{
+			desc: "literals",
+			res: []regen.Regexp{
+				regen.String("abc"),
+				regen.Sequence(regen.String("a"), regen.String("bc")),
+				regen.Sequence(regen.String("ab"), regen.String("c").Group().NoCapture()),
+			},
+			expected: `abc`,
+		},
+		{
+			desc: "quantifiers",
+			res: []regen.Regexp{
+				regen.Digit.Repeat().Min(2).Max(2),
+				regen.Digit.Repeat().Min(2).Max(2).Ungreedy(),
+				regen.Digit.Repeat().Min(1).Max(1).Group().NoCapture().Repeat().Min(2).Max(2).Ungreedy(),
+			},
+			expected: `\d{2}`,
+		},
+		{
+			desc: "flags",
+			res: []regen.Regexp{
+				regen.Sequence(regen.String("a"), regen.Any).Group().NoCapture().SetFlags(regen.FlagMatchNewLine | regen.FlagCaseInsensitive),
+				regen.Sequence(regen.String("a"), regen.Any).Group().NoCapture().SetFlags(regen.FlagCaseInsensitive | regen.FlagMatchNewLine),
+				regen.Sequence(regen.String("a").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive), regen.Any).Group().NoCapture().SetFlags(regen.FlagCaseInsensitive | regen.FlagMatchNewLine),
+			},
+			expected: `(?is:a.)`,
+		},
+		{
+			desc: "alternatives",
+			res: []regen.Regexp{
+				regen.OneOf(regen.String("b"), regen.String("a"), regen.String("cd")).Group().NoCapture(),
+				regen.OneOf(regen.CharSet('a', 'b'), regen.String("cd")).Group().NoCapture(),
+			},
+			expected: `(?:[ab]|cd)`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			for _, re := range tt.res {
+				rendered, err := regen.DialectRE2.RenderCanonical(re)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if rendered != tt.expected {
+					t.Errorf("expected %s to be rendered as %s, got %s", re.Regexp(), tt.expected, rendered)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package celregen exposes the patterns in a regen Registry to CEL (Common Expression Language)
+// environments, so that policies can refer to centrally managed patterns by name rather than
+// embedding raw regular expressions.
+package celregen
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/aoldershaw/regen"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// Library returns a CEL environment option that declares the following functions over the patterns
+// in r:
+//
+//	regen.matches(pattern_name, input) -> bool
+//	regen.extract(pattern_name, input, group) -> string
+//
+// regen.matches returns true if the registered pattern matches input. regen.extract returns the text
+// captured by the named group in the first match, or an empty string if the pattern does not match or
+// the group does not participate in the match. Both evaluate to an error if no pattern is registered
+// under the name, and regen.extract evaluates to an error if the pattern has no such group.
+//
+// The functions are qualified with "regen." to avoid conflicting with CEL's built-in matches function.
+// Patterns are looked up on each evaluation, so patterns registered after the environment is created
+// can be used.
+func Library(r *regen.Registry) cel.EnvOption {
+	return cel.Lib(&library{registry: r})
+}
+
+type library struct {
+	registry *regen.Registry
+	// compiled caches the compiled form of each pattern, keyed by its regular expression
+	compiled sync.Map
+}
+
+func (l *library) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("regen.matches",
+			cel.Overload("regen_matches_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(l.matches),
+			),
+		),
+		cel.Function("regen.extract",
+			cel.Overload("regen_extract_string_string_string", []*cel.Type{cel.StringType, cel.StringType, cel.StringType}, cel.StringType,
+				cel.FunctionBinding(l.extract),
+			),
+		),
+	}
+}
+
+func (l *library) ProgramOptions() []cel.ProgramOption {
+	return nil
+}
+
+func (l *library) matches(name, input ref.Val) ref.Val {
+	re, err := l.lookup(name)
+	if err != nil {
+		return err
+	}
+	return types.Bool(re.MatchString(string(input.(types.String))))
+}
+
+func (l *library) extract(args ...ref.Val) ref.Val {
+	re, err := l.lookup(args[0])
+	if err != nil {
+		return err
+	}
+	group := string(args[2].(types.String))
+	index := re.SubexpIndex(group)
+	if index < 0 {
+		return types.NewErr("celregen: pattern %q has no group %q", args[0], group)
+	}
+	match := re.FindStringSubmatch(string(args[1].(types.String)))
+	if match == nil {
+		return types.String("")
+	}
+	return types.String(match[index])
+}
+
+// lookup returns the compiled form of the pattern registered under name, or a CEL error
+func (l *library) lookup(name ref.Val) (*regexp.Regexp, ref.Val) {
+	entry, ok := l.registry.Lookup(string(name.(types.String)))
+	if !ok {
+		return nil, types.NewErr("celregen: no pattern registered as %q", name)
+	}
+	pattern := entry.Regexp.Regexp()
+	if re, ok := l.compiled.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, types.NewErr("celregen: pattern %q: %v", name, err)
+	}
+	l.compiled.Store(pattern, re)
+	return re, nil
+}
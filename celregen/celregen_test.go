@@ -0,0 +1,68 @@
+package celregen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+	"github.com/aoldershaw/regen/celregen"
+	"github.com/google/cel-go/cel"
+)
+
+func TestLibrary(t *testing.T) {
+	r := regen.NewRegistry()
+	r.MustRegister("semver", regen.Sequence(
+		regen.TextStart,
+		regen.Digit.Repeat().Min(1).Group().CaptureAs("major"),
+		regen.String("."),
+		regen.Digit.Repeat().Min(1).Group().CaptureAs("minor"),
+		regen.Sequence(regen.String("-"), regen.WordCharacter.Repeat().Min(1).Group().CaptureAs("pre")).Group().NoCapture().Optional(),
+		regen.TextEnd,
+	))
+	env, err := cel.NewEnv(
+		cel.Variable("version", cel.StringType),
+		celregen.Library(r),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tt := range []struct {
+		desc     string
+		expr     string
+		version  string
+		expected interface{}
+		err      string
+	}{
+		{desc: "matches", expr: `regen.matches("semver", version)`, version: "1.2", expected: true},
+		{desc: "does not match", expr: `regen.matches("semver", version)`, version: "v1.2", expected: false},
+		{desc: "extract", expr: `regen.extract("semver", version, "minor")`, version: "1.23-rc1", expected: "23"},
+		{desc: "extract in a policy", expr: `int(regen.extract("semver", version, "major")) >= 2`, version: "2.0", expected: true},
+		{desc: "extract non-participating group", expr: `regen.extract("semver", version, "pre")`, version: "1.2", expected: ""},
+		{desc: "extract without a match", expr: `regen.extract("semver", version, "major")`, version: "x", expected: ""},
+		{desc: "unknown pattern", expr: `regen.matches("uuid", version)`, version: "1.2", err: `celregen: no pattern registered as "uuid"`},
+		{desc: "unknown group", expr: `regen.extract("semver", version, "patch")`, version: "1.2", err: `celregen: pattern "semver" has no group "patch"`},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			ast, issues := env.Compile(tt.expr)
+			if issues.Err() != nil {
+				t.Fatalf("unexpected error: %v", issues.Err())
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			out, _, err := prg.Eval(map[string]interface{}{"version": tt.version})
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.Value() != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, out.Value())
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package regen
+
+// AWSARN returns a Regexp that matches an Amazon Resource Name, e.g.
+// arn:aws:s3:::my-bucket or arn:aws:iam::123456789012:role/admin.
+// The following named groups are captured: partition, service, region, account and resource.
+// Region and account may be empty, as they are for several global services.
+func AWSARN() Regexp {
+	lowerAlnumDash := Union(CharRange('a', 'z'), CharRange('0', '9'), CharSet('-'))
+	return Sequence(
+		String("arn:"),
+		Sequence(String("aws"), Union(CharRange('a', 'z'), CharSet('-')).Repeat()).Group().CaptureAs("partition"),
+		String(":"),
+		lowerAlnumDash.Repeat().Min(1).Group().CaptureAs("service"),
+		String(":"),
+		lowerAlnumDash.Repeat().Group().CaptureAs("region"),
+		String(":"),
+		OneOf(Digit.Repeat().Exactly(12), String("aws")).Group().NoCapture().Optional().Group().CaptureAs("account"),
+		String(":"),
+		Any.Repeat().Min(1).Group().CaptureAs("resource"),
+	)
+}
+
+// GCPResourceName returns a Regexp that matches a full Google Cloud resource name, e.g.
+// //compute.googleapis.com/projects/my-project/zones/us-east1-b/instances/vm-1.
+// The following named groups are captured: service, project, location and resource.
+// Location is empty for resources that are not regional or zonal, and resource holds the
+// remainder of the path after the project (and location, if any).
+func GCPResourceName() Regexp {
+	lowerAlnumDash := Union(CharRange('a', 'z'), CharRange('0', '9'), CharSet('-'))
+	segment := CharSet('/').Negate().Repeat().Min(1)
+	return Sequence(
+		String("//"),
+		lowerAlnumDash.Repeat().Min(1).Group().CaptureAs("service"),
+		String(".googleapis.com/projects/"),
+		segment.Group().CaptureAs("project"),
+		Sequence(
+			String("/"),
+			OneOf(String("locations"), String("regions"), String("zones")).Group().NoCapture(),
+			String("/"),
+			segment.Group().CaptureAs("location"),
+		).Group().NoCapture().Optional(),
+		Sequence(
+			String("/"),
+			Any.Repeat().Min(1).Group().CaptureAs("resource"),
+		).Group().NoCapture().Optional(),
+	)
+}
+
+// AzureResourceID returns a Regexp that matches an Azure Resource Manager resource ID, e.g.
+// /subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm-1.
+// The following named groups are captured: subscription, resource_group, provider and resource.
+// Path segment names are matched case-insensitively, since Azure treats them that way.
+func AzureResourceID() Regexp {
+	hex := Union(CharRange('0', '9'), CharRange('a', 'f'), CharRange('A', 'F'))
+	segment := CharSet('/').Negate().Repeat().Min(1)
+	keyword := func(s string) Regexp {
+		return String(s).Group().NoCapture().SetFlags(FlagCaseInsensitive)
+	}
+	return Sequence(
+		keyword("/subscriptions/"),
+		Sequence(
+			hex.Repeat().Exactly(8), String("-"),
+			hex.Repeat().Exactly(4), String("-"),
+			hex.Repeat().Exactly(4), String("-"),
+			hex.Repeat().Exactly(4), String("-"),
+			hex.Repeat().Exactly(12),
+		).Group().CaptureAs("subscription"),
+		Sequence(
+			keyword("/resourceGroups/"),
+			segment.Group().CaptureAs("resource_group"),
+			Sequence(
+				keyword("/providers/"),
+				segment.Group().CaptureAs("provider"),
+				String("/"),
+				Any.Repeat().Min(1).Group().CaptureAs("resource"),
+			).Group().NoCapture().Optional(),
+		).Group().NoCapture().Optional(),
+	)
+}
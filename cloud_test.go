@@ -0,0 +1,107 @@
+package regen_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestCloudResourceNames(t *testing.T) {
+	tests := []struct {
+		description string
+		re          regen.Regexp
+		input       string
+		expected    map[string]string
+	}{
+		{
+			description: "AWSARN captures each component",
+			re:          regen.AWSARN(),
+			input:       "arn:aws-us-gov:iam::123456789012:role/admin",
+			expected: map[string]string{
+				"partition": "aws-us-gov",
+				"service":   "iam",
+				"region":    "",
+				"account":   "123456789012",
+				"resource":  "role/admin",
+			},
+		},
+		{
+			description: "AWSARN allows resources containing colons",
+			re:          regen.AWSARN(),
+			input:       "arn:aws:logs:us-east-1:123456789012:log-group:/aws/lambda/fn:*",
+			expected: map[string]string{
+				"partition": "aws",
+				"service":   "logs",
+				"region":    "us-east-1",
+				"account":   "123456789012",
+				"resource":  "log-group:/aws/lambda/fn:*",
+			},
+		},
+		{
+			description: "AWSARN allows AWS managed accounts",
+			re:          regen.AWSARN(),
+			input:       "arn:aws:iam::aws:policy/ReadOnlyAccess",
+			expected: map[string]string{
+				"account":  "aws",
+				"resource": "policy/ReadOnlyAccess",
+			},
+		},
+		{
+			description: "GCPResourceName captures a zonal resource",
+			re:          regen.GCPResourceName(),
+			input:       "//compute.googleapis.com/projects/my-project/zones/us-east1-b/instances/vm-1",
+			expected: map[string]string{
+				"service":  "compute",
+				"project":  "my-project",
+				"location": "us-east1-b",
+				"resource": "instances/vm-1",
+			},
+		},
+		{
+			description: "GCPResourceName captures a global resource",
+			re:          regen.GCPResourceName(),
+			input:       "//pubsub.googleapis.com/projects/my-project/topics/events",
+			expected: map[string]string{
+				"service":  "pubsub",
+				"project":  "my-project",
+				"location": "",
+				"resource": "topics/events",
+			},
+		},
+		{
+			description: "AzureResourceID captures each component",
+			re:          regen.AzureResourceID(),
+			input:       "/subscriptions/0b1f6471-1bf0-4dda-aec3-cb9272f09590/resourceGroups/rg-prod/providers/Microsoft.Compute/virtualMachines/vm-1",
+			expected: map[string]string{
+				"subscription":   "0b1f6471-1bf0-4dda-aec3-cb9272f09590",
+				"resource_group": "rg-prod",
+				"provider":       "Microsoft.Compute",
+				"resource":       "virtualMachines/vm-1",
+			},
+		},
+		{
+			description: "AzureResourceID matches path keywords case-insensitively",
+			re:          regen.AzureResourceID(),
+			input:       "/SUBSCRIPTIONS/0b1f6471-1bf0-4dda-aec3-cb9272f09590/resourcegroups/rg-prod",
+			expected: map[string]string{
+				"subscription":   "0b1f6471-1bf0-4dda-aec3-cb9272f09590",
+				"resource_group": "rg-prod",
+			},
+		},
+	}
+	for _, tt := range tests {
+		re := regexp.MustCompile(`^` + tt.re.Regexp() + `$`)
+		groups, ok := namedSubmatches(re, tt.input)
+		if !ok {
+			t.Errorf(`cloud test "%s" failed: "%s" did not match "%s"`, tt.description, re, tt.input)
+			continue
+		}
+		for name, expected := range tt.expected {
+			actual := groups[name]
+			if actual != expected {
+				t.Errorf(`cloud test "%s" failed: group "%s" got "%s", expected "%s"`, tt.description, name, actual, expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/aoldershaw/regen"
+)
+
+// convert translates a regular expression into regen builder code, or builder code into a regular
+// expression
+func convert(s streams, args []string) error {
+	flags := newFlagSet(s, "convert", "[-to go|regexp] [input]")
+	to := flags.String("to", "", "the output, go or regexp (defaults to the opposite of the input)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	input, err := readInput(s, flags.Args())
+	if err != nil {
+		return err
+	}
+	if *to == "" {
+		*to = "go"
+		if isGoCode(input) {
+			*to = "regexp"
+		}
+	}
+
+	switch *to {
+	case "go":
+		src, err := regen.GenerateGo(input)
+		if err != nil {
+			return err
+		}
+		generated, err := regen.ParseGo(src)
+		if err != nil {
+			return err
+		}
+		if err := checkGroups(input, generated.Regexp()); err != nil {
+			return err
+		}
+		formatted, err := format.Source([]byte(src))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(s.out, string(formatted))
+	case "regexp":
+		re, err := regen.ParseGo(input)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(s.out, re.Regexp())
+	default:
+		return fmt.Errorf("unknown output %q (expected go or regexp)", *to)
+	}
+	return nil
+}
+
+// checkGroups returns an error if the converted expression numbers or names its groups differently
+// from the original, since callers using submatch indices would silently break
+func checkGroups(original, converted string) error {
+	a, err := regexp.Compile(original)
+	if err != nil {
+		return err
+	}
+	b, err := regexp.Compile(converted)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(a.SubexpNames(), b.SubexpNames()) {
+		return fmt.Errorf("refusing to convert: %s would change the groups of %s", converted, original)
+	}
+	return nil
+}
+
+// isGoCode returns true if input is regen builder code rather than a regular expression
+func isGoCode(input string) bool {
+	return strings.HasPrefix(input, "regen.")
+}
+
+// parseInput parses a regular expression or builder code
+func parseInput(input string) (regen.Regexp, error) {
+	if isGoCode(input) {
+		return regen.ParseGo(input)
+	}
+	return regen.Parse(input)
+}
+
+// readInput returns the arguments joined by spaces, or standard input if there are none, without
+// surrounding whitespace
+func readInput(s streams, args []string) (string, error) {
+	if len(args) > 0 {
+		return strings.Join(args, " "), nil
+	}
+	input, err := ioutil.ReadAll(s.in)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(input)), nil
+}
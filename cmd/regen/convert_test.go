@@ -0,0 +1,103 @@
+package main
+
+import "testing"
+
+func TestConvert(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		stdin    string
+		args     []string
+		expected string
+	}{
+		{
+			desc:     "regexp to go",
+			args:     []string{"convert", `^v\d+$`},
+			expected: "regen.Sequence(regen.LineStart, regen.String(\"v\"), regen.Digit.Repeat().Min(1), regen.LineEnd)\n",
+		},
+		{
+			desc:  "long regexp from stdin",
+			stdin: "  ^(?P<major>\\d+)\\.(?P<minor>\\d+)(?:-(?P<pre>[0-9A-Za-z.]+))?$\n",
+			args:  []string{"convert"},
+			expected: `regen.Sequence(
+	regen.LineStart,
+	regen.Digit.Repeat().Min(1).Group().CaptureAs("major"),
+	regen.String("."),
+	regen.Digit.Repeat().Min(1).Group().CaptureAs("minor"),
+	regen.Sequence(
+		regen.String("-"),
+		regen.Union(
+			regen.CharSet('.'),
+			regen.Digit,
+			regen.CharRange('A', 'Z'),
+			regen.CharRange('a', 'z'),
+		).Repeat().Min(1).Group().CaptureAs("pre"),
+	).Group().NoCapture().Optional(),
+	regen.LineEnd,
+)
+`,
+		},
+		{
+			desc:     "go to regexp",
+			args:     []string{"convert", `regen.Sequence(regen.String("a.b"),`, `regen.Digit.Repeat())`},
+			expected: "a\\.b\\d*\n",
+		},
+		{
+			desc:     "explicit output",
+			args:     []string{"convert", "-to", "go", "regen"},
+			expected: "regen.String(\"regen\")\n",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			code, stdout, stderr := runCommand(tt.stdin, tt.args...)
+			if code != 0 {
+				t.Fatalf("unexpected exit code %d: %s", code, stderr)
+			}
+			if stdout != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, stdout)
+			}
+		})
+	}
+}
+
+func TestConvert_Invalid(t *testing.T) {
+	for _, tt := range []struct {
+		desc   string
+		args   []string
+		stderr string
+	}{
+		{desc: "invalid go", args: []string{"convert", "regen.Bogus"}, stderr: "regen convert: regen: unknown variable regen.Bogus\n"},
+		{desc: "unknown output", args: []string{"convert", "-to", "perl", "a"}, stderr: "regen convert: unknown output \"perl\" (expected go or regexp)\n"},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			code, _, stderr := runCommand("", tt.args...)
+			if code != 1 {
+				t.Errorf("expected exit code 1, got %d", code)
+			}
+			if stderr != tt.stderr {
+				t.Errorf("expected %q, got %q", tt.stderr, stderr)
+			}
+		})
+	}
+}
+
+func TestCheckGroups(t *testing.T) {
+	for _, tt := range []struct {
+		original  string
+		converted string
+		valid     bool
+	}{
+		{original: `(?:ab)+(x)`, converted: `(?:ab)+(x)`, valid: true},
+		{original: `(?P<a>a)b`, converted: `(?P<a>a)(?:b)`, valid: true},
+		{original: `(?:ab)+(x)`, converted: `(ab)+(x)`},
+		{original: `(?P<a>a)`, converted: `(?P<b>a)`},
+		{original: `(a)`, converted: `a`},
+	} {
+		err := checkGroups(tt.original, tt.converted)
+		if tt.valid && err != nil {
+			t.Errorf("%s -> %s: unexpected error: %v", tt.original, tt.converted, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s -> %s: expected an error", tt.original, tt.converted)
+		}
+	}
+}
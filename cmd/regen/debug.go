@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/aoldershaw/regen"
+)
+
+// debug matches a regular expression (or builder code) against input text, highlighting the text
+// captured by each named group (see regen.Debug)
+func debug(s streams, args []string) error {
+	flags := newFlagSet(s, "debug", "[-no-color] pattern [input]")
+	noColor := flags.Bool("no-color", false, "identify groups using only markers (the default if the output isn't a terminal)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("a pattern is required")
+	}
+	re, err := parseInput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	var opts []regen.DebugOption
+	if *noColor || !isTerminal(s.out) {
+		opts = append(opts, regen.WithoutColor())
+	}
+	if flags.NArg() > 1 {
+		return regen.Debug(s.out, re, strings.Join(flags.Args()[1:], " "), opts...)
+	}
+	// each line of standard input is matched separately
+	scanner := bufio.NewScanner(s.in)
+	for scanner.Scan() {
+		if err := regen.Debug(s.out, re, scanner.Text(), opts...); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// isTerminal returns true if w is a terminal
+func isTerminal(w interface{}) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,52 @@
+package main
+
+import "testing"
+
+func TestDebug(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		stdin    string
+		args     []string
+		expected string
+	}{
+		{
+			desc: "input argument",
+			args: []string{"debug", `(?P<key>\w+)=(?P<value>\d+)`, "retries=3"},
+			expected: `match [0:9]
+retries=3
+1111111-2
+[1] key [0:7] "retries"
+[2] value [8:9] "3"
+`,
+		},
+		{
+			desc:  "lines of standard input",
+			stdin: "n=1\nn=x\n",
+			args:  []string{"debug", "-no-color", `regen.Sequence(regen.String("n="), regen.Digit.Group().CaptureAs("n"))`},
+			expected: `match [0:3]
+n=1
+--1
+[1] n [2:3] "1"
+no match, the longest partial match is [0:2]
+n=x
+^^
+`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			code, stdout, stderr := runCommand(tt.stdin, tt.args...)
+			if code != 0 {
+				t.Fatalf("unexpected exit code %d: %s", code, stderr)
+			}
+			if stdout != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, stdout)
+			}
+		})
+	}
+}
+
+func TestDebug_NoPattern(t *testing.T) {
+	if code, _, _ := runCommand("", "debug"); code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+}
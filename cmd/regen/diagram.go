@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/aoldershaw/regen"
+)
+
+// diagramFormats render a diagram of an expression
+var diagramFormats = map[string]func(regen.Regexp) string{
+	"ascii": regen.ToRailroadASCII,
+	"svg":   regen.ToRailroadSVG,
+	"dot":   regen.ToDOT,
+}
+
+// diagram draws a regular expression (or builder code) as a railroad diagram, or as a Graphviz graph of
+// its structure
+func diagram(s streams, args []string) error {
+	flags := newFlagSet(s, "diagram", "[-o file] [-format ascii|svg|dot] [pattern]")
+	output := flags.String("o", "", "the file to write the diagram to (defaults to standard output)")
+	format := flags.String("format", "", "the format of the diagram, ascii, svg or dot (defaults to the extension of -o, or ascii)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = "ascii"
+		if ext := strings.TrimPrefix(filepath.Ext(*output), "."); diagramFormats[ext] != nil {
+			*format = ext
+		}
+	}
+	render, ok := diagramFormats[*format]
+	if !ok {
+		return fmt.Errorf("unknown format %q (expected ascii, svg or dot)", *format)
+	}
+	input, err := readInput(s, flags.Args())
+	if err != nil {
+		return err
+	}
+	re, err := parseInput(input)
+	if err != nil {
+		return err
+	}
+	out := render(re)
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	if *output == "" {
+		fmt.Fprint(s.out, out)
+		return nil
+	}
+	return ioutil.WriteFile(*output, []byte(out), 0644)
+}
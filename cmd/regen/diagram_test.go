@@ -0,0 +1,62 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiagram(t *testing.T) {
+	code, stdout, stderr := runCommand("", "diagram", `v\d+`)
+	if code != 0 {
+		t.Fatalf("unexpected exit code %d: %s", code, stderr)
+	}
+	expected := "o-\"v\"---+-[\\d]-+--o\n        +<-----+\n"
+	if stdout != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, stdout)
+	}
+}
+
+func TestDiagram_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "regen-diagram")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, tt := range []struct {
+		file   string
+		args   []string
+		prefix string
+	}{
+		{file: "out.svg", prefix: "<svg"},
+		{file: "out.dot", prefix: "digraph regen {"},
+		{file: "out.txt", args: []string{"-format", "svg"}, prefix: "<svg"},
+		{file: "out.txt", prefix: `o-"a"-`},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			args := append([]string{"diagram", "-o", path}, tt.args...)
+			code, _, stderr := runCommand("regen.Sequence(regen.String(\"a\"), regen.Any)", args...)
+			if code != 0 {
+				t.Fatalf("unexpected exit code %d: %s", code, stderr)
+			}
+			contents, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(contents), tt.prefix) {
+				t.Errorf("expected the diagram to begin with %q, got:\n%s", tt.prefix, contents)
+			}
+		})
+	}
+}
+
+func TestDiagram_UnknownFormat(t *testing.T) {
+	code, _, stderr := runCommand("", "diagram", "-format", "png", "a")
+	if code != 1 || stderr != "regen diagram: unknown format \"png\" (expected ascii, svg or dot)\n" {
+		t.Errorf("unexpected result %d: %q", code, stderr)
+	}
+}
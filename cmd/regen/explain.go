@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/aoldershaw/regen"
+)
+
+// explain lays out a regular expression (or builder code) over multiple lines using regen.Format, with
+// each element of a sequence or alternation on its own line and nested groups indented
+func explain(s streams, args []string) error {
+	flags := newFlagSet(s, "explain", "[pattern]")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	input, err := readInput(s, flags.Args())
+	if err != nil {
+		return err
+	}
+	re, err := parseInput(input)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(s.out, regen.Format(re))
+	return nil
+}
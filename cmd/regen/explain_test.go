@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestExplain(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		stdin    string
+		args     []string
+		expected string
+	}{
+		{
+			desc: "regexp",
+			args: []string{"explain", `^(?P<key>\w+)=(?:\d+|true)$`},
+			expected: `^
+(?P<key>\w+)
+=
+(?:
+  \d+
+  |
+  true
+)
+$
+`,
+		},
+		{
+			desc:  "builder code",
+			stdin: `regen.Sequence(regen.Annotate(regen.TextStart, "start"), regen.String("id"))`,
+			args:  []string{"explain"},
+			expected: `\A  # start
+id
+`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			code, stdout, stderr := runCommand(tt.stdin, tt.args...)
+			if code != 0 {
+				t.Fatalf("unexpected exit code %d: %s", code, stderr)
+			}
+			if stdout != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, stdout)
+			}
+		})
+	}
+}
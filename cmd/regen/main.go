@@ -0,0 +1,92 @@
+// Command regen is a command-line interface to the regen package, for exploring and migrating
+// regular expressions without writing a Go program.
+//
+// Usage:
+//
+//	regen <command> [arguments]
+//
+// Run regen help to list the commands, and regen <command> -h for the arguments of a command.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// streams are the standard streams used by a command
+type streams struct {
+	in  io.Reader
+	out io.Writer
+	err io.Writer
+}
+
+type command struct {
+	summary string
+	run     func(s streams, args []string) error
+}
+
+var commands = map[string]command{
+	"convert": {summary: "convert between a regular expression and regen builder code", run: convert},
+	"debug":   {summary: "highlight the text captured by each named group of a match", run: debug},
+	"diagram": {summary: "draw a railroad diagram of an expression", run: diagram},
+	"explain": {summary: "lay out the structure of an expression over multiple lines", run: explain},
+	"repl":    {summary: "interactively build expressions and test them against sample lines", run: repl},
+}
+
+func main() {
+	os.Exit(run(streams{in: os.Stdin, out: os.Stdout, err: os.Stderr}, os.Args[1:]))
+}
+
+// run runs the command named by args[0], returning the exit code
+func run(s streams, args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(s.err)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(s.err, "regen: unknown command %q\n", args[0])
+		usage(s.err)
+		return 2
+	}
+	if err := cmd.run(s, args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(s.err, "regen %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: regen <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "\t%-10s %s\n", name, commands[name].summary)
+	}
+}
+
+// newFlagSet returns a flag set for a command, which reports errors to s.err
+func newFlagSet(s streams, name, args string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(s.err)
+	flags.Usage = func() {
+		fmt.Fprintf(s.err, "usage: regen %s %s\n", name, args)
+		flags.PrintDefaults()
+	}
+	return flags
+}
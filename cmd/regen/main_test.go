@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// runCommand runs regen with the given arguments and standard input, returning the exit code and
+// the standard output and error
+func runCommand(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(streams{in: strings.NewReader(stdin), out: &stdout, err: &stderr}, args)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	for _, tt := range []struct {
+		desc   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{desc: "no command", code: 2, stderr: "usage: regen <command>"},
+		{desc: "help", args: []string{"help"}, code: 0, stderr: "convert "},
+		{desc: "unknown command", args: []string{"frobnicate"}, code: 2, stderr: `regen: unknown command "frobnicate"`},
+		{desc: "command help", args: []string{"convert", "-h"}, code: 0, stderr: "usage: regen convert"},
+		{desc: "command error", args: []string{"convert", "("}, code: 1, stderr: "regen convert: error parsing regexp"},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			code, _, stderr := runCommand("", tt.args...)
+			if code != tt.code {
+				t.Errorf("expected exit code %d, got %d", tt.code, code)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("expected standard error to contain %q, got %q", tt.stderr, stderr)
+			}
+		})
+	}
+}
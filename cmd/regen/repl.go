@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aoldershaw/regen"
+)
+
+const replHelp = `Enter builder code to render it, e.g. regen.Digit.Repeat().Min(1)
+  name = <code>   define a variable that later code can refer to
+  :test           match the following lines against the pattern, until an empty line
+  :vars           list the variables
+  :help           show this help
+  :quit           exit (or end the input)
+`
+
+// repl is an interactive session for building expressions and testing them against sample lines
+func repl(s streams, args []string) error {
+	flags := newFlagSet(s, "repl", "")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	r := &replSession{streams: s, vars: make(map[string]regen.Regexp)}
+	fmt.Fprint(s.out, replHelp)
+	scanner := bufio.NewScanner(s.in)
+	for r.prompt(); scanner.Scan(); r.prompt() {
+		if quit := r.handle(scanner.Text()); quit {
+			return nil
+		}
+	}
+	fmt.Fprintln(s.out)
+	return scanner.Err()
+}
+
+type replSession struct {
+	streams
+	vars map[string]regen.Regexp
+	// current is the most recently entered expression, and matcher is its compiled form
+	current regen.Regexp
+	matcher *regexp.Regexp
+	// testing is true while sample lines are being entered
+	testing bool
+}
+
+func (r *replSession) prompt() {
+	if r.testing {
+		fmt.Fprint(r.out, "test> ")
+		return
+	}
+	fmt.Fprint(r.out, "regen> ")
+}
+
+// handle handles a line of input, returning true if the session should end
+func (r *replSession) handle(line string) bool {
+	if r.testing {
+		if line == "" {
+			r.testing = false
+			return false
+		}
+		r.test(line)
+		return false
+	}
+	line = strings.TrimSpace(line)
+	switch line {
+	case "":
+	case ":quit", ":q":
+		return true
+	case ":help":
+		fmt.Fprint(r.out, replHelp)
+	case ":vars":
+		var names []string
+		for name := range r.vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(r.out, "%s = %s\n", name, r.vars[name].Regexp())
+		}
+	case ":test":
+		if r.current == nil {
+			r.error(errors.New("there is no pattern to test, enter some code first"))
+			return false
+		}
+		r.testing = true
+	default:
+		if strings.HasPrefix(line, ":") {
+			r.error(fmt.Errorf("unknown command %s (see :help)", line))
+			return false
+		}
+		r.evaluate(line)
+	}
+	return false
+}
+
+// evaluate evaluates builder code, optionally assigned to a variable, making it the current pattern
+func (r *replSession) evaluate(line string) {
+	name, src := "", line
+	if i := strings.Index(line, "="); i > 0 {
+		if candidate := strings.TrimSpace(line[:i]); token.IsIdentifier(candidate) {
+			name, src = candidate, line[i+1:]
+		}
+	}
+	re, err := regen.ParseGo(src, regen.WithGoVariables(r.vars))
+	if err != nil {
+		r.error(err)
+		return
+	}
+	matcher, err := regexp.Compile(re.Regexp())
+	if err != nil {
+		r.error(err)
+		return
+	}
+	if name != "" {
+		r.vars[name] = re
+	}
+	r.current, r.matcher = re, matcher
+	fmt.Fprintln(r.out, re.Regexp())
+}
+
+// test matches a sample line against the current pattern, showing the named groups that participated
+func (r *replSession) test(line string) {
+	match := r.matcher.FindStringSubmatchIndex(line)
+	if match == nil {
+		fmt.Fprintln(r.out, "no match")
+		return
+	}
+	fmt.Fprintf(r.out, "match [%d:%d] %q\n", match[0], match[1], line[match[0]:match[1]])
+	for i, name := range r.matcher.SubexpNames() {
+		if name == "" || match[2*i] < 0 {
+			continue
+		}
+		fmt.Fprintf(r.out, "  %s = %q\n", name, line[match[2*i]:match[2*i+1]])
+	}
+}
+
+func (r *replSession) error(err error) {
+	fmt.Fprintf(r.out, "error: %v\n", err)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRepl(t *testing.T) {
+	input := strings.Join([]string{
+		`key = regen.WordCharacter.Repeat().Min(1).Group().CaptureAs("key")`,
+		`value = regen.Digit.Repeat().Min(1).Group().CaptureAs("value")`,
+		`regen.Sequence(key, regen.String("="), value.Optional())`,
+		`:test`,
+		`x a=12`,
+		`b=`,
+		`!`,
+		``,
+		`:vars`,
+		`regen.Bogus`,
+		`:bogus`,
+		`:quit`,
+		`regen.Any`,
+	}, "\n")
+	code, stdout, stderr := runCommand(input, "repl")
+	if code != 0 {
+		t.Fatalf("unexpected exit code %d: %s", code, stderr)
+	}
+	expected := replHelp + `regen> (?P<key>\w+)
+regen> (?P<value>\d+)
+regen> (?P<key>\w+)=(?P<value>\d+)?
+regen> test> match [2:6] "a=12"
+  key = "a"
+  value = "12"
+test> match [0:2] "b="
+  key = "b"
+test> no match
+test> regen> key = (?P<key>\w+)
+value = (?P<value>\d+)
+regen> error: regen: unknown variable regen.Bogus
+regen> error: unknown command :bogus (see :help)
+regen> `
+	if stdout != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, stdout)
+	}
+}
+
+func TestRepl_NoPattern(t *testing.T) {
+	_, stdout, _ := runCommand(":test\n", "repl")
+	if expected := replHelp + "regen> error: there is no pattern to test, enter some code first\nregen> \n"; stdout != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, stdout)
+	}
+}
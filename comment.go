@@ -0,0 +1,54 @@
+package regen
+
+// CommentOption configures the comments matched by LineComment and BlockComment
+type CommentOption func(*commentConfig)
+
+type commentConfig struct {
+	literals []Regexp
+}
+
+// WithStringLiterals makes the pattern also match the given string literals (e.g. Quoted()), so that
+// text within them that looks like a comment, such as "http://example.com", isn't mistaken for one when
+// finding all of the matches in source code. The comment is then captured by the group named comment,
+// which doesn't participate in matches of literals:
+//
+//	re := regen.LineComment("//", regen.WithStringLiterals(regen.Quoted(), regen.Quoted(regen.WithQuoteChars('\''))))
+//	for _, result := range regen.MustCompile(re).FindAllResults(source, -1) {
+//		if comment, ok := result.Capture("comment"); ok {
+//			...
+//		}
+//	}
+//
+// Literals should include every kind of literal that can contain quotes or comment delimiters, such as
+// character and raw string literals, otherwise comments may still be found within them.
+func WithStringLiterals(literals ...Regexp) CommentOption {
+	return func(c *commentConfig) {
+		c.literals = append(c.literals, literals...)
+	}
+}
+
+// LineComment returns a Regexp that matches a comment that starts with prefix and continues until the end
+// of the line, e.g. LineComment("//") or LineComment("#"). The comment doesn't include the newline.
+func LineComment(prefix string, opts ...CommentOption) Regexp {
+	return commentPattern(Sequence(String(prefix), CharSet('\n').Negate().Repeat()), opts)
+}
+
+// BlockComment returns a Regexp that matches a comment from open to the first close after it, which may
+// span lines, e.g. BlockComment("/*", "*/"). Nested comments are not supported: in /* a /* b */ c */, the
+// comment ends after b, which is correct for C and Go, but not for languages that allow nesting (such as
+// Rust and Swift).
+func BlockComment(open, close string, opts ...CommentOption) Regexp {
+	text := Any.Group().NoCapture().SetFlags(FlagMatchNewLine).Repeat().Ungreedy()
+	return commentPattern(Sequence(String(open), text, String(close)), opts)
+}
+
+func commentPattern(comment Regexp, opts []CommentOption) Regexp {
+	var c commentConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if len(c.literals) == 0 {
+		return comment
+	}
+	return OneOf(append(c.literals, comment.Group().CaptureAs("comment"))...).Group().NoCapture()
+}
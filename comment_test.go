@@ -0,0 +1,68 @@
+package regen_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestComments(t *testing.T) {
+	tests := []struct {
+		description string
+		re          regen.Regexp
+		expected    string
+		input       string
+		comments    []string
+	}{
+		{
+			description: "line comments end at the end of the line",
+			re:          regen.LineComment("//"),
+			expected:    `//[^\x{A}]*`,
+			input:       "a := 1 // one\nb := 2 // two // 2",
+			comments:    []string{"// one", "// two // 2"},
+		},
+		{
+			description: "line comments with other prefixes",
+			re:          regen.LineComment("#"),
+			input:       "x = 1  # set x\n# done",
+			comments:    []string{"# set x", "# done"},
+		},
+		{
+			description: "block comments end at the first close",
+			re:          regen.BlockComment("/*", "*/"),
+			expected:    `/\*(?s:.)*?\*/`,
+			input:       "/* a\n * b */ x /* c /* d */ e */",
+			comments:    []string{"/* a\n * b */", "/* c /* d */"},
+		},
+		{
+			description: "comments within string literals are skipped",
+			re:          regen.LineComment("//", regen.WithStringLiterals(regen.Quoted())),
+			input:       `url := "http://example.com" // the "home" page`,
+			comments:    []string{`// the "home" page`},
+		},
+		{
+			description: "string literals within block comments are ignored",
+			re:          regen.BlockComment("/*", "*/", regen.WithStringLiterals(regen.Quoted(), regen.Quoted(regen.WithQuoteChars('\'')))),
+			input:       `f("/* no */", '"') /* "yes */`,
+			comments:    []string{`/* "yes */`},
+		},
+	}
+	for _, tt := range tests {
+		if tt.expected != "" && tt.re.Regexp() != tt.expected {
+			t.Errorf(`comment test "%s" failed: got %s, expected %s`, tt.description, tt.re.Regexp(), tt.expected)
+		}
+		_, hasLiterals := regen.GroupIndex(tt.re, "comment")
+		var comments []string
+		for _, result := range regen.MustCompile(tt.re).FindAllResults(tt.input, -1) {
+			if !hasLiterals {
+				comments = append(comments, tt.input[result.Span.Start:result.Span.End])
+			} else if comment, ok := result.Capture("comment"); ok {
+				comments = append(comments, comment.Text)
+			}
+		}
+		if !reflect.DeepEqual(comments, tt.comments) {
+			t.Errorf(`comment test "%s" failed: got %q, expected %q`, tt.description, comments, tt.comments)
+		}
+	}
+}
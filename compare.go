@@ -0,0 +1,100 @@
+package regen
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Report describes how the outcome of matching a corpus changes between two Sets (see Compare)
+type Report struct {
+	// Lines is the number of lines in the corpus
+	Lines int
+	// Changes contains the lines whose outcome changed, in the order they appear in the corpus
+	Changes []Change
+}
+
+// Change is a line of a corpus that is matched differently by two Sets
+type Change struct {
+	// Line is the line number, starting from 1
+	Line int
+	Text string
+	Old  Outcome
+	New  Outcome
+}
+
+// Outcome is the result of matching a line against a Set
+type Outcome struct {
+	// Pattern is the name of the matching pattern, or empty if no pattern matched
+	Pattern string `json:"pattern"`
+	// Fields contains the text captured by each named group of the matching pattern
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+func (o Outcome) String() string {
+	if o.Pattern == "" {
+		return "no match"
+	}
+	names := make([]string, 0, len(o.Fields))
+	for name := range o.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	sb.WriteString(o.Pattern)
+	for _, name := range names {
+		fmt.Fprintf(&sb, " %s=%q", name, o.Fields[name])
+	}
+	return sb.String()
+}
+
+func (o Outcome) equal(other Outcome) bool {
+	if o.Pattern != other.Pattern || len(o.Fields) != len(other.Fields) {
+		return false
+	}
+	for name, value := range o.Fields {
+		if otherValue, ok := other.Fields[name]; !ok || otherValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare matches each line of corpus against oldSet and newSet, reporting the lines for which the
+// matching pattern or the captured fields differ. This allows changes to a Set to be validated against
+// recorded input (e.g. production logs) before they are rolled out.
+func Compare(oldSet, newSet *Set, corpus io.Reader) (*Report, error) {
+	report := &Report{}
+	scanner := bufio.NewScanner(corpus)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		report.Lines++
+		text := scanner.Text()
+		oldOutcome, newOutcome := outcome(oldSet, text), outcome(newSet, text)
+		if !oldOutcome.equal(newOutcome) {
+			report.Changes = append(report.Changes, Change{Line: report.Lines, Text: text, Old: oldOutcome, New: newOutcome})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func outcome(s *Set, text string) Outcome {
+	pattern, fields, _ := s.MatchFields(text)
+	return Outcome{Pattern: pattern, Fields: fields}
+}
+
+// WriteTo writes a human-readable summary of the report to w
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d of %d lines changed\n", len(r.Changes), r.Lines)
+	for _, c := range r.Changes {
+		fmt.Fprintf(&sb, "line %d: %s\n  - %s\n  + %s\n", c.Line, c.Text, c.Old, c.New)
+	}
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
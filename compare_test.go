@@ -0,0 +1,62 @@
+package regen_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestCompare(t *testing.T) {
+	level := func(levels ...string) regen.Regexp {
+		return regen.Sequence(
+			regen.LineStart,
+			regen.OneOfStrings(levels...).Group().CaptureAs("level"),
+			regen.String(" "),
+			regen.Any.Repeat().Group().CaptureAs("message"),
+		)
+	}
+	oldSet := regen.NewSet().MustAdd("log", level("ERROR", "INFO"))
+	newSet := regen.NewSet().
+		MustAdd("log", level("ERROR", "INFO", "WARN")).
+		MustAdd("other", regen.Any.Repeat().Min(1))
+
+	corpus := strings.NewReader("INFO started\nWARN slow\n\nERROR failed\n")
+	report, err := regen.Compare(oldSet, newSet, corpus)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Lines != 4 {
+		t.Errorf("expected 4 lines, got %d", report.Lines)
+	}
+	if len(report.Changes) != 1 {
+		t.Fatalf("expected 1 change, got %+v", report.Changes)
+	}
+	change := report.Changes[0]
+	if change.Line != 2 || change.Old.Pattern != "" || change.New.Pattern != "log" || change.New.Fields["level"] != "WARN" {
+		t.Errorf("unexpected change %+v", change)
+	}
+
+	var buf bytes.Buffer
+	if _, err := report.WriteTo(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "1 of 4 lines changed\nline 2: WARN slow\n  - no match\n  + log level=\"WARN\" message=\"slow\"\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestCompareCapturedFields(t *testing.T) {
+	oldSet := regen.NewSet().MustAdd("kv", regen.Sequence(regen.WordCharacter.Repeat().Group().CaptureAs("key"), regen.String("=")))
+	newSet := regen.NewSet().MustAdd("kv", regen.Sequence(regen.WordCharacter.Repeat().Min(2).Group().CaptureAs("key"), regen.String("=")))
+
+	report, err := regen.Compare(oldSet, newSet, strings.NewReader("ab=1\na=1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Changes) != 1 || report.Changes[0].Line != 2 {
+		t.Errorf("unexpected changes %+v", report.Changes)
+	}
+}
@@ -36,15 +36,17 @@ func (f Flag) String() string {
 }
 
 var (
-	LineStart        = Raw(`^`)
-	LineEnd          = Raw(`$`)
-	TextStart        = Raw(`\A`)
-	TextEnd          = Raw(`\z`)
-	ASCIIBoundary    = Raw(`\b`)
-	NotASCIIBoundary = Raw(`\B`)
+	LineStart        Regexp = anchorRegexp{re: `^`}
+	LineEnd          Regexp = anchorRegexp{re: `$`}
+	TextStart        Regexp = anchorRegexp{re: `\A`}
+	TextEnd          Regexp = anchorRegexp{re: `\z`}
+	ASCIIBoundary    Regexp = anchorRegexp{re: `\b`}
+	NotASCIIBoundary Regexp = anchorRegexp{re: `\B`}
 
-	Any              = Raw(`.`)
-	Digit            = perlCharClass('d')
-	Whitespace       = perlCharClass('s')
-	WordCharacter    = perlCharClass('w')
+	Any           Regexp = anyRegexp{}
+	Digit                = perlCharClass('d')
+	Whitespace           = perlCharClass('s')
+	WordCharacter        = perlCharClass('w')
+	// HexDigit matches a hexadecimal digit, [0-9A-Fa-f]
+	HexDigit = perlCharClass('h')
 )
@@ -0,0 +1,74 @@
+package regen
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	convertersMu sync.RWMutex
+	converters   = make(map[reflect.Type]reflect.Value)
+)
+
+var (
+	stringType = reflect.TypeOf("")
+	errorType  = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// RegisterConverter registers a function of the form func(string) (T, error) that Unmarshal uses to
+// convert captured text into fields of type T (or *T), allowing groups to be decoded directly into
+// domain types such as netip.Addr:
+//
+//	regen.RegisterConverter(netip.ParseAddr)
+//
+// Converters take precedence over the built-in conversions, and replace any converter that was
+// previously registered for T. RegisterConverter panics if fn does not have the expected signature.
+// It is typically called from an init function.
+func RegisterConverter(fn interface{}) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() || !isConverterType(v.Type()) {
+		panic(fmt.Sprintf("regen: RegisterConverter requires a func(string) (T, error), got %T", fn))
+	}
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	converters[v.Type().Out(0)] = v
+}
+
+func isConverterType(t reflect.Type) bool {
+	return t.NumIn() == 1 && t.In(0) == stringType && t.NumOut() == 2 && t.Out(1) == errorType
+}
+
+// converter returns the converter registered for t
+func converter(t reflect.Type) (reflect.Value, bool) {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	fn, ok := converters[t]
+	return fn, ok
+}
+
+// hasConverter returns true if a converter is registered for t or, if t is a pointer, its element type
+func hasConverter(t reflect.Type) bool {
+	if _, ok := converter(t); ok {
+		return true
+	}
+	if t.Kind() == reflect.Ptr {
+		_, ok := converter(t.Elem())
+		return ok
+	}
+	return false
+}
+
+// convert sets v using the converter registered for its type, returning false if there isn't one
+func convert(v reflect.Value, s string) (bool, error) {
+	fn, ok := converter(v.Type())
+	if !ok {
+		return false, nil
+	}
+	out := fn.Call([]reflect.Value{reflect.ValueOf(s)})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return true, err
+	}
+	v.Set(out[0])
+	return true, nil
+}
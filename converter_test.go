@@ -0,0 +1,92 @@
+package regen_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+type point struct {
+	X, Y string
+}
+
+func parsePoint(s string) (point, error) {
+	parts := strings.Split(s, ",")
+	return point{X: parts[0], Y: parts[1]}, nil
+}
+
+func parseIP(s string) (net.IP, error) {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, &net.ParseError{Type: "IP address", Text: s}
+	}
+	return ip, nil
+}
+
+func init() {
+	regen.RegisterConverter(parsePoint)
+	regen.RegisterConverter(parseIP)
+}
+
+func TestRegisterConverter(t *testing.T) {
+	re := regen.Sequence(
+		regen.Raw(`\S+`).Group().CaptureAs("ip"),
+		regen.String(" "),
+		regen.Raw(`\d+,\d+`).Group().CaptureAs("at"),
+		regen.Sequence(regen.String(" "), regen.Raw(`\d+,\d+`).Group().CaptureAs("to")).Optional(),
+	)
+	var v struct {
+		IP net.IP `regen:"ip"`
+		At point  `regen:"at"`
+		To *point `regen:"to"`
+	}
+	if err := regen.Unmarshal(re, "10.0.0.1 1,2", &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !v.IP.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Errorf("expected IP 10.0.0.1, got %s", v.IP)
+	}
+	if v.At != (point{X: "1", Y: "2"}) {
+		t.Errorf("unexpected point: %+v", v.At)
+	}
+	if v.To != nil {
+		t.Errorf("expected nil, got %+v", v.To)
+	}
+
+	if err := regen.Unmarshal(re, "::1 1,2 3,4", &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.To == nil || *v.To != (point{X: "3", Y: "4"}) {
+		t.Errorf("unexpected point: %+v", v.To)
+	}
+}
+
+func TestRegisterConverter_Error(t *testing.T) {
+	var v struct {
+		IP net.IP `regen:"ip"`
+	}
+	if err := regen.Unmarshal(regen.Raw(`.*`).Group().CaptureAs("ip"), "nope", &v); err == nil {
+		t.Error("expected conversion error")
+	}
+}
+
+func TestRegisterConverter_InvalidSignature(t *testing.T) {
+	for _, fn := range []interface{}{
+		nil,
+		"not a func",
+		func(string) int { return 0 },
+		func(int) (int, error) { return 0, nil },
+		func(string) (int, bool) { return 0, false },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for %T", fn)
+				}
+			}()
+			regen.RegisterConverter(fn)
+		}()
+	}
+}
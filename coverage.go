@@ -0,0 +1,128 @@
+package regen
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// coverageGroupPrefix is the prefix of the names of the groups added to instrument an expression
+const coverageGroupPrefix = "regen_coverage_"
+
+// BranchCoverage is the number of times that an alternative of a OneOf, or the body of an optional
+// expression (a repetition with a minimum of zero), took part in a match
+type BranchCoverage struct {
+	// Node is the alternation (the choices of a OneOf), or the optional expression
+	Node Regexp
+	// Index is the index of the alternative within the alternation, or -1 for an optional expression
+	Index int
+	// Matches is the number of lines in which the branch took part in the match
+	Matches int
+}
+
+func (b BranchCoverage) String() string {
+	if b.Index < 0 {
+		return fmt.Sprintf("optional %s: %d matches", b.Node.Regexp(), b.Matches)
+	}
+	return fmt.Sprintf("alternative %d of %s: %d matches", b.Index+1, b.Node.Regexp(), b.Matches)
+}
+
+// CoverageReport describes which branches of an expression were used to match a corpus
+type CoverageReport struct {
+	// Lines is the number of lines in the corpus
+	Lines int
+	// Matches is the number of lines that the expression matched
+	Matches int
+	// Branches are the alternatives and optional expressions, in the order they appear
+	Branches []BranchCoverage
+}
+
+// Unused returns the branches that never took part in a match, which are candidates for removal
+func (c *CoverageReport) Unused() []BranchCoverage {
+	var unused []BranchCoverage
+	for _, b := range c.Branches {
+		if b.Matches == 0 {
+			unused = append(unused, b)
+		}
+	}
+	return unused
+}
+
+// Coverage reports which alternatives of each OneOf, and which optional expressions, took part in
+// the first match of re in each line of corpus. This helps to prune alternatives that are never used
+// from large (e.g. generated) expressions. re is instrumented by wrapping each branch in a named group,
+// which doesn't change what it matches.
+func Coverage(re Regexp, corpus io.Reader) (*CoverageReport, error) {
+	report := &CoverageReport{}
+	instrumented := instrumentCoverage(re, report)
+	compiled, err := regexp.Compile(instrumented.Regexp())
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]int, len(report.Branches))
+	for index, name := range compiled.SubexpNames() {
+		if i, err := strconv.Atoi(strings.TrimPrefix(name, coverageGroupPrefix)); err == nil && strings.HasPrefix(name, coverageGroupPrefix) {
+			indexes[i] = index
+		}
+	}
+
+	scanner := bufio.NewScanner(corpus)
+	for scanner.Scan() {
+		report.Lines++
+		match := compiled.FindSubmatchIndex(scanner.Bytes())
+		if match == nil {
+			continue
+		}
+		report.Matches++
+		for i, index := range indexes {
+			if match[2*index] >= 0 {
+				report.Branches[i].Matches++
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// instrumentCoverage returns re with each branch wrapped in a named group, adding the branches to the
+// report
+func instrumentCoverage(re Regexp, report *CoverageReport) Regexp {
+	// add records a branch, returning it wrapped in a named group
+	add := func(b BranchCoverage, branch Regexp) Regexp {
+		name := coverageGroupPrefix + strconv.Itoa(len(report.Branches))
+		report.Branches = append(report.Branches, b)
+		return groupedRegexp{re: instrumentCoverage(branch, report), name: name}
+	}
+	switch node := re.(type) {
+	case CharClass:
+		return re
+	case multiRegexp:
+		if node.separator != "" {
+			res := make([]Regexp, len(node.res))
+			for i, choice := range node.res {
+				res[i] = add(BranchCoverage{Node: node, Index: i}, choice)
+			}
+			node.res, node.cache = res, nil
+			return node
+		}
+	case repeatedRegexp:
+		if !node.hasMin || node.min == 0 {
+			node.re, node.cache = add(BranchCoverage{Node: node, Index: -1}, node.re), nil
+			return node
+		}
+	}
+	kids := Children(re)
+	if len(kids) == 0 {
+		return re
+	}
+	instrumented := make([]Regexp, len(kids))
+	for i, kid := range kids {
+		instrumented[i] = instrumentCoverage(kid, report)
+	}
+	return withChildren(re, instrumented)
+}
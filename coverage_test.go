@@ -0,0 +1,47 @@
+package regen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestCoverage(t *testing.T) {
+	re := regen.Sequence(
+		regen.OneOf(regen.String("GET"), regen.String("POST"), regen.String("DELETE")).Group().CaptureAs("method"),
+		regen.String(" /"),
+		regen.WordCharacter.Repeat().Min(1),
+		regen.Sequence(regen.String("?"), regen.OneOf(regen.String("q"), regen.String("page")).Group().NoCapture()).Group().NoCapture().Optional(),
+	)
+	corpus := strings.NewReader("GET /index\nPOST /form?q\nGET /search?q\nnot a request\n")
+	report, err := regen.Coverage(re, corpus)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Lines != 4 || report.Matches != 3 {
+		t.Errorf("expected 3 of 4 lines to match, got %d of %d", report.Matches, report.Lines)
+	}
+	var branches []string
+	for _, b := range report.Branches {
+		branches = append(branches, b.String())
+	}
+	expected := []string{
+		"alternative 1 of GET|POST|DELETE: 2 matches",
+		"alternative 2 of GET|POST|DELETE: 1 matches",
+		"alternative 3 of GET|POST|DELETE: 0 matches",
+		`optional (?:\?(?:q|page))?: 2 matches`,
+		"alternative 1 of q|page: 2 matches",
+		"alternative 2 of q|page: 0 matches",
+	}
+	if strings.Join(branches, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected branches:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(branches, "\n"))
+	}
+	var unused []int
+	for _, b := range report.Unused() {
+		unused = append(unused, b.Index)
+	}
+	if len(unused) != 2 || unused[0] != 2 || unused[1] != 1 {
+		t.Errorf("expected the DELETE and page alternatives to be unused, got %v", report.Unused())
+	}
+}
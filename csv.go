@@ -0,0 +1,80 @@
+package regen
+
+import (
+	"fmt"
+)
+
+// CSVOption configures the fields and records matched by CSVField and CSVRecord
+type CSVOption func(*csvConfig)
+
+type csvConfig struct {
+	delimiter rune
+}
+
+// WithDelimiter sets the character that separates fields (, by default), e.g. '\t' for tab-separated
+// values
+func WithDelimiter(delimiter rune) CSVOption {
+	return func(c *csvConfig) {
+		c.delimiter = delimiter
+	}
+}
+
+func newCSVConfig(opts []CSVOption) csvConfig {
+	c := csvConfig{delimiter: ','}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// CSVField returns a Regexp that matches a field of a CSV record, as specified by RFC 4180: either a
+// string quoted by ", in which quotes are doubled and which may span lines, or unquoted text that doesn't
+// contain quotes, delimiters or line breaks. Quoted fields are matched along with their quotes.
+func CSVField(opts ...CSVOption) Regexp {
+	return newCSVConfig(opts).field()
+}
+
+func (c csvConfig) field() Regexp {
+	return OneOf(
+		Quoted(WithEscapeChar(0), WithDoubledQuotes(), WithNewlines()),
+		CharSet('"', c.delimiter, '\r', '\n').Negate().Repeat(),
+	).Group().NoCapture()
+}
+
+// CSVRecord returns a Regexp that matches an entire CSV record (see CSVField), which may end with \r. If
+// a header is given, the record must have a field for each of its columns, which is captured by a group
+// named after the column (with characters other than letters, digits and underscores replaced by
+// underscores, as in KeyValues). Otherwise, the record may have any number of fields.
+//
+//	re, err := regen.CSVRecord([]string{"name", "e-mail"})
+//	regen.MustCompile(re).MatchFields(`"Doe, Jane",jane@example.com`)
+//	// map[e_mail:jane@example.com name:"Doe, Jane"]
+//
+// An error is returned if a column has no name, or two columns would be captured by groups with the same
+// name.
+func CSVRecord(header []string, opts ...CSVOption) (Regexp, error) {
+	c := newCSVConfig(opts)
+	delimiter := String(string(c.delimiter))
+	end := Sequence(String("\r").Optional(), LineEnd)
+	if len(header) == 0 {
+		return Sequence(LineStart, c.field(), Sequence(delimiter, c.field()).Group().NoCapture().Repeat(), end), nil
+	}
+
+	parts := []Regexp{LineStart}
+	columns := make(map[string]string)
+	for i, column := range header {
+		if column == "" {
+			return nil, fmt.Errorf("regen: column %d of the header has no name", i+1)
+		}
+		name := groupNameFor(column)
+		if other, ok := columns[name]; ok {
+			return nil, fmt.Errorf("regen: columns %q and %q would both be captured by the group %s", other, column, name)
+		}
+		columns[name] = column
+		if i > 0 {
+			parts = append(parts, delimiter)
+		}
+		parts = append(parts, c.field().Group().CaptureAs(name))
+	}
+	return Sequence(append(parts, end)...), nil
+}
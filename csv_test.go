@@ -0,0 +1,89 @@
+package regen_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestCSVField(t *testing.T) {
+	m := regen.MustCompile(regen.Sequence(regen.TextStart, regen.CSVField(), regen.TextEnd))
+	for _, field := range []string{"", "abc", "a b", `"a,b"`, `"say ""hi"""`, "\"a\r\nb\""} {
+		if !m.MatchString(field) {
+			t.Errorf("expected %q to be a field", field)
+		}
+	}
+	for _, field := range []string{"a,b", `a"b`, `"a"b"`, `"a`, "a\nb"} {
+		if m.MatchString(field) {
+			t.Errorf("expected %q not to be a field", field)
+		}
+	}
+}
+
+func TestCSVRecord(t *testing.T) {
+	tests := []struct {
+		description string
+		header      []string
+		opts        []regen.CSVOption
+		input       string
+		expected    map[string]string
+	}{
+		{
+			description: "captures each column of the header",
+			header:      []string{"name", "e-mail", "age"},
+			input:       "\"Doe, Jane\",jane@example.com,\r",
+			expected:    map[string]string{"name": `"Doe, Jane"`, "e_mail": "jane@example.com", "age": ""},
+		},
+		{
+			description: "requires a field for each column",
+			header:      []string{"a", "b"},
+			input:       "1,2,3",
+		},
+		{
+			description: "supports other delimiters",
+			header:      []string{"a", "b"},
+			opts:        []regen.CSVOption{regen.WithDelimiter('\t')},
+			input:       "1,2\t\"3\t4\"",
+			expected:    map[string]string{"a": "1,2", "b": "\"3\t4\""},
+		},
+		{
+			description: "matches any number of fields without a header",
+			input:       `a,"b",,d`,
+			expected:    map[string]string{},
+		},
+		{
+			description: "does not match malformed quoted fields",
+			input:       `a,"b"c`,
+		},
+	}
+	for _, tt := range tests {
+		re, err := regen.CSVRecord(tt.header, tt.opts...)
+		if err != nil {
+			t.Errorf(`CSV test "%s" failed: unexpected error: %v`, tt.description, err)
+			continue
+		}
+		actual, ok := regen.MustCompile(re).MatchFields(tt.input)
+		if tt.expected == nil {
+			if ok {
+				t.Errorf(`CSV test "%s" failed: expected %s not to match, got %v`, tt.description, re.Regexp(), actual)
+			}
+			continue
+		}
+		if !ok || !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf(`CSV test "%s" failed: got %v (matched: %v), expected %v`, tt.description, actual, ok, tt.expected)
+		}
+	}
+}
+
+func TestCSVRecord_Errors(t *testing.T) {
+	tests := map[string][]string{
+		"regen: column 2 of the header has no name":                              {"a", ""},
+		`regen: columns "a b" and "a-b" would both be captured by the group a_b`: {"a b", "a-b"},
+	}
+	for expected, header := range tests {
+		if _, err := regen.CSVRecord(header); err == nil || err.Error() != expected {
+			t.Errorf("expected error %q for header %q, got %v", expected, header, err)
+		}
+	}
+}
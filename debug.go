@@ -0,0 +1,154 @@
+package regen
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// debugColors are the ANSI color codes used for named groups, in order
+var debugColors = []string{"31", "32", "33", "34", "35", "36", "91", "92", "93", "94", "95", "96"}
+
+// debugMatchColor is the ANSI code for text that is matched outside of any named group (underlined)
+const debugMatchColor = "4"
+
+// debugMarkers identify the named groups under the input
+const debugMarkers = "123456789abcdefghijklmnopqrstuvwxyz"
+
+// DebugOption configures Debug
+type DebugOption func(*debugConfig)
+
+type debugConfig struct {
+	color bool
+}
+
+// WithoutColor makes Debug identify groups using only the markers under the input, without ANSI
+// escape codes (e.g. when the output isn't a terminal)
+func WithoutColor() DebugOption {
+	return func(c *debugConfig) {
+		c.color = false
+	}
+}
+
+// Debug writes a description of the first match of re in input to w, to help investigate what each
+// named group captures. The input is written with the text captured by each named group highlighted in
+// a distinct color, and a line of markers underneath identifying the innermost named group at each
+// position ('-' for matched text outside of any named group), followed by a legend of the groups, e.g.
+//
+//	match [0:9]
+//	retries=3
+//	1111111-2
+//	[1] key [0:7] "retries"
+//	[2] value [8:9] "3"
+//
+// If re doesn't match, the longest partial match (see PartialMatch) is marked instead, showing where
+// matching stopped. Characters that aren't printable are escaped, and an error is returned if re doesn't
+// compile.
+func Debug(w io.Writer, re Regexp, input string, opts ...DebugOption) error {
+	c := debugConfig{color: true}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	compiled, err := regexp.Compile(re.Regexp())
+	if err != nil {
+		return err
+	}
+	match := compiled.FindStringSubmatchIndex(input)
+	if match == nil {
+		start, end, err := PartialMatch(re, input)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "no match, the longest partial match is [%d:%d]\n%s\n%s\n", start, end,
+			debugText(input, 0, len(input)), strings.Repeat(" ", debugWidth(input[:start]))+strings.Repeat("^", debugWidth(input[start:end])))
+		return err
+	}
+
+	// the named groups that participated, in order, which puts nested groups after their parents
+	type group struct {
+		name       string
+		start, end int
+		marker     byte
+		color      string
+	}
+	var groups, legend []group
+	for i, name := range compiled.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		g := group{name: name, start: match[2*i], end: match[2*i+1], marker: '*'}
+		if n := len(legend); n < len(debugMarkers) {
+			g.marker = debugMarkers[n]
+		}
+		g.color = debugColors[len(legend)%len(debugColors)]
+		legend = append(legend, g)
+		if g.start >= 0 {
+			groups = append(groups, g)
+		}
+	}
+
+	var text, markers strings.Builder
+	text.WriteString(debugText(input, 0, match[0]))
+	markers.WriteString(strings.Repeat(" ", debugWidth(input[:match[0]])))
+	for i := match[0]; i < match[1]; {
+		// the span up to the next boundary of a group is covered by the same groups, of which the
+		// innermost is the last
+		color, marker, end := debugMatchColor, byte('-'), match[1]
+		for _, g := range groups {
+			if i < g.start && g.start < end {
+				end = g.start
+			}
+			if i < g.end && g.end < end {
+				end = g.end
+			}
+			if g.start <= i && i < g.end {
+				color, marker = g.color, g.marker
+			}
+		}
+		width := debugWidth(input[i:end])
+		text.WriteString(debugColor(c, color, debugText(input, i, end)))
+		markers.WriteString(debugColor(c, color, strings.Repeat(string(marker), width)))
+		i = end
+	}
+	text.WriteString(debugText(input, match[1], len(input)))
+
+	lines := []string{fmt.Sprintf("match [%d:%d]", match[0], match[1]), text.String(), strings.TrimRight(markers.String(), " ")}
+	for _, g := range legend {
+		entry := fmt.Sprintf("[%c] %s did not participate", g.marker, g.name)
+		if g.start >= 0 {
+			entry = fmt.Sprintf("[%c] %s [%d:%d] %q", g.marker, g.name, g.start, g.end, input[g.start:g.end])
+		}
+		lines = append(lines, debugColor(c, g.color, entry))
+	}
+	_, err = io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// debugText returns input[start:end] with characters that aren't printable escaped
+func debugText(input string, start, end int) string {
+	var sb strings.Builder
+	for _, r := range input[start:end] {
+		if strconv.IsPrint(r) {
+			sb.WriteRune(r)
+			continue
+		}
+		quoted := strconv.QuoteRune(r)
+		sb.WriteString(quoted[1 : len(quoted)-1])
+	}
+	return sb.String()
+}
+
+// debugWidth returns the number of characters that s is displayed as by debugText
+func debugWidth(s string) int {
+	return len([]rune(debugText(s, 0, len(s))))
+}
+
+// debugColor wraps s in the ANSI escape codes for the color, if enabled
+func debugColor(c debugConfig, color, s string) string {
+	if !c.color || s == "" {
+		return s
+	}
+	return "\x1b[" + color + "m" + s + "\x1b[0m"
+}
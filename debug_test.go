@@ -0,0 +1,91 @@
+package regen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestDebug(t *testing.T) {
+	key := regen.WordCharacter.Repeat().Min(1).Group().CaptureAs("key")
+	value := regen.Digit.Repeat().Min(1).Group().CaptureAs("value")
+	for _, tt := range []struct {
+		desc     string
+		re       regen.Regexp
+		input    string
+		expected string
+	}{
+		{
+			desc:  "named groups",
+			re:    regen.Sequence(key, regen.String("="), value),
+			input: "retries=3",
+			expected: `match [0:9]
+retries=3
+1111111-2
+[1] key [0:7] "retries"
+[2] value [8:9] "3"
+`,
+		},
+		{
+			desc:  "nested groups and surrounding text",
+			re:    regen.Sequence(regen.String("v"), regen.Sequence(value, regen.String("."), value.Group().CaptureAs("minor")).Group().CaptureAs("version")),
+			input: "ver v1.23 ok",
+			expected: `match [4:9]
+ver v1.23 ok
+    -2133
+[1] version [5:9] "1.23"
+[2] value [5:6] "1"
+[3] minor [7:9] "23"
+`,
+		},
+		{
+			desc:  "group that did not participate",
+			re:    regen.Sequence(key, regen.Sequence(regen.String("="), value).Optional()),
+			input: "a\tb",
+			expected: `match [0:1]
+a\tb
+1
+[1] key [0:1] "a"
+[2] value did not participate
+`,
+		},
+		{
+			desc:  "no match",
+			re:    regen.Sequence(regen.String("id="), value),
+			input: "x id\t=1",
+			expected: `no match, the longest partial match is [2:4]
+x id\t=1
+  ^^
+`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			var sb strings.Builder
+			if err := regen.Debug(&sb, tt.re, tt.input, regen.WithoutColor()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual := sb.String(); actual != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDebug_Color(t *testing.T) {
+	var sb strings.Builder
+	re := regen.Sequence(regen.String("n="), regen.Digit.Group().CaptureAs("n"))
+	if err := regen.Debug(&sb, re, "n=4"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "match [0:3]\n\x1b[4mn=\x1b[0m\x1b[31m4\x1b[0m\n\x1b[4m--\x1b[0m\x1b[31m1\x1b[0m\n\x1b[31m[1] n [2:3] \"4\"\x1b[0m\n"
+	if actual := sb.String(); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestDebug_Invalid(t *testing.T) {
+	if err := regen.Debug(&strings.Builder{}, regen.Raw("("), "a"); err == nil {
+		t.Errorf("expected an error")
+	}
+}
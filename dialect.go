@@ -0,0 +1,612 @@
+package regen
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Dialect is a regular expression syntax that a Regexp can be rendered in.
+// Regexp.Regexp always renders in the syntax of DialectRE2, which is what the standard
+// library's regexp package accepts. To target another regular expression engine, use Render.
+type Dialect struct {
+	name             string
+	namedGroupPrefix string
+	flagLetters      map[Flag]byte
+	balancingGroups  bool
+	// missing contains the Features that have been removed from the dialect using Without
+	missing Feature
+	// perlClasses contains the letters of the Perl character classes (e.g. \d) that match the same
+	// characters as in RE2. Other Perl character classes are expanded into ranges.
+	perlClasses    string
+	unicodeScripts bool
+	unicodeBraces  bool
+	// bmpOnly is true if code points outside of the Basic Multilingual Plane cannot be used in character classes
+	bmpOnly bool
+	// codeUnits is true if patterns match UTF-16 code units rather than code points, so a character
+	// outside of the Basic Multilingual Plane is matched as a pair of surrogates
+	codeUnits bool
+	// noUnicodeEscapes is true if escapes such as \p{L}, \u{1F600} and \x{1F600} are not supported, so raw
+	// expressions using them are unsupported
+	noUnicodeEscapes bool
+	// codePointFormat is the format string used to escape a code point
+	codePointFormat string
+	// lineAnchorsOnly is true if ^ and $ always match at line boundaries
+	lineAnchorsOnly bool
+	// dollarBeforeFinalNewline is true if $ matches before a trailing newline when not in multi-line mode
+	dollarBeforeFinalNewline bool
+	// freeSpacing is true if the dialect supports free-spacing mode, (?x)
+	freeSpacing bool
+	// unicodeScriptPrefix is written before the names of Unicode scripts, e.g. \p{Script=Greek}
+	unicodeScriptPrefix string
+	// noTextAnchors is true if \A and \z are not supported, but ^ and $ only match at the start and
+	// end of the text when not in multi-line mode
+	noTextAnchors bool
+	// dotExcludesLineTerminators is true if . does not match \r, U+2028 or U+2029 (in addition to \n)
+	dotExcludesLineTerminators bool
+	// dotMatchesNewLine is true if . matches \n without FlagMatchNewLine
+	dotMatchesNewLine bool
+	// boundaryEscapes contains the escapes for word boundaries that differ from RE2, where an empty
+	// escape indicates that the boundary is unsupported
+	boundaryEscapes map[string]string
+	// noNonCapturingGroups is true if (?:...) is not supported, so non-capturing groups are rendered
+	// as capturing groups. This is only suitable for dialects that don't report submatches
+	noNonCapturingGroups bool
+	// sqlBackslashEscapes is true if backslashes are escape characters in SQL string literals
+	sqlBackslashEscapes bool
+	// backtracking is true if the dialect's engines use backtracking, so matching can take
+	// exponential time (see ReDoS)
+	backtracking bool
+	// subroutineCallFormat is the format string used to call a named group as a subroutine (e.g. \g<%s>),
+	// and subroutineDefinitionFormat defines a named group without matching it. Shared expressions are
+	// only rendered as subroutines by RenderShared.
+	subroutineCallFormat       string
+	subroutineDefinitionFormat string
+	// notes returns a description of how node matches differently in the dialect, if it does, where
+	// active are the flags in effect
+	notes func(node Regexp, active Flag) string
+}
+
+var (
+	// DialectRE2 is the syntax accepted by RE2 and Go's regexp package
+	DialectRE2 = Dialect{
+		name:             "RE2",
+		namedGroupPrefix: "?P<",
+		flagLetters: map[Flag]byte{
+			FlagCaseInsensitive: 'i',
+			FlagMultiLine:       'm',
+			FlagMatchNewLine:    's',
+			FlagUngreedy:        'U',
+		},
+		perlClasses:     "dsw",
+		unicodeScripts:  true,
+		codePointFormat: `\x{%X}`,
+	}
+	// DialectDotNet is the syntax accepted by .NET's System.Text.RegularExpressions.
+	// Since \d, \w and \s match Unicode characters in .NET, they are expanded into the ASCII
+	// ranges that they match in RE2. ASCII character classes are expanded similarly.
+	// Unicode scripts are expanded into ranges, as .NET only supports general categories.
+	DialectDotNet = Dialect{
+		name:             ".NET",
+		namedGroupPrefix: "?<",
+		flagLetters: map[Flag]byte{
+			FlagCaseInsensitive: 'i',
+			FlagMultiLine:       'm',
+			FlagMatchNewLine:    's',
+		},
+		missing:                  FeatureASCIIClasses,
+		balancingGroups:          true,
+		unicodeBraces:            true,
+		bmpOnly:                  true,
+		codePointFormat:          `\u%04X`,
+		dollarBeforeFinalNewline: true,
+		freeSpacing:              true,
+		backtracking:             true,
+	}
+	// DialectRuby is the syntax accepted by Ruby's Onigmo engine (and by Oniguruma, which is used by
+	// tools such as jq). Since ^ and $ always match at line boundaries in Ruby, they are rendered as
+	// \A and \z when FlagMultiLine is not set. \s is expanded, as it also matches vertical tabs in Ruby.
+	// Note that ASCII character classes (e.g. [[:alpha:]]) also match non-ASCII characters in Ruby.
+	DialectRuby = Dialect{
+		name:             "Ruby",
+		namedGroupPrefix: "?<",
+		flagLetters: map[Flag]byte{
+			FlagCaseInsensitive: 'i',
+			FlagMultiLine:       0,
+			FlagMatchNewLine:    'm',
+		},
+		perlClasses:                "dwh",
+		unicodeScripts:             true,
+		unicodeBraces:              true,
+		codePointFormat:            `\x{%X}`,
+		lineAnchorsOnly:            true,
+		freeSpacing:                true,
+		backtracking:               true,
+		subroutineCallFormat:       `\g<%s>`,
+		subroutineDefinitionFormat: `(?<%s>%s){0}`,
+	}
+	// DialectPCRE is the syntax accepted by PCRE2 (e.g. in PHP's preg functions) with the UTF option.
+	// \s is expanded, as it also matches vertical tabs in PCRE2. Shared expressions (see RenderShared)
+	// are defined in (?(DEFINE)...) groups and called using (?&name), which is atomic before PCRE2 10.30.
+	DialectPCRE = Dialect{
+		name:             "PCRE",
+		namedGroupPrefix: "?<",
+		flagLetters: map[Flag]byte{
+			FlagCaseInsensitive: 'i',
+			FlagMultiLine:       'm',
+			FlagMatchNewLine:    's',
+			FlagUngreedy:        'U',
+		},
+		perlClasses:                "dw",
+		unicodeScripts:             true,
+		codePointFormat:            `\x{%X}`,
+		dollarBeforeFinalNewline:   true,
+		freeSpacing:                true,
+		backtracking:               true,
+		subroutineCallFormat:       `(?&%s)`,
+		subroutineDefinitionFormat: `(?(DEFINE)(?<%s>%s))`,
+	}
+	// DialectECMAScript is the syntax accepted by JavaScript's RegExp (as specified by ECMA-262) when
+	// used with the u flag. Since ECMAScript does not support flag groups, flags are emulated (see
+	// FeatureFlagGroups), and FlagMultiLine is unsupported. ASCII character classes are expanded, and
+	// \A and \z are rendered as ^ and $.
+	DialectECMAScript = Dialect{
+		name:                       "ECMAScript",
+		namedGroupPrefix:           "?<",
+		flagLetters:                map[Flag]byte{},
+		missing:                    FeatureFlagGroups | FeatureASCIIClasses,
+		perlClasses:                "dw",
+		unicodeScripts:             true,
+		unicodeBraces:              true,
+		unicodeScriptPrefix:        "Script=",
+		codePointFormat:            `\u{%X}`,
+		noTextAnchors:              true,
+		dotExcludesLineTerminators: true,
+		backtracking:               true,
+	}
+)
+
+// String returns the name of the dialect
+func (d Dialect) String() string {
+	return d.name
+}
+
+// Render returns the regular expression string for re in the syntax of the dialect.
+// An *UnsupportedError is returned if re contains a construct that has no equivalent in the dialect.
+func (d Dialect) Render(re Regexp) (string, error) {
+	r := renderer{dialect: d, repeated: repeatedCaches(re), counted: true}
+	var sb strings.Builder
+	sb.Grow(estimateLen(re))
+	r.appendRegexp(&sb, re)
+	if r.err != nil {
+		return "", r.err
+	}
+	return sb.String(), nil
+}
+
+// DialectNote describes a node of a Regexp that matches differently once rendered in a Dialect
+type DialectNote struct {
+	Node Regexp
+	Note string
+}
+
+// Notes returns the nodes of re (in pre-order) whose behavior in the dialect differs from RE2 in
+// ways that rendering cannot account for, such as word boundaries that depend on the locale.
+// Differences are currently only documented for the SQL dialects.
+func (d Dialect) Notes(re Regexp) []DialectNote {
+	if d.notes == nil {
+		return nil
+	}
+	var notes []DialectNote
+	var visit func(node Regexp, active Flag)
+	visit = func(node Regexp, active Flag) {
+		if note := d.notes(node, active); note != "" {
+			notes = append(notes, DialectNote{Node: node, Note: note})
+		}
+		switch n := node.(type) {
+		case CharClass:
+			// the members of a union are noted as part of the union
+			return
+		case groupedRegexp:
+			active = active&^n.unsetFlags | n.setFlags
+		}
+		for _, child := range Children(node) {
+			visit(child, active)
+		}
+	}
+	visit(re, 0)
+	return notes
+}
+
+// UnsupportedError is returned when a Regexp cannot be rendered in a Dialect
+type UnsupportedError struct {
+	// Dialect is the name of the dialect being rendered
+	Dialect string
+	// Construct describes the part of the Regexp that the dialect does not support
+	Construct string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("regen: %s is not supported by the %s dialect", e.Construct, e.Dialect)
+}
+
+// renderable is implemented by all of the Regexps in this package, allowing them to be rendered
+// in any Dialect. Expressions are rendered in a single pass, with each node appending to the same
+// buffer.
+type renderable interface {
+	appendTo(sb *strings.Builder, r *renderer)
+}
+
+type renderer struct {
+	dialect Dialect
+	err     error
+	// activeFlags are the flags in effect for the expression currently being rendered
+	activeFlags Flag
+	// nested is true when rendering the members of a union of character classes, and classPreceded and
+	// classFollowed are true if other members precede or follow the member being rendered
+	nested        bool
+	classPreceded bool
+	classFollowed bool
+	// memoize is true if renderings can be cached, which is only the case for DialectRE2 (where the
+	// rendering of an expression doesn't depend on the enclosing expression)
+	memoize bool
+	// shared contains the renderings of the composite expressions that have been rendered, so that an
+	// expression that is used in several places is rendered once for each context
+	shared map[sharedRendering]string
+	// repeated contains the composite expressions that appear more than once in the expression being
+	// rendered, if counted is true, so that the renderings of the others aren't recorded in shared
+	repeated map[*renderCache]bool
+	counted  bool
+	// subroutines contains the names of the shared expressions that are rendered as subroutine calls
+	// (see RenderShared), and defining is the expression whose definition is being rendered
+	subroutines map[*renderCache]string
+	defining    *renderCache
+	// out is the buffer of a streaming render (see RenderTo), which is flushed to w as it fills up.
+	// written counts the bytes written to w, and writeErr is the error returned by w, if any.
+	out      *strings.Builder
+	w        io.Writer
+	written  int64
+	writeErr error
+}
+
+// sharedRendering identifies the rendering of a composite expression (by its cache, which is unique to
+// each value) in the context that it is rendered in
+type sharedRendering struct {
+	cache       *renderCache
+	activeFlags Flag
+	nested      bool
+}
+
+// WriteTo writes the regular expression for re (as returned by re.Regexp()) to w. The expression is
+// rendered incrementally, and written to w in chunks as sequences and alternations are rendered, so that
+// huge alternations (e.g. from OneOfStrings) aren't held in memory in full.
+func WriteTo(w io.Writer, re Regexp) (int64, error) {
+	r := renderer{dialect: DialectRE2, memoize: true}
+	r.stream(w, re)
+	return r.written, r.writeErr
+}
+
+// RenderTo is like Render, but writes the regular expression to w incrementally (see WriteTo), returning
+// the number of bytes written. If an error is returned, part of the expression may have been written.
+func (d Dialect) RenderTo(w io.Writer, re Regexp) (int64, error) {
+	r := renderer{dialect: d, repeated: repeatedCaches(re), counted: true}
+	r.stream(w, re)
+	if r.writeErr != nil {
+		return r.written, r.writeErr
+	}
+	return r.written, r.err
+}
+
+// streamChunkSize is the size at which the buffer of a streaming render is written out
+const streamChunkSize = 32 << 10
+
+// stream renders re into a buffer that is written to w as it fills up
+func (r *renderer) stream(w io.Writer, re Regexp) {
+	var sb strings.Builder
+	r.out, r.w = &sb, w
+	r.appendRegexp(&sb, re)
+	r.flush(&sb, true)
+}
+
+// flush writes sb to w and empties it, if sb is the buffer of a streaming render and it has filled up
+// (or force is true). Other buffers contain renderings that are still needed, e.g. to decide whether a
+// repeated expression requires parentheses, so they can't be flushed.
+func (r *renderer) flush(sb *strings.Builder, force bool) {
+	if sb != r.out || sb.Len() == 0 || (sb.Len() < streamChunkSize && !force) {
+		return
+	}
+	if r.writeErr == nil {
+		n, err := io.WriteString(r.w, sb.String())
+		r.written += int64(n)
+		r.writeErr = err
+	}
+	sb.Reset()
+}
+
+// renderRE2 renders re in DialectRE2. Unsupported constructs (such as balancing groups) are
+// rendered regardless, and will fail to compile.
+func renderRE2(re Regexp) string {
+	r := renderer{dialect: DialectRE2, memoize: true}
+	var sb strings.Builder
+	sb.Grow(estimateLen(re))
+	r.appendRegexp(&sb, re)
+	return sb.String()
+}
+
+// estimateLen estimates the length of the rendering of re in DialectRE2, so that the buffer that it is
+// rendered into can be allocated once. Cached renderings are used where available.
+func estimateLen(re Regexp) int {
+	switch re := re.(type) {
+	case literalRegexp:
+		return len(re.re)
+	case anchorRegexp:
+		return len(re.re)
+	case anyRegexp:
+		return 1
+	case charSetRegexp:
+		return len(re.chars) + 3
+	case unionCharClassRegexp:
+		n := 3
+		for _, class := range re.charClasses {
+			n += estimateLen(class)
+		}
+		return n
+	case CharClass:
+		return 8
+	case annotatedRegexp:
+		return estimateLen(re.re)
+	case multiRegexp:
+		if s, ok := re.cache.cached(); ok {
+			return len(s)
+		}
+		n := len(re.separator) * len(re.res)
+		for _, sub := range re.res {
+			n += estimateLen(sub)
+		}
+		return n
+	case groupedRegexp:
+		if s, ok := re.cache.cached(); ok {
+			return len(s)
+		}
+		return len(re.name) + len(re.balance) + 8 + estimateLen(re.re)
+	case repeatedRegexp:
+		if s, ok := re.cache.cached(); ok {
+			return len(s)
+		}
+		return estimateLen(re.re) + 8
+	}
+	return 0
+}
+
+// repeatedCaches returns the caches of the composite expressions that appear more than once in re, or
+// nil if there are none. Expressions within a repeated expression are only counted once, since their
+// renderings are reused along with it.
+func repeatedCaches(re Regexp) map[*renderCache]bool {
+	var counter cacheCounter
+	counter.visit(re)
+	return counter.repeated
+}
+
+// cacheCounter finds the composite expressions that appear more than once in an expression. The caches
+// that have been seen are kept in an array (so that small expressions are counted without allocating)
+// until there are too many to search.
+type cacheCounter struct {
+	few      [16]*renderCache
+	n        int
+	many     map[*renderCache]bool
+	repeated map[*renderCache]bool
+}
+
+func (c *cacheCounter) visit(re Regexp) {
+	if cache := renderCacheOf(re); cache != nil && c.seen(cache) {
+		if c.repeated == nil {
+			c.repeated = make(map[*renderCache]bool)
+		}
+		c.repeated[cache] = true
+		return
+	}
+	switch re := re.(type) {
+	case multiRegexp:
+		for _, sub := range re.res {
+			c.visit(sub)
+		}
+	case groupedRegexp:
+		c.visit(re.re)
+	case repeatedRegexp:
+		c.visit(re.re)
+	case annotatedRegexp:
+		c.visit(re.re)
+	}
+}
+
+// seen records cache, returning true if it had already been seen
+func (c *cacheCounter) seen(cache *renderCache) bool {
+	if c.many == nil {
+		for _, s := range c.few[:c.n] {
+			if s == cache {
+				return true
+			}
+		}
+		if c.n < len(c.few) {
+			c.few[c.n] = cache
+			c.n++
+			return false
+		}
+		c.many = make(map[*renderCache]bool, 2*len(c.few))
+		for _, s := range c.few {
+			c.many[s] = true
+		}
+	}
+	if c.many[cache] {
+		return true
+	}
+	c.many[cache] = true
+	return false
+}
+
+// renderCache holds the rendering of an expression in DialectRE2, which is computed at most once since
+// expressions are immutable. Shared sub-expressions are then only rendered once, and repeated calls to
+// Regexp are cheap. Builder methods that return a modified copy of an expression must give the copy a
+// new cache (or nil, which disables caching).
+type renderCache struct {
+	once sync.Once
+	s    string
+	// done is set to 1 once s has been computed
+	done uint32
+}
+
+// cached returns the cached rendering, if it has been computed
+func (c *renderCache) cached() (string, bool) {
+	if c == nil || atomic.LoadUint32(&c.done) == 0 {
+		return "", false
+	}
+	return c.s, true
+}
+
+// appendTo appends the cached rendering of an expression to sb, computing it by calling render (which
+// appends to sb) if necessary. The cached string shares sb's memory rather than being copied.
+// Renderings in other dialects are only cached for the duration of a single render (see appendShared).
+func (c *renderCache) appendTo(sb *strings.Builder, r *renderer, render func()) {
+	if c == nil {
+		render()
+		return
+	}
+	if name, ok := r.subroutines[c]; ok && c != r.defining {
+		sb.WriteString(fmt.Sprintf(r.dialect.subroutineCallFormat, name))
+		return
+	}
+	if r.out != nil {
+		// a streaming render empties its buffer as it goes, so renderings can't be cached as slices of it
+		if s, ok := c.cached(); ok && r.memoize {
+			sb.WriteString(s)
+			return
+		}
+		render()
+		return
+	}
+	if !r.memoize {
+		r.appendShared(sb, c, render)
+		return
+	}
+	rendered := false
+	c.once.Do(func() {
+		start := sb.Len()
+		render()
+		c.s = sb.String()[start:]
+		atomic.StoreUint32(&c.done, 1)
+		rendered = true
+	})
+	if !rendered {
+		sb.WriteString(c.s)
+	}
+}
+
+// appendShared appends the rendering of the expression with the given cache to sb, reusing the rendering
+// from elsewhere in the expression being rendered if the expression is shared and the context is the same
+func (r *renderer) appendShared(sb *strings.Builder, c *renderCache, render func()) {
+	if r.counted && !r.repeated[c] {
+		render()
+		return
+	}
+	key := sharedRendering{cache: c, activeFlags: r.activeFlags, nested: r.nested}
+	if s, ok := r.shared[key]; ok {
+		sb.WriteString(s)
+		return
+	}
+	start := sb.Len()
+	render()
+	if r.shared == nil {
+		r.shared = make(map[sharedRendering]string)
+	}
+	r.shared[key] = sb.String()[start:]
+}
+
+// regexp returns the rendering of re
+func (r *renderer) regexp(re Regexp) string {
+	var sb strings.Builder
+	r.appendRegexp(&sb, re)
+	return sb.String()
+}
+
+// appendRegexp appends the rendering of re to sb
+func (r *renderer) appendRegexp(sb *strings.Builder, re Regexp) {
+	if re, ok := re.(renderable); ok {
+		re.appendTo(sb, r)
+		return
+	}
+	sb.WriteString(re.Regexp())
+}
+
+func (r *renderer) unsupported(construct string) {
+	if r.err == nil {
+		r.err = &UnsupportedError{Dialect: r.dialect.name, Construct: construct}
+	}
+}
+
+func (r *renderer) flags(f Flag) string {
+	return string(r.appendFlags(nil, f))
+}
+
+// appendFlags appends the letters of the flags in the dialect to b
+func (r *renderer) appendFlags(b []byte, f Flag) []byte {
+	for _, flag := range []Flag{FlagCaseInsensitive, FlagMultiLine, FlagMatchNewLine, FlagUngreedy} {
+		if f&flag == 0 {
+			continue
+		}
+		letter, ok := r.dialect.flagLetters[flag]
+		if !ok {
+			r.unsupported("flag " + flag.String())
+			letter = flag.String()[0]
+		}
+		// A zero letter indicates that the dialect's rendering already accounts for the flag
+		if letter != 0 {
+			b = append(b, letter)
+		}
+	}
+	return b
+}
+
+// expandClass returns the contents of a bracketed character class matching the given ranges.
+// Negated classes cannot be expanded within a union, since they would negate the entire union.
+func (r *renderer) expandClass(construct string, ranges string, negated bool) string {
+	if ranges == "" {
+		r.unsupported(construct)
+	}
+	if !negated {
+		return ranges
+	}
+	if r.nested {
+		r.unsupported("negated " + construct + " within a union")
+	}
+	return "^" + ranges
+}
+
+// asciiClassRanges contains the ranges matched by each of the ASCII character classes
+// supported by RE2, escaped for use within square brackets
+var asciiClassRanges = map[string]string{
+	"alnum":  `0-9A-Za-z`,
+	"alpha":  `A-Za-z`,
+	"ascii":  `\x00-\x7F`,
+	"blank":  `\t `,
+	"cntrl":  `\x00-\x1F\x7F`,
+	"digit":  `0-9`,
+	"graph":  `!-~`,
+	"lower":  `a-z`,
+	"print":  ` -~`,
+	"punct":  `!-/:-@\[-` + "`" + `{-~`,
+	"space":  `\t\n\v\f\r `,
+	"upper":  `A-Z`,
+	"word":   `0-9A-Za-z_`,
+	"xdigit": `0-9A-Fa-f`,
+}
+
+// perlClassRanges contains the ranges matched by each of the Perl character classes (with the
+// semantics of RE2, or Ruby for \h), escaped for use within square brackets
+var perlClassRanges = map[byte]string{
+	'd': `0-9`,
+	'h': `0-9A-Fa-f`,
+	's': `\t\n\f\r `,
+	'w': `0-9A-Za-z_`,
+}
@@ -0,0 +1,296 @@
+package regen_test
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestDialects(t *testing.T) {
+	tests := []struct {
+		description string
+		dialect     regen.Dialect
+		re          regen.Regexp
+		expected    string
+		unsupported bool
+	}{
+		{
+			description: "RE2 matches Regexp()",
+			dialect:     regen.DialectRE2,
+			re:          regen.String("hello").Group().CaptureAs("greeting").SetFlags(regen.FlagUngreedy),
+			expected:    `(?P<greeting>(?U)hello)`,
+		},
+		{
+			description: "RE2 does not support balancing groups",
+			dialect:     regen.DialectRE2,
+			re:          regen.String(")").Group().CaptureAs("close").Balance("open"),
+			unsupported: true,
+		},
+		{
+			description: ".NET uses (?<name>) for named groups",
+			dialect:     regen.DialectDotNet,
+			re:          regen.String("hello").Group().CaptureAs("greeting"),
+			expected:    `(?<greeting>hello)`,
+		},
+		{
+			description: ".NET supports named balancing groups",
+			dialect:     regen.DialectDotNet,
+			re:          regen.String(")").Group().CaptureAs("close").Balance("open"),
+			expected:    `(?<close-open>\))`,
+		},
+		{
+			description: ".NET supports unnamed balancing groups",
+			dialect:     regen.DialectDotNet,
+			re:          regen.String(")").Group().NoCapture().Balance("open"),
+			expected:    `(?<-open>\))`,
+		},
+		{
+			description: ".NET supports flags other than U",
+			dialect:     regen.DialectDotNet,
+			re:          regen.String("hello").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive).UnsetFlags(regen.FlagMatchNewLine),
+			expected:    `(?i-s:hello)`,
+		},
+		{
+			description: ".NET does not support the ungreedy flag",
+			dialect:     regen.DialectDotNet,
+			re:          regen.String("hello").Group().SetFlags(regen.FlagUngreedy),
+			unsupported: true,
+		},
+		{
+			description: ".NET expands Perl character classes to ASCII ranges",
+			dialect:     regen.DialectDotNet,
+			re:          regen.Sequence(regen.Digit, regen.WordCharacter.Negate().Repeat()),
+			expected:    `[0-9][^0-9A-Za-z_]*`,
+		},
+		{
+			description: ".NET expands ASCII character classes",
+			dialect:     regen.DialectDotNet,
+			re:          regen.Union(regen.ASCIICharClass("xdigit"), regen.CharSet('-')),
+			expected:    `[-0-9A-Fa-f]`,
+		},
+		{
+			description: ".NET cannot expand negated classes within a union",
+			dialect:     regen.DialectDotNet,
+			re:          regen.Union(regen.Digit.Negate(), regen.CharSet('-')),
+			unsupported: true,
+		},
+		{
+			description: ".NET always wraps Unicode categories in braces",
+			dialect:     regen.DialectDotNet,
+			re:          regen.UnicodeCharClass("L").Negate(),
+			expected:    `\P{L}`,
+		},
+		{
+			description: ".NET does not support Unicode scripts",
+			dialect:     regen.DialectDotNet,
+			re:          regen.UnicodeCharClass("Greek"),
+			unsupported: true,
+		},
+		{
+			description: "Ruby uses (?<name>) for named groups",
+			dialect:     regen.DialectRuby,
+			re:          regen.String("hello").Group().CaptureAs("greeting"),
+			expected:    `(?<greeting>hello)`,
+		},
+		{
+			description: "Ruby renders ^ and $ as text anchors outside of multi-line mode",
+			dialect:     regen.DialectRuby,
+			re: regen.Sequence(
+				regen.LineStart,
+				regen.Sequence(regen.LineStart, regen.Any, regen.LineEnd).Group().NoCapture().SetFlags(regen.FlagMultiLine),
+				regen.LineEnd,
+			),
+			expected: `\A(?:^.$)\z`,
+		},
+		{
+			description: "Ruby uses m for FlagMatchNewLine",
+			dialect:     regen.DialectRuby,
+			re:          regen.Any.Group().NoCapture().SetFlags(regen.FlagCaseInsensitive | regen.FlagMatchNewLine),
+			expected:    `(?im:.)`,
+		},
+		{
+			description: "Ruby supports \\h and POSIX brackets, but expands \\s",
+			dialect:     regen.DialectRuby,
+			re:          regen.Sequence(regen.HexDigit, regen.ASCIICharClass("alpha"), regen.Whitespace),
+			expected:    `\h[[:alpha:]][\t\n\f\r ]`,
+		},
+		{
+			description: "ECMAScript renders text anchors as ^ and $",
+			dialect:     regen.DialectECMAScript,
+			re:          regen.Sequence(regen.TextStart, regen.String("a"), regen.TextEnd),
+			expected:    `^a$`,
+		},
+		{
+			description: "ECMAScript does not support multi-line anchors",
+			dialect:     regen.DialectECMAScript,
+			re:          regen.LineStart.Group().NoCapture().SetFlags(regen.FlagMultiLine),
+			unsupported: true,
+		},
+		{
+			description: "PCRE uses (?<name>) for named groups and supports the ungreedy flag",
+			dialect:     regen.DialectPCRE,
+			re:          regen.String("hello").Group().CaptureAs("greeting").SetFlags(regen.FlagUngreedy),
+			expected:    `(?<greeting>(?U)hello)`,
+		},
+		{
+			description: "PCRE supports POSIX brackets and Unicode scripts, but expands \\s",
+			dialect:     regen.DialectPCRE,
+			re:          regen.Sequence(regen.Digit, regen.ASCIICharClass("alpha"), regen.UnicodeCharClass("Greek"), regen.Whitespace),
+			expected:    `\d[[:alpha:]]\p{Greek}[\t\n\f\r ]`,
+		},
+		{
+			description: "ECMAScript emulates flags",
+			dialect:     regen.DialectECMAScript,
+			re:          regen.Sequence(regen.String("a"), regen.Any).Group().CaptureAs("x").SetFlags(regen.FlagCaseInsensitive | regen.FlagMatchNewLine),
+			expected:    `(?<x>[Aa][\s\S])`,
+		},
+		{
+			description: "ECMAScript excludes only \\n from .",
+			dialect:     regen.DialectECMAScript,
+			re:          regen.Any.Repeat(),
+			expected:    `[^\n]*`,
+		},
+		{
+			description: "ECMAScript prefixes Unicode scripts",
+			dialect:     regen.DialectECMAScript,
+			re:          regen.Sequence(regen.UnicodeCharClass("Greek"), regen.UnicodeCharClass("L").Negate()),
+			expected:    `\p{Script=Greek}\P{L}`,
+		},
+		{
+			description: "ECMAScript expands ASCII classes and \\s",
+			dialect:     regen.DialectECMAScript,
+			re:          regen.Sequence(regen.ASCIICharClass("alpha"), regen.Whitespace, regen.Digit),
+			expected:    `[A-Za-z][\t\n\f\r ]\d`,
+		},
+		{
+			description: "RE2 expands HexDigit",
+			dialect:     regen.DialectRE2,
+			re:          regen.HexDigit.Negate(),
+			expected:    `[^0-9A-Fa-f]`,
+		},
+		{
+			description: ".NET renders $ as \\z outside of multi-line mode",
+			dialect:     regen.DialectDotNet,
+			re:          regen.Sequence(regen.LineStart, regen.String("a"), regen.LineEnd),
+			expected:    `^a\z`,
+		},
+		{
+			description: "Without FeatureFlagGroups, case-insensitive literals and classes are expanded",
+			dialect:     regen.DialectRE2.Without(regen.FeatureFlagGroups),
+			re: regen.Sequence(
+				regen.String("Hi."),
+				regen.CharRange('a', 'c'),
+				regen.CharSet('x', '1'),
+				regen.Raw(`\d`),
+			).Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+			expected: `(?:[Hh][Ii]\.[a-cABC][1Xx]\d)`,
+		},
+		{
+			description: "Without FeatureFlagGroups, FlagMatchNewLine and FlagUngreedy are emulated",
+			dialect:     regen.DialectRE2.Without(regen.FeatureFlagGroups),
+			re:          regen.Any.Repeat().Group().SetFlags(regen.FlagMatchNewLine | regen.FlagUngreedy),
+			expected:    `([\s\S]*?)`,
+		},
+		{
+			description: "Without FeatureFlagGroups, case-sensitive raw expressions cannot be emulated",
+			dialect:     regen.DialectRE2.Without(regen.FeatureFlagGroups),
+			re:          regen.Raw(`a`).Group().SetFlags(regen.FlagCaseInsensitive),
+			unsupported: true,
+		},
+		{
+			description: "Without FeatureFlagGroups, FlagMultiLine cannot be emulated",
+			dialect:     regen.DialectRE2.Without(regen.FeatureFlagGroups),
+			re:          regen.LineStart.Group().SetFlags(regen.FlagMultiLine),
+			unsupported: true,
+		},
+		{
+			description: "Without FeatureUnicodeClasses, Unicode classes are expanded",
+			dialect:     regen.DialectRE2.Without(regen.FeatureUnicodeClasses),
+			re:          regen.UnicodeCharClass("Nko").Negate(),
+			expected:    `[^߀-ߺ߽-߿]`,
+		},
+		{
+			description: ".NET expands Unicode scripts",
+			dialect:     regen.DialectDotNet,
+			re:          regen.UnicodeCharClass("Nko"),
+			expected:    `[߀-ߺ߽-߿]`,
+		},
+		{
+			description: "Without FeatureNamedGroups, named groups are unnamed",
+			dialect:     regen.DialectRE2.Without(regen.FeatureNamedGroups),
+			re:          regen.String("a").Group().CaptureAs("name"),
+			expected:    `(a)`,
+		},
+		{
+			description: "Without FeatureCountedRepetition, repetitions are expanded",
+			dialect:     regen.DialectRE2.Without(regen.FeatureCountedRepetition),
+			re:          regen.Sequence(regen.Digit.Repeat().Min(2), regen.String("ab").Repeat().Min(1).Max(3)),
+			expected:    `\d\d\d*(?:ab)(?:(?:ab)(?:(?:ab))?)?`,
+		},
+		{
+			description: "Without FeatureCountedRepetition, capturing groups cannot be repeated",
+			dialect:     regen.DialectRE2.Without(regen.FeatureCountedRepetition),
+			re:          regen.String("ab").Group().Repeat().Exactly(2),
+			unsupported: true,
+		},
+		{
+			description: "Without FeatureLazyQuantifiers, ungreedy repetitions are unsupported",
+			dialect:     regen.DialectRE2.Without(regen.FeatureLazyQuantifiers),
+			re:          regen.Digit.Repeat().Ungreedy(),
+			unsupported: true,
+		},
+	}
+	for _, tt := range tests {
+		actual, err := tt.dialect.Render(tt.re)
+		if tt.unsupported {
+			var unsupportedErr *regen.UnsupportedError
+			if !errors.As(err, &unsupportedErr) {
+				t.Errorf(`dialect test "%s" failed: expected an UnsupportedError, got %v`, tt.description, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf(`dialect test "%s" failed: unexpected error: %v`, tt.description, err)
+			continue
+		}
+		if actual != tt.expected {
+			t.Errorf(`dialect test "%s" failed: got "%s", expected "%s"`, tt.description, actual, tt.expected)
+		}
+		var sb strings.Builder
+		if n, err := tt.dialect.RenderTo(&sb, tt.re); err != nil || sb.String() != actual || n != int64(len(actual)) {
+			t.Errorf(`dialect test "%s" failed: RenderTo wrote "%s" (%d bytes, error %v)`, tt.description, sb.String(), n, err)
+		}
+	}
+}
+
+func TestDialects_EmulatedCaseFolding(t *testing.T) {
+	classes := []regen.CharClass{
+		regen.UnicodeCharClass("Lu"),
+		regen.UnicodeCharClass("Ll").Negate(),
+		regen.UnicodeCharClass("Greek"),
+		regen.WordCharacter,
+		regen.WordCharacter.Negate(),
+		regen.Digit,
+		regen.ASCIICharClass("upper"),
+		regen.ASCIICharClass("alpha").Negate(),
+	}
+	emulated := regen.DialectRE2.Without(regen.FeatureFlagGroups)
+	for _, class := range classes {
+		re := class.Group().NoCapture().SetFlags(regen.FlagCaseInsensitive)
+		original := regexp.MustCompile(`\A` + re.Regexp() + `\z`)
+		pattern, err := emulated.Render(re)
+		if err != nil {
+			t.Errorf("unexpected error rendering %s: %v", re.Regexp(), err)
+			continue
+		}
+		emulation := regexp.MustCompile(`\A` + pattern + `\z`)
+		for _, s := range []string{"a", "A", "ſ", "K", "σ", "ς", "1", "-"} {
+			if expected := original.MatchString(s); emulation.MatchString(s) != expected {
+				t.Errorf("%s matches %q: %v, but its emulation %s does not agree", re.Regexp(), s, expected, pattern)
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package regen
+
+// Dispatcher calls the handler of the first of its patterns that matches a line, in the order that
+// they were added, like the routes of a log processor or the commands of a chat bot. Patterns should
+// be added before lines are dispatched, since a Dispatcher isn't safe for concurrent modification.
+type Dispatcher struct {
+	routes   []route
+	fallback func(line string)
+}
+
+type route struct {
+	matcher *Matcher
+	handler func(captures map[string]string)
+}
+
+// NewDispatcher returns a Dispatcher without any patterns
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{}
+}
+
+// Handle compiles re and adds it after the existing patterns, to be handled by handler, which is passed
+// the text captured by each named group that participated in the match (see MatchFields). An error is
+// returned if re fails to compile.
+func (d *Dispatcher) Handle(re Regexp, handler func(captures map[string]string)) error {
+	m, err := Compile(re)
+	if err != nil {
+		return err
+	}
+	d.routes = append(d.routes, route{matcher: m, handler: handler})
+	return nil
+}
+
+// MustHandle is like Handle, but panics if re cannot be compiled. The Dispatcher is returned to allow
+// chaining.
+func (d *Dispatcher) MustHandle(re Regexp, handler func(captures map[string]string)) *Dispatcher {
+	if err := d.Handle(re, handler); err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Fallback sets a handler for lines that don't match any pattern
+func (d *Dispatcher) Fallback(handler func(line string)) *Dispatcher {
+	d.fallback = handler
+	return d
+}
+
+// Dispatch calls the handler of the first pattern that matches line, returning false if none does (in
+// which case the fallback is called, if set)
+func (d *Dispatcher) Dispatch(line string) bool {
+	for _, r := range d.routes {
+		if captures, ok := r.matcher.MatchFields(line); ok {
+			r.handler(captures)
+			return true
+		}
+	}
+	if d.fallback != nil {
+		d.fallback(line)
+	}
+	return false
+}
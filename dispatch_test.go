@@ -0,0 +1,49 @@
+package regen_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestDispatcher(t *testing.T) {
+	var calls []string
+	handler := func(name string) func(map[string]string) {
+		return func(captures map[string]string) {
+			calls = append(calls, fmt.Sprintf("%s %v", name, captures))
+		}
+	}
+	word := regen.WordCharacter.Repeat().Min(1)
+	d := regen.NewDispatcher().
+		MustHandle(regen.Sequence(regen.TextStart, regen.String("!ban "), word.Group().CaptureAs("user")), handler("ban")).
+		MustHandle(regen.Sequence(regen.TextStart, regen.String("!"), word.Group().CaptureAs("command")), handler("command")).
+		Fallback(func(line string) {
+			calls = append(calls, "fallback "+line)
+		})
+
+	for _, tt := range []struct {
+		line     string
+		expected string
+		ok       bool
+	}{
+		{line: "!ban gopher", expected: "ban map[user:gopher]", ok: true},
+		{line: "!help", expected: "command map[command:help]", ok: true},
+		{line: "hello", expected: "fallback hello", ok: false},
+	} {
+		t.Run(tt.line, func(t *testing.T) {
+			calls = nil
+			if ok := d.Dispatch(tt.line); ok != tt.ok {
+				t.Errorf("expected Dispatch to return %t", tt.ok)
+			}
+			if expected := []string{tt.expected}; !reflect.DeepEqual(calls, expected) {
+				t.Errorf("expected calls %q, got %q", expected, calls)
+			}
+		})
+	}
+
+	if err := d.Handle(regen.Raw(`(`), handler("invalid")); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
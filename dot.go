@@ -0,0 +1,102 @@
+package regen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToDOT returns a Graphviz graph (in the DOT language) of the structure of re. Each node is labelled
+// with its kind (e.g. Sequence or Group), along with details such as group names, flags, quantifiers
+// and the literal text or class that it matches. Children are ordered from left to right.
+func ToDOT(re Regexp) string {
+	g := dotGraph{}
+	g.sb.WriteString("digraph regen {\n")
+	g.sb.WriteString("  graph [ordering=out];\n")
+	g.sb.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	g.node(re)
+	g.sb.WriteString("}\n")
+	return g.sb.String()
+}
+
+type dotGraph struct {
+	sb    strings.Builder
+	nodes int
+}
+
+// node writes the node for re (and its children) to the graph, returning its ID
+func (g *dotGraph) node(re Regexp) string {
+	id := fmt.Sprintf("n%d", g.nodes)
+	g.nodes++
+	label, children := dotLabel(re)
+	fmt.Fprintf(&g.sb, "  %s [label=%s];\n", id, dotQuote(label))
+	for _, child := range children {
+		fmt.Fprintf(&g.sb, "  %s -> %s;\n", id, g.node(child))
+	}
+	return id
+}
+
+// dotLabel returns the lines of the label for re, and its children
+func dotLabel(re Regexp) ([]string, []Regexp) {
+	switch re := re.(type) {
+	case literalRegexp:
+		if re.literal {
+			return []string{"String", fmt.Sprintf("%q", re.value)}, nil
+		}
+		return []string{"Raw", re.re}, nil
+	case anyRegexp:
+		return []string{"Any"}, nil
+	case anchorRegexp:
+		return []string{anchorNames[re.re]}, nil
+	case annotatedRegexp:
+		return []string{"Annotation", re.comment}, []Regexp{re.re}
+	case CharClass:
+		return []string{"CharClass", re.Regexp()}, nil
+	case multiRegexp:
+		if re.separator != "" {
+			return []string{"OneOf"}, re.res
+		}
+		return []string{"Sequence"}, re.res
+	case groupedRegexp:
+		label := []string{"Group"}
+		switch {
+		case re.balance != "":
+			label = append(label, "balance: "+re.name+"-"+re.balance)
+		case re.name != "":
+			label = append(label, "name: "+re.name)
+		case re.noCapture:
+			label = append(label, "non-capturing")
+		}
+		if re.setFlags != 0 || re.unsetFlags != 0 {
+			flags := "flags: " + re.setFlags.String()
+			if re.unsetFlags != 0 {
+				flags += "-" + re.unsetFlags.String()
+			}
+			label = append(label, flags)
+		}
+		return label, []Regexp{re.re}
+	case repeatedRegexp:
+		return []string{"Repeat", re.quantifier(re.ungreedy)}, []Regexp{re.re}
+	}
+	return []string{"Regexp", re.Regexp()}, nil
+}
+
+// anchorNames contains the names of the variables for each anchor
+var anchorNames = map[string]string{
+	`^`:  "LineStart",
+	`$`:  "LineEnd",
+	`\A`: "TextStart",
+	`\z`: "TextEnd",
+	`\b`: "ASCIIBoundary",
+	`\B`: "NotASCIIBoundary",
+}
+
+// dotQuote returns the lines as a quoted DOT string, separated by newlines
+func dotQuote(lines []string) string {
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		line = strings.Replace(line, `\`, `\\`, -1)
+		line = strings.Replace(line, `"`, `\"`, -1)
+		escaped[i] = strings.Replace(line, "\n", `\n`, -1)
+	}
+	return `"` + strings.Join(escaped, `\n`) + `"`
+}
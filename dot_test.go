@@ -0,0 +1,34 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestToDOT(t *testing.T) {
+	re := regen.Sequence(
+		regen.TextStart,
+		regen.String(`say "hi"`).Group().CaptureAs("greeting").SetFlags(regen.FlagCaseInsensitive),
+		regen.Digit.Repeat().Min(2).Max(3).Ungreedy(),
+	)
+	expected := `digraph regen {
+  graph [ordering=out];
+  node [shape=box, fontname="monospace"];
+  n0 [label="Sequence"];
+  n1 [label="TextStart"];
+  n0 -> n1;
+  n2 [label="Group\nname: greeting\nflags: i"];
+  n3 [label="String\n\"say \\\"hi\\\"\""];
+  n2 -> n3;
+  n0 -> n2;
+  n4 [label="Repeat\n{2,3}?"];
+  n5 [label="CharClass\n\\d"];
+  n4 -> n5;
+  n0 -> n4;
+}
+`
+	if actual := regen.ToDOT(re); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
@@ -0,0 +1,572 @@
+package regen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/scanner"
+)
+
+// DSLError is returned by ParseDSL for source that isn't valid, identifying where the problem is
+type DSLError struct {
+	// Line and Column are the 1-based position of the problem
+	Line, Column int
+	Message      string
+}
+
+func (e *DSLError) Error() string {
+	return fmt.Sprintf("regen: %d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// dslConstants are the predefined patterns of the DSL
+var dslConstants = map[string]Regexp{
+	"any":          Any,
+	"digit":        Digit,
+	"whitespace":   Whitespace,
+	"word":         WordCharacter,
+	"hex_digit":    HexDigit,
+	"line_start":   LineStart,
+	"line_end":     LineEnd,
+	"text_start":   TextStart,
+	"text_end":     TextEnd,
+	"boundary":     ASCIIBoundary,
+	"not_boundary": NotASCIIBoundary,
+}
+
+// dslFunc builds a pattern from the arguments of a call
+type dslFunc struct {
+	// usage describes the arguments, for error messages
+	usage string
+	call  func(p *dslParser, args []dslArg) (Regexp, error)
+}
+
+// dslFuncs are the functions of the DSL. It is populated by init, since the functions look themselves up
+// in it to describe their usage.
+var dslFuncs map[string]dslFunc
+
+func init() {
+	dslFuncs = map[string]dslFunc{
+		"seq": {"seq(pattern, ...)", func(p *dslParser, args []dslArg) (Regexp, error) {
+			res, err := p.patterns(args)
+			return Sequence(res...), err
+		}},
+		"one_of": {"one_of(pattern, ...)", func(p *dslParser, args []dslArg) (Regexp, error) {
+			res, err := p.patterns(args)
+			return OneOf(res...).Group().NoCapture(), err
+		}},
+		"one_of_strings": {`one_of_strings("text", ...)`, func(p *dslParser, args []dslArg) (Regexp, error) {
+			choices := make([]string, len(args))
+			for i, arg := range args {
+				s, err := p.string(arg)
+				if err != nil {
+					return nil, err
+				}
+				choices[i] = s
+			}
+			return OneOfStrings(choices...), nil
+		}},
+		"repeat": {"repeat(pattern), repeat(pattern, n), repeat(pattern, min..), repeat(pattern, ..max) or repeat(pattern, min..max)", func(p *dslParser, args []dslArg) (Regexp, error) {
+			if len(args) < 1 || len(args) > 2 {
+				return nil, p.usage()
+			}
+			re, err := p.pattern(args[0])
+			if err != nil {
+				return nil, err
+			}
+			repeated := re.Repeat()
+			if len(args) == 2 {
+				if args[1].kind != dslCount {
+					return nil, p.errorf(args[1].pos, "expected a count, such as 3, 1.. or 2..4")
+				}
+				if args[1].hasMin {
+					repeated = repeated.Min(args[1].min)
+				}
+				if args[1].hasMax {
+					repeated = repeated.Max(args[1].max)
+				}
+			}
+			return repeated, nil
+		}},
+		"lazy": {"lazy(repeat(...))", func(p *dslParser, args []dslArg) (Regexp, error) {
+			re, err := p.single(args)
+			if err != nil {
+				return nil, err
+			}
+			repeated, ok := re.(RepeatedRegexp)
+			if !ok {
+				return nil, p.errorf(args[0].pos, "only repetitions can be lazy")
+			}
+			return repeated.Ungreedy(), nil
+		}},
+		"optional": {"optional(pattern)", func(p *dslParser, args []dslArg) (Regexp, error) {
+			re, err := p.single(args)
+			if err != nil {
+				return nil, err
+			}
+			return re.Optional(), nil
+		}},
+		"group": {`group(pattern) or group("name", pattern)`, func(p *dslParser, args []dslArg) (Regexp, error) {
+			if len(args) == 1 {
+				re, err := p.pattern(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return re.Group().Capture(), nil
+			}
+			if len(args) != 2 {
+				return nil, p.usage()
+			}
+			name, err := p.string(args[0])
+			if err != nil {
+				return nil, err
+			}
+			re, err := p.pattern(args[1])
+			if err != nil {
+				return nil, err
+			}
+			return re.Group().CaptureAs(name), nil
+		}},
+		"flags": {`flags("letters", pattern), e.g. flags("i", "yes")`, func(p *dslParser, args []dslArg) (Regexp, error) {
+			if len(args) != 2 {
+				return nil, p.usage()
+			}
+			letters, err := p.string(args[0])
+			if err != nil {
+				return nil, err
+			}
+			var flags Flag
+			for _, letter := range letters {
+				flag, ok := dslFlags[letter]
+				if !ok {
+					return nil, p.errorf(args[0].pos, "unknown flag %q (the flags are i, m, s and U)", letter)
+				}
+				flags |= flag
+			}
+			re, err := p.pattern(args[1])
+			if err != nil {
+				return nil, err
+			}
+			return re.Group().NoCapture().SetFlags(flags), nil
+		}},
+		"comment": {`comment("text", pattern)`, func(p *dslParser, args []dslArg) (Regexp, error) {
+			if len(args) != 2 {
+				return nil, p.usage()
+			}
+			comment, err := p.string(args[0])
+			if err != nil {
+				return nil, err
+			}
+			re, err := p.pattern(args[1])
+			if err != nil {
+				return nil, err
+			}
+			return Annotate(re, comment), nil
+		}},
+		"raw": {`raw("expression")`, func(p *dslParser, args []dslArg) (Regexp, error) {
+			s, err := p.singleString(args)
+			return Raw(s), err
+		}},
+		"chars": {`chars("characters")`, func(p *dslParser, args []dslArg) (Regexp, error) {
+			s, err := p.singleString(args)
+			if err != nil {
+				return nil, err
+			}
+			if s == "" {
+				return nil, p.errorf(args[0].pos, "chars needs at least one character")
+			}
+			return CharSet([]rune(s)...), nil
+		}},
+		"range": {`range("a", "z")`, func(p *dslParser, args []dslArg) (Regexp, error) {
+			if len(args) != 2 {
+				return nil, p.usage()
+			}
+			var bounds [2]rune
+			for i, arg := range args {
+				s, err := p.string(arg)
+				if err != nil {
+					return nil, err
+				}
+				runes := []rune(s)
+				if len(runes) != 1 {
+					return nil, p.errorf(arg.pos, "expected a single character, got %q", s)
+				}
+				bounds[i] = runes[0]
+			}
+			if bounds[0] > bounds[1] {
+				return nil, p.errorf(args[0].pos, "the range %q-%q is reversed", bounds[0], bounds[1])
+			}
+			return CharRange(bounds[0], bounds[1]), nil
+		}},
+		"ascii": {`ascii("name"), e.g. ascii("alpha")`, func(p *dslParser, args []dslArg) (Regexp, error) {
+			s, err := p.singleString(args)
+			return ASCIICharClass(s), err
+		}},
+		"unicode": {`unicode("name"), e.g. unicode("Greek")`, func(p *dslParser, args []dslArg) (Regexp, error) {
+			s, err := p.singleString(args)
+			return UnicodeCharClass(s), err
+		}},
+		"union": {"union(class, ...)", func(p *dslParser, args []dslArg) (Regexp, error) {
+			classes := make([]CharClass, len(args))
+			for i, arg := range args {
+				class, err := p.class(arg)
+				if err != nil {
+					return nil, err
+				}
+				classes[i] = class
+			}
+			return Union(classes...), nil
+		}},
+		"not": {"not(class)", func(p *dslParser, args []dslArg) (Regexp, error) {
+			if len(args) != 1 {
+				return nil, p.usage()
+			}
+			class, err := p.class(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return class.Negate(), nil
+		}},
+	}
+}
+
+var dslFlags = map[rune]Flag{
+	'i': FlagCaseInsensitive,
+	'm': FlagMultiLine,
+	's': FlagMatchNewLine,
+	'U': FlagUngreedy,
+}
+
+// ParseDSL parses a pattern written in regen's textual language, a compact and readable alternative to
+// regular expression syntax for patterns that are supplied at runtime (e.g. in configuration files):
+//
+//	seq(line_start, group("id", repeat(digit, 1..)), ": ", repeat(any))
+//
+// A pattern is a string literal (in Go syntax, matched literally), one of the predefined patterns any,
+// digit, whitespace, word, hex_digit, line_start, line_end, text_start, text_end, boundary and
+// not_boundary, or a call to one of the following functions:
+//
+//	seq(pattern, ...)                  the patterns in order
+//	one_of(pattern, ...)               any of the patterns, preferring earlier ones
+//	one_of_strings("text", ...)        any of the strings (see OneOfStrings)
+//	repeat(pattern[, count])           the pattern repeated any number of times, or count times, where
+//	                                   count is n, min.., ..max or min..max
+//	lazy(repeat(...))                  a repetition that prefers fewer matches
+//	optional(pattern)                  the pattern, or nothing
+//	group(["name", ]pattern)           the pattern captured by a (named) group
+//	flags("letters", pattern)          the pattern with flags set, e.g. flags("i", "yes")
+//	comment("text", pattern)           the pattern, annotated with a comment (see Annotate)
+//	raw("expression")                  a regular expression, which isn't validated (see Raw)
+//	chars("characters")                any of the characters
+//	range("a", "z")                    any character in the range
+//	ascii("name"), unicode("name")     an ASCII or Unicode character class
+//	union(class, ...)                  any character in one of the classes
+//	not(class)                         any character not in the class
+//
+// Whitespace and comments (// and /* */) are ignored. A *DSLError is returned for invalid source,
+// describing what was expected and where.
+func ParseDSL(src string) (Regexp, error) {
+	p := &dslParser{}
+	p.s.Init(strings.NewReader(src))
+	p.s.Mode = scanner.ScanIdents | scanner.ScanInts | scanner.ScanStrings | scanner.ScanRawStrings | scanner.ScanComments | scanner.SkipComments
+	p.s.Error = func(s *scanner.Scanner, msg string) {
+		if p.err == nil {
+			p.err = p.errorf(s.Position, "%s", msg)
+		}
+	}
+	p.next()
+	arg, err := p.arg()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok != scanner.EOF {
+		return nil, p.errorf(p.pos, "unexpected %s after the pattern", p.describe())
+	}
+	return p.pattern(arg)
+}
+
+type dslParser struct {
+	s   scanner.Scanner
+	tok rune
+	pos scanner.Position
+	err error
+	// calls are the functions being called, innermost last, for usage errors
+	calls []dslCall
+}
+
+// dslCall is a call to a function
+type dslCall struct {
+	name string
+	pos  scanner.Position
+}
+
+// dslArgKind is the kind of an argument to a function
+type dslArgKind int
+
+const (
+	dslPattern dslArgKind = iota
+	dslString
+	dslCount
+)
+
+// dslArg is an argument to a function (or the top-level pattern)
+type dslArg struct {
+	pos  scanner.Position
+	kind dslArgKind
+	re   Regexp
+	s    string
+	// min and max are the bounds of a count
+	min, max       uint
+	hasMin, hasMax bool
+}
+
+func (p *dslParser) next() {
+	p.tok = p.s.Scan()
+	p.pos = p.s.Position
+	if p.tok == scanner.EOF {
+		// there is no token, so the position is that of the end of the input
+		p.pos = p.s.Pos()
+	}
+}
+
+// arg parses a pattern, string or count
+func (p *dslParser) arg() (dslArg, error) {
+	if p.err != nil {
+		return dslArg{}, p.err
+	}
+	arg := dslArg{pos: p.pos}
+	switch p.tok {
+	case scanner.String, scanner.RawString:
+		s, err := strconv.Unquote(p.s.TokenText())
+		if err != nil {
+			return arg, p.errorf(p.pos, "invalid string %s", p.s.TokenText())
+		}
+		arg.kind, arg.s = dslString, s
+		p.next()
+		return arg, p.err
+	case scanner.Int, '.':
+		return p.count()
+	case scanner.Ident:
+		name := p.s.TokenText()
+		p.next()
+		if p.tok != '(' {
+			re, ok := dslConstants[name]
+			if !ok {
+				if _, isFunc := dslFuncs[name]; isFunc {
+					return arg, p.errorf(arg.pos, "%s is a function, e.g. %s", name, dslFuncs[name].usage)
+				}
+				return arg, p.errorf(arg.pos, "unknown pattern %s%s", name, dslSuggestion(name))
+			}
+			arg.re = re
+			return arg, p.err
+		}
+		f, ok := dslFuncs[name]
+		if !ok {
+			return arg, p.errorf(arg.pos, "unknown function %s%s", name, dslSuggestion(name))
+		}
+		p.next()
+		var args []dslArg
+		for p.tok != ')' {
+			a, err := p.arg()
+			if err != nil {
+				return arg, err
+			}
+			args = append(args, a)
+			if p.tok == ',' {
+				p.next()
+				continue
+			}
+			if p.tok != ')' {
+				return arg, p.errorf(p.pos, "expected , or ) in the arguments of %s, got %s", name, p.describe())
+			}
+		}
+		p.next()
+		p.calls = append(p.calls, dslCall{name: name, pos: arg.pos})
+		re, err := f.call(p, args)
+		p.calls = p.calls[:len(p.calls)-1]
+		if err != nil {
+			return arg, err
+		}
+		arg.re = re
+		return arg, p.err
+	case scanner.EOF:
+		return arg, p.errorf(p.pos, "expected a pattern, got the end of the input")
+	}
+	return arg, p.errorf(p.pos, "expected a pattern, got %s", p.describe())
+}
+
+// count parses a count: n, min.., ..max or min..max
+func (p *dslParser) count() (dslArg, error) {
+	arg := dslArg{pos: p.pos, kind: dslCount}
+	if p.tok == scanner.Int {
+		n, err := p.int()
+		if err != nil {
+			return arg, err
+		}
+		arg.min, arg.hasMin = n, true
+		if p.tok != '.' {
+			arg.max, arg.hasMax = n, true
+			return arg, p.err
+		}
+	}
+	if !p.dots() {
+		return arg, p.errorf(p.pos, "expected .. in the count")
+	}
+	if p.tok == scanner.Int {
+		n, err := p.int()
+		if err != nil {
+			return arg, err
+		}
+		arg.max, arg.hasMax = n, true
+		if arg.hasMin && arg.max < arg.min {
+			return arg, p.errorf(arg.pos, "the count %d..%d is reversed", arg.min, arg.max)
+		}
+	} else if !arg.hasMin {
+		return arg, p.errorf(p.pos, "expected the maximum count after ..")
+	}
+	return arg, p.err
+}
+
+// dots consumes .., returning false if it isn't next
+func (p *dslParser) dots() bool {
+	if p.tok != '.' || p.s.Peek() != '.' {
+		return false
+	}
+	p.next()
+	p.next()
+	return true
+}
+
+func (p *dslParser) int() (uint, error) {
+	n, err := strconv.ParseUint(p.s.TokenText(), 10, 32)
+	if err != nil {
+		return 0, p.errorf(p.pos, "invalid count %s", p.s.TokenText())
+	}
+	p.next()
+	return uint(n), nil
+}
+
+// pattern returns the pattern of an argument, where strings are matched literally
+func (p *dslParser) pattern(arg dslArg) (Regexp, error) {
+	switch arg.kind {
+	case dslString:
+		return String(arg.s), nil
+	case dslCount:
+		return nil, p.errorf(arg.pos, "expected a pattern, got a count (numbers are written as strings, e.g. \"42\")")
+	}
+	return arg.re, nil
+}
+
+func (p *dslParser) patterns(args []dslArg) ([]Regexp, error) {
+	res := make([]Regexp, len(args))
+	for i, arg := range args {
+		re, err := p.pattern(arg)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = re
+	}
+	return res, nil
+}
+
+// single returns the pattern of the only argument
+func (p *dslParser) single(args []dslArg) (Regexp, error) {
+	if len(args) != 1 {
+		return nil, p.usage()
+	}
+	return p.pattern(args[0])
+}
+
+func (p *dslParser) string(arg dslArg) (string, error) {
+	if arg.kind != dslString {
+		return "", p.errorf(arg.pos, "expected a string in %s", p.calls[len(p.calls)-1].name)
+	}
+	return arg.s, nil
+}
+
+// singleString returns the string of the only argument
+func (p *dslParser) singleString(args []dslArg) (string, error) {
+	if len(args) != 1 {
+		return "", p.usage()
+	}
+	return p.string(args[0])
+}
+
+func (p *dslParser) class(arg dslArg) (CharClass, error) {
+	re, err := p.pattern(arg)
+	if err != nil {
+		return nil, err
+	}
+	class, ok := re.(CharClass)
+	if !ok {
+		return nil, p.errorf(arg.pos, "expected a character class, such as digit or chars(\"abc\")")
+	}
+	return class, nil
+}
+
+// usage returns an error describing the arguments of the function being called
+func (p *dslParser) usage() error {
+	call := p.calls[len(p.calls)-1]
+	return p.errorf(call.pos, "wrong number of arguments to %s, expected %s", call.name, dslFuncs[call.name].usage)
+}
+
+// describe describes the current token
+func (p *dslParser) describe() string {
+	if p.tok == scanner.EOF {
+		return "the end of the input"
+	}
+	return strconv.Quote(p.s.TokenText())
+}
+
+func (p *dslParser) errorf(pos scanner.Position, format string, args ...interface{}) error {
+	return &DSLError{Line: pos.Line, Column: pos.Column, Message: fmt.Sprintf(format, args...)}
+}
+
+// dslSuggestion suggests a known name that is similar to name, if there is one
+func dslSuggestion(name string) string {
+	var names []string
+	for known := range dslConstants {
+		names = append(names, known)
+	}
+	for known := range dslFuncs {
+		names = append(names, known)
+	}
+	sort.Strings(names)
+	for _, known := range names {
+		if strings.HasPrefix(known, name) || strings.HasPrefix(name, known) || editDistance(known, name) <= 2 {
+			return " (did you mean " + known + "?)"
+		}
+	}
+	return ""
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
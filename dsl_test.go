@@ -0,0 +1,70 @@
+package regen_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestParseDSL(t *testing.T) {
+	for _, tt := range []struct {
+		src      string
+		expected string
+	}{
+		{`seq(line_start, group("id", repeat(digit, 1..)), ": ", repeat(any))`, `^(?P<id>\d+): .*`},
+		{`"a.b"`, `a\.b`},
+		{"`C:\\temp`", `C:\\temp`},
+		{`seq(text_start, word, whitespace, hex_digit, boundary, not_boundary, line_end, text_end)`, `\A\w\s[0-9A-Fa-f]\b\B$\z`},
+		{`one_of("cat", digit)`, `(?:cat|\d)`},
+		{`one_of_strings("foo", "foobar", "fizz")`, `f(?:izz|oo(?:bar)?)`},
+		{`seq(repeat(digit), repeat(digit, 3), repeat(digit, ..2), repeat(digit, 2..4))`, `\d*\d{3}\d{0,2}\d{2,4}`},
+		{`seq(lazy(repeat(any, 1..)), optional("x"))`, `.+?x?`},
+		{`seq(group("ab"), flags("is", "cd"))`, `(ab)(?is:cd)`},
+		{`comment("the prefix", "id")`, `id`},
+		{`raw("\\d{2}")`, `\d{2}`},
+		{`seq(chars("ab"), range("0", "9"), ascii("alpha"), unicode("Greek"), not(digit))`, `[ab][0-9][[:alpha:]]\p{Greek}\D`},
+		{`union(chars("_"), word)`, `[_\w]`},
+		{"seq(\n  // the key\n  group(\"key\", repeat(word, 1..)), /* separator */ \"=\",\n)", `(?P<key>\w+)=`},
+	} {
+		re, err := regen.ParseDSL(tt.src)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.src, err)
+			continue
+		}
+		if actual := re.Regexp(); actual != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.src, tt.expected, actual)
+		}
+	}
+}
+
+func TestParseDSL_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		src      string
+		expected string
+	}{
+		{`seq(digit`, `regen: 1:10: expected , or ) in the arguments of seq, got the end of the input`},
+		{`seq(digit) digit`, `regen: 1:12: unexpected "digit" after the pattern`},
+		{`sequence(digit)`, `regen: 1:1: unknown function sequence (did you mean seq?)`},
+		{`seq(digits)`, `regen: 1:5: unknown pattern digits (did you mean digit?)`},
+		{`seq(repeat)`, `regen: 1:5: repeat is a function, e.g. repeat(pattern), repeat(pattern, n), repeat(pattern, min..), repeat(pattern, ..max) or repeat(pattern, min..max)`},
+		{`group("a", "b", "c")`, `regen: 1:1: wrong number of arguments to group, expected group(pattern) or group("name", pattern)`},
+		{`group(digit, "b")`, `regen: 1:7: expected a string in group`},
+		{`repeat(digit, 4..2)`, `regen: 1:15: the count 4..2 is reversed`},
+		{`repeat(digit, ..)`, `regen: 1:17: expected the maximum count after ..`},
+		{`repeat(digit, "2")`, `regen: 1:15: expected a count, such as 3, 1.. or 2..4`},
+		{`seq(digit, 3)`, `regen: 1:12: expected a pattern, got a count (numbers are written as strings, e.g. "42")`},
+		{`not("a")`, `regen: 1:5: expected a character class, such as digit or chars("abc")`},
+		{`lazy(digit)`, `regen: 1:6: only repetitions can be lazy`},
+		{`flags("x", digit)`, `regen: 1:7: unknown flag 'x' (the flags are i, m, s and U)`},
+		{`range("z", "a")`, `regen: 1:7: the range 'z'-'a' is reversed`},
+		{`seq("unterminated)`, `regen: 1:5: literal not terminated`},
+		{``, `regen: 1:1: expected a pattern, got the end of the input`},
+	} {
+		_, err := regen.ParseDSL(tt.src)
+		var dslErr *regen.DSLError
+		if !errors.As(err, &dslErr) || err.Error() != tt.expected {
+			t.Errorf("%s: expected error %q, got %v", tt.src, tt.expected, err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package regen
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"unicode"
+)
+
+var (
+	errInfinite = errors.New("infinitely many strings")
+	errTooMany  = errors.New("too many strings")
+)
+
+// Enumerate returns every string that re matches in its entirety, sorted, e.g. to document exactly
+// what a small token pattern accepts. It returns an error if re matches infinitely many strings
+// (i.e. it contains an unbounded repetition), or if it matches more than limit strings.
+func Enumerate(re Regexp, limit int) ([]string, error) {
+	pattern := re.Regexp()
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := regexp.Compile(`\A(?:` + pattern + `)\z`)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := enumerate(parsed, limit)
+	switch err {
+	case nil:
+	case errInfinite:
+		return nil, fmt.Errorf("regen: %s matches infinitely many strings", pattern)
+	default:
+		return nil, fmt.Errorf("regen: %s matches more than %d strings", pattern, limit)
+	}
+	var matches []string
+	for s := range candidates {
+		// Zero-width assertions are checked against the whole string
+		if matcher.MatchString(s) {
+			matches = append(matches, s)
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// enumerate returns the strings matched by re, ignoring zero-width assertions
+func enumerate(re *syntax.Regexp, limit int) (map[string]bool, error) {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return map[string]bool{}, nil
+	case syntax.OpLiteral:
+		strs := map[string]bool{"": true}
+		for _, c := range re.Rune {
+			runes := []rune{c}
+			if re.Flags&syntax.FoldCase != 0 {
+				for f := unicode.SimpleFold(c); f != c; f = unicode.SimpleFold(f) {
+					runes = append(runes, f)
+				}
+			}
+			var err error
+			if strs, err = concatStrings(strs, runeStrings(runes), limit); err != nil {
+				return nil, err
+			}
+		}
+		return strs, nil
+	case syntax.OpCharClass:
+		return rangeStrings(re.Rune, limit)
+	case syntax.OpAnyCharNotNL:
+		return rangeStrings([]rune{0, '\n' - 1, '\n' + 1, unicode.MaxRune}, limit)
+	case syntax.OpAnyChar:
+		return rangeStrings([]rune{0, unicode.MaxRune}, limit)
+	case syntax.OpCapture:
+		return enumerate(re.Sub[0], limit)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		sub, err := enumerate(re.Sub[0], limit)
+		if err != nil {
+			return nil, err
+		}
+		min, max := repeatBounds(re)
+		if max < 0 {
+			if len(sub) > 1 || len(sub) == 1 && !sub[""] {
+				return nil, errInfinite
+			}
+			max = min
+		}
+		strs := map[string]bool{}
+		power := map[string]bool{"": true}
+		for n := 0; n <= max; n++ {
+			if n >= min {
+				for s := range power {
+					strs[s] = true
+				}
+				if len(strs) > limit {
+					return nil, errTooMany
+				}
+			}
+			if n < max {
+				if power, err = concatStrings(power, sub, limit); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return strs, nil
+	case syntax.OpConcat:
+		strs := map[string]bool{"": true}
+		for _, sub := range re.Sub {
+			subStrs, err := enumerate(sub, limit)
+			if err != nil {
+				return nil, err
+			}
+			if strs, err = concatStrings(strs, subStrs, limit); err != nil {
+				return nil, err
+			}
+		}
+		return strs, nil
+	case syntax.OpAlternate:
+		strs := map[string]bool{}
+		for _, sub := range re.Sub {
+			subStrs, err := enumerate(sub, limit)
+			if err != nil {
+				return nil, err
+			}
+			for s := range subStrs {
+				strs[s] = true
+			}
+			if len(strs) > limit {
+				return nil, errTooMany
+			}
+		}
+		return strs, nil
+	}
+	// Empty matches and zero-width assertions
+	return map[string]bool{"": true}, nil
+}
+
+// concatStrings returns each string in a followed by each string in b
+func concatStrings(a, b map[string]bool, limit int) (map[string]bool, error) {
+	strs := make(map[string]bool)
+	for x := range a {
+		for y := range b {
+			strs[x+y] = true
+			if len(strs) > limit {
+				return nil, errTooMany
+			}
+		}
+	}
+	return strs, nil
+}
+
+// rangeStrings returns the runes in the (sorted) pairs of inclusive ranges as strings
+func rangeStrings(ranges []rune, limit int) (map[string]bool, error) {
+	var runes []rune
+	for i := 0; i < len(ranges); i += 2 {
+		if len(runes)+int(ranges[i+1]-ranges[i]) >= limit {
+			return nil, errTooMany
+		}
+		for c := ranges[i]; c <= ranges[i+1]; c++ {
+			// Surrogates can't be encoded in UTF-8
+			if c < 0xD800 || c > 0xDFFF {
+				runes = append(runes, c)
+			}
+		}
+	}
+	return runeStrings(runes), nil
+}
+
+func runeStrings(runes []rune) map[string]bool {
+	strs := make(map[string]bool, len(runes))
+	for _, c := range runes {
+		strs[string(c)] = true
+	}
+	return strs
+}
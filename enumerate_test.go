@@ -0,0 +1,88 @@
+package regen_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestEnumerate(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		re       regen.Regexp
+		limit    int
+		expected []string
+		err      string
+	}{
+		{
+			desc:     "alternatives",
+			re:       regen.Sequence(regen.OneOfStrings("GET", "PUT"), regen.CharSet('1', '2').Optional()),
+			limit:    10,
+			expected: []string{"GET", "GET1", "GET2", "PUT", "PUT1", "PUT2"},
+		},
+		{
+			desc:     "counted repetition",
+			re:       regen.CharSet('a', 'b').Repeat().Min(1).Max(2),
+			limit:    10,
+			expected: []string{"a", "aa", "ab", "b", "ba", "bb"},
+		},
+		{
+			desc:     "case-insensitive",
+			re:       regen.String("no").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+			limit:    10,
+			expected: []string{"NO", "No", "nO", "no"},
+		},
+		{
+			desc:     "zero-width assertions",
+			re:       regen.Sequence(regen.CharSet('a', ' ').Optional(), regen.ASCIIBoundary, regen.String("b")),
+			limit:    10,
+			expected: []string{" b", "b"},
+		},
+		{
+			desc:  "no matches",
+			re:    regen.Sequence(regen.String("a"), regen.TextEnd, regen.String("b")),
+			limit: 10,
+		},
+		{
+			desc:  "unbounded repetition",
+			re:    regen.Digit.Repeat(),
+			limit: 10,
+			err:   `regen: \d* matches infinitely many strings`,
+		},
+		{
+			desc:     "unbounded repetition of an empty string",
+			re:       regen.String("").Group().NoCapture().Repeat(),
+			limit:    10,
+			expected: []string{""},
+		},
+		{
+			desc:  "too many strings",
+			re:    regen.Digit.Repeat().Min(2).Max(2),
+			limit: 99,
+			err:   `regen: \d{2} matches more than 99 strings`,
+		},
+		{
+			desc:  "any character",
+			re:    regen.Any,
+			limit: 1000,
+			err:   `regen: . matches more than 1000 strings`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			strs, err := regen.Enumerate(tt.re, tt.limit)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(strs, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, strs)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package regen
+
+// Equivalent reports whether a and b match exactly the same strings, which can be used to check that
+// a refactoring (e.g. using Simplify) doesn't change the meaning of an expression. If they differ,
+// counterexample is one of the shortest strings that is matched by only one of them.
+//
+// Strings are compared as if each expression were required to match them in their entirety, i.e. as
+// if surrounded by \A(?: and )\z, and zero-width assertions are taken into account. The comparison
+// constructs DFAs for both expressions, which is exponential in the worst case. Submatches are ignored,
+// as are ungreedy repetitions. Expressions that fail to compile (e.g. due to an invalid Raw
+// expression) are never equivalent.
+func Equivalent(a, b Regexp) (equivalent bool, counterexample string) {
+	automatonA, err := newAutomaton(a)
+	if err != nil {
+		return false, ""
+	}
+	automatonB, err := newAutomaton(b)
+	if err != nil {
+		return false, ""
+	}
+	counterexample, found := findString(func(accepted []bool) bool {
+		return accepted[0] != accepted[1]
+	}, automatonA, automatonB)
+	return !found, counterexample
+}
+
+// Subsumes reports whether every string matched by b is also matched by a, which can be used to check
+// that a new version of a validation pattern (a) still accepts everything that the old version (b)
+// did. If not, witness is one of the shortest strings that is matched by b but not a. Strings are
+// compared as described by Equivalent.
+func Subsumes(a, b Regexp) (subsumes bool, witness string) {
+	automatonA, err := newAutomaton(a)
+	if err != nil {
+		return false, ""
+	}
+	automatonB, err := newAutomaton(b)
+	if err != nil {
+		return false, ""
+	}
+	witness, found := findString(func(accepted []bool) bool {
+		return !accepted[0] && accepted[1]
+	}, automatonA, automatonB)
+	return !found, witness
+}
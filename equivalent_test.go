@@ -0,0 +1,134 @@
+package regen_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestEquivalent(t *testing.T) {
+	for _, tt := range []struct {
+		desc           string
+		a, b           regen.Regexp
+		equivalent     bool
+		counterexample string
+	}{
+		{
+			desc:       "simplified",
+			a:          regen.Sequence(regen.OneOf(regen.String("a"), regen.String("b"), regen.Digit).Group().NoCapture(), regen.String("c").Repeat().Min(1).Group().NoCapture().Optional()),
+			b:          regen.Raw(`[ab\d]c*`),
+			equivalent: true,
+		},
+		{
+			desc:       "different structure",
+			a:          regen.Raw(`(a|b)*`),
+			b:          regen.Raw(`(a*b*)*`),
+			equivalent: true,
+		},
+		{
+			desc:           "different languages",
+			a:              regen.Raw(`a+`),
+			b:              regen.Raw(`a*`),
+			counterexample: "",
+		},
+		{
+			desc:           "shortest counterexample",
+			a:              regen.Raw(`\d{2,3}`),
+			b:              regen.Raw(`\d{2,4}`),
+			counterexample: "0000",
+		},
+		{
+			desc:       "case-insensitive",
+			a:          regen.String("ok").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+			b:          regen.Raw(`[oO][kK\x{212A}]`),
+			equivalent: true,
+		},
+		{
+			desc:           "any excludes newlines",
+			a:              regen.Any,
+			b:              regen.Any.Group().NoCapture().SetFlags(regen.FlagMatchNewLine),
+			counterexample: "\n",
+		},
+		{
+			desc:       "anchors",
+			a:          regen.Sequence(regen.TextStart, regen.String("a"), regen.LineEnd),
+			b:          regen.String("a"),
+			equivalent: true,
+		},
+		{
+			desc:       "word boundaries",
+			a:          regen.Sequence(regen.String("a"), regen.ASCIIBoundary, regen.String("b")),
+			b:          regen.Raw(`[^\x00-\x{10FFFF}]`),
+			equivalent: true,
+		},
+		{
+			desc:           "word boundaries that can match",
+			a:              regen.Sequence(regen.String("a"), regen.ASCIIBoundary, regen.Any),
+			b:              regen.Sequence(regen.String("a"), regen.Any),
+			counterexample: "a0",
+		},
+		{
+			desc: "invalid",
+			a:    regen.Raw(`(`),
+			b:    regen.Raw(`(`),
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			equivalent, counterexample := regen.Equivalent(tt.a, tt.b)
+			if equivalent != tt.equivalent || counterexample != tt.counterexample {
+				t.Fatalf("expected (%v, %q), got (%v, %q)", tt.equivalent, tt.counterexample, equivalent, counterexample)
+			}
+			if equivalent || tt.desc == "invalid" {
+				return
+			}
+			full := func(re regen.Regexp) bool {
+				return regexp.MustCompile(`\A(?:` + re.Regexp() + `)\z`).MatchString(counterexample)
+			}
+			if full(tt.a) == full(tt.b) {
+				t.Errorf("expected exactly one expression to match %q", counterexample)
+			}
+		})
+	}
+}
+
+func TestSubsumes(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		a, b     regen.Regexp
+		subsumes bool
+		witness  string
+	}{
+		{
+			desc:     "looser pattern",
+			a:        regen.Digit.Repeat().Min(1).Max(5),
+			b:        regen.Digit.Repeat().Exactly(3),
+			subsumes: true,
+		},
+		{
+			desc:    "tighter pattern",
+			a:       regen.Digit.Repeat().Exactly(3),
+			b:       regen.Digit.Repeat().Min(1).Max(5),
+			witness: "0",
+		},
+		{
+			desc:     "equivalent patterns subsume each other",
+			a:        regen.Raw(`[a-c]`),
+			b:        regen.OneOfStrings("a", "b", "c"),
+			subsumes: true,
+		},
+		{
+			desc:    "disjoint patterns",
+			a:       regen.WordCharacter.Repeat().Min(1),
+			b:       regen.Sequence(regen.String("id-"), regen.Digit),
+			witness: "id-0",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			subsumes, witness := regen.Subsumes(tt.a, tt.b)
+			if subsumes != tt.subsumes || witness != tt.witness {
+				t.Errorf("expected (%v, %q), got (%v, %q)", tt.subsumes, tt.witness, subsumes, witness)
+			}
+		})
+	}
+}
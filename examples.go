@@ -0,0 +1,151 @@
+package regen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Examples are strings that a pattern must and must not match in their entirety. They serve as
+// executable documentation for the pattern, and can be checked using Verify (e.g. in a test), or
+// attached to a registered Regexp using WithExamples.
+type Examples struct {
+	// Match are the strings that the pattern must match
+	Match []string `json:"match,omitempty"`
+	// NoMatch are the strings that the pattern must not match
+	NoMatch []string `json:"no_match,omitempty"`
+}
+
+// ExampleFailure is an example that a pattern doesn't handle as expected
+type ExampleFailure struct {
+	// Example is the string that was checked
+	Example string
+	// ShouldMatch is true if the example is one that the pattern must match
+	ShouldMatch bool
+	// Offset is, for an example that should match, the byte offset of the first character at which
+	// the example stops being the start of a match: no string matched by the pattern begins with
+	// Example[:Offset+1]. It is len(Example) if the example is incomplete.
+	Offset int
+	// Captures are, for an example that shouldn't match, the named groups that participated in the
+	// match and the text that they captured
+	Captures map[string]string
+}
+
+func (f ExampleFailure) String() string {
+	if !f.ShouldMatch {
+		s := strconv.Quote(f.Example) + " should not match, but does"
+		if len(f.Captures) > 0 {
+			var captures []string
+			for name, text := range f.Captures {
+				captures = append(captures, name+"="+strconv.Quote(text))
+			}
+			sort.Strings(captures)
+			s += " (" + strings.Join(captures, ", ") + ")"
+		}
+		return s
+	}
+	if f.Offset == len(f.Example) {
+		return strconv.Quote(f.Example) + " should match, but ends too early"
+	}
+	return fmt.Sprintf("%q should match, but fails at offset %d: %q ^ %q",
+		f.Example, f.Offset, f.Example[:f.Offset], f.Example[f.Offset:])
+}
+
+// ExamplesError is returned by Examples.Verify if any of the examples fail
+type ExamplesError struct {
+	// Pattern is the rendered expression that was checked
+	Pattern  string
+	Failures []ExampleFailure
+}
+
+func (e *ExamplesError) Error() string {
+	lines := []string{fmt.Sprintf("regen: %d examples failed for %s:", len(e.Failures), e.Pattern)}
+	for _, f := range e.Failures {
+		lines = append(lines, "\t"+f.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Verify checks that re matches every string in Match, and none of the strings in NoMatch, returning
+// an *ExamplesError describing each one that fails.
+func (e Examples) Verify(re Regexp) error {
+	pattern := re.Regexp()
+	matcher, err := regexp.Compile(`\A(?:` + pattern + `)\z`)
+	if err != nil {
+		return err
+	}
+	a, err := newAutomaton(re)
+	if err != nil {
+		return err
+	}
+	var failures []ExampleFailure
+	for _, s := range e.Match {
+		if !matcher.MatchString(s) {
+			failures = append(failures, ExampleFailure{Example: s, ShouldMatch: true, Offset: a.failureOffset(s)})
+		}
+	}
+	for _, s := range e.NoMatch {
+		submatches := matcher.FindStringSubmatchIndex(s)
+		if submatches == nil {
+			continue
+		}
+		f := ExampleFailure{Example: s}
+		for i, name := range matcher.SubexpNames() {
+			if name != "" && submatches[2*i] >= 0 {
+				if f.Captures == nil {
+					f.Captures = make(map[string]string)
+				}
+				f.Captures[name] = s[submatches[2*i]:submatches[2*i+1]]
+			}
+		}
+		failures = append(failures, f)
+	}
+	if len(failures) > 0 {
+		return &ExamplesError{Pattern: pattern, Failures: failures}
+	}
+	return nil
+}
+
+// WithExamples attaches examples to an Entry, which are verified when it is registered
+func WithExamples(e Examples) EntryOption {
+	return func(entry *Entry) {
+		entry.Examples = &e
+	}
+}
+
+// failureOffset returns the byte offset of the first rune of s after which no string accepted by the
+// automaton can continue as s does, or len(s) if every prefix of s can be completed
+func (a *automaton) failureOffset(s string) int {
+	state := a.start()
+	runes := alphabet(a)
+	for i, r := range s {
+		state = a.step(state, r)
+		if !a.completable(state, runes) {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// completable returns true if the automaton accepts some string from state s
+func (a *automaton) completable(s *dfaState, runes []rune) bool {
+	seen := make(map[*dfaState]bool)
+	queue := []*dfaState{s}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		if s == nil || seen[s] {
+			continue
+		}
+		seen[s] = true
+		if a.accepts(s) {
+			return true
+		}
+		for _, r := range runes {
+			queue = append(queue, a.step(s, r))
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package regen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestExamplesVerify(t *testing.T) {
+	date := regen.Sequence(
+		regen.Digit.Repeat().Min(4).Max(4).Group().CaptureAs("year"),
+		regen.String("-"),
+		regen.OneOf(
+			regen.Sequence(regen.String("0"), regen.CharRange('1', '9')),
+			regen.Sequence(regen.String("1"), regen.CharRange('0', '2')),
+		).Group().CaptureAs("month"),
+	)
+	for _, tt := range []struct {
+		desc     string
+		examples regen.Examples
+		failures []string
+	}{
+		{
+			desc: "passing",
+			examples: regen.Examples{
+				Match:   []string{"2023-01", "1999-12"},
+				NoMatch: []string{"2023-13", "23-01", ""},
+			},
+		},
+		{
+			desc: "failing",
+			examples: regen.Examples{
+				Match:   []string{"2023-01", "2023-13", "2023-1", "2023-011"},
+				NoMatch: []string{"2023-12"},
+			},
+			failures: []string{
+				`"2023-13" should match, but fails at offset 6: "2023-1" ^ "3"`,
+				`"2023-1" should match, but ends too early`,
+				`"2023-011" should match, but fails at offset 7: "2023-01" ^ "1"`,
+				`"2023-12" should not match, but does (month="12", year="2023")`,
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.examples.Verify(date)
+			if len(tt.failures) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			examplesErr, ok := err.(*regen.ExamplesError)
+			if !ok {
+				t.Fatalf("expected an *ExamplesError, got %v", err)
+			}
+			var failures []string
+			for _, f := range examplesErr.Failures {
+				failures = append(failures, f.String())
+			}
+			if strings.Join(failures, "\n") != strings.Join(tt.failures, "\n") {
+				t.Errorf("expected failures:\n%s\ngot:\n%s", strings.Join(tt.failures, "\n"), strings.Join(failures, "\n"))
+			}
+		})
+	}
+}
+
+func TestWithExamples(t *testing.T) {
+	r := regen.NewRegistry()
+	examples := regen.Examples{Match: []string{"abc"}, NoMatch: []string{"abd"}}
+	if err := r.Register("ok", regen.String("abc"), regen.WithExamples(examples)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry, _ := r.Lookup("ok"); entry.Examples == nil || entry.Examples.Match[0] != "abc" {
+		t.Errorf("expected the examples to be attached to the entry")
+	}
+	err := r.Register("bad", regen.Sequence(regen.String("ab"), regen.Any), regen.WithExamples(examples))
+	if err == nil || !strings.Contains(err.Error(), `"abd" should not match, but does`) {
+		t.Errorf("expected the examples to fail, got %v", err)
+	}
+	if _, ok := r.Lookup("bad"); ok {
+		t.Errorf("expected an entry that fails its examples not to be registered")
+	}
+}
@@ -0,0 +1,262 @@
+package regen
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Feature represents one or more regular expression constructs that a Dialect may support.
+// Multiple features can be combined using a bitwise OR |.
+type Feature uint
+
+const (
+	// FeatureFlagGroups corresponds with inline flags, e.g. (?i) and (?i:...).
+	// Without it, flags are emulated: case-insensitive literals and classes are expanded (e.g. [hH][iI]),
+	// . is expanded to [\s\S] when FlagMatchNewLine is set, and ungreedy repetitions are swapped when
+	// FlagUngreedy is set. FlagMultiLine cannot be emulated.
+	FeatureFlagGroups Feature = 1 << iota
+	// FeatureUnicodeClasses corresponds with Unicode classes, e.g. \p{Greek}.
+	// Without it, Unicode classes are expanded into the ranges of code points that they contain.
+	FeatureUnicodeClasses
+	// FeatureASCIIClasses corresponds with ASCII classes, e.g. [[:alpha:]].
+	// Without it, ASCII classes are expanded into the ranges that they contain.
+	FeatureASCIIClasses
+	// FeatureNamedGroups corresponds with named capturing groups, e.g. (?P<name>...).
+	// Without it, named groups are rendered as unnamed capturing groups, which does not affect their index.
+	FeatureNamedGroups
+	// FeatureCountedRepetition corresponds with counted repetition, e.g. x{2,3}.
+	// Without it, repetitions are expanded (e.g. xx(?:x)?), provided the repeated expression has no capturing groups.
+	FeatureCountedRepetition
+	// FeatureLazyQuantifiers corresponds with ungreedy repetition, e.g. x*?. It cannot be emulated.
+	FeatureLazyQuantifiers
+)
+
+// Without returns a copy of the dialect that does not support the given features.
+// Where possible, constructs relying on those features are rewritten into equivalent forms;
+// otherwise, rendering fails with an *UnsupportedError. This is useful for targeting engines that
+// only support a subset of a dialect's syntax.
+func (d Dialect) Without(features Feature) Dialect {
+	d.missing |= features
+	return d
+}
+
+func (d Dialect) supports(features Feature) bool {
+	return d.missing&features == 0
+}
+
+// emulating returns true if flag is active, but the dialect cannot express it using flag groups
+func (r *renderer) emulating(flag Flag) bool {
+	return r.activeFlags&flag != 0 && !r.dialect.supports(FeatureFlagGroups)
+}
+
+// writeClassRune writes a rune for use within square brackets, escaping it as necessary
+func (r *renderer) writeClassRune(sb *strings.Builder, char rune) {
+	if char > 0xFFFF && r.dialect.bmpOnly {
+		r.unsupported(fmt.Sprintf("code point U+%04X in a character class", char))
+	}
+	if !unicode.IsPrint(char) {
+		sb.WriteString(fmt.Sprintf(r.dialect.codePointFormat, char))
+		return
+	}
+	writeCharSetRune(sb, char)
+}
+
+// unicodeClassRanges returns the ranges of code points in the named Unicode class, for use within
+// square brackets. An empty string is returned if there is no such class.
+func (r *renderer) unicodeClassRanges(name string) string {
+	table, ok := unicode.Categories[name]
+	if !ok {
+		table, ok = unicode.Scripts[name]
+	}
+	if !ok {
+		return ""
+	}
+	var sb strings.Builder
+	for _, rng := range table.R16 {
+		for lo := uint32(rng.Lo); lo <= uint32(rng.Hi); lo += uint32(rng.Stride) {
+			if rng.Stride == 1 {
+				r.writeClassRange(&sb, rune(lo), rune(rng.Hi))
+				break
+			}
+			r.writeClassRange(&sb, rune(lo), rune(lo))
+		}
+	}
+	for _, rng := range table.R32 {
+		for lo := rng.Lo; lo <= rng.Hi; lo += rng.Stride {
+			if rng.Stride == 1 {
+				r.writeClassRange(&sb, rune(lo), rune(rng.Hi))
+				break
+			}
+			r.writeClassRange(&sb, rune(lo), rune(lo))
+		}
+	}
+	return sb.String()
+}
+
+// foldedClassRanges returns the ranges of code points matched by class (a character class in the
+// syntax of RE2, e.g. \p{Lu} or [[:upper:]]) when it is case-insensitive, for use within square
+// brackets. This includes the characters that are equivalent under simple case folding, as RE2 matches
+// them. An empty string is returned if class isn't a valid character class.
+func (r *renderer) foldedClassRanges(class string) string {
+	parsed, err := syntax.Parse(class, syntax.Perl|syntax.FoldCase)
+	if err != nil {
+		return ""
+	}
+	var pairs []rune
+	switch parsed.Op {
+	case syntax.OpCharClass:
+		pairs = parsed.Rune
+	case syntax.OpLiteral:
+		for _, char := range foldRunes(parsed.Rune) {
+			pairs = append(pairs, char, char)
+		}
+	default:
+		return ""
+	}
+	var sb strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		r.writeClassRange(&sb, pairs[i], pairs[i+1])
+	}
+	return sb.String()
+}
+
+// writeClassRange writes the range of code points [lo, hi] for use within square brackets
+func (r *renderer) writeClassRange(sb *strings.Builder, lo, hi rune) {
+	r.writeClassRangeRune(sb, lo)
+	if hi > lo {
+		if hi > lo+1 {
+			sb.WriteByte('-')
+		}
+		r.writeClassRangeRune(sb, hi)
+	}
+}
+
+// writeClassRangeRune is like writeClassRune, but escapes hyphens so that they cannot form ranges
+func (r *renderer) writeClassRangeRune(sb *strings.Builder, char rune) {
+	if char == '-' {
+		sb.WriteByte('\\')
+	}
+	r.writeClassRune(sb, char)
+}
+
+// foldLiteral returns a regular expression matching s case-insensitively, without using flags
+func (r *renderer) foldLiteral(s string) string {
+	var sb strings.Builder
+	for _, char := range s {
+		folded := foldRunes([]rune{char})
+		if len(folded) == 1 {
+			sb.WriteString(regexp.QuoteMeta(string(char)))
+			continue
+		}
+		sb.WriteByte('[')
+		for _, f := range folded {
+			r.writeClassRune(&sb, f)
+		}
+		sb.WriteByte(']')
+	}
+	return sb.String()
+}
+
+// foldRunes returns the sorted set of runes that are equivalent to any of chars under simple case folding
+func foldRunes(chars []rune) []rune {
+	seen := make(map[rune]bool)
+	var folded []rune
+	for _, char := range chars {
+		for f := char; !seen[f]; f = unicode.SimpleFold(f) {
+			seen[f] = true
+			folded = append(folded, f)
+		}
+	}
+	sort.Slice(folded, func(i, j int) bool { return folded[i] < folded[j] })
+	return folded
+}
+
+// foldRange returns the runes outside of [start, end] that are equivalent to a rune within it under
+// simple case folding
+func foldRange(start, end rune) []rune {
+	var extra []rune
+	seen := make(map[rune]bool)
+	for char := start; char <= end; char++ {
+		for f := unicode.SimpleFold(char); f != char; f = unicode.SimpleFold(f) {
+			if (f < start || f > end) && !seen[f] {
+				seen[f] = true
+				extra = append(extra, f)
+			}
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i] < extra[j] })
+	return extra
+}
+
+// caseInsensitiveInvariant returns true if the raw regular expression matches the same strings
+// regardless of whether FlagCaseInsensitive is set
+func caseInsensitiveInvariant(raw string) bool {
+	sensitive, err := syntax.Parse(raw, syntax.Perl)
+	if err != nil {
+		return false
+	}
+	insensitive, err := syntax.Parse(raw, syntax.Perl|syntax.FoldCase)
+	if err != nil {
+		return false
+	}
+	return sensitive.Simplify().String() == insensitive.Simplify().String()
+}
+
+// expand renders the repetition without counted repetition, e.g. x{2,3} as xx(?:x)?
+func (r repeatedRegexp) expand(rr *renderer, subRe string, requiresParens bool, ungreedy bool) string {
+	if hasCapture(r.re) {
+		rr.unsupported("counted repetition of a capturing group")
+	}
+	if requiresParens {
+		subRe = "(?:" + subRe + ")"
+	}
+	lazy := ""
+	if ungreedy {
+		lazy = "?"
+	}
+	var sb strings.Builder
+	for i := uint(0); i < r.min; i++ {
+		sb.WriteString(subRe)
+	}
+	if !r.hasMax {
+		sb.WriteString(subRe + "*" + lazy)
+		return sb.String()
+	}
+	// Each optional repetition is nested within the previous one, e.g. x{0,3} is (?:x(?:x(?:x)?)?)?
+	optional := r.max - r.min
+	for i := uint(0); i < optional; i++ {
+		sb.WriteString("(?:" + subRe)
+	}
+	for i := uint(0); i < optional; i++ {
+		sb.WriteString(")?" + lazy)
+	}
+	return sb.String()
+}
+
+// hasCapture returns true if re contains a capturing group
+func hasCapture(re Regexp) bool {
+	switch re := re.(type) {
+	case groupedRegexp:
+		return !re.noCapture || re.balance != "" || hasCapture(re.re)
+	case annotatedRegexp:
+		return hasCapture(re.re)
+	case repeatedRegexp:
+		// Repetitions that require parentheses are wrapped in a capturing group
+		return hasCapture(re.re) || requiresParens(re.re, re.re.Regexp())
+	case multiRegexp:
+		for _, sub := range re.res {
+			if hasCapture(sub) {
+				return true
+			}
+		}
+		return false
+	case CharClass, anchorRegexp, anyRegexp:
+		return false
+	}
+	parsed, err := syntax.Parse(re.Regexp(), syntax.Perl)
+	return err != nil || parsed.MaxCap() > 0
+}
@@ -0,0 +1,83 @@
+package regen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FluentBitOption configures the parser generated by ToFluentBitParser
+type FluentBitOption func(*fluentBitParser)
+
+type fluentBitParser struct {
+	timeKey    string
+	timeFormat string
+	types      map[string]string
+}
+
+// WithTimeKey sets the group that contains the time of the record, and its format (in strptime syntax,
+// e.g. %d/%b/%Y:%H:%M:%S %z)
+func WithTimeKey(group, format string) FluentBitOption {
+	return func(p *fluentBitParser) {
+		p.timeKey = group
+		p.timeFormat = format
+	}
+}
+
+// WithType converts the value of the group to a type (integer, float, bool, string, hex or json)
+// rather than leaving it as a string
+func WithType(group, typ string) FluentBitOption {
+	return func(p *fluentBitParser) {
+		if p.types == nil {
+			p.types = make(map[string]string)
+		}
+		p.types[group] = typ
+	}
+}
+
+// ToFluentBitParser returns a [PARSER] section for a Fluent Bit parsers file, which parses records
+// using re (rendered in DialectRuby, since Fluent Bit uses Onigmo). Each named group becomes a key
+// in the parsed record. An error is returned if an option refers to a group that is not in re.
+func ToFluentBitParser(name string, re Regexp, opts ...FluentBitOption) (string, error) {
+	var p fluentBitParser
+	for _, opt := range opts {
+		opt(&p)
+	}
+	pattern, err := DialectRuby.Render(re)
+	if err != nil {
+		return "", err
+	}
+	groups := make(map[string]bool)
+	Walk(re, func(node Regexp) bool {
+		if g, ok := node.(groupedRegexp); ok && g.name != "" {
+			groups[g.name] = true
+		}
+		return true
+	})
+
+	lines := [][2]string{{"Name", name}, {"Format", "regex"}, {"Regex", pattern}}
+	if p.timeKey != "" {
+		if !groups[p.timeKey] {
+			return "", fmt.Errorf("regen: time key %q is not a named group", p.timeKey)
+		}
+		lines = append(lines, [2]string{"Time_Key", p.timeKey}, [2]string{"Time_Format", p.timeFormat})
+	}
+	if len(p.types) > 0 {
+		var types []string
+		for group, typ := range p.types {
+			if !groups[group] {
+				return "", fmt.Errorf("regen: %q is not a named group", group)
+			}
+			types = append(types, group+":"+typ)
+		}
+		sort.Strings(types)
+		lines = append(lines, [2]string{"Types", strings.Join(types, " ")})
+	}
+
+	var sb strings.Builder
+	sb.WriteString("[PARSER]\n")
+	for _, line := range lines {
+		fmt.Fprintf(&sb, "    %-11s %s\n", line[0], line[1])
+	}
+	return sb.String(), nil
+}
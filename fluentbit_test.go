@@ -0,0 +1,50 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+var nginxPattern = regen.Sequence(
+	regen.LineStart,
+	regen.Whitespace.Negate().Repeat().Min(1).Group().CaptureAs("remote"),
+	regen.String(" ["),
+	regen.CharSet(']').Negate().Repeat().Min(1).Group().CaptureAs("time"),
+	regen.String("] "),
+	regen.Digit.Repeat().Exactly(3).Group().CaptureAs("code"),
+	regen.String(" "),
+	regen.Digit.Repeat().Min(1).Group().CaptureAs("size"),
+	regen.LineEnd,
+)
+
+func TestToFluentBitParser(t *testing.T) {
+	actual, err := regen.ToFluentBitParser("nginx", nginxPattern,
+		regen.WithTimeKey("time", "%d/%b/%Y:%H:%M:%S %z"),
+		regen.WithType("size", "integer"),
+		regen.WithType("code", "integer"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `[PARSER]
+    Name        nginx
+    Format      regex
+    Regex       \A(?<remote>[^\t\n\f\r ]+) \[(?<time>[^\]]+)\] (?<code>\d{3}) (?<size>\d+)\z
+    Time_Key    time
+    Time_Format %d/%b/%Y:%H:%M:%S %z
+    Types       code:integer size:integer
+`
+	if actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestToFluentBitParser_UnknownGroup(t *testing.T) {
+	if _, err := regen.ToFluentBitParser("nginx", nginxPattern, regen.WithTimeKey("timestamp", "%s")); err == nil {
+		t.Error("expected error for unknown time key")
+	}
+	if _, err := regen.ToFluentBitParser("nginx", nginxPattern, regen.WithType("bytes", "integer")); err == nil {
+		t.Error("expected error for unknown type")
+	}
+}
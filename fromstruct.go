@@ -0,0 +1,326 @@
+package regen
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// structPatterns contains the patterns that can be selected by the pattern option of FromStruct
+var structPatterns = map[string]Regexp{
+	"alnum": ASCIICharClass("alnum").Repeat().Min(1),
+	"alpha": ASCIICharClass("alpha").Repeat().Min(1),
+	"bool":  OneOfStrings("1", "t", "T", "TRUE", "true", "True", "0", "f", "F", "FALSE", "false", "False"),
+	"field": Whitespace.Negate().Repeat().Min(1),
+	"float": Sequence(
+		CharSet('-', '+').Optional(),
+		Digit.Repeat().Min(1),
+		Sequence(String("."), Digit.Repeat().Min(1)).Group().NoCapture().Optional(),
+	),
+	"hex":  HexDigit.Repeat().Min(1),
+	"int":  Sequence(CharSet('-', '+').Optional(), Digit.Repeat().Min(1)),
+	"ipv4": Sequence(Digit.Repeat().Min(1).Max(3), Sequence(String("."), Digit.Repeat().Min(1).Max(3)).Group().NoCapture().Repeat().Exactly(3)),
+	"rest": Any.Repeat(),
+	"uint": Digit.Repeat().Min(1),
+	"uuid": Sequence(
+		HexDigit.Repeat().Exactly(8),
+		String("-"),
+		HexDigit.Repeat().Exactly(4),
+		String("-"),
+		HexDigit.Repeat().Exactly(4),
+		String("-"),
+		HexDigit.Repeat().Exactly(4),
+		String("-"),
+		HexDigit.Repeat().Exactly(12),
+	),
+	"word": WordCharacter.Repeat().Min(1),
+}
+
+// FromStruct returns a Regexp that matches an entire line made up of the fields of the struct v
+// (or pointer to a struct), in order, with each field captured by a named group that Unmarshal
+// stores in it. This keeps a pattern and the type that it is extracted into in one place.
+//
+// Fields are separated by a single space by default, and match a pattern that depends on their
+// type (e.g. integers match an optional sign followed by digits, while strings match any
+// non-whitespace characters). Options in the regen tag (see Unmarshal) change this:
+//
+//	pattern=NAME   matches one of the predefined patterns: alnum, alpha, bool, field (non-whitespace),
+//	               float, hex, int, ipv4, rest (the remainder of the line), uint, uuid or word
+//	regexp='RE'    matches the raw regular expression RE
+//	sep='SEP'      sets the literal that follows the field. If it is the last field, the line
+//	               must end with SEP
+//	charset=NAME   matches characters of the ASCII class NAME, e.g. alnum, lower or xdigit (see
+//	               ASCIICharClass), rather than any non-whitespace characters
+//	min=N, max=N   sets the minimum (1 by default) and maximum number of characters to match
+//
+// For example: ID string `regen:"id,pattern=uuid,sep=': '"`, or
+// Name string `regen:"name,min=3,max=20,charset=alnum"`
+//
+// Pointer fields are optional, along with the separator before them. Nested structs are composed
+// from their fields, which are captured with the field's name as a prefix, as expected by Unmarshal.
+// Slices are not supported.
+func FromStruct(v interface{}) (Regexp, error) {
+	t, err := structType(v, "FromStruct")
+	if err != nil {
+		return nil, err
+	}
+	fields, err := structPattern(t, "")
+	if err != nil {
+		return nil, err
+	}
+	return Sequence(LineStart, fields, LineEnd), nil
+}
+
+// structPattern returns the pattern for the fields of the struct type t, whose groups are named
+// with the given prefix
+func structPattern(t reflect.Type, prefix string) (Regexp, error) {
+	var parts []Regexp
+	var last fieldTag
+	sep, fields := "", 0
+	err := eachField(t, func(field reflect.StructField, tag fieldTag) error {
+		re, err := fieldPattern(field.Type, prefix+tag.name, tag)
+		if err != nil {
+			return fmt.Errorf("regen: field %s: %v", field.Name, err)
+		}
+		if fields > 0 {
+			re = Sequence(String(sep), re)
+		}
+		if field.Type.Kind() == reflect.Ptr {
+			re = re.Group().NoCapture().Optional()
+		}
+		parts = append(parts, re)
+		fields++
+		sep = " "
+		if tag.hasSep {
+			sep = tag.sep
+		}
+		last = tag
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if fields == 0 {
+		return nil, fmt.Errorf("regen: %s has no fields", t)
+	}
+	if last.hasSep {
+		parts = append(parts, String(last.sep))
+	}
+	return Sequence(parts...), nil
+}
+
+// fieldPattern returns the pattern for a field of type t, whose group is named name
+func fieldPattern(t reflect.Type, name string, tag fieldTag) (Regexp, error) {
+	if isStruct(t) {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		return structPattern(t, name+"_")
+	}
+	re, err := valuePattern(t, tag)
+	if err != nil {
+		return nil, err
+	}
+	return re.Group().CaptureAs(name), nil
+}
+
+// valuePattern returns the pattern for the value of a field of type t, which isn't a struct
+func valuePattern(t reflect.Type, tag fieldTag) (Regexp, error) {
+	lengths := tag.charset != "" || tag.hasMin || tag.hasMax
+	var re Regexp
+	switch {
+	case lengths && (tag.regexp != "" || tag.pattern != ""):
+		return nil, fmt.Errorf("charset, min and max can't be combined with pattern or regexp")
+	case lengths:
+		return lengthPattern(tag)
+	case tag.regexp != "":
+		re = Raw(tag.regexp)
+	case tag.pattern != "":
+		var ok bool
+		if re, ok = structPatterns[tag.pattern]; !ok {
+			return nil, fmt.Errorf("unknown pattern %q (expected one of %s)", tag.pattern, strings.Join(structPatternNames(), ", "))
+		}
+	default:
+		if t.Kind() == reflect.Slice && !hasConverter(t) {
+			return nil, fmt.Errorf("slices are not supported")
+		}
+		re = structPatterns[defaultPatternName(t)]
+	}
+	return re, nil
+}
+
+// lengthPattern returns the pattern for the charset, min and max options of tag
+func lengthPattern(tag fieldTag) (Regexp, error) {
+	var class CharClass = Whitespace.Negate()
+	if tag.charset != "" {
+		if _, ok := asciiClassRanges[tag.charset]; !ok {
+			names := make([]string, 0, len(asciiClassRanges))
+			for name := range asciiClassRanges {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown charset %q (expected one of %s)", tag.charset, strings.Join(names, ", "))
+		}
+		class = ASCIICharClass(tag.charset)
+	}
+	min := uint(1)
+	if tag.hasMin {
+		min = tag.min
+	}
+	re := class.Repeat().Min(min)
+	if tag.hasMax {
+		if tag.max < min {
+			return nil, fmt.Errorf("max %d is less than min %d", tag.max, min)
+		}
+		re = re.Max(tag.max)
+	}
+	return re, nil
+}
+
+// FieldPatterns returns a pattern for each field of the struct v (or pointer to a struct), keyed by the
+// name of its group in the pattern returned by FromStruct, which matches an entire value of the field.
+// These validate fields individually, e.g. input from a form.
+func FieldPatterns(v interface{}) (map[string]Regexp, error) {
+	t, err := structType(v, "FieldPatterns")
+	if err != nil {
+		return nil, err
+	}
+	patterns := make(map[string]Regexp)
+	if err := fieldPatterns(t, "", patterns); err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
+
+func fieldPatterns(t reflect.Type, prefix string, patterns map[string]Regexp) error {
+	return eachField(t, func(field reflect.StructField, tag fieldTag) error {
+		name := prefix + tag.name
+		if isStruct(field.Type) {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			return fieldPatterns(ft, name+"_", patterns)
+		}
+		re, err := valuePattern(field.Type, tag)
+		if err != nil {
+			return fmt.Errorf("regen: field %s: %v", field.Name, err)
+		}
+		patterns[name] = Sequence(TextStart, re, TextEnd)
+		return nil
+	})
+}
+
+// Validate checks that the string fields of the struct v (or pointer to a struct), including those
+// of nested structs, match their patterns (see FieldPatterns). Nil pointers are not checked. An error
+// describing the first field that doesn't match is returned.
+func Validate(v interface{}) error {
+	t, err := structType(v, "Validate")
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	return validateStruct(t, rv)
+}
+
+func validateStruct(t reflect.Type, v reflect.Value) error {
+	return eachField(t, func(field reflect.StructField, tag fieldTag) error {
+		fv := v.FieldByIndex(field.Index)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				return nil
+			}
+			fv = fv.Elem()
+		}
+		if isStruct(field.Type) {
+			return validateStruct(fv.Type(), fv)
+		}
+		if fv.Kind() != reflect.String {
+			return nil
+		}
+		re, err := valuePattern(field.Type, tag)
+		if err != nil {
+			return fmt.Errorf("regen: field %s: %v", field.Name, err)
+		}
+		re = Sequence(TextStart, re, TextEnd)
+		compiled, err := CompileCached(re)
+		if err != nil {
+			return fmt.Errorf("regen: field %s: %v", field.Name, err)
+		}
+		if !compiled.MatchString(fv.String()) {
+			return fmt.Errorf("regen: field %s: %q does not match %s", field.Name, fv.String(), re.Regexp())
+		}
+		return nil
+	})
+}
+
+// structType returns the type of the struct v, or pointer to a struct, for the function fn
+func structType(v interface{}, fn string) (reflect.Type, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("regen: %s requires a struct or a pointer to a struct, got %T", fn, v)
+	}
+	return t, nil
+}
+
+// eachField calls fn with the exported fields of the struct type t that aren't ignored, stopping at
+// the first error
+func eachField(t reflect.Type, fn func(reflect.StructField, fieldTag) error) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag, err := parseFieldTag(field)
+		if err != nil {
+			return err
+		}
+		if tag.name == "-" {
+			continue
+		}
+		if err := fn(field, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defaultPatternName returns the name of the pattern used for fields of type t
+func defaultPatternName(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr && !hasConverter(t) {
+		t = t.Elem()
+	}
+	if hasConverter(t) {
+		return "field"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	}
+	return "field"
+}
+
+func structPatternNames() []string {
+	names := make([]string, 0, len(structPatterns))
+	for name := range structPatterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
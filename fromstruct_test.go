@@ -0,0 +1,179 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+type accessLog struct {
+	ID      string `regen:"id,pattern=uuid,sep=': '"`
+	Status  int    `regen:"status"`
+	Elapsed *float64
+	Client  struct {
+		IP   string `regen:"ip,pattern=ipv4,sep=':'"`
+		Port uint   `regen:"port"`
+	} `regen:"client"`
+	Path    string `regen:"path,regexp='/[^ ,]*',sep=','"`
+	Ignored string `regen:"-"`
+}
+
+func TestFromStruct(t *testing.T) {
+	re, err := regen.FromStruct(&accessLog{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `^(?P<id>[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}): ` +
+		`(?P<status>[-+]?\d+)(?: (?P<Elapsed>[-+]?\d+(?:\.\d+)?))? ` +
+		`(?P<client_ip>\d{1,3}(?:\.\d{1,3}){3}):(?P<client_port>\d+) (?P<path>/[^ ,]*),$`
+	if actual := re.Regexp(); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	var v accessLog
+	if err := regen.Unmarshal(re, "123e4567-e89b-12d3-a456-426614174000: 200 0.25 10.0.0.1:8080 /index.html,", &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Status != 200 || v.Elapsed == nil || *v.Elapsed != 0.25 || v.Client.IP != "10.0.0.1" || v.Client.Port != 8080 || v.Path != "/index.html" {
+		t.Errorf("unexpected result: %+v", v)
+	}
+
+	v = accessLog{}
+	if err := regen.Unmarshal(re, "123e4567-e89b-12d3-a456-426614174000: -1 10.0.0.1:1 /,", &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Status != -1 || v.Elapsed != nil {
+		t.Errorf("unexpected result: %+v", v)
+	}
+}
+
+func TestFromStruct_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		desc string
+		v    interface{}
+	}{
+		{desc: "not a struct", v: "nope"},
+		{desc: "nil", v: nil},
+		{desc: "no fields", v: struct{ unexported int }{}},
+		{desc: "unknown pattern", v: struct {
+			A string `regen:"a,pattern=nope"`
+		}{}},
+		{desc: "slice", v: struct {
+			A []string `regen:"a"`
+		}{}},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			if _, err := regen.FromStruct(tt.v); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+type signup struct {
+	User struct {
+		Name  string `regen:"name,min=3,max=20,charset=alnum"`
+		Email string `regen:"email,regexp='[^@ ]+@[^@ ]+'"`
+	} `regen:"user"`
+	Token    string  `regen:"token,pattern=uuid"`
+	Referrer *string `regen:"referrer,charset=lower,min=0"`
+	Age      int     `regen:"age"`
+}
+
+func TestFromStruct_Lengths(t *testing.T) {
+	re, err := regen.FromStruct(signup{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `^(?P<user_name>[[:alnum:]]{3,20}) (?P<user_email>[^@ ]+@[^@ ]+) ` +
+		`(?P<token>[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12})` +
+		`(?: (?P<referrer>[[:lower:]]*))? (?P<age>[-+]?\d+)$`
+	if actual := re.Regexp(); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestFieldPatterns(t *testing.T) {
+	patterns, err := regen.FieldPatterns(&signup{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, expected := range map[string]string{
+		"user_name":  `\A[[:alnum:]]{3,20}\z`,
+		"user_email": `\A[^@ ]+@[^@ ]+\z`,
+		"referrer":   `\A[[:lower:]]*\z`,
+		"age":        `\A[-+]?\d+\z`,
+	} {
+		if re, ok := patterns[name]; !ok || re.Regexp() != expected {
+			t.Errorf("expected the pattern for %s to be %s, got %v", name, expected, re)
+		}
+	}
+	if len(patterns) != 5 {
+		t.Errorf("expected 5 patterns, got %d", len(patterns))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *signup {
+		var v signup
+		v.User.Name = "gopher"
+		v.User.Email = "gopher@example.com"
+		v.Token = "123e4567-e89b-12d3-a456-426614174000"
+		return &v
+	}
+	for _, tt := range []struct {
+		desc     string
+		modify   func(*signup)
+		expected string
+	}{
+		{desc: "valid", modify: func(*signup) {}},
+		{desc: "too short", modify: func(v *signup) { v.User.Name = "go" }, expected: `regen: field Name: "go" does not match \A[[:alnum:]]{3,20}\z`},
+		{desc: "wrong characters", modify: func(v *signup) { v.User.Name = "go-pher" }, expected: `regen: field Name: "go-pher" does not match \A[[:alnum:]]{3,20}\z`},
+		{desc: "optional field", modify: func(v *signup) { r := "Friend"; v.Referrer = &r }, expected: `regen: field Referrer: "Friend" does not match \A[[:lower:]]*\z`},
+		{desc: "predefined pattern", modify: func(v *signup) { v.Token = "nope" }, expected: `regen: field Token: "nope" does not match \A[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}\z`},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			v := valid()
+			tt.modify(v)
+			err := regen.Validate(v)
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		v        interface{}
+		expected string
+	}{
+		{desc: "not a struct", v: "nope", expected: `regen: Validate requires a struct or a pointer to a struct, got string`},
+		{desc: "unknown charset", v: struct {
+			A string `regen:"a,charset=emoji"`
+		}{}, expected: `regen: field A: unknown charset "emoji" (expected one of alnum, alpha, ascii, blank, cntrl, digit, graph, lower, print, punct, space, upper, word, xdigit)`},
+		{desc: "invalid length", v: struct {
+			A string `regen:"a,min=-1"`
+		}{}, expected: `regen: field A: min must be a non-negative integer, got "-1"`},
+		{desc: "max less than min", v: struct {
+			A string `regen:"a,min=3,max=2"`
+		}{}, expected: `regen: field A: max 2 is less than min 3`},
+		{desc: "conflicting options", v: struct {
+			A string `regen:"a,pattern=word,max=2"`
+		}{}, expected: `regen: field A: charset, min and max can't be combined with pattern or regexp`},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := regen.Validate(tt.v)
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package regen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// WriteFuzzCorpus writes up to n strings that re matches, and up to n strings that are close to
+// matching (see GenerateNearMiss), to dir as seed corpus files for go test -fuzz. dir should be the
+// corpus directory of a fuzz test taking a single string, e.g. testdata/fuzz/FuzzParseDate, and is
+// created if needed.
+//
+// The strings are generated deterministically from the structure of re, and files are named after
+// a hash of their contents (as the go command does), so rewriting the corpus for an unchanged
+// expression doesn't change the files.
+func WriteFuzzCorpus(re Regexp, dir string, n int) error {
+	g, err := newGenerator(re, nil)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	r := rand.New(rand.NewSource(int64(Hash(re))))
+	for i := 0; i < n; i++ {
+		s, err := g.generate(r)
+		if err != nil {
+			return err
+		}
+		if err := writeFuzzInput(dir, s); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < n; i++ {
+		s, err := GenerateNearMiss(re, r)
+		if err != nil {
+			// re matches (almost) every string
+			break
+		}
+		if err := writeFuzzInput(dir, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFuzzInput writes s to dir in the format of the go command's fuzz corpus files
+func writeFuzzInput(dir, s string) error {
+	data := []byte("go test fuzz v1\nstring(" + strconv.Quote(s) + ")\n")
+	digest := sha256.Sum256(data)
+	name := hex.EncodeToString(digest[:])[:16]
+	return ioutil.WriteFile(filepath.Join(dir, name), data, 0644)
+}
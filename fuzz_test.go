@@ -0,0 +1,75 @@
+package regen_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestWriteFuzzCorpus(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "regen-fuzz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "testdata", "fuzz", "FuzzParse")
+
+	re := regen.Sequence(regen.Digit.Repeat().Min(4).Max(4), regen.String("-"), regen.Digit.Repeat().Min(2).Max(2))
+	if err := regen.WriteFuzzCorpus(re, dir, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 || len(files) > 20 {
+		t.Fatalf("expected between 1 and 20 files, got %d", len(files))
+	}
+
+	matcher := regexp.MustCompile(`\A(?:` + re.Regexp() + `)\z`)
+	var matches, misses int
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		if len(lines) != 2 || lines[0] != "go test fuzz v1" || !strings.HasPrefix(lines[1], "string(") {
+			t.Fatalf("unexpected corpus file contents %q", data)
+		}
+		s, err := strconv.Unquote(strings.TrimSuffix(strings.TrimPrefix(lines[1], "string("), ")"))
+		if err != nil {
+			t.Fatalf("invalid string in %q: %v", data, err)
+		}
+		if matcher.MatchString(s) {
+			matches++
+		} else {
+			misses++
+		}
+	}
+	if matches == 0 || misses == 0 {
+		t.Errorf("expected matching and near-matching inputs, got %d and %d", matches, misses)
+	}
+
+	// Rewriting the corpus doesn't add files
+	if err := regen.WriteFuzzCorpus(re, dir, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	files, _ = ioutil.ReadDir(dir)
+	var rewritten []string
+	for _, f := range files {
+		rewritten = append(rewritten, f.Name())
+	}
+	if !reflect.DeepEqual(names, rewritten) {
+		t.Errorf("expected files %v, got %v", names, rewritten)
+	}
+}
@@ -0,0 +1,310 @@
+package regen
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// GenerateOption configures the strings produced by Generate
+type GenerateOption func(*generateConfig)
+
+type generateConfig struct {
+	maxRepeat int
+	runes     []rune
+}
+
+// WithMaxRepeat limits the number of times that an unbounded repetition (e.g. a* or a{2,}) is
+// repeated beyond its minimum. The default is 10.
+func WithMaxRepeat(n int) GenerateOption {
+	return func(c *generateConfig) {
+		c.maxRepeat = n
+	}
+}
+
+// WithRunePool sets the characters that are preferred when generating a match for a character
+// class or Any. Characters outside of the pool are only used for classes that match none of the
+// characters in it. The default pool is printable ASCII.
+func WithRunePool(runes string) GenerateOption {
+	return func(c *generateConfig) {
+		c.runes = []rune(runes)
+	}
+}
+
+// generateAttempts is the number of strings that Generate tries before giving up, since strings
+// that satisfy each node may not satisfy the zero-width assertions (such as \b) between them
+const generateAttempts = 100
+
+// Generate returns a random string that re matches in its entirety, e.g. for use as test data.
+// It returns an error if re doesn't compile, or if no matching string was found (for instance, if
+// re can never match).
+func Generate(re Regexp, r *rand.Rand, opts ...GenerateOption) (string, error) {
+	g, err := newGenerator(re, opts)
+	if err != nil {
+		return "", err
+	}
+	return g.generate(r)
+}
+
+// generator produces random strings that match an expression
+type generator struct {
+	config  generateConfig
+	pattern string
+	parsed  *syntax.Regexp
+	matcher *regexp.Regexp
+}
+
+func newGenerator(re Regexp, opts []GenerateOption) (*generator, error) {
+	config := generateConfig{maxRepeat: 10}
+	for i := 0; i <= unicode.MaxASCII; i++ {
+		if unicode.IsPrint(rune(i)) {
+			config.runes = append(config.runes, rune(i))
+		}
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	pattern := re.Regexp()
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := regexp.Compile(`\A(?:` + pattern + `)\z`)
+	if err != nil {
+		return nil, err
+	}
+	return &generator{config: config, pattern: pattern, parsed: parsed, matcher: matcher}, nil
+}
+
+func (g *generator) generate(r *rand.Rand) (string, error) {
+	for i := 0; i < generateAttempts; i++ {
+		var sb strings.Builder
+		if g.write(&sb, r, g.parsed, nil) && g.matcher.MatchString(sb.String()) {
+			return sb.String(), nil
+		}
+	}
+	return "", fmt.Errorf("regen: could not generate a string matching %s", g.pattern)
+}
+
+// write writes a random string matching re to sb, returning false if re can't match. If m is not
+// nil, the string is mutated at one of the sites that it counts (see GenerateNearMiss).
+func (g *generator) write(sb *strings.Builder, r *rand.Rand, re *syntax.Regexp, m *mutation) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpLiteral:
+		if m.at() {
+			g.mutateLiteral(sb, r, re)
+			return true
+		}
+		for _, c := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 {
+				c = randomFold(r, c)
+			}
+			sb.WriteRune(c)
+		}
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return false
+		}
+		g.writeClass(sb, r, re.Rune, m)
+	case syntax.OpAnyCharNotNL:
+		g.writeClass(sb, r, []rune{0, '\n' - 1, '\n' + 1, unicode.MaxRune}, m)
+	case syntax.OpAnyChar:
+		if m.at() {
+			// Every character matches
+			return false
+		}
+		sb.WriteRune(g.pick(r, []rune{0, unicode.MaxRune}))
+	case syntax.OpCapture:
+		return g.write(sb, r, re.Sub[0], m)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := repeatBounds(re)
+		var n int
+		if (min > 0 || max >= 0) && m.at() {
+			// Repeat just outside of the bounds
+			n = min - 1
+			if max >= 0 && (n < 0 || r.Intn(2) == 0) {
+				n = max + 1
+			}
+		} else {
+			if max < 0 {
+				max = min + g.config.maxRepeat
+			}
+			n = min + r.Intn(max-min+1)
+		}
+		for ; n > 0; n-- {
+			if !g.write(sb, r, re.Sub[0], m) {
+				return false
+			}
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !g.write(sb, r, sub, m) {
+				return false
+			}
+		}
+	case syntax.OpAlternate:
+		return g.write(sb, r, re.Sub[r.Intn(len(re.Sub))], m)
+	}
+	// Empty matches and zero-width assertions are checked once the whole string is generated
+	return true
+}
+
+// writeClass writes a random rune within the (sorted) pairs of inclusive ranges, or one just outside
+// of them if the mutation is applied here
+func (g *generator) writeClass(sb *strings.Builder, r *rand.Rand, ranges []rune, m *mutation) {
+	if !m.at() {
+		sb.WriteRune(g.pick(r, ranges))
+		return
+	}
+	outside := complementRanges(ranges)
+	if len(outside) == 0 {
+		return
+	}
+	// Prefer the characters on either side of each range
+	var neighbours []rune
+	for i := 0; i < len(ranges); i += 2 {
+		if c := ranges[i] - 1; c >= 0 {
+			neighbours = append(neighbours, c)
+		}
+		if c := ranges[i+1] + 1; c <= unicode.MaxRune {
+			neighbours = append(neighbours, c)
+		}
+	}
+	if len(neighbours) > 0 && r.Intn(2) == 0 {
+		sb.WriteRune(neighbours[r.Intn(len(neighbours))])
+		return
+	}
+	sb.WriteRune(g.pick(r, outside))
+}
+
+// mutateLiteral writes the literal with one of its characters replaced or removed
+func (g *generator) mutateLiteral(sb *strings.Builder, r *rand.Rand, re *syntax.Regexp) {
+	i := r.Intn(len(re.Rune))
+	for j, c := range re.Rune {
+		if j != i {
+			sb.WriteRune(c)
+			continue
+		}
+		if r.Intn(2) == 0 {
+			continue
+		}
+		var ranges []rune
+		for _, f := range append([]rune{c}, foldedRunes(c, re.Flags)...) {
+			ranges = append(ranges, f, f)
+		}
+		sort.Slice(ranges, func(i, j int) bool { return ranges[i] < ranges[j] })
+		sb.WriteRune(g.pick(r, complementRanges(ranges)))
+	}
+}
+
+// pick returns a random rune within the (sorted) pairs of inclusive ranges, preferring those in the
+// rune pool
+func (g *generator) pick(r *rand.Rand, ranges []rune) rune {
+	var pool []rune
+	for _, c := range g.config.runes {
+		if inRanges(c, ranges) {
+			pool = append(pool, c)
+		}
+	}
+	if len(pool) > 0 {
+		return pool[r.Intn(len(pool))]
+	}
+	for i := 0; i < generateAttempts; i++ {
+		j := 2 * r.Intn(len(ranges)/2)
+		c := ranges[j] + rune(r.Int63n(int64(ranges[j+1]-ranges[j])+1))
+		// Surrogates can't be encoded in UTF-8
+		if c < 0xD800 || c > 0xDFFF {
+			return c
+		}
+	}
+	return ranges[0]
+}
+
+// repeatBounds returns the minimum and maximum number of repetitions of re, where max is -1 if
+// it is unbounded
+func repeatBounds(re *syntax.Regexp) (min, max int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, -1
+	case syntax.OpPlus:
+		return 1, -1
+	case syntax.OpQuest:
+		return 0, 1
+	}
+	return re.Min, re.Max
+}
+
+// inRanges returns true if c is within the (sorted) pairs of inclusive ranges
+func inRanges(c rune, ranges []rune) bool {
+	for i := 0; i < len(ranges); i += 2 {
+		if c >= ranges[i] && c <= ranges[i+1] {
+			return true
+		}
+	}
+	return false
+}
+
+// randomFold returns a random rune that is equivalent to c under case folding
+func randomFold(r *rand.Rand, c rune) rune {
+	folds := append([]rune{c}, foldedRunes(c, syntax.FoldCase)...)
+	return folds[r.Intn(len(folds))]
+}
+
+// foldedRunes returns the other runes that are equivalent to c under case folding, if enabled by flags
+func foldedRunes(c rune, flags syntax.Flags) []rune {
+	var folds []rune
+	if flags&syntax.FoldCase != 0 {
+		for f := unicode.SimpleFold(c); f != c; f = unicode.SimpleFold(f) {
+			folds = append(folds, f)
+		}
+	}
+	return folds
+}
+
+// mutation selects the site at which a string is mutated: sites are counted as they are generated,
+// and the mutation is applied at the target
+type mutation struct {
+	sites  int
+	target int
+}
+
+// at returns true if the mutation should be applied at the next site
+func (m *mutation) at() bool {
+	if m == nil {
+		return false
+	}
+	m.sites++
+	return m.sites-1 == m.target
+}
+
+// GenerateNearMiss returns a random string that re doesn't match, but which is close to one that it
+// does: a generated match is mutated at a single site, e.g. by repeating a quantifier once more than
+// its maximum, using a character just outside of a class or dropping a character from a literal.
+// This produces adversarial test cases for validation patterns. It returns an error if re doesn't
+// compile, or if no such string was found (for instance, if re matches every string).
+func GenerateNearMiss(re Regexp, r *rand.Rand, opts ...GenerateOption) (string, error) {
+	g, err := newGenerator(re, opts)
+	if err != nil {
+		return "", err
+	}
+	for i := 0; i < generateAttempts; i++ {
+		// Count the sites in one generated string, and then mutate a random one in the next
+		counter := &mutation{target: -1}
+		var sb strings.Builder
+		g.write(&sb, r, g.parsed, counter)
+		if counter.sites == 0 {
+			break
+		}
+		sb.Reset()
+		if g.write(&sb, r, g.parsed, &mutation{target: r.Intn(counter.sites)}) && !g.matcher.MatchString(sb.String()) {
+			return sb.String(), nil
+		}
+	}
+	return "", fmt.Errorf("regen: could not generate a string close to matching %s", g.pattern)
+}
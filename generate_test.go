@@ -0,0 +1,139 @@
+package regen_test
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestGenerate(t *testing.T) {
+	for _, tt := range []struct {
+		desc string
+		re   regen.Regexp
+		opts []regen.GenerateOption
+	}{
+		{
+			desc: "literals and classes",
+			re:   regen.Sequence(regen.String("id-"), regen.Digit.Repeat().Min(3).Max(5), regen.CharSet('a', 'b').Optional()),
+		},
+		{
+			desc: "alternatives and groups",
+			re: regen.Sequence(
+				regen.OneOfStrings("GET", "POST").Group().CaptureAs("method"),
+				regen.String(" /"),
+				regen.Union(regen.WordCharacter, regen.CharSet('/', '.')).Repeat().Group().CaptureAs("path"),
+			),
+		},
+		{
+			desc: "case-insensitive",
+			re:   regen.String("hello").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+		},
+		{
+			desc: "negated class",
+			re:   regen.CharSet('a').Negate().Repeat().Min(1),
+		},
+		{
+			desc: "word boundaries",
+			re:   regen.Sequence(regen.WordCharacter.Repeat(), regen.ASCIIBoundary, regen.Any.Repeat()),
+		},
+		{
+			desc: "raw",
+			re:   regen.Raw(`[[:upper:]]{2}\d+`),
+		},
+		{
+			desc: "rune pool",
+			re:   regen.Any.Repeat().Min(1),
+			opts: []regen.GenerateOption{regen.WithRunePool("é")},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			matcher := regexp.MustCompile(`\A(?:` + tt.re.Regexp() + `)\z`)
+			for i := 0; i < 20; i++ {
+				s, err := regen.Generate(tt.re, r, tt.opts...)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !matcher.MatchString(s) {
+					t.Errorf("generated %q, which doesn't match %s", s, tt.re.Regexp())
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateOptions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		s, err := regen.Generate(regen.CharSet('a', 'b', 'c').Repeat().Min(2), r, regen.WithMaxRepeat(1), regen.WithRunePool("b"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(s) > 3 || strings.Trim(s, "b") != "" {
+			t.Errorf("expected 2 or 3 b's, got %q", s)
+		}
+	}
+}
+
+func TestGenerateNoMatch(t *testing.T) {
+	re := regen.Sequence(regen.String("a"), regen.TextEnd, regen.String("b"))
+	if _, err := regen.Generate(re, rand.New(rand.NewSource(1))); err == nil {
+		t.Errorf("expected an error for an expression that can't match")
+	}
+}
+
+func TestGenerateNearMiss(t *testing.T) {
+	for _, tt := range []struct {
+		desc           string
+		re             regen.Regexp
+		minLen, maxLen int
+	}{
+		{
+			desc:   "counted repetition",
+			re:     regen.Digit.Repeat().Min(3).Max(4),
+			minLen: 2,
+			maxLen: 5,
+		},
+		{
+			desc:   "class",
+			re:     regen.CharRange('a', 'f'),
+			minLen: 1,
+			maxLen: 1,
+		},
+		{
+			desc:   "literal",
+			re:     regen.String("abc").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+			minLen: 2,
+			maxLen: 3,
+		},
+		{
+			desc:   "identifier",
+			re:     regen.Sequence(regen.CharRange('a', 'z'), regen.Union(regen.CharRange('a', 'z'), regen.Digit).Repeat().Max(7)),
+			minLen: 0,
+			maxLen: 9,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			matcher := regexp.MustCompile(`\A(?:` + tt.re.Regexp() + `)\z`)
+			for i := 0; i < 20; i++ {
+				s, err := regen.GenerateNearMiss(tt.re, r)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if matcher.MatchString(s) {
+					t.Errorf("generated %q, which matches %s", s, tt.re.Regexp())
+				}
+				if n := len([]rune(s)); n < tt.minLen || n > tt.maxLen {
+					t.Errorf("expected %q to have between %d and %d characters", s, tt.minLen, tt.maxLen)
+				}
+			}
+		})
+	}
+	if _, err := regen.GenerateNearMiss(regen.Any.Group().NoCapture().SetFlags(regen.FlagMatchNewLine).Repeat(), rand.New(rand.NewSource(1))); err == nil {
+		t.Errorf("expected an error for an expression that matches every string")
+	}
+}
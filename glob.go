@@ -0,0 +1,209 @@
+package regen
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// GlobOption configures how glob patterns are converted by FromGlob and FromGlobs
+type GlobOption func(*globConverter)
+
+// WithPathSeparator makes glob patterns aware of the path separator sep (typically '/'):
+// *, ? and [...] do not match sep, and ** matches any number of path segments when it makes up
+// an entire segment (e.g. a/**/b matches a/b and a/x/y/b). Without this option, * and ** are equivalent.
+// A class that matches nothing but sep, such as [/], is an error.
+func WithPathSeparator(sep rune) GlobOption {
+	return func(c *globConverter) {
+		c.separator = sep
+	}
+}
+
+// FromGlob returns a Regexp that is equivalent to the glob pattern. The following syntax is supported:
+// * matches any sequence of characters, ? matches any single character, [abc] and [a-z] match any of
+// the enclosed characters (negated using [!...] or [^...]), ** matches across path separators (see
+// WithPathSeparator), and \ matches the following character literally.
+// Since globs match an entire string, the result should be anchored (e.g. with TextStart and TextEnd)
+// unless it is composed into a larger expression.
+func FromGlob(pattern string, opts ...GlobOption) (Regexp, error) {
+	c := globConverter{}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c.convert(pattern)
+}
+
+// FromGlobs returns a Regexp that matches any of the glob patterns (see FromGlob)
+func FromGlobs(patterns []string, opts ...GlobOption) (Regexp, error) {
+	choices := make([]Regexp, len(patterns))
+	for i, pattern := range patterns {
+		re, err := FromGlob(pattern, opts...)
+		if err != nil {
+			return nil, err
+		}
+		choices[i] = re
+	}
+	return OneOf(choices...).Group().NoCapture(), nil
+}
+
+type globConverter struct {
+	separator rune
+}
+
+func (c globConverter) convert(pattern string) (Regexp, error) {
+	var parts []Regexp
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			parts = append(parts, String(literal.String()))
+			literal.Reset()
+		}
+	}
+	for i := 0; i < len(pattern); {
+		char, size := utf8.DecodeRuneInString(pattern[i:])
+		switch char {
+		case '\\':
+			if i+size == len(pattern) {
+				return nil, fmt.Errorf("regen: glob %q ends with an unterminated escape", pattern)
+			}
+			escaped, escapedSize := utf8.DecodeRuneInString(pattern[i+size:])
+			literal.WriteRune(escaped)
+			i += size + escapedSize
+		case '*':
+			flush()
+			if strings.HasPrefix(pattern[i:], "**") {
+				re, n := c.doubleStar(pattern, i)
+				parts = append(parts, re)
+				i += n
+				continue
+			}
+			parts = append(parts, c.anyChar().Repeat())
+			i += size
+		case '?':
+			flush()
+			parts = append(parts, c.anyChar())
+			i += size
+		case '[':
+			flush()
+			re, n, err := c.class(pattern[i:])
+			if err != nil {
+				return nil, fmt.Errorf("regen: glob %q: %v", pattern, err)
+			}
+			parts = append(parts, re)
+			i += n
+		default:
+			literal.WriteRune(char)
+			i += size
+		}
+	}
+	flush()
+	// * and ? can match newlines
+	return Sequence(parts...).Group().NoCapture().SetFlags(FlagMatchNewLine), nil
+}
+
+// anyChar returns a Regexp matching any single character other than the path separator
+func (c globConverter) anyChar() Regexp {
+	if c.separator == 0 {
+		return Any
+	}
+	return CharSet(c.separator).Negate()
+}
+
+// doubleStar converts the ** at pattern[i:], returning the Regexp and the number of bytes consumed
+func (c globConverter) doubleStar(pattern string, i int) (Regexp, int) {
+	n := strings.IndexFunc(pattern[i:], func(r rune) bool { return r != '*' })
+	if n < 0 {
+		n = len(pattern) - i
+	}
+	if c.separator == 0 {
+		return Any.Repeat(), n
+	}
+	sep := string(c.separator)
+	startsSegment := i == 0 || strings.HasSuffix(pattern[:i], sep)
+	rest := pattern[i+n:]
+	switch {
+	case startsSegment && rest == "":
+		return Any.Repeat(), n
+	case startsSegment && strings.HasPrefix(rest, sep):
+		// **/ matches zero or more complete path segments
+		return Sequence(Any.Repeat(), String(sep)).Group().NoCapture().Optional(), n + len(sep)
+	}
+	return c.anyChar().Repeat(), n
+}
+
+// class converts the bracket expression at the start of pattern, returning the Regexp and the number
+// of bytes consumed
+func (c globConverter) class(pattern string) (Regexp, int, error) {
+	i := 1
+	negated := false
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		negated = true
+		i++
+	}
+	var classes []CharClass
+	var chars []rune
+	for first := true; ; first = false {
+		if i >= len(pattern) {
+			return nil, 0, fmt.Errorf("unterminated character class")
+		}
+		char, size := utf8.DecodeRuneInString(pattern[i:])
+		if char == ']' && !first {
+			i += size
+			break
+		}
+		if char == '\\' && i+size < len(pattern) {
+			i += size
+			char, size = utf8.DecodeRuneInString(pattern[i:])
+		}
+		i += size
+		if i+1 < len(pattern) && pattern[i] == '-' && pattern[i+1] != ']' {
+			end, endSize := utf8.DecodeRuneInString(pattern[i+1:])
+			if end < char {
+				return nil, 0, fmt.Errorf("invalid character range %c-%c", char, end)
+			}
+			classes = append(classes, c.classRange(char, end, negated)...)
+			i += 1 + endSize
+			continue
+		}
+		if char != c.separator || negated {
+			chars = append(chars, char)
+		}
+	}
+	if len(chars) > 0 {
+		classes = append([]CharClass{CharSet(chars...)}, classes...)
+	}
+	if negated && c.separator != 0 {
+		classes = append(classes, CharSet(c.separator))
+	}
+	if len(classes) == 0 {
+		return nil, 0, fmt.Errorf("character class only matches the path separator %c", c.separator)
+	}
+	var re CharClass
+	if len(classes) == 1 {
+		re = classes[0]
+	} else {
+		re = Union(classes...).(CharClass)
+	}
+	if negated {
+		re = re.Negate()
+	}
+	return re, i, nil
+}
+
+// classRange returns the character classes matching the range lo-hi within a bracket expression. Unless
+// the expression is negated, the path separator is left out of the range, since classes can't match it.
+func (c globConverter) classRange(lo, hi rune, negated bool) []CharClass {
+	if negated || c.separator == 0 || c.separator < lo || c.separator > hi {
+		return []CharClass{CharRange(lo, hi)}
+	}
+	var classes []CharClass
+	for _, r := range [][2]rune{{lo, c.separator - 1}, {c.separator + 1, hi}} {
+		switch {
+		case r[0] == r[1]:
+			classes = append(classes, CharSet(r[0]))
+		case r[0] < r[1]:
+			classes = append(classes, CharRange(r[0], r[1]))
+		}
+	}
+	return classes
+}
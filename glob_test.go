@@ -0,0 +1,142 @@
+package regen_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestFromGlob(t *testing.T) {
+	pathAware := []regen.GlobOption{regen.WithPathSeparator('/')}
+	tests := []struct {
+		description string
+		patterns    []string
+		opts        []regen.GlobOption
+		expected    string
+		matches     []string
+		nonMatches  []string
+	}{
+		{
+			description: "* matches any sequence of characters",
+			patterns:    []string{"*.tar.gz"},
+			expected:    `(?s:.*\.tar\.gz)`,
+			matches:     []string{"a.tar.gz", ".tar.gz", "dir/a.tar.gz"},
+			nonMatches:  []string{"a.tar", "a.tarxgz"},
+		},
+		{
+			description: "? matches a single character",
+			patterns:    []string{"file?.txt"},
+			matches:     []string{"file1.txt", "filea.txt"},
+			nonMatches:  []string{"file.txt", "file12.txt"},
+		},
+		{
+			description: "Character classes support ranges and negation",
+			patterns:    []string{"[a-c]x[!0-9]"},
+			expected:    `(?s:[a-c]x[^0-9])`,
+			matches:     []string{"axb", "cx-"},
+			nonMatches:  []string{"dxb", "ax1"},
+		},
+		{
+			description: "Character classes allow a leading ]",
+			patterns:    []string{"[]a]"},
+			matches:     []string{"]", "a"},
+			nonMatches:  []string{"b"},
+		},
+		{
+			description: "Escaped characters are literal",
+			patterns:    []string{`\*\?`},
+			matches:     []string{"*?"},
+			nonMatches:  []string{"a?"},
+		},
+		{
+			description: "With a path separator, * does not match across segments",
+			patterns:    []string{"src/*.go"},
+			opts:        pathAware,
+			expected:    `(?s:src/[^/]*\.go)`,
+			matches:     []string{"src/main.go"},
+			nonMatches:  []string{"src/pkg/main.go"},
+		},
+		{
+			description: "With a path separator, ** matches any number of segments",
+			patterns:    []string{"src/**/*.go"},
+			opts:        pathAware,
+			matches:     []string{"src/main.go", "src/a/b/main.go"},
+			nonMatches:  []string{"src/main.txt", "main.go"},
+		},
+		{
+			description: "With a path separator, trailing ** matches everything",
+			patterns:    []string{"vendor/**"},
+			opts:        pathAware,
+			matches:     []string{"vendor/", "vendor/a/b"},
+			nonMatches:  []string{"vendored/a"},
+		},
+		{
+			description: "With a path separator, negated classes do not match the separator",
+			patterns:    []string{"a[!b]c"},
+			opts:        pathAware,
+			matches:     []string{"axc"},
+			nonMatches:  []string{"a/c", "abc"},
+		},
+		{
+			description: "With a path separator, classes do not match the separator",
+			patterns:    []string{"a[/b]c", "x[--0]"},
+			opts:        pathAware,
+			expected:    `(?:(?s:a[b]c)|(?s:x[--.0]))`,
+			matches:     []string{"abc", "x-", "x.", "x0"},
+			nonMatches:  []string{"a/c", "x/"},
+		},
+		{
+			description: "Hyphens within classes are literal",
+			patterns:    []string{"[a-z_-]"},
+			expected:    `(?s:[_\-a-z])`,
+			matches:     []string{"a", "_", "-"},
+			nonMatches:  []string{"`", "A"},
+		},
+		{
+			description: "FromGlobs matches any of the globs",
+			patterns:    []string{"*.go", "go.mod"},
+			expected:    `(?:(?s:.*\.go)|(?s:go\.mod))`,
+			matches:     []string{"main.go", "go.mod"},
+			nonMatches:  []string{"go.sum"},
+		},
+	}
+	for _, tt := range tests {
+		var re regen.Regexp
+		var err error
+		if len(tt.patterns) == 1 {
+			re, err = regen.FromGlob(tt.patterns[0], tt.opts...)
+		} else {
+			re, err = regen.FromGlobs(tt.patterns, tt.opts...)
+		}
+		if err != nil {
+			t.Errorf(`glob test "%s" failed: unexpected error: %v`, tt.description, err)
+			continue
+		}
+		if tt.expected != "" && re.Regexp() != tt.expected {
+			t.Errorf(`glob test "%s" failed: got "%s", expected "%s"`, tt.description, re.Regexp(), tt.expected)
+		}
+		compiled := regexp.MustCompile(regen.Sequence(regen.TextStart, re, regen.TextEnd).Regexp())
+		for _, s := range tt.matches {
+			if !compiled.MatchString(s) {
+				t.Errorf(`glob test "%s" failed: expected "%s" to match %s`, tt.description, s, compiled)
+			}
+		}
+		for _, s := range tt.nonMatches {
+			if compiled.MatchString(s) {
+				t.Errorf(`glob test "%s" failed: expected "%s" not to match %s`, tt.description, s, compiled)
+			}
+		}
+	}
+}
+
+func TestFromGlobErrors(t *testing.T) {
+	for _, pattern := range []string{"[abc", `abc\`, "[z-a]"} {
+		if _, err := regen.FromGlob(pattern); err == nil {
+			t.Errorf(`expected glob "%s" to be invalid`, pattern)
+		}
+	}
+	if _, err := regen.FromGlob("a[/]", regen.WithPathSeparator('/')); err == nil {
+		t.Errorf(`expected glob "a[/]" to be invalid with the path separator /`)
+	}
+}
@@ -0,0 +1,263 @@
+package regen
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// GenerateGo parses pattern (see Parse) and returns Go source code for an equivalent Regexp built
+// using this package, e.g. regen.Sequence(regen.LineStart, regen.String("abc")). This is intended for
+// migrating hand-written regular expressions to the builder.
+func GenerateGo(pattern string) (string, error) {
+	re, err := Parse(pattern)
+	if err != nil {
+		return "", err
+	}
+	return GoCode(re), nil
+}
+
+// GoCode returns a Go expression that constructs re using this package (imported as regen).
+// Long expressions are split over multiple lines, indented using tabs.
+func GoCode(re Regexp) string {
+	return goExpr(re, 0, true)
+}
+
+// GoSourceOption configures the declarations generated by GoSource
+type GoSourceOption func(*goSourceConfig)
+
+type goSourceConfig struct {
+	groupIndexes bool
+}
+
+// WithGroupIndexConstants makes GoSource also declare a constant for the index of each named group,
+// e.g. datePatternYearIndex for the group year in datePattern
+func WithGroupIndexConstants() GoSourceOption {
+	return func(c *goSourceConfig) {
+		c.groupIndexes = true
+	}
+}
+
+// GoSource returns a gofmt-ed Go declaration of the compiled form of re, e.g.
+//
+//	var varName = regexp.MustCompile(`^\d+$`)
+//
+// The expression is written as a raw string literal unless it contains characters that can't be
+// represented in one. An error is returned if varName is not a valid identifier or re does not compile.
+func GoSource(re Regexp, varName string, opts ...GoSourceOption) (string, error) {
+	var c goSourceConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if !token.IsIdentifier(varName) {
+		return "", fmt.Errorf("regen: %q is not a valid Go identifier", varName)
+	}
+	compiled, err := regexp.Compile(re.Regexp())
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString("var " + varName + " = regexp.MustCompile(" + goRawString(compiled.String()) + ")\n")
+	if c.groupIndexes {
+		var consts []string
+		for i, name := range compiled.SubexpNames() {
+			if name != "" {
+				consts = append(consts, varName+goExportedName(name)+"Index = "+strconv.Itoa(i))
+			}
+		}
+		if len(consts) > 0 {
+			sb.WriteString("\nconst (\n\t" + strings.Join(consts, "\n\t") + "\n)\n")
+		}
+	}
+	src, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return "", err
+	}
+	return string(src), nil
+}
+
+// goExportedName converts a group name such as first_name into FirstName
+func goExportedName(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(part)
+		sb.WriteRune(unicode.ToUpper(r))
+		sb.WriteString(part[size:])
+	}
+	return sb.String()
+}
+
+// goMaxLineLength is the length above which calls are split over multiple lines
+const goMaxLineLength = 100
+
+// goExpr returns the Go expression for re. If multiline is true, long calls are split over multiple
+// lines, with arguments indented to depth+1.
+func goExpr(re Regexp, depth int, multiline bool) string {
+	switch re := re.(type) {
+	case literalRegexp:
+		if re.literal {
+			return "regen.String(" + strconv.Quote(re.value) + ")"
+		}
+		return "regen.Raw(" + goRawString(re.re) + ")"
+	case anyRegexp:
+		return "regen.Any"
+	case anchorRegexp:
+		return "regen." + anchorNames[re.re]
+	case annotatedRegexp:
+		return goCall("regen.Annotate", []string{goExpr(re.re, depth+1, multiline), strconv.Quote(re.comment)}, depth, multiline)
+	case multiRegexp:
+		name := "regen.Sequence"
+		if re.separator != "" {
+			name = "regen.OneOf"
+		}
+		return goCall(name, goExprs(re.res, depth, multiline), depth, multiline)
+	case groupedRegexp:
+		var sb strings.Builder
+		if m, ok := re.re.(multiRegexp); ok && m.separator != "" {
+			// OneOf returns a capturing group
+			sb.WriteString(goCall("regen.OneOf", goExprs(m.res, depth, multiline), depth, multiline))
+			if re.name == "" && !re.noCapture && re.balance == "" && re.setFlags == 0 && re.unsetFlags == 0 {
+				return sb.String()
+			}
+		} else {
+			sb.WriteString(goExpr(re.re, depth, multiline))
+		}
+		sb.WriteString(".Group()")
+		switch {
+		case re.name != "":
+			sb.WriteString(".CaptureAs(" + strconv.Quote(re.name) + ")")
+		case re.noCapture:
+			sb.WriteString(".NoCapture()")
+		}
+		if re.balance != "" {
+			sb.WriteString(".Balance(" + strconv.Quote(re.balance) + ")")
+		}
+		if re.setFlags != 0 {
+			sb.WriteString(".SetFlags(" + goFlags(re.setFlags) + ")")
+		}
+		if re.unsetFlags != 0 {
+			sb.WriteString(".UnsetFlags(" + goFlags(re.unsetFlags) + ")")
+		}
+		return sb.String()
+	case repeatedRegexp:
+		sub := goExpr(re.re, depth, multiline)
+		if !re.ungreedy && re.hasMax && re.min == 0 && re.max == 1 {
+			return sub + ".Optional()"
+		}
+		var sb strings.Builder
+		sb.WriteString(sub + ".Repeat()")
+		switch {
+		case re.hasMax && re.min == re.max:
+			sb.WriteString(".Exactly(" + strconv.Itoa(int(re.min)) + ")")
+		default:
+			if re.min > 0 {
+				sb.WriteString(".Min(" + strconv.Itoa(int(re.min)) + ")")
+			}
+			if re.hasMax {
+				sb.WriteString(".Max(" + strconv.Itoa(int(re.max)) + ")")
+			}
+		}
+		if re.ungreedy {
+			sb.WriteString(".Ungreedy()")
+		}
+		return sb.String()
+	case charSetRegexp:
+		chars := make([]string, len(re.chars))
+		for i, char := range re.chars {
+			chars[i] = strconv.QuoteRune(char)
+		}
+		return "regen.CharSet(" + strings.Join(chars, ", ") + ")" + goNegate(re.negated)
+	case charRangeRegexp:
+		return "regen.CharRange(" + strconv.QuoteRune(re.start) + ", " + strconv.QuoteRune(re.end) + ")" + goNegate(re.negated)
+	case asciiCharClassRegexp:
+		return "regen.ASCIICharClass(" + strconv.Quote(re.name) + ")" + goNegate(re.negated)
+	case unicodeCharClassRegexp:
+		return "regen.UnicodeCharClass(" + strconv.Quote(re.name) + ")" + goNegate(re.negated)
+	case perlCharClassRegexp:
+		return "regen." + perlClassNames[re.letter] + goNegate(re.negated)
+	case unionCharClassRegexp:
+		classes := make([]Regexp, len(re.charClasses))
+		for i, class := range re.charClasses {
+			classes[i] = class
+		}
+		union := goCall("regen.Union", goExprs(classes, depth, multiline), depth, multiline)
+		if re.negated {
+			union += ".(regen.CharClass).Negate()"
+		}
+		return union
+	}
+	return "regen.Raw(" + goRawString(re.Regexp()) + ")"
+}
+
+// perlClassNames contains the names of the variables for each Perl character class
+var perlClassNames = map[byte]string{
+	'd': "Digit",
+	'h': "HexDigit",
+	's': "Whitespace",
+	'w': "WordCharacter",
+}
+
+func goExprs(res []Regexp, depth int, multiline bool) []string {
+	exprs := make([]string, len(res))
+	for i, re := range res {
+		exprs[i] = goExpr(re, depth+1, multiline)
+	}
+	return exprs
+}
+
+// goCall returns a call to the function with the given arguments, which are placed on separate
+// lines if the call would otherwise be too long
+func goCall(name string, args []string, depth int, multiline bool) string {
+	inline := name + "(" + strings.Join(args, ", ") + ")"
+	if !multiline || len(args) < 2 || (!strings.Contains(inline, "\n") && len(inline)+4*depth <= goMaxLineLength) {
+		return inline
+	}
+	var sb strings.Builder
+	sb.WriteString(name + "(\n")
+	for _, arg := range args {
+		sb.WriteString(strings.Repeat("\t", depth+1) + arg + ",\n")
+	}
+	sb.WriteString(strings.Repeat("\t", depth) + ")")
+	return sb.String()
+}
+
+func goFlags(flags Flag) string {
+	var names []string
+	for _, flag := range []struct {
+		flag Flag
+		name string
+	}{
+		{FlagCaseInsensitive, "regen.FlagCaseInsensitive"},
+		{FlagMultiLine, "regen.FlagMultiLine"},
+		{FlagMatchNewLine, "regen.FlagMatchNewLine"},
+		{FlagUngreedy, "regen.FlagUngreedy"},
+	} {
+		if flags&flag.flag != 0 {
+			names = append(names, flag.name)
+		}
+	}
+	return strings.Join(names, " | ")
+}
+
+func goNegate(negated bool) string {
+	if negated {
+		return ".Negate()"
+	}
+	return ""
+}
+
+// goRawString returns s as a raw string literal if possible, or an interpreted string literal otherwise
+func goRawString(s string) string {
+	if strings.ContainsAny(s, "`\r") || !strconv.CanBackquote(s) {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
@@ -0,0 +1,159 @@
+package regen_test
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestGoCode(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		re       regen.Regexp
+		expected string
+	}{
+		{
+			desc:     "short",
+			re:       regen.Sequence(regen.LineStart, regen.String("a\"b"), regen.Digit.Repeat().Min(1)),
+			expected: `regen.Sequence(regen.LineStart, regen.String("a\"b"), regen.Digit.Repeat().Min(1))`,
+		},
+		{
+			desc:     "group modifiers",
+			re:       regen.Whitespace.Negate().Optional().Group().CaptureAs("x").SetFlags(regen.FlagMultiLine | regen.FlagCaseInsensitive),
+			expected: `regen.Whitespace.Negate().Optional().Group().CaptureAs("x").SetFlags(regen.FlagCaseInsensitive | regen.FlagMultiLine)`,
+		},
+		{
+			desc:     "one of",
+			re:       regen.OneOf(regen.CharSet('a', '\n'), regen.CharRange('0', '9').Repeat().Exactly(2).Ungreedy()).Group().NoCapture(),
+			expected: `regen.OneOf(regen.CharSet('a', '\n'), regen.CharRange('0', '9').Repeat().Exactly(2).Ungreedy()).Group().NoCapture()`,
+		},
+		{
+			desc: "long",
+			re: regen.Sequence(
+				regen.TextStart,
+				regen.OneOf(regen.String("alpha"), regen.String("beta"), regen.String("gamma")),
+				regen.Union(regen.ASCIICharClass("alpha"), regen.UnicodeCharClass("Greek")).Repeat(),
+				regen.Raw(`\pL`),
+			),
+			expected: `regen.Sequence(
+	regen.TextStart,
+	regen.OneOf(regen.String("alpha"), regen.String("beta"), regen.String("gamma")),
+	regen.Union(regen.ASCIICharClass("alpha"), regen.UnicodeCharClass("Greek")).Repeat(),
+	regen.Raw(` + "`\\pL`" + `),
+)`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			if actual := regen.GoCode(tt.re); actual != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	actual, err := regen.GenerateGo(`^(?P<key>[a-z_]+)=(?:"[^"]*"|\S+)$`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `regen.Sequence(
+	regen.LineStart,
+	regen.Union(regen.CharSet('_'), regen.CharRange('a', 'z')).Repeat().Min(1).Group().CaptureAs("key"),
+	regen.String("="),
+	regen.OneOf(
+		regen.Sequence(regen.String("\""), regen.CharSet('"').Negate().Repeat(), regen.String("\"")),
+		regen.Whitespace.Negate().Repeat().Min(1),
+	).Group().NoCapture(),
+	regen.LineEnd,
+)`
+	if actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestGenerateGo_Groups(t *testing.T) {
+	for _, pattern := range []string{
+		`(?:ab)+(x)`,
+		`(?:\d{2})?(?P<y>y)`,
+		`^(?P<key>[a-z_]+)=(?:"[^"]*"|\S+)$`,
+		`(?:(a)|b)+(?P<c>c)`,
+	} {
+		t.Run(pattern, func(t *testing.T) {
+			code, err := regen.GenerateGo(pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			re, err := regen.ParseGo(code)
+			if err != nil {
+				t.Fatalf("unexpected error evaluating %s: %v", code, err)
+			}
+			original := regexp.MustCompile(pattern)
+			generated := regexp.MustCompile(re.Regexp())
+			if !reflect.DeepEqual(generated.SubexpNames(), original.SubexpNames()) {
+				t.Errorf("expected groups %q, got %q from %s", original.SubexpNames(), generated.SubexpNames(), code)
+			}
+		})
+	}
+}
+
+func TestGoSource(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		re       regen.Regexp
+		varName  string
+		opts     []regen.GoSourceOption
+		expected string
+	}{
+		{
+			desc:     "raw string",
+			re:       regen.Sequence(regen.LineStart, regen.Digit.Repeat().Min(1), regen.String(`\`)),
+			varName:  "numberPattern",
+			expected: "var numberPattern = regexp.MustCompile(`^\\d+\\\\`)\n",
+		},
+		{
+			desc:     "backquote",
+			re:       regen.String("`x`"),
+			varName:  "quoted",
+			expected: "var quoted = regexp.MustCompile(\"`x`\")\n",
+		},
+		{
+			desc: "group indexes",
+			re: regen.Sequence(
+				regen.Digit.Repeat().Exactly(4).Group().CaptureAs("year"),
+				regen.String("-"),
+				regen.Digit.Repeat().Exactly(2).Group(),
+				regen.String("-"),
+				regen.Digit.Repeat().Exactly(2).Group().CaptureAs("day_of_month"),
+			),
+			varName: "DatePattern",
+			opts:    []regen.GoSourceOption{regen.WithGroupIndexConstants()},
+			expected: "var DatePattern = regexp.MustCompile(`(?P<year>\\d{4})-(\\d{2})-(?P<day_of_month>\\d{2})`)\n" +
+				"\n" +
+				"const (\n" +
+				"\tDatePatternYearIndex       = 1\n" +
+				"\tDatePatternDayOfMonthIndex = 3\n" +
+				")\n",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			actual, err := regen.GoSource(tt.re, tt.varName, tt.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGoSource_Invalid(t *testing.T) {
+	if _, err := regen.GoSource(regen.String("a"), "not valid"); err == nil {
+		t.Error("expected error for invalid identifier")
+	}
+	if _, err := regen.GoSource(regen.Raw("a("), "re"); err == nil {
+		t.Error("expected error for invalid expression")
+	}
+}
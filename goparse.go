@@ -0,0 +1,267 @@
+package regen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+)
+
+// goFuncs are the functions that can be called by ParseGo
+var goFuncs = map[string]reflect.Value{
+	"String":               reflect.ValueOf(String),
+	"Raw":                  reflect.ValueOf(Raw),
+	"Sequence":             reflect.ValueOf(Sequence),
+	"OneOf":                reflect.ValueOf(OneOf),
+	"OneOfStrings":         reflect.ValueOf(OneOfStrings),
+	"Union":                reflect.ValueOf(Union),
+	"CharSet":              reflect.ValueOf(CharSet),
+	"CharRange":            reflect.ValueOf(CharRange),
+	"ASCIICharClass":       reflect.ValueOf(ASCIICharClass),
+	"UnicodeCharClass":     reflect.ValueOf(UnicodeCharClass),
+	"Annotate":             reflect.ValueOf(Annotate),
+	"NotFollowedByLiteral": reflect.ValueOf(NotFollowedByLiteral),
+	"Simplify":             reflect.ValueOf(Simplify),
+}
+
+// goValues are the variables and constants that can be referred to by ParseGo
+var goValues = map[string]reflect.Value{
+	"LineStart":           reflect.ValueOf(&LineStart).Elem(),
+	"LineEnd":             reflect.ValueOf(&LineEnd).Elem(),
+	"TextStart":           reflect.ValueOf(&TextStart).Elem(),
+	"TextEnd":             reflect.ValueOf(&TextEnd).Elem(),
+	"ASCIIBoundary":       reflect.ValueOf(&ASCIIBoundary).Elem(),
+	"NotASCIIBoundary":    reflect.ValueOf(&NotASCIIBoundary).Elem(),
+	"Any":                 reflect.ValueOf(&Any).Elem(),
+	"Digit":               reflect.ValueOf(Digit),
+	"Whitespace":          reflect.ValueOf(Whitespace),
+	"WordCharacter":       reflect.ValueOf(WordCharacter),
+	"HexDigit":            reflect.ValueOf(HexDigit),
+	"FlagCaseInsensitive": reflect.ValueOf(FlagCaseInsensitive),
+	"FlagMultiLine":       reflect.ValueOf(FlagMultiLine),
+	"FlagMatchNewLine":    reflect.ValueOf(FlagMatchNewLine),
+	"FlagUngreedy":        reflect.ValueOf(FlagUngreedy),
+}
+
+// goTypes are the types that can be used in type assertions by ParseGo
+var goTypes = map[string]reflect.Type{
+	"Regexp":    reflect.TypeOf((*Regexp)(nil)).Elem(),
+	"CharClass": reflect.TypeOf((*CharClass)(nil)).Elem(),
+}
+
+// ParseGoOption configures ParseGo
+type ParseGoOption func(*goEvaluator)
+
+// goEvaluator evaluates Go expressions that use this package
+type goEvaluator struct {
+	vars map[string]Regexp
+}
+
+// WithGoVariables allows the source code passed to ParseGo to refer to the given expressions by name
+func WithGoVariables(vars map[string]Regexp) ParseGoOption {
+	return func(e *goEvaluator) {
+		e.vars = vars
+	}
+}
+
+// ParseGo evaluates Go source code that constructs a Regexp using this package (imported as regen),
+// such as the code returned by GoCode. It is the inverse of GoCode, e.g. for converting builder code
+// back into a regular expression. Only calls to the functions and methods of this package, references
+// to its variables and constants, string, character and integer literals, and variables provided by
+// WithGoVariables are supported.
+func ParseGo(src string, opts ...ParseGoOption) (Regexp, error) {
+	var e goEvaluator
+	for _, opt := range opts {
+		opt(&e)
+	}
+	expr, err := parser.ParseExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	v, err := e.eval(expr)
+	if err != nil {
+		return nil, err
+	}
+	re, ok := v.Interface().(Regexp)
+	if !ok {
+		return nil, fmt.Errorf("regen: %s is not a Regexp", types.ExprString(expr))
+	}
+	return re, nil
+}
+
+// eval evaluates a Go expression
+func (e *goEvaluator) eval(expr ast.Expr) (reflect.Value, error) {
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return e.eval(expr.X)
+	case *ast.Ident:
+		if re, ok := e.vars[expr.Name]; ok && re != nil {
+			return reflect.ValueOf(re), nil
+		}
+		return reflect.Value{}, fmt.Errorf("regen: undefined: %s", expr.Name)
+	case *ast.BasicLit:
+		return goLiteral(expr)
+	case *ast.SelectorExpr:
+		if goIsPackage(expr.X) {
+			if v, ok := goValues[expr.Sel.Name]; ok {
+				return v, nil
+			}
+			return reflect.Value{}, fmt.Errorf("regen: unknown variable %s", types.ExprString(expr))
+		}
+	case *ast.BinaryExpr:
+		if expr.Op != token.OR {
+			break
+		}
+		x, err := e.eval(expr.X)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		y, err := e.eval(expr.Y)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		flagType := reflect.TypeOf(Flag(0))
+		if x.Type() != flagType || y.Type() != flagType {
+			return reflect.Value{}, fmt.Errorf("regen: %s combines values other than flags", types.ExprString(expr))
+		}
+		return reflect.ValueOf(x.Interface().(Flag) | y.Interface().(Flag)), nil
+	case *ast.TypeAssertExpr:
+		sel, ok := expr.Type.(*ast.SelectorExpr)
+		if !ok || !goIsPackage(sel.X) || goTypes[sel.Sel.Name] == nil {
+			break
+		}
+		x, err := e.eval(expr.X)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		t := goTypes[sel.Sel.Name]
+		if x.Kind() == reflect.Interface {
+			x = x.Elem()
+		}
+		if !x.Type().Implements(t) {
+			return reflect.Value{}, fmt.Errorf("regen: %s is not a %s", types.ExprString(expr.X), t.Name())
+		}
+		return x.Convert(t), nil
+	case *ast.CallExpr:
+		return e.call(expr)
+	}
+	return reflect.Value{}, fmt.Errorf("regen: unsupported Go expression %s", types.ExprString(expr))
+}
+
+// call evaluates a call to a function or method of this package
+func (e *goEvaluator) call(call *ast.CallExpr) (result reflect.Value, err error) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if ok {
+		// the only identifiers that can be used in a selector are the package and variables
+		if ident, isIdent := sel.X.(*ast.Ident); isIdent && !goIsPackage(ident) && e.vars[ident.Name] == nil {
+			ok = false
+		}
+	}
+	if !ok || call.Ellipsis.IsValid() {
+		return reflect.Value{}, fmt.Errorf("regen: unsupported call %s", types.ExprString(call))
+	}
+	var f reflect.Value
+	if goIsPackage(sel.X) {
+		if f, ok = goFuncs[sel.Sel.Name]; !ok {
+			return reflect.Value{}, fmt.Errorf("regen: unknown function %s", types.ExprString(sel))
+		}
+	} else {
+		receiver, err := e.eval(sel.X)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if f = receiver.MethodByName(sel.Sel.Name); !f.IsValid() {
+			return reflect.Value{}, fmt.Errorf("regen: unknown method %s", sel.Sel.Name)
+		}
+	}
+
+	fType := f.Type()
+	if n := len(call.Args); (fType.IsVariadic() && n < fType.NumIn()-1) || (!fType.IsVariadic() && n != fType.NumIn()) {
+		return reflect.Value{}, fmt.Errorf("regen: wrong number of arguments in %s", types.ExprString(call))
+	}
+	args := make([]reflect.Value, len(call.Args))
+	for i, arg := range call.Args {
+		var paramType reflect.Type
+		if fType.IsVariadic() && i >= fType.NumIn()-1 {
+			paramType = fType.In(fType.NumIn() - 1).Elem()
+		} else {
+			paramType = fType.In(i)
+		}
+		v, err := e.eval(arg)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if args[i], err = goConvert(v, paramType); err != nil {
+			return reflect.Value{}, fmt.Errorf("regen: argument %s: %v", types.ExprString(arg), err)
+		}
+	}
+
+	defer func() {
+		// Some functions panic if their arguments are invalid
+		if r := recover(); r != nil {
+			err = fmt.Errorf("regen: %s: %v", types.ExprString(call), r)
+		}
+	}()
+	results := f.Call(args)
+	if len(results) != 1 {
+		return reflect.Value{}, fmt.Errorf("regen: %s doesn't return a single value", types.ExprString(call))
+	}
+	return results[0], nil
+}
+
+// goConvert converts v to type t, as the compiler does for arguments
+func goConvert(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+	if v.Kind() == reflect.Interface && v.Elem().Type().AssignableTo(t) {
+		return v.Elem(), nil
+	}
+	// integer literals are untyped constants, and can be used as any integer type
+	if v.Kind() == reflect.Int64 && v.Type() == reflect.TypeOf(int64(0)) {
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return v.Convert(t), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if v.Int() < 0 {
+				return reflect.Value{}, fmt.Errorf("%d is negative", v.Int())
+			}
+			return v.Convert(t), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("cannot use %s as %s", v.Type(), t)
+}
+
+// goLiteral evaluates a string, character or integer literal
+func goLiteral(lit *ast.BasicLit) (reflect.Value, error) {
+	switch lit.Kind {
+	case token.STRING:
+		s, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(s), nil
+	case token.CHAR:
+		r, _, _, err := strconv.UnquoteChar(lit.Value[1:len(lit.Value)-1], '\'')
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(r), nil
+	case token.INT:
+		n, err := strconv.ParseInt(lit.Value, 0, 64)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(n), nil
+	}
+	return reflect.Value{}, fmt.Errorf("regen: unsupported literal %s", lit.Value)
+}
+
+// goIsPackage returns true if expr refers to this package
+func goIsPackage(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "regen"
+}
@@ -0,0 +1,121 @@
+package regen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestParseGo(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		src      string
+		expected string
+	}{
+		{
+			desc:     "sequence",
+			src:      `regen.Sequence(regen.LineStart, regen.String("a.b"), regen.Digit.Repeat().Min(1), regen.LineEnd)`,
+			expected: `^a\.b\d+$`,
+		},
+		{
+			desc:     "group modifiers",
+			src:      `regen.Whitespace.Negate().Optional().Group().CaptureAs("x").SetFlags(regen.FlagCaseInsensitive | regen.FlagMultiLine)`,
+			expected: `(?P<x>(?im)\S?)`,
+		},
+		{
+			desc:     "characters and counts",
+			src:      `regen.OneOf(regen.CharSet('a', '\n'), regen.CharRange('0', '9').Repeat().Exactly(2).Ungreedy()).Group().NoCapture()`,
+			expected: `(?:[a\x{A}]|[0-9]{2}?)`,
+		},
+		{
+			desc:     "type assertion",
+			src:      "regen.Union(regen.Digit, regen.CharSet('x')).(regen.CharClass).Negate()",
+			expected: `[^x\d]`,
+		},
+		{
+			desc:     "raw",
+			src:      "(regen.Raw(`a|b`))",
+			expected: `a|b`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			re, err := regen.ParseGo(tt.src)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual := re.Regexp(); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseGo_Variables(t *testing.T) {
+	vars := map[string]regen.Regexp{
+		"word":  regen.WordCharacter.Repeat().Min(1),
+		"digit": regen.Digit,
+	}
+	re, err := regen.ParseGo(`regen.Sequence(word.Group().CaptureAs("key"), regen.String("="), digit.Negate())`, regen.WithGoVariables(vars))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected, actual := `(?P<key>\w+)=\D`, re.Regexp(); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	if _, err := regen.ParseGo(`regen.Sequence(other)`, regen.WithGoVariables(vars)); err == nil || err.Error() != "regen: undefined: other" {
+		t.Errorf("expected an undefined error, got %v", err)
+	}
+}
+
+func TestParseGo_RoundTrip(t *testing.T) {
+	for _, pattern := range []string{
+		`^(?P<key>[a-z_]+)=(?:"[^"]*"|\S+)$`,
+		`(?i:\bfoo(?:bar)*?)\z`,
+		`[[:alpha:]\p{Greek}]{2,5}\.`,
+	} {
+		t.Run(pattern, func(t *testing.T) {
+			src, err := regen.GenerateGo(pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			re, err := regen.ParseGo(src)
+			if err != nil {
+				t.Fatalf("unexpected error parsing %s: %v", src, err)
+			}
+			parsed, err := regen.Parse(pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !regen.Equal(re, parsed) {
+				t.Errorf("expected %s, got %s", parsed.Regexp(), re.Regexp())
+			}
+		})
+	}
+}
+
+func TestParseGo_Invalid(t *testing.T) {
+	for _, tt := range []struct {
+		src      string
+		expected string
+	}{
+		{src: `regen.String(`, expected: "expected"},
+		{src: `len("a")`, expected: "regen: unsupported call len"},
+		{src: `fmt.Sprintf("a")`, expected: "regen: unsupported call fmt.Sprintf"},
+		{src: `regen.Compile(regen.Any)`, expected: "regen: unknown function regen.Compile"},
+		{src: `regen.Anything`, expected: "regen: unknown variable regen.Anything"},
+		{src: `regen.Any.Repeat().Min(-1)`, expected: "regen: unsupported Go expression -1"},
+		{src: `regen.String("a", "b")`, expected: "regen: wrong number of arguments"},
+		{src: `regen.String(1)`, expected: "regen: argument 1: cannot use int64 as string"},
+		{src: `regen.FlagMultiLine`, expected: "regen: regen.FlagMultiLine is not a Regexp"},
+		{src: `regen.Any.(regen.CharClass)`, expected: "regen: regen.Any is not a CharClass"},
+	} {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := regen.ParseGo(tt.src)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected an error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+// Package grammar composes patterns from named rules that refer to each other, like the productions of
+// a grammar. Rules are defined with regen expressions, in which Ref stands for another rule:
+//
+//	g := grammar.New().
+//		MustDefine("digits", regen.Digit.Repeat().Min(1)).
+//		MustDefine("version", regen.Sequence(grammar.Ref("digits"), regen.String("."), grammar.Ref("digits")))
+//	pattern, err := g.Render(regen.DialectRE2, "version") // \d+\.\d+
+//
+// In dialects with subroutine calls (DialectPCRE and DialectRuby), each rule is defined once and
+// called wherever it is referenced, so rules may be recursive. In other dialects, references are
+// expanded in place, and a rule that refers to itself, directly or indirectly, is an error.
+package grammar
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aoldershaw/regen"
+)
+
+// ruleName matches the names of rules, which are also used as the names of groups
+var ruleName = regexp.MustCompile(`\A[A-Za-z_][A-Za-z0-9_]*\z`)
+
+// Ref returns an expression that stands for the rule named name. It is replaced with the rule when the
+// grammar is expanded or rendered. On its own, it renders as a PCRE subroutine call, (?&name).
+func Ref(name string) regen.Regexp {
+	return ref(name)
+}
+
+type ref string
+
+func (r ref) Regexp() string {
+	return "(?&" + string(r) + ")"
+}
+
+func (r ref) Group() regen.GroupedRegexp {
+	return regen.Sequence(r).Group()
+}
+
+func (r ref) Repeat() regen.RepeatedRegexp {
+	return regen.Sequence(r).Repeat()
+}
+
+func (r ref) Optional() regen.Regexp {
+	return regen.Sequence(r).Optional()
+}
+
+// Grammar is a set of named rules
+type Grammar struct {
+	rules map[string]regen.Regexp
+	// names are the names of the rules, in the order that they were defined
+	names []string
+}
+
+// New returns an empty Grammar
+func New() *Grammar {
+	return &Grammar{rules: make(map[string]regen.Regexp)}
+}
+
+// Define adds a rule named name that matches re. Names start with a letter or underscore, followed by
+// letters, digits or underscores. An error is returned if the name is invalid or already defined.
+// References to rules that aren't defined yet are allowed, and are checked when the grammar is used.
+func (g *Grammar) Define(name string, re regen.Regexp) error {
+	if !ruleName.MatchString(name) {
+		return fmt.Errorf("grammar: invalid rule name %q", name)
+	}
+	if _, ok := g.rules[name]; ok {
+		return fmt.Errorf("grammar: rule %s is already defined", name)
+	}
+	g.rules[name] = re
+	g.names = append(g.names, name)
+	return nil
+}
+
+// MustDefine is like Define, but panics if the rule cannot be defined. It returns the Grammar so that
+// rules can be chained.
+func (g *Grammar) MustDefine(name string, re regen.Regexp) *Grammar {
+	if err := g.Define(name, re); err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// Rules returns the names of the rules, in the order that they were defined
+func (g *Grammar) Rules() []string {
+	return append([]string(nil), g.names...)
+}
+
+// Expand returns the expression of the rule named entry, with every reference replaced by the rule
+// that it refers to. An error is returned if a rule refers to an undefined rule or to itself, directly
+// or indirectly, since that can't be expanded.
+func (g *Grammar) Expand(entry string) (regen.Regexp, error) {
+	e := expander{grammar: g, expanded: make(map[string]regen.Regexp)}
+	return e.expand(entry, nil)
+}
+
+// expander expands the rules of a grammar, expanding each rule once
+type expander struct {
+	grammar  *Grammar
+	expanded map[string]regen.Regexp
+}
+
+// expand returns the expanded expression of the rule named name, which is referenced by the rules in
+// path (in order)
+func (e *expander) expand(name string, path []string) (regen.Regexp, error) {
+	if re, ok := e.expanded[name]; ok {
+		return re, nil
+	}
+	for i, p := range path {
+		if p == name {
+			return nil, fmt.Errorf("grammar: rule %s is recursive (%s)", name, strings.Join(path[i:], " -> ")+" -> "+name)
+		}
+	}
+	re, err := e.grammar.rule(name, path)
+	if err != nil {
+		return nil, err
+	}
+	path = append(path, name)
+	var expandErr error
+	re = regen.Transform(re, func(node regen.Regexp) regen.Regexp {
+		r, ok := node.(ref)
+		if !ok || expandErr != nil {
+			return node
+		}
+		expanded, err := e.expand(string(r), path)
+		if err != nil {
+			expandErr = err
+			return node
+		}
+		return expanded
+	})
+	if expandErr != nil {
+		return nil, expandErr
+	}
+	e.expanded[name] = re
+	return re, nil
+}
+
+// rule returns the expression of the rule named name, which is referenced by the last rule in path
+func (g *Grammar) rule(name string, path []string) (regen.Regexp, error) {
+	re, ok := g.rules[name]
+	if ok {
+		return re, nil
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("grammar: undefined rule %s", name)
+	}
+	return nil, fmt.Errorf("grammar: rule %s refers to undefined rule %s", path[len(path)-1], name)
+}
+
+// Render renders the rule named entry in the dialect d. If d supports subroutine calls (see
+// regen.Dialect.SubroutineCall), the other rules that entry uses are defined at the start of the
+// pattern (see regen.Dialect.RenderSubroutine), and references are rendered as calls, so rules may be
+// recursive. Since the definitions are named groups, rules should not share names with the groups of
+// the pattern. Groups within called rules don't capture, and calls match with the flags in effect
+// outside of the pattern, rather than those around the reference. In other dialects, the expanded rule
+// is rendered (see Expand).
+func (g *Grammar) Render(d regen.Dialect, entry string) (string, error) {
+	if _, ok := d.SubroutineCall(entry); !ok {
+		re, err := g.Expand(entry)
+		if err != nil {
+			return "", err
+		}
+		return d.Render(re)
+	}
+
+	called := make(map[string]bool)
+	calls := make(map[string]regen.Regexp)
+	if _, err := g.rule(entry, nil); err != nil {
+		return "", err
+	}
+	pending := []string{entry}
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		var err error
+		calls[name] = regen.Transform(g.rules[name], func(node regen.Regexp) regen.Regexp {
+			r, ok := node.(ref)
+			if !ok || err != nil {
+				return node
+			}
+			if _, err = g.rule(string(r), []string{name}); err != nil {
+				return node
+			}
+			if !called[string(r)] {
+				called[string(r)] = true
+				if _, ok := calls[string(r)]; !ok && string(r) != entry {
+					pending = append(pending, string(r))
+				}
+			}
+			call, _ := d.SubroutineCall(string(r))
+			return call
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var sb strings.Builder
+	for _, name := range g.names {
+		if !called[name] {
+			continue
+		}
+		definition, err := d.RenderSubroutine(name, calls[name])
+		if err != nil {
+			return "", fmt.Errorf("grammar: rule %s: %w", name, err)
+		}
+		sb.WriteString(definition)
+	}
+	pattern, err := d.Render(calls[entry])
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(pattern)
+	return sb.String(), nil
+}
@@ -0,0 +1,139 @@
+package grammar_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+	"github.com/aoldershaw/regen/grammar"
+)
+
+func versionGrammar() *grammar.Grammar {
+	return grammar.New().
+		MustDefine("digits", regen.Digit.Repeat().Min(1)).
+		MustDefine("version", regen.Sequence(grammar.Ref("digits"), regen.String("."), grammar.Ref("digits"))).
+		MustDefine("range", regen.Sequence(grammar.Ref("version"), regen.String("-"), grammar.Ref("version")))
+}
+
+func TestGrammar_Render(t *testing.T) {
+	nested := grammar.New().
+		MustDefine("value", regen.OneOf(regen.Digit.Repeat().Min(1), grammar.Ref("list"))).
+		MustDefine("list", regen.Sequence(
+			regen.String("["),
+			regen.Sequence(grammar.Ref("value"), regen.Sequence(regen.String(","), grammar.Ref("value")).Group().NoCapture().Repeat()).Group().NoCapture().Optional(),
+			regen.String("]"),
+		))
+
+	tests := []struct {
+		description string
+		grammar     *grammar.Grammar
+		dialect     regen.Dialect
+		entry       string
+		expected    string
+		err         string
+	}{
+		{
+			description: "RE2 expands references",
+			grammar:     versionGrammar(),
+			dialect:     regen.DialectRE2,
+			entry:       "range",
+			expected:    `\d+\.\d+-\d+\.\d+`,
+		},
+		{
+			description: "references are grouped when they are repeated",
+			grammar: grammar.New().
+				MustDefine("pair", regen.String("ab")).
+				MustDefine("pairs", grammar.Ref("pair").Repeat().Min(1)),
+			dialect:  regen.DialectRE2,
+			entry:    "pairs",
+			expected: `(ab)+`,
+		},
+		{
+			description: "PCRE calls the rules that the entry uses",
+			grammar:     versionGrammar(),
+			dialect:     regen.DialectPCRE,
+			entry:       "range",
+			expected:    `(?(DEFINE)(?<digits>\d+))(?(DEFINE)(?<version>(?&digits)\.(?&digits)))(?&version)-(?&version)`,
+		},
+		{
+			description: "Ruby calls the rules that the entry uses",
+			grammar:     versionGrammar(),
+			dialect:     regen.DialectRuby,
+			entry:       "version",
+			expected:    `(?<digits>\d+){0}\g<digits>\.\g<digits>`,
+		},
+		{
+			description: "PCRE supports recursive rules",
+			grammar:     nested,
+			dialect:     regen.DialectPCRE,
+			entry:       "list",
+			expected:    `(?(DEFINE)(?<value>(\d+|(?&list))))(?(DEFINE)(?<list>\[(?:(?&value)(?:,(?&value))*)?\]))\[(?:(?&value)(?:,(?&value))*)?\]`,
+		},
+		{
+			description: "RE2 does not support recursive rules",
+			grammar:     nested,
+			dialect:     regen.DialectRE2,
+			entry:       "list",
+			err:         "grammar: rule list is recursive (list -> value -> list)",
+		},
+		{
+			description: "references to undefined rules are reported",
+			grammar:     grammar.New().MustDefine("a", grammar.Ref("b")),
+			dialect:     regen.DialectPCRE,
+			entry:       "a",
+			err:         "grammar: rule a refers to undefined rule b",
+		},
+		{
+			description: "undefined entries are reported",
+			grammar:     versionGrammar(),
+			dialect:     regen.DialectRE2,
+			entry:       "date",
+			err:         "grammar: undefined rule date",
+		},
+	}
+	for _, tt := range tests {
+		actual, err := tt.grammar.Render(tt.dialect, tt.entry)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf(`grammar test "%s" failed: expected error %q, got %v`, tt.description, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf(`grammar test "%s" failed: unexpected error: %v`, tt.description, err)
+			continue
+		}
+		if actual != tt.expected {
+			t.Errorf(`grammar test "%s" failed: got "%s", expected "%s"`, tt.description, actual, tt.expected)
+		}
+	}
+}
+
+func TestGrammar_Expand(t *testing.T) {
+	re, err := versionGrammar().Expand("range")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	compiled := regexp.MustCompile(regen.Sequence(regen.TextStart, re, regen.TextEnd).Regexp())
+	for input, expected := range map[string]bool{"1.2-1.10": true, "1.2": false, "1.2-3": false} {
+		if actual := compiled.MatchString(input); actual != expected {
+			t.Errorf("expected %s to match %q: %v", re.Regexp(), input, expected)
+		}
+	}
+}
+
+func TestGrammar_Define(t *testing.T) {
+	g := grammar.New()
+	if err := g.Define("1st", regen.Digit); err == nil || err.Error() != `grammar: invalid rule name "1st"` {
+		t.Errorf("unexpected error for an invalid name: %v", err)
+	}
+	if err := g.Define("first", regen.Digit); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.Define("first", regen.Digit); err == nil || err.Error() != "grammar: rule first is already defined" {
+		t.Errorf("unexpected error for a duplicate name: %v", err)
+	}
+	if rules := g.Rules(); len(rules) != 1 || rules[0] != "first" {
+		t.Errorf("unexpected rules: %v", rules)
+	}
+}
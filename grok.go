@@ -0,0 +1,147 @@
+package regen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GrokPattern is a Grok expression (as used by Logstash and Elasticsearch ingest pipelines), along with
+// the custom patterns that it references
+type GrokPattern struct {
+	// Pattern is the Grok expression, e.g. %{CLIENT:client} %{STATUS:status}
+	Pattern string
+	// Definitions maps the names of custom patterns to their regular expressions
+	Definitions map[string]string
+}
+
+// PatternsFile returns the definitions in the format of a Logstash patterns file (one "NAME regex"
+// per line), sorted by name
+func (g GrokPattern) PatternsFile() string {
+	names := make([]string, 0, len(g.Definitions))
+	for name := range g.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(name + " " + g.Definitions[name] + "\n")
+	}
+	return sb.String()
+}
+
+// ToGrok converts re into Grok syntax, replacing each named group with a reference to a custom
+// pattern (named after the group in upper case, e.g. %{CLIENT_IP:client_ip}) that is defined using
+// DialectRuby, since Grok uses Onigmo. Groups with identical sub-expressions share a definition.
+func ToGrok(re Regexp) (GrokPattern, error) {
+	g := GrokPattern{Definitions: make(map[string]string)}
+	byPattern := make(map[string]string)
+	var err error
+	grok := Transform(re, func(node Regexp) Regexp {
+		group, ok := node.(groupedRegexp)
+		if !ok || group.name == "" || err != nil {
+			return node
+		}
+		var pattern string
+		if pattern, err = DialectRuby.Render(group.re); err != nil {
+			return node
+		}
+		name, ok := byPattern[pattern]
+		if !ok {
+			name = grokName(group.name, g.Definitions)
+			byPattern[pattern] = name
+			g.Definitions[name] = pattern
+		}
+		ref := Raw("%{" + name + ":" + group.name + "}")
+		if group.setFlags == 0 && group.unsetFlags == 0 {
+			return ref
+		}
+		return ref.Group().NoCapture().SetFlags(group.setFlags).UnsetFlags(group.unsetFlags)
+	})
+	if err != nil {
+		return GrokPattern{}, err
+	}
+	if g.Pattern, err = DialectRuby.Render(grok); err != nil {
+		return GrokPattern{}, err
+	}
+	return g, nil
+}
+
+// grokName returns an unused pattern name for the group
+func grokName(group string, definitions map[string]string) string {
+	base := strings.ToUpper(group)
+	name := base
+	for i := 2; ; i++ {
+		if _, ok := definitions[name]; !ok {
+			return name
+		}
+		name = base + "_" + strconv.Itoa(i)
+	}
+}
+
+var grokReference = regexp.MustCompile(`%\{(\w+)(?::([\w@.\[\]-]+))?(?::\w+)?\}`)
+
+// FromGrok converts a Grok expression into a Regexp, expanding references to the patterns in
+// definitions (which may refer to each other). References with a field name, such as %{IP:client},
+// become named groups; fields must be valid group names. Type conversions (e.g. %{INT:size:int}) are
+// ignored. The remaining text (and each definition) is included using Raw, so it must be supported by
+// RE2.
+func FromGrok(pattern string, definitions map[string]string) (Regexp, error) {
+	return fromGrok(pattern, definitions, make(map[string]bool))
+}
+
+func fromGrok(pattern string, definitions map[string]string, expanding map[string]bool) (Regexp, error) {
+	var parts []Regexp
+	last := 0
+	for _, match := range grokReference.FindAllStringSubmatchIndex(pattern, -1) {
+		if match[0] > last {
+			parts = append(parts, Raw(pattern[last:match[0]]))
+		}
+		last = match[1]
+		name := pattern[match[2]:match[3]]
+		definition, ok := definitions[name]
+		if !ok {
+			return nil, fmt.Errorf("regen: undefined grok pattern %q", name)
+		}
+		if expanding[name] {
+			return nil, fmt.Errorf("regen: grok pattern %q refers to itself", name)
+		}
+		expanding[name] = true
+		sub, err := fromGrok(definition, definitions, expanding)
+		delete(expanding, name)
+		if err != nil {
+			return nil, err
+		}
+		if match[4] < 0 {
+			parts = append(parts, sub.Group().NoCapture())
+			continue
+		}
+		field := pattern[match[4]:match[5]]
+		if !isGroupName(field) {
+			return nil, fmt.Errorf("regen: grok field %q is not a valid group name", field)
+		}
+		parts = append(parts, sub.Group().CaptureAs(field))
+	}
+	if last < len(pattern) {
+		parts = append(parts, Raw(pattern[last:]))
+	}
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	return Sequence(parts...), nil
+}
+
+// isGroupName returns true if name can be used as the name of a group
+func isGroupName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package regen_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestToGrok(t *testing.T) {
+	for _, tt := range []struct {
+		desc        string
+		re          regen.Regexp
+		pattern     string
+		patternFile string
+	}{
+		{
+			desc: "named groups",
+			re: regen.Sequence(
+				regen.LineStart,
+				regen.Digit.Repeat().Min(1).Group().CaptureAs("status"),
+				regen.String(" "),
+				regen.Whitespace.Negate().Repeat().Min(1).Group().CaptureAs("path"),
+			),
+			pattern:     `\A%{STATUS:status} %{PATH:path}`,
+			patternFile: "PATH [^\\t\\n\\f\\r ]+\nSTATUS \\d+\n",
+		},
+		{
+			desc: "identical groups share a definition",
+			re: regen.Sequence(
+				regen.Digit.Repeat().Min(1).Group().CaptureAs("src"),
+				regen.String("->"),
+				regen.Digit.Repeat().Min(1).Group().CaptureAs("dst"),
+			),
+			pattern:     `%{SRC:src}->%{SRC:dst}`,
+			patternFile: "SRC \\d+\n",
+		},
+		{
+			desc: "names are made unique",
+			re: regen.Sequence(
+				regen.Digit.Group().CaptureAs("id"),
+				regen.String("."),
+				regen.Sequence(regen.String("x"), regen.Digit.Repeat()).Group().CaptureAs("ID"),
+			),
+			pattern:     `%{ID:id}\.%{ID_2:ID}`,
+			patternFile: "ID \\d\nID_2 x\\d*\n",
+		},
+		{
+			desc: "nested groups",
+			re: regen.Sequence(
+				regen.String("ip="),
+				regen.Sequence(
+					regen.Digit.Repeat().Min(1).Group().CaptureAs("host"),
+					regen.String(":"),
+					regen.Digit.Repeat().Min(1).Group().CaptureAs("port"),
+				).Group().CaptureAs("addr"),
+			),
+			pattern:     `ip=%{ADDR:addr}`,
+			patternFile: "ADDR %{HOST:host}:%{HOST:port}\nHOST \\d+\n",
+		},
+		{
+			desc:        "flags are kept",
+			re:          regen.String("get").Group().CaptureAs("method").SetFlags(regen.FlagCaseInsensitive),
+			pattern:     `(?i:%{METHOD:method})`,
+			patternFile: "METHOD get\n",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			g, err := regen.ToGrok(tt.re)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if g.Pattern != tt.pattern {
+				t.Errorf("expected pattern %s, got %s", tt.pattern, g.Pattern)
+			}
+			if actual := g.PatternsFile(); actual != tt.patternFile {
+				t.Errorf("expected patterns file:\n%s\ngot:\n%s", tt.patternFile, actual)
+			}
+		})
+	}
+}
+
+func TestFromGrok(t *testing.T) {
+	definitions := map[string]string{
+		"INT":      `[+-]?\d+`,
+		"WORD":     `\b\w+\b`,
+		"HOSTPORT": `%{WORD:host}:%{INT:port}`,
+		"LOOP":     `a%{LOOP}`,
+	}
+	for _, tt := range []struct {
+		desc     string
+		pattern  string
+		expected string
+		err      string
+	}{
+		{
+			desc:     "references",
+			pattern:  `^%{WORD:method} %{INT:size:int}`,
+			expected: `^(?P<method>\b\w+\b) (?P<size>[+-]?\d+)`,
+		},
+		{
+			desc:     "unnamed references",
+			pattern:  `%{INT}-%{INT:n}`,
+			expected: `(?:[+-]?\d+)-(?P<n>[+-]?\d+)`,
+		},
+		{
+			desc:     "nested references",
+			pattern:  `to %{HOSTPORT:dest}`,
+			expected: `to (?P<dest>(?P<host>\b\w+\b):(?P<port>[+-]?\d+))`,
+		},
+		{
+			desc:    "undefined",
+			pattern: `%{IP:client}`,
+			err:     `regen: undefined grok pattern "IP"`,
+		},
+		{
+			desc:    "recursive",
+			pattern: `%{LOOP}`,
+			err:     `regen: grok pattern "LOOP" refers to itself`,
+		},
+		{
+			desc:    "invalid field",
+			pattern: `%{INT:[http][status]}`,
+			err:     `regen: grok field "[http][status]" is not a valid group name`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			re, err := regen.FromGrok(tt.pattern, definitions)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual := re.Regexp(); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGrokRoundTrip(t *testing.T) {
+	re := regen.Sequence(
+		regen.TextStart,
+		regen.OneOfStrings("GET", "POST").Group().CaptureAs("method"),
+		regen.String(" "),
+		regen.Sequence(
+			regen.String("/"),
+			regen.WordCharacter.Repeat().Group().CaptureAs("resource"),
+		).Group().CaptureAs("path"),
+		regen.TextEnd,
+	)
+	g, err := regen.ToGrok(re)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	imported, err := regen.FromGrok(g.Pattern, g.Definitions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	compiled := regexp.MustCompile(imported.Regexp())
+	match := compiled.FindStringSubmatch("POST /users")
+	if match == nil {
+		t.Fatalf("expected %s to match", imported.Regexp())
+	}
+	for name, expected := range map[string]string{"method": "POST", "path": "/users", "resource": "users"} {
+		if actual := match[compiled.SubexpIndex(name)]; actual != expected {
+			t.Errorf("expected %s to be %q, got %q", name, expected, actual)
+		}
+	}
+}
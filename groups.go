@@ -0,0 +1,76 @@
+package regen
+
+import "regexp/syntax"
+
+// Group describes a capturing group of a Regexp
+type Group struct {
+	// Index is the index of the group's submatch, as returned by FindStringSubmatch
+	Index int
+	// Name is the name of the group, or "" if it is unnamed
+	Name string
+	// Regexp is the sub-expression within the group
+	Regexp Regexp
+}
+
+// Groups returns the capturing groups of re in order, as they are numbered by FindStringSubmatch
+// (starting at 1), without compiling it. This includes the groups added when an expression that
+// requires parentheses is repeated (e.g. Sequence(a, b).Repeat() renders as (ab)*), and any groups
+// within Raw expressions.
+func Groups(re Regexp) []Group {
+	var groups []Group
+	collectGroups(re, &groups)
+	return groups
+}
+
+// GroupIndex returns the index of the submatch for the named capturing group in re, as used by
+// FindStringSubmatch, without compiling it. It returns false if there is no such group.
+func GroupIndex(re Regexp, name string) (int, bool) {
+	if name == "" {
+		return 0, false
+	}
+	for _, group := range Groups(re) {
+		if group.Name == name {
+			return group.Index, true
+		}
+	}
+	return 0, false
+}
+
+func collectGroups(re Regexp, groups *[]Group) {
+	switch re := re.(type) {
+	case groupedRegexp:
+		if !re.noCapture {
+			*groups = append(*groups, Group{Index: len(*groups) + 1, Name: re.name, Regexp: re.re})
+		}
+	case repeatedRegexp:
+		if requiresParens(re.re, re.re.Regexp()) {
+			*groups = append(*groups, Group{Index: len(*groups) + 1, Regexp: re.re})
+		}
+	case literalRegexp:
+		if !re.literal {
+			collectRawGroups(re.re, groups)
+		}
+		return
+	}
+	for _, child := range Children(re) {
+		collectGroups(child, groups)
+	}
+}
+
+// collectRawGroups adds the capturing groups within a raw regular expression
+func collectRawGroups(raw string, groups *[]Group) {
+	parsed, err := syntax.Parse(raw, syntax.Perl)
+	if err != nil {
+		return
+	}
+	var visit func(re *syntax.Regexp)
+	visit = func(re *syntax.Regexp) {
+		if re.Op == syntax.OpCapture {
+			*groups = append(*groups, Group{Index: len(*groups) + 1, Name: re.Name, Regexp: Raw(re.Sub[0].String())})
+		}
+		for _, sub := range re.Sub {
+			visit(sub)
+		}
+	}
+	visit(parsed)
+}
@@ -0,0 +1,74 @@
+package regen_test
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestGroups(t *testing.T) {
+	re := regen.Sequence(
+		regen.String("a").Group().CaptureAs("first"),
+		regen.Sequence(regen.String("b"), regen.Digit.Group()).Repeat(),
+		regen.OneOf(regen.String("c"), regen.String("d").Group().NoCapture()),
+		regen.Raw(`(?P<raw>e)(f)`),
+		regen.Annotate(regen.String("g").Group().CaptureAs("last"), "comment"),
+	)
+	groups := regen.Groups(re)
+
+	compiled := regexp.MustCompile(re.Regexp())
+	names := []string{""}
+	for _, g := range groups {
+		names = append(names, g.Name)
+	}
+	if !reflect.DeepEqual(names, compiled.SubexpNames()) {
+		t.Errorf("expected names %q, got %q", compiled.SubexpNames(), names)
+	}
+
+	expected := []string{`a`, `b(\d)`, `\d`, `c|(?:d)`, `e`, `f`, `g`}
+	for i, g := range groups {
+		if g.Index != i+1 {
+			t.Errorf("expected group %d to have index %d, got %d", i, i+1, g.Index)
+		}
+		if i < len(expected) && g.Regexp.Regexp() != expected[i] {
+			t.Errorf("expected group %d to contain %s, got %s", g.Index, expected[i], g.Regexp.Regexp())
+		}
+	}
+	if len(groups) != len(expected) {
+		t.Errorf("expected %d groups, got %d", len(expected), len(groups))
+	}
+}
+
+func TestGroupIndex(t *testing.T) {
+	re := regen.Sequence(
+		regen.String("hello").Group(),
+		regen.String(", "),
+		regen.OneOfStrings("world", "there").Group().CaptureAs("greeting"),
+		regen.Raw(`(?P<raw>!)?`),
+	)
+	compiled := regexp.MustCompile(re.Regexp())
+	for _, tt := range []struct {
+		name  string
+		found bool
+	}{
+		{name: "greeting", found: true},
+		{name: "raw", found: true},
+		{name: "missing"},
+		{name: ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			index, ok := regen.GroupIndex(re, tt.name)
+			if ok != tt.found {
+				t.Fatalf("expected found to be %v, got %v", tt.found, ok)
+			}
+			if ok && index != compiled.SubexpIndex(tt.name) {
+				t.Errorf("expected index %d, got %d", compiled.SubexpIndex(tt.name), index)
+			}
+		})
+	}
+	if _, ok := regen.GroupIndex(regen.Digit, "greeting"); ok {
+		t.Errorf("expected no groups in a character class")
+	}
+}
@@ -0,0 +1,122 @@
+package regen
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DialectHyperscan is the syntax accepted by Hyperscan and Vectorscan when patterns are compiled with
+// HyperscanUTF8 (see RenderHyperscan). Hyperscan does not report capturing groups, so named groups are
+// rendered as unnamed groups. Since Hyperscan reports every match, greediness has no effect on its
+// results, and FlagUngreedy is ignored. \s is expanded, as it also matches vertical tabs in Hyperscan.
+var DialectHyperscan = Dialect{
+	name: "Hyperscan",
+	flagLetters: map[Flag]byte{
+		FlagCaseInsensitive: 'i',
+		FlagMultiLine:       'm',
+		FlagMatchNewLine:    's',
+		FlagUngreedy:        0,
+	},
+	missing:                  FeatureNamedGroups,
+	perlClasses:              "dw",
+	unicodeScripts:           true,
+	codePointFormat:          `\x{%X}`,
+	dollarBeforeFinalNewline: true,
+	freeSpacing:              true,
+}
+
+// HyperscanFlag is a flag that a Hyperscan pattern is compiled with. The values correspond with
+// Hyperscan's HS_FLAG_* constants, so they can be passed to hs_compile_multi directly.
+type HyperscanFlag uint
+
+const (
+	// HyperscanCaseless corresponds with HS_FLAG_CASELESS, equivalent to FlagCaseInsensitive
+	HyperscanCaseless HyperscanFlag = 1 << iota
+	// HyperscanDotAll corresponds with HS_FLAG_DOTALL, equivalent to FlagMatchNewLine
+	HyperscanDotAll
+	// HyperscanMultiLine corresponds with HS_FLAG_MULTILINE, equivalent to FlagMultiLine
+	HyperscanMultiLine
+	// HyperscanSingleMatch corresponds with HS_FLAG_SINGLEMATCH
+	HyperscanSingleMatch
+	// HyperscanAllowEmpty corresponds with HS_FLAG_ALLOWEMPTY, which is required for patterns that
+	// can match an empty string
+	HyperscanAllowEmpty
+	// HyperscanUTF8 corresponds with HS_FLAG_UTF8, which makes patterns match UTF-8 encoded characters
+	// rather than bytes, as in Go
+	HyperscanUTF8
+	// HyperscanUCP corresponds with HS_FLAG_UCP
+	HyperscanUCP
+)
+
+// hyperscanFlagLetters are the letters used for each HyperscanFlag by Hyperscan's tools (e.g. hscollider)
+var hyperscanFlagLetters = []struct {
+	flag   HyperscanFlag
+	letter byte
+}{
+	{HyperscanCaseless, 'i'},
+	{HyperscanDotAll, 's'},
+	{HyperscanMultiLine, 'm'},
+	{HyperscanSingleMatch, 'H'},
+	{HyperscanAllowEmpty, 'V'},
+	{HyperscanUTF8, '8'},
+	{HyperscanUCP, 'W'},
+}
+
+// String returns the letters representing the flags, as used in Hyperscan's pattern files
+func (f HyperscanFlag) String() string {
+	var sb strings.Builder
+	for _, l := range hyperscanFlagLetters {
+		if f&l.flag != 0 {
+			sb.WriteByte(l.letter)
+		}
+	}
+	return sb.String()
+}
+
+// HyperscanExpression is a pattern rendered for Hyperscan, along with the flags that it must be compiled with
+type HyperscanExpression struct {
+	Expression string
+	Flags      HyperscanFlag
+}
+
+// String returns the expression in the /expression/flags format used by Hyperscan's pattern files
+func (e HyperscanExpression) String() string {
+	return "/" + e.Expression + "/" + e.Flags.String()
+}
+
+// RenderHyperscan renders re in DialectHyperscan, expressing the flags that are set for the entire
+// expression (e.g. using re.Group().NoCapture().SetFlags(...)) as HyperscanFlags rather than inline.
+// HyperscanUTF8 is always set, and HyperscanAllowEmpty is set if re can match an empty string.
+// An *UnsupportedError is returned if re contains a construct that Hyperscan does not support.
+func RenderHyperscan(re Regexp) (HyperscanExpression, error) {
+	var flags Flag
+	inner := re
+	for {
+		g, ok := inner.(groupedRegexp)
+		if !ok || !g.noCapture || g.balance != "" || g.setFlags&^flags == 0 {
+			break
+		}
+		flags = flags&^g.unsetFlags | g.setFlags
+		inner = g.re
+	}
+	r := renderer{dialect: DialectHyperscan, activeFlags: flags}
+	expression := r.regexp(inner)
+	if r.err != nil {
+		return HyperscanExpression{}, r.err
+	}
+
+	hsFlags := HyperscanUTF8
+	if flags&FlagCaseInsensitive != 0 {
+		hsFlags |= HyperscanCaseless
+	}
+	if flags&FlagMatchNewLine != 0 {
+		hsFlags |= HyperscanDotAll
+	}
+	if flags&FlagMultiLine != 0 {
+		hsFlags |= HyperscanMultiLine
+	}
+	if compiled, err := regexp.Compile(re.Regexp()); err == nil && compiled.MatchString("") {
+		hsFlags |= HyperscanAllowEmpty
+	}
+	return HyperscanExpression{Expression: expression, Flags: hsFlags}, nil
+}
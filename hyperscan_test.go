@@ -0,0 +1,82 @@
+package regen_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestRenderHyperscan(t *testing.T) {
+	tests := []struct {
+		description string
+		re          regen.Regexp
+		expected    string
+		unsupported bool
+	}{
+		{
+			description: "always uses UTF-8 mode",
+			re:          regen.String("hello"),
+			expected:    `/hello/8`,
+		},
+		{
+			description: "expresses top-level flags externally",
+			re:          regen.Sequence(regen.String("a"), regen.Any).Group().NoCapture().SetFlags(regen.FlagCaseInsensitive | regen.FlagMatchNewLine),
+			expected:    `/a./is8`,
+		},
+		{
+			description: "keeps inner flags inline",
+			re:          regen.Sequence(regen.String("a"), regen.String("b").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive)),
+			expected:    `/a(?i:b)/8`,
+		},
+		{
+			description: "renders named groups as unnamed groups",
+			re:          regen.String("a").Group().CaptureAs("name"),
+			expected:    `/(a)/8`,
+		},
+		{
+			description: "ignores the ungreedy flag",
+			re:          regen.String("a").Repeat().Group().NoCapture().SetFlags(regen.FlagUngreedy),
+			expected:    `/a*/V8`,
+		},
+		{
+			description: "allows patterns that match an empty string",
+			re:          regen.Digit.Repeat(),
+			expected:    `/\d*/V8`,
+		},
+		{
+			description: "expands \\s",
+			re:          regen.Whitespace,
+			expected:    `/[\t\n\f\r ]/8`,
+		},
+		{
+			description: "renders $ as \\z without multi-line mode",
+			re:          regen.Sequence(regen.String("a"), regen.LineEnd),
+			expected:    `/a\z/8`,
+		},
+		{
+			description: "does not support balancing groups",
+			re:          regen.String(")").Group().CaptureAs("close").Balance("open"),
+			unsupported: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			actual, err := regen.RenderHyperscan(tt.re)
+			if tt.unsupported {
+				var unsupported *regen.UnsupportedError
+				if !errors.As(err, &unsupported) {
+					t.Fatalf("expected an UnsupportedError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual.String() != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
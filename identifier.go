@@ -0,0 +1,78 @@
+package regen
+
+// IdentifierOption configures the identifiers matched by GoIdentifier and JavaScriptIdentifier
+type IdentifierOption func(*identifierConfig)
+
+type identifierConfig struct {
+	unicode bool
+}
+
+// WithUnicodeIdentifiers allows identifiers to contain the non-ASCII letters and digits that the language
+// allows, rather than only ASCII characters
+func WithUnicodeIdentifiers() IdentifierOption {
+	return func(c *identifierConfig) {
+		c.unicode = true
+	}
+}
+
+// Identifier returns a Regexp that matches an identifier made of a character of first followed by any
+// number of characters of rest. Identifiers should usually be delimited (e.g. with ASCIIBoundary), so
+// that part of a longer token isn't matched. Keywords are not excluded, since this requires lookahead.
+func Identifier(first, rest CharClass) Regexp {
+	return Sequence(first, rest.Repeat())
+}
+
+var (
+	asciiLetters = unionCharClassRegexp{charClasses: []CharClass{CharRange('A', 'Z'), CharRange('a', 'z'), CharSet('_')}}
+	// unicodeLetters are the letters of Go identifiers, as defined by the Go specification
+	unicodeLetters = unionCharClassRegexp{charClasses: []CharClass{UnicodeCharClass("L"), CharSet('_')}}
+)
+
+// CIdentifier returns a Regexp that matches an identifier in C (and languages with the same identifiers,
+// such as Java without Unicode identifiers), i.e. [A-Za-z_]\w*
+func CIdentifier() Regexp {
+	return Identifier(asciiLetters, WordCharacter)
+}
+
+// PythonIdentifier returns a Regexp that matches an ASCII identifier in Python, i.e. [A-Za-z_]\w*.
+// Python 3 also allows non-ASCII identifiers, which are normalized before they're compared, so they
+// aren't matched.
+func PythonIdentifier() Regexp {
+	return Identifier(asciiLetters, WordCharacter)
+}
+
+// GoIdentifier returns a Regexp that matches an identifier in Go: a letter or underscore followed by
+// letters, digits and underscores. Letters and digits are ASCII unless WithUnicodeIdentifiers is given,
+// in which case any Unicode letter (\pL) and decimal digit (\p{Nd}) is allowed, as in the Go specification.
+func GoIdentifier(opts ...IdentifierOption) Regexp {
+	var c identifierConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if !c.unicode {
+		return CIdentifier()
+	}
+	return Identifier(unicodeLetters, unionCharClassRegexp{charClasses: []CharClass{UnicodeCharClass("L"), UnicodeCharClass("Nd"), CharSet('_')}})
+}
+
+// JavaScriptIdentifier returns a Regexp that matches an identifier in JavaScript: a letter, $ or
+// underscore followed by letters, digits, $ and underscores. Characters are ASCII unless
+// WithUnicodeIdentifiers is given, in which case the identifier approximates the ID_Start and ID_Continue
+// properties of ECMA-262 using Unicode categories (which don't include the few characters that are
+// only allowed for compatibility), along with the zero-width joiner and non-joiner. Unicode escape
+// sequences (e.g. \u0061) are not matched.
+func JavaScriptIdentifier(opts ...IdentifierOption) Regexp {
+	var c identifierConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if !c.unicode {
+		return Identifier(
+			unionCharClassRegexp{charClasses: []CharClass{asciiLetters, CharSet('$')}},
+			unionCharClassRegexp{charClasses: []CharClass{WordCharacter, CharSet('$')}},
+		)
+	}
+	start := []CharClass{UnicodeCharClass("L"), UnicodeCharClass("Nl"), CharSet('$', '_')}
+	rest := append(start[:len(start):len(start)], UnicodeCharClass("Mn"), UnicodeCharClass("Mc"), UnicodeCharClass("Nd"), UnicodeCharClass("Pc"), CharSet('\u200c', '\u200d'))
+	return Identifier(unionCharClassRegexp{charClasses: start}, unionCharClassRegexp{charClasses: rest})
+}
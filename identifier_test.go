@@ -0,0 +1,80 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestIdentifiers(t *testing.T) {
+	tests := []struct {
+		description string
+		re          regen.Regexp
+		expected    string
+		matches     []string
+		nonMatches  []string
+	}{
+		{
+			description: "C identifiers",
+			re:          regen.CIdentifier(),
+			expected:    `[A-Za-z_]\w*`,
+			matches:     []string{"_", "main", "x86_64"},
+			nonMatches:  []string{"1st", "a-b", "café", "$x"},
+		},
+		{
+			description: "Python identifiers",
+			re:          regen.PythonIdentifier(),
+			matches:     []string{"__init__", "self"},
+			nonMatches:  []string{"2x", "a.b"},
+		},
+		{
+			description: "ASCII Go identifiers",
+			re:          regen.GoIdentifier(),
+			matches:     []string{"ErrNotFound", "_x1"},
+			nonMatches:  []string{"café", "١x"},
+		},
+		{
+			description: "Unicode Go identifiers",
+			re:          regen.GoIdentifier(regen.WithUnicodeIdentifiers()),
+			expected:    `[\pL_][\pL\p{Nd}_]*`,
+			matches:     []string{"café", "αβγ", "x١"},
+			nonMatches:  []string{"١x", "$x", "a\u0301"},
+		},
+		{
+			description: "ASCII JavaScript identifiers",
+			re:          regen.JavaScriptIdentifier(),
+			expected:    `[A-Za-z_$][\w$]*`,
+			matches:     []string{"$", "_$x", "jQuery"},
+			nonMatches:  []string{"1a", "café"},
+		},
+		{
+			description: "Unicode JavaScript identifiers",
+			re:          regen.JavaScriptIdentifier(regen.WithUnicodeIdentifiers()),
+			matches:     []string{"café", "a\u0301", "x\u200dy", "Ⅻ", "$αβ"},
+			nonMatches:  []string{"\u0301a", "١x", "a-b"},
+		},
+		{
+			description: "custom identifiers",
+			re:          regen.Identifier(regen.ASCIICharClass("lower"), regen.CharSet('-').Negate()),
+			expected:    `[[:lower:]][^-]*`,
+			matches:     []string{"kebab"},
+			nonMatches:  []string{"Kebab", "kebab-case"},
+		},
+	}
+	for _, tt := range tests {
+		if tt.expected != "" && tt.re.Regexp() != tt.expected {
+			t.Errorf(`identifier test "%s" failed: got %s, expected %s`, tt.description, tt.re.Regexp(), tt.expected)
+		}
+		m := regen.MustCompile(regen.Sequence(regen.TextStart, tt.re, regen.TextEnd))
+		for _, s := range tt.matches {
+			if !m.MatchString(s) {
+				t.Errorf(`identifier test "%s" failed: expected %s to match %q`, tt.description, tt.re.Regexp(), s)
+			}
+		}
+		for _, s := range tt.nonMatches {
+			if m.MatchString(s) {
+				t.Errorf(`identifier test "%s" failed: expected %s not to match %q`, tt.description, tt.re.Regexp(), s)
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package regen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// inferToken is a run of characters of the same kind within an example: digits, lower case letters,
+// upper case letters or whitespace. Any other character is a token on its own.
+type inferToken struct {
+	kind rune
+	text string
+}
+
+// Token kinds (other characters are their own kind)
+const (
+	inferDigit rune = -1 - iota
+	inferLower
+	inferUpper
+	inferSpace
+)
+
+// Infer proposes an expression that matches each of the positive examples in its entirety, while
+// rejecting each of the negatives, as a starting point for writing a pattern. It is experimental:
+// the result is a suggestion that should be reviewed, and may change between releases.
+//
+// Examples are split into tokens, which are runs of digits, lower case letters, upper case letters
+// or whitespace, and individual punctuation characters. Positives with the same sequence of kinds
+// of tokens are aligned, and each position is generalized to an unbounded run of its class. While
+// negatives are matched, individual positions are made more specific if that rejects more of them:
+// first by limiting the run to the observed range of lengths, and then by using a literal if the
+// position has the same text in every positive. Positives with different sequences of tokens become
+// alternatives. If the negatives still can't be rejected, the positives are matched exactly.
+func Infer(positives, negatives []string) (Regexp, error) {
+	if len(positives) == 0 {
+		return nil, fmt.Errorf("regen: at least one positive example is required")
+	}
+	isPositive := make(map[string]bool)
+	for _, p := range positives {
+		isPositive[p] = true
+	}
+	for _, n := range negatives {
+		if isPositive[n] {
+			return nil, fmt.Errorf("regen: %q is both a positive and a negative example", n)
+		}
+	}
+
+	// Group the positives by their sequence of kinds of tokens, in order of first appearance
+	var signatures []string
+	groups := make(map[string][][]inferToken)
+	for _, p := range positives {
+		tokens := inferTokens(p)
+		signature := inferSignature(tokens)
+		if _, ok := groups[signature]; !ok {
+			signatures = append(signatures, signature)
+		}
+		groups[signature] = append(groups[signature], tokens)
+	}
+
+	// Start with the most general expression, and make individual positions more specific while that
+	// rejects more of the negatives
+	levels := make([][]inferLevel, len(signatures))
+	for i, signature := range signatures {
+		levels[i] = make([]inferLevel, len(groups[signature][0]))
+	}
+	candidate := func() Regexp {
+		var alternatives []Regexp
+		for i, signature := range signatures {
+			alternatives = append(alternatives, inferAligned(groups[signature], levels[i]))
+		}
+		if len(alternatives) == 1 {
+			return alternatives[0]
+		}
+		return OneOf(alternatives...).Group().NoCapture()
+	}
+	matched := countMatches(candidate(), negatives)
+	for level := inferBounded; level <= inferLiterals && matched > 0; level++ {
+		for i := range levels {
+			for j := range levels[i] {
+				if matched == 0 {
+					break
+				}
+				previous := levels[i][j]
+				levels[i][j] = level
+				if m := countMatches(candidate(), negatives); m < matched {
+					matched = m
+				} else {
+					levels[i][j] = previous
+				}
+			}
+		}
+	}
+	if matched == 0 {
+		return candidate(), nil
+	}
+	exact := append([]string{}, positives...)
+	sort.Strings(exact)
+	return OneOfStrings(exact...).Group().NoCapture(), nil
+}
+
+// inferLevel is how specific an inferred expression is
+type inferLevel int
+
+const (
+	inferUnbounded inferLevel = iota
+	inferBounded
+	inferLiterals
+)
+
+// inferAligned returns an expression matching each of the sequences of tokens, which have the same
+// kinds of tokens, where levels are how specific each position should be
+func inferAligned(examples [][]inferToken, levels []inferLevel) Regexp {
+	var parts []Regexp
+	for i, first := range examples[0] {
+		if first.kind >= 0 {
+			parts = append(parts, String(first.text))
+			continue
+		}
+		same := true
+		min, max := len(first.text), len(first.text)
+		for _, tokens := range examples {
+			text := tokens[i].text
+			same = same && text == first.text
+			if len(text) < min {
+				min = len(text)
+			}
+			if len(text) > max {
+				max = len(text)
+			}
+		}
+		class := inferClass(first.kind)
+		switch {
+		case levels[i] == inferLiterals && same:
+			parts = append(parts, String(first.text))
+		case levels[i] == inferUnbounded:
+			parts = append(parts, class.Repeat().Min(1))
+		default:
+			parts = append(parts, class.Repeat().Min(uint(min)).Max(uint(max)))
+		}
+	}
+	if len(parts) == 0 {
+		return String("")
+	}
+	return Simplify(Sequence(parts...))
+}
+
+func inferClass(kind rune) CharClass {
+	switch kind {
+	case inferDigit:
+		return Digit
+	case inferLower:
+		return CharRange('a', 'z')
+	case inferUpper:
+		return CharRange('A', 'Z')
+	}
+	return Whitespace
+}
+
+// inferTokens splits s into tokens
+func inferTokens(s string) []inferToken {
+	var tokens []inferToken
+	for _, r := range s {
+		kind := r
+		switch {
+		case r >= '0' && r <= '9':
+			kind = inferDigit
+		case r >= 'a' && r <= 'z':
+			kind = inferLower
+		case r >= 'A' && r <= 'Z':
+			kind = inferUpper
+		case strings.ContainsRune("\t\n\f\r ", r):
+			kind = inferSpace
+		}
+		if n := len(tokens); n > 0 && kind < 0 && tokens[n-1].kind == kind {
+			tokens[n-1].text += string(r)
+			continue
+		}
+		tokens = append(tokens, inferToken{kind: kind, text: string(r)})
+	}
+	return tokens
+}
+
+// inferSignature returns a key identifying the kinds of the tokens
+func inferSignature(tokens []inferToken) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		if t.kind < 0 {
+			sb.WriteString(fmt.Sprintf("<%d>", -t.kind))
+		} else {
+			sb.WriteString(fmt.Sprintf("%q", t.kind))
+		}
+	}
+	return sb.String()
+}
+
+// countMatches returns the number of strings that re matches in their entirety
+func countMatches(re Regexp, strs []string) int {
+	matcher := regexp.MustCompile(`\A(?:` + re.Regexp() + `)\z`)
+	count := 0
+	for _, s := range strs {
+		if matcher.MatchString(s) {
+			count++
+		}
+	}
+	return count
+}
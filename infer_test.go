@@ -0,0 +1,75 @@
+package regen_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestInfer(t *testing.T) {
+	for _, tt := range []struct {
+		desc       string
+		positives  []string
+		negatives  []string
+		expected   string
+		unexpected []string
+	}{
+		{
+			desc:      "token classes",
+			positives: []string{"2023-01-15", "1999-12-31"},
+			expected:  `\d+-\d+-\d+`,
+		},
+		{
+			desc:      "bounded lengths",
+			positives: []string{"2023-01-15", "1999-12-31"},
+			negatives: []string{"2023-001-15", "2023-1-15"},
+			expected:  `\d+-\d{2}-\d+`,
+		},
+		{
+			desc:      "literals",
+			positives: []string{"user-17", "user-4096"},
+			negatives: []string{"admin-1", "user-12345", "anon-12"},
+			expected:  `user-\d{2,4}`,
+		},
+		{
+			desc:      "alternatives",
+			positives: []string{"INFO: started", "ERROR 42"},
+			expected:  `(?:[A-Z]+:\s+[a-z]+|[A-Z]+\s+\d+)`,
+		},
+		{
+			desc:      "exact",
+			positives: []string{"a1", "b2"},
+			negatives: []string{"a2", "b1"},
+			expected:  `(?:a1|b2)`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			re, err := regen.Infer(tt.positives, tt.negatives)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if re.Regexp() != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, re.Regexp())
+			}
+			matcher := regexp.MustCompile(`\A(?:` + re.Regexp() + `)\z`)
+			for _, p := range tt.positives {
+				if !matcher.MatchString(p) {
+					t.Errorf("expected %q to match", p)
+				}
+			}
+			for _, n := range tt.negatives {
+				if matcher.MatchString(n) {
+					t.Errorf("expected %q not to match", n)
+				}
+			}
+		})
+	}
+
+	if _, err := regen.Infer(nil, nil); err == nil {
+		t.Errorf("expected an error without positive examples")
+	}
+	if _, err := regen.Infer([]string{"a"}, []string{"a"}); err == nil {
+		t.Errorf("expected an error for contradictory examples")
+	}
+}
@@ -0,0 +1,313 @@
+package regen
+
+import "strconv"
+
+// Node is implemented by every expression created by this package, so that its structure can be
+// inspected. Its kind determines the other interfaces it implements: a node of KindGroup is a
+// GroupNode, a node of KindRepeat is a RepeatNode, and a CharClass is a ClassNode.
+type Node interface {
+	Regexp
+	// Kind returns the type of the node
+	Kind() NodeKind
+	// Children returns the sub-expressions of the node, if any
+	Children() []Regexp
+}
+
+// GroupNode is implemented by the nodes of KindGroup
+type GroupNode interface {
+	GroupedRegexp
+	// Name returns the name of the group, or "" if it is not a named capturing group
+	Name() string
+	// IsCapturing returns true unless NoCapture has been called
+	IsCapturing() bool
+	// Flags returns the flags that are set and unset by the group
+	Flags() (set Flag, unset Flag)
+	// BalancedGroup returns the name of the group that is popped by a balancing group (see
+	// GroupedRegexp.Balance), or "" if this is not a balancing group
+	BalancedGroup() string
+}
+
+// RepeatNode is implemented by the nodes of KindRepeat
+type RepeatNode interface {
+	RepeatedRegexp
+	// Bounds returns the minimum and maximum number of repetitions. bounded is false if there is no maximum
+	Bounds() (min uint, max uint, bounded bool)
+	// IsUngreedy returns true if the repetition prefers fewer matches
+	IsUngreedy() bool
+}
+
+// ClassNode is implemented by the CharClass nodes
+type ClassNode interface {
+	CharClass
+	// Chars returns the characters of a CharSet, or the start and end of a CharRange. It returns nil
+	// for other classes
+	Chars() []rune
+	// ClassName returns the name of an ASCII or Unicode class, or the letter of a Perl class (e.g. d
+	// for Digit). It returns "" for other classes
+	ClassName() string
+}
+
+// Kind returns the kind of re. Expressions that are implemented outside of this package are rendered
+// as they are, so they are reported as KindRaw.
+func Kind(re Regexp) NodeKind {
+	if node, ok := re.(Node); ok {
+		return node.Kind()
+	}
+	return KindRaw
+}
+
+// Children returns the sub-expressions of re, or nil if it has none or is implemented outside of this
+// package
+func Children(re Regexp) []Regexp {
+	if node, ok := re.(Node); ok {
+		return node.Children()
+	}
+	return nil
+}
+
+// NodeKind identifies the type of a node in an expression tree (see Kind)
+type NodeKind int
+
+const (
+	// KindString is a literal string created by String
+	KindString NodeKind = iota
+	// KindRaw is a raw regular expression created by Raw
+	KindRaw
+	// KindAny is Any
+	KindAny
+	// KindAnchor is one of the anchors, such as LineStart or ASCIIBoundary
+	KindAnchor
+	// KindSequence is created by Sequence
+	KindSequence
+	// KindOneOf is the alternation within the group returned by OneOf
+	KindOneOf
+	// KindGroup is a GroupedRegexp
+	KindGroup
+	// KindRepeat is a RepeatedRegexp (including Optional)
+	KindRepeat
+	// KindCharSet is a CharClass created by CharSet
+	KindCharSet
+	// KindCharRange is a CharClass created by CharRange
+	KindCharRange
+	// KindASCIIClass is a CharClass created by ASCIICharClass
+	KindASCIIClass
+	// KindUnicodeClass is a CharClass created by UnicodeCharClass
+	KindUnicodeClass
+	// KindPerlClass is one of the Perl character classes, such as Digit
+	KindPerlClass
+	// KindUnion is a CharClass created by Union
+	KindUnion
+	// KindAnnotation is created by Annotate
+	KindAnnotation
+)
+
+var nodeKindNames = []string{
+	KindString:       "String",
+	KindRaw:          "Raw",
+	KindAny:          "Any",
+	KindAnchor:       "Anchor",
+	KindSequence:     "Sequence",
+	KindOneOf:        "OneOf",
+	KindGroup:        "Group",
+	KindRepeat:       "Repeat",
+	KindCharSet:      "CharSet",
+	KindCharRange:    "CharRange",
+	KindASCIIClass:   "ASCIIClass",
+	KindUnicodeClass: "UnicodeClass",
+	KindPerlClass:    "PerlClass",
+	KindUnion:        "Union",
+	KindAnnotation:   "Annotation",
+}
+
+func (k NodeKind) String() string {
+	if k < 0 || int(k) >= len(nodeKindNames) {
+		return "NodeKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return nodeKindNames[k]
+}
+
+func (l literalRegexp) Kind() NodeKind {
+	if l.literal {
+		return KindString
+	}
+	return KindRaw
+}
+
+func (l literalRegexp) Children() []Regexp {
+	return nil
+}
+
+func (a anyRegexp) Kind() NodeKind {
+	return KindAny
+}
+
+func (a anyRegexp) Children() []Regexp {
+	return nil
+}
+
+func (a anchorRegexp) Kind() NodeKind {
+	return KindAnchor
+}
+
+func (a anchorRegexp) Children() []Regexp {
+	return nil
+}
+
+func (m multiRegexp) Kind() NodeKind {
+	if m.separator == "" {
+		return KindSequence
+	}
+	return KindOneOf
+}
+
+func (m multiRegexp) Children() []Regexp {
+	return append([]Regexp(nil), m.res...)
+}
+
+func (g groupedRegexp) Kind() NodeKind {
+	return KindGroup
+}
+
+func (g groupedRegexp) Children() []Regexp {
+	return []Regexp{g.re}
+}
+
+func (g groupedRegexp) Name() string {
+	return g.name
+}
+
+func (g groupedRegexp) IsCapturing() bool {
+	return !g.noCapture
+}
+
+func (g groupedRegexp) Flags() (set Flag, unset Flag) {
+	return g.setFlags, g.unsetFlags
+}
+
+func (g groupedRegexp) BalancedGroup() string {
+	return g.balance
+}
+
+func (r repeatedRegexp) Kind() NodeKind {
+	return KindRepeat
+}
+
+func (r repeatedRegexp) Children() []Regexp {
+	return []Regexp{r.re}
+}
+
+func (r repeatedRegexp) Bounds() (min uint, max uint, bounded bool) {
+	return r.min, r.max, r.hasMax
+}
+
+func (r repeatedRegexp) IsUngreedy() bool {
+	return r.ungreedy
+}
+
+func (a annotatedRegexp) Kind() NodeKind {
+	return KindAnnotation
+}
+
+func (a annotatedRegexp) Children() []Regexp {
+	return []Regexp{a.re}
+}
+
+func (c charSetRegexp) Kind() NodeKind {
+	return KindCharSet
+}
+
+func (c charSetRegexp) Children() []Regexp {
+	return nil
+}
+
+func (c charSetRegexp) Chars() []rune {
+	return append([]rune(nil), c.chars...)
+}
+
+func (c charSetRegexp) ClassName() string {
+	return ""
+}
+
+func (c charRangeRegexp) Kind() NodeKind {
+	return KindCharRange
+}
+
+func (c charRangeRegexp) Children() []Regexp {
+	return nil
+}
+
+func (c charRangeRegexp) Chars() []rune {
+	return []rune{c.start, c.end}
+}
+
+func (c charRangeRegexp) ClassName() string {
+	return ""
+}
+
+func (a asciiCharClassRegexp) Kind() NodeKind {
+	return KindASCIIClass
+}
+
+func (a asciiCharClassRegexp) Children() []Regexp {
+	return nil
+}
+
+func (a asciiCharClassRegexp) Chars() []rune {
+	return nil
+}
+
+func (a asciiCharClassRegexp) ClassName() string {
+	return a.name
+}
+
+func (u unicodeCharClassRegexp) Kind() NodeKind {
+	return KindUnicodeClass
+}
+
+func (u unicodeCharClassRegexp) Children() []Regexp {
+	return nil
+}
+
+func (u unicodeCharClassRegexp) Chars() []rune {
+	return nil
+}
+
+func (u unicodeCharClassRegexp) ClassName() string {
+	return u.name
+}
+
+func (p perlCharClassRegexp) Kind() NodeKind {
+	return KindPerlClass
+}
+
+func (p perlCharClassRegexp) Children() []Regexp {
+	return nil
+}
+
+func (p perlCharClassRegexp) Chars() []rune {
+	return nil
+}
+
+func (p perlCharClassRegexp) ClassName() string {
+	return string(p.letter)
+}
+
+func (u unionCharClassRegexp) Kind() NodeKind {
+	return KindUnion
+}
+
+func (u unionCharClassRegexp) Children() []Regexp {
+	classes := make([]Regexp, len(u.charClasses))
+	for i, class := range u.charClasses {
+		classes[i] = class
+	}
+	return classes
+}
+
+func (u unionCharClassRegexp) Chars() []rune {
+	return nil
+}
+
+func (u unionCharClassRegexp) ClassName() string {
+	return ""
+}
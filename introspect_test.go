@@ -0,0 +1,102 @@
+package regen_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestKind(t *testing.T) {
+	for _, tt := range []struct {
+		re       regen.Regexp
+		expected regen.NodeKind
+		children int
+	}{
+		{re: regen.String("a"), expected: regen.KindString},
+		{re: regen.Raw("a+"), expected: regen.KindRaw},
+		{re: regen.Any, expected: regen.KindAny},
+		{re: regen.LineStart, expected: regen.KindAnchor},
+		{re: regen.Sequence(regen.String("a"), regen.Any), expected: regen.KindSequence, children: 2},
+		{re: regen.Children(regen.OneOf(regen.String("a"), regen.Any))[0], expected: regen.KindOneOf, children: 2},
+		{re: regen.Any.Group(), expected: regen.KindGroup, children: 1},
+		{re: regen.Any.Optional(), expected: regen.KindRepeat, children: 1},
+		{re: regen.CharSet('a'), expected: regen.KindCharSet},
+		{re: regen.CharRange('a', 'z'), expected: regen.KindCharRange},
+		{re: regen.ASCIICharClass("alpha"), expected: regen.KindASCIIClass},
+		{re: regen.UnicodeCharClass("Greek"), expected: regen.KindUnicodeClass},
+		{re: regen.Digit, expected: regen.KindPerlClass},
+		{re: regen.Union(regen.Digit, regen.CharSet('_')), expected: regen.KindUnion, children: 2},
+		{re: regen.Annotate(regen.Any, "anything"), expected: regen.KindAnnotation, children: 1},
+	} {
+		t.Run(tt.expected.String(), func(t *testing.T) {
+			if actual := regen.Kind(tt.re); actual != tt.expected {
+				t.Errorf("expected kind %s, got %s", tt.expected, actual)
+			}
+			if actual := len(regen.Children(tt.re)); actual != tt.children {
+				t.Errorf("expected %d children, got %d", tt.children, actual)
+			}
+		})
+	}
+}
+
+func TestKind_External(t *testing.T) {
+	// expressions implemented outside of the package are opaque
+	re := external("a+")
+	if actual := regen.Kind(re); actual != regen.KindRaw {
+		t.Errorf("expected kind Raw, got %s", actual)
+	}
+	if actual := regen.Children(re); actual != nil {
+		t.Errorf("expected no children, got %v", actual)
+	}
+}
+
+// external implements Regexp without the methods of Node
+type external string
+
+func (e external) Regexp() string                { return string(e) }
+func (e external) Group() regen.GroupedRegexp    { return regen.Raw(string(e)).Group() }
+func (e external) Repeat() regen.RepeatedRegexp  { return regen.Raw(string(e)).Repeat() }
+func (e external) Optional() regen.Regexp        { return regen.Raw(string(e)).Optional() }
+func (e external) GroupIndex(string) (int, bool) { return 0, false }
+
+func TestAccessors(t *testing.T) {
+	g := regen.Any.Group().CaptureAs("x").SetFlags(regen.FlagMatchNewLine).UnsetFlags(regen.FlagUngreedy).(regen.GroupNode)
+	set, unset := g.Flags()
+	if g.Name() != "x" || !g.IsCapturing() || set != regen.FlagMatchNewLine || unset != regen.FlagUngreedy || g.BalancedGroup() != "" {
+		t.Errorf("unexpected group accessors for %s", g.Regexp())
+	}
+	if g := g.NoCapture(); g.(regen.GroupNode).IsCapturing() {
+		t.Errorf("expected %s not to capture", g.Regexp())
+	}
+	if g := regen.Any.Group().Balance("open").(regen.GroupNode); g.BalancedGroup() != "open" {
+		t.Errorf("expected balanced group open, got %q", g.BalancedGroup())
+	}
+
+	r := regen.Digit.Repeat().Min(2).Max(5).Ungreedy().(regen.RepeatNode)
+	if min, max, bounded := r.Bounds(); min != 2 || max != 5 || !bounded || !r.IsUngreedy() {
+		t.Errorf("unexpected repeat accessors for %s", r.Regexp())
+	}
+	if _, _, bounded := regen.Digit.Repeat().(regen.RepeatNode).Bounds(); bounded {
+		t.Error("expected unbounded repetition")
+	}
+
+	for _, tt := range []struct {
+		class regen.CharClass
+		chars []rune
+		name  string
+	}{
+		{class: regen.CharSet('a', 'c'), chars: []rune{'a', 'c'}},
+		{class: regen.CharRange('0', '9'), chars: []rune{'0', '9'}},
+		{class: regen.ASCIICharClass("alpha"), name: "alpha"},
+		{class: regen.UnicodeCharClass("Greek").Negate(), name: "Greek"},
+		{class: regen.WordCharacter, name: "w"},
+	} {
+		if actual := tt.class.(regen.ClassNode).Chars(); !reflect.DeepEqual(actual, tt.chars) {
+			t.Errorf("expected chars %q for %s, got %q", tt.chars, tt.class.Regexp(), actual)
+		}
+		if actual := tt.class.(regen.ClassNode).ClassName(); actual != tt.name {
+			t.Errorf("expected name %q for %s, got %q", tt.name, tt.class.Regexp(), actual)
+		}
+	}
+}
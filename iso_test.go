@@ -0,0 +1,49 @@
+package regen_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestISOCodes(t *testing.T) {
+	tests := []struct {
+		description string
+		re          regen.Regexp
+		matches     []string
+		nonMatches  []string
+	}{
+		{
+			description: "ISO4217 matches currency codes",
+			re:          regen.ISO4217(),
+			matches:     []string{"USD", "EUR", "JPY", "XAU", "ZWG"},
+			nonMatches:  []string{"usd", "ABC", "US", "USDX"},
+		},
+		{
+			description: "ISO3166Alpha2 matches country codes",
+			re:          regen.ISO3166Alpha2(),
+			matches:     []string{"US", "GB", "AX", "ZW"},
+			nonMatches:  []string{"UK", "us", "AA", "USA"},
+		},
+		{
+			description: "BCP47Primary matches two-letter language subtags",
+			re:          regen.BCP47Primary(),
+			matches:     []string{"en", "zh", "aa", "zu"},
+			nonMatches:  []string{"EN", "xx", "eng", "iw"},
+		},
+	}
+	for _, tt := range tests {
+		re := regexp.MustCompile(`^` + tt.re.Regexp() + `$`)
+		for _, s := range tt.matches {
+			if !re.MatchString(s) {
+				t.Errorf(`ISO test "%s" failed: expected "%s" to match`, tt.description, s)
+			}
+		}
+		for _, s := range tt.nonMatches {
+			if re.MatchString(s) {
+				t.Errorf(`ISO test "%s" failed: expected "%s" not to match`, tt.description, s)
+			}
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package regen
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+// jsonNode is the JSON representation of a node of an expression tree. Only the fields that apply to
+// the kind of node are set.
+type jsonNode struct {
+	Kind string `json:"kind"`
+	// Value is the string matched by a String, or the regular expression of a Raw expression or an anchor
+	Value string `json:"value,omitempty"`
+	// Name, Balance, NoCapture, SetFlags and UnsetFlags describe a group
+	Name       string `json:"name,omitempty"`
+	Balance    string `json:"balance,omitempty"`
+	NoCapture  bool   `json:"noCapture,omitempty"`
+	SetFlags   string `json:"setFlags,omitempty"`
+	UnsetFlags string `json:"unsetFlags,omitempty"`
+	// Min, Max and Ungreedy describe a repetition, where a missing Max is unbounded
+	Min      *uint `json:"min,omitempty"`
+	Max      *uint `json:"max,omitempty"`
+	Ungreedy bool  `json:"ungreedy,omitempty"`
+	// Chars are the characters of a CharSet, and Start and End are the bounds of a CharRange
+	Chars string `json:"chars,omitempty"`
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+	// Class is the name of an ASCII or Unicode class, or the letter of a Perl class
+	Class    string      `json:"class,omitempty"`
+	Negated  bool        `json:"negated,omitempty"`
+	Comment  string      `json:"comment,omitempty"`
+	Children []*jsonNode `json:"children,omitempty"`
+}
+
+// toJSONNode converts re into its JSON representation. Expressions that weren't built by this package
+// are represented as Raw expressions.
+func toJSONNode(re Regexp) *jsonNode {
+	switch re := re.(type) {
+	case literalRegexp:
+		if re.literal {
+			return &jsonNode{Kind: KindString.String(), Value: re.value}
+		}
+		return &jsonNode{Kind: KindRaw.String(), Value: re.re}
+	case anyRegexp:
+		return &jsonNode{Kind: KindAny.String()}
+	case anchorRegexp:
+		return &jsonNode{Kind: KindAnchor.String(), Value: re.re}
+	case multiRegexp:
+		return &jsonNode{Kind: re.Kind().String(), Children: toJSONNodes(re.res)}
+	case groupedRegexp:
+		return &jsonNode{
+			Kind:       KindGroup.String(),
+			Name:       re.name,
+			Balance:    re.balance,
+			NoCapture:  re.noCapture,
+			SetFlags:   re.setFlags.String(),
+			UnsetFlags: re.unsetFlags.String(),
+			Children:   []*jsonNode{toJSONNode(re.re)},
+		}
+	case repeatedRegexp:
+		n := &jsonNode{Kind: KindRepeat.String(), Ungreedy: re.ungreedy, Children: []*jsonNode{toJSONNode(re.re)}}
+		if re.hasMin {
+			min := re.min
+			n.Min = &min
+		}
+		if re.hasMax {
+			max := re.max
+			n.Max = &max
+		}
+		return n
+	case annotatedRegexp:
+		return &jsonNode{Kind: KindAnnotation.String(), Comment: re.comment, Children: []*jsonNode{toJSONNode(re.re)}}
+	case charSetRegexp:
+		return &jsonNode{Kind: KindCharSet.String(), Chars: string(re.chars), Negated: re.negated}
+	case charRangeRegexp:
+		return &jsonNode{Kind: KindCharRange.String(), Start: string(re.start), End: string(re.end), Negated: re.negated}
+	case asciiCharClassRegexp:
+		return &jsonNode{Kind: KindASCIIClass.String(), Class: re.name, Negated: re.negated}
+	case unicodeCharClassRegexp:
+		return &jsonNode{Kind: KindUnicodeClass.String(), Class: re.name, Negated: re.negated}
+	case perlCharClassRegexp:
+		return &jsonNode{Kind: KindPerlClass.String(), Class: string(re.letter), Negated: re.negated}
+	case unionCharClassRegexp:
+		n := &jsonNode{Kind: KindUnion.String(), Negated: re.negated}
+		for _, class := range re.charClasses {
+			n.Children = append(n.Children, toJSONNode(class))
+		}
+		return n
+	}
+	return &jsonNode{Kind: KindRaw.String(), Value: re.Regexp()}
+}
+
+func toJSONNodes(res []Regexp) []*jsonNode {
+	nodes := make([]*jsonNode, len(res))
+	for i, re := range res {
+		nodes[i] = toJSONNode(re)
+	}
+	return nodes
+}
+
+// ParseJSON decodes the JSON representation of an expression tree (as produced by json.Marshal for any
+// Regexp built by this package) into a Regexp. Each node is an object with a "kind" (see NodeKind) and
+// the fields that apply to that kind of node, e.g.
+//
+//	{"kind": "Repeat", "min": 1, "children": [{"kind": "PerlClass", "class": "d"}]}
+//
+// Unlike the rendered regular expression, the representation preserves the structure of the expression
+// (including annotations), so it can be stored, diffed and edited by other tools.
+func ParseJSON(data []byte) (Regexp, error) {
+	var n jsonNode
+	if err := json.Unmarshal(data, &n); err != nil {
+		return nil, err
+	}
+	return n.regexp()
+}
+
+// jsonAnchors are the anchors that can be decoded by ParseJSON
+var jsonAnchors = map[string]Regexp{
+	LineStart.Regexp():        LineStart,
+	LineEnd.Regexp():          LineEnd,
+	TextStart.Regexp():        TextStart,
+	TextEnd.Regexp():          TextEnd,
+	ASCIIBoundary.Regexp():    ASCIIBoundary,
+	NotASCIIBoundary.Regexp(): NotASCIIBoundary,
+}
+
+// regexp converts the node back into a Regexp
+func (n *jsonNode) regexp() (Regexp, error) {
+	children := make([]Regexp, len(n.Children))
+	for i, child := range n.Children {
+		if child == nil {
+			return nil, fmt.Errorf("regen: %s has a null child", n.Kind)
+		}
+		re, err := child.regexp()
+		if err != nil {
+			return nil, err
+		}
+		children[i] = re
+	}
+	child := func() (Regexp, error) {
+		if len(children) != 1 {
+			return nil, fmt.Errorf("regen: %s must have exactly one child, got %d", n.Kind, len(children))
+		}
+		return children[0], nil
+	}
+
+	switch n.Kind {
+	case KindString.String():
+		return String(n.Value), nil
+	case KindRaw.String():
+		return Raw(n.Value), nil
+	case KindAny.String():
+		return Any, nil
+	case KindAnchor.String():
+		if anchor, ok := jsonAnchors[n.Value]; ok {
+			return anchor, nil
+		}
+		return nil, fmt.Errorf("regen: unknown anchor %q", n.Value)
+	case KindSequence.String():
+		return multiRegexp{res: children, cache: new(renderCache)}, nil
+	case KindOneOf.String():
+		return multiRegexp{res: children, separator: "|", cache: new(renderCache)}, nil
+	case KindGroup.String():
+		re, err := child()
+		if err != nil {
+			return nil, err
+		}
+		setFlags, err := jsonFlags(n.SetFlags)
+		if err != nil {
+			return nil, err
+		}
+		unsetFlags, err := jsonFlags(n.UnsetFlags)
+		if err != nil {
+			return nil, err
+		}
+		return groupedRegexp{
+			re:         re,
+			name:       n.Name,
+			setFlags:   setFlags,
+			unsetFlags: unsetFlags,
+			noCapture:  n.NoCapture,
+			balance:    n.Balance,
+			cache:      new(renderCache),
+		}, nil
+	case KindRepeat.String():
+		re, err := child()
+		if err != nil {
+			return nil, err
+		}
+		r := repeatedRegexp{re: re, ungreedy: n.Ungreedy, cache: new(renderCache)}
+		if n.Min != nil {
+			r.min, r.hasMin = *n.Min, true
+		}
+		if n.Max != nil {
+			r.max, r.hasMax = *n.Max, true
+		}
+		if r.hasMax && r.max < r.min {
+			return nil, fmt.Errorf("regen: %s has a max of %d, which is less than its min of %d", n.Kind, r.max, r.min)
+		}
+		return r, nil
+	case KindAnnotation.String():
+		re, err := child()
+		if err != nil {
+			return nil, err
+		}
+		return Annotate(re, n.Comment), nil
+	case KindCharSet.String():
+		if n.Chars == "" {
+			return nil, fmt.Errorf("regen: %s must have at least one character", n.Kind)
+		}
+		return charSetRegexp{chars: []rune(n.Chars), negated: n.Negated}, nil
+	case KindCharRange.String():
+		start, startSize := utf8.DecodeRuneInString(n.Start)
+		end, endSize := utf8.DecodeRuneInString(n.End)
+		if startSize == 0 || startSize != len(n.Start) || endSize == 0 || endSize != len(n.End) {
+			return nil, fmt.Errorf("regen: the start and end of a %s must be single characters", n.Kind)
+		}
+		return charRangeRegexp{start: start, end: end, negated: n.Negated}, nil
+	case KindASCIIClass.String():
+		return asciiCharClassRegexp{name: n.Class, negated: n.Negated}, nil
+	case KindUnicodeClass.String():
+		return unicodeCharClassRegexp{name: n.Class, negated: n.Negated}, nil
+	case KindPerlClass.String():
+		if len(n.Class) != 1 {
+			return nil, fmt.Errorf("regen: unknown Perl class %q", n.Class)
+		}
+		return perlCharClassRegexp{letter: n.Class[0], negated: n.Negated}, nil
+	case KindUnion.String():
+		classes := make([]CharClass, len(children))
+		for i, child := range children {
+			class, ok := child.(CharClass)
+			if !ok {
+				return nil, fmt.Errorf("regen: %s can only contain character classes, got %s", n.Kind, n.Children[i].Kind)
+			}
+			classes[i] = class
+		}
+		return unionCharClassRegexp{charClasses: classes, negated: n.Negated}, nil
+	}
+	return nil, fmt.Errorf("regen: unknown kind of node %q", n.Kind)
+}
+
+// jsonFlags parses the letters of flags, as returned by Flag.String
+func jsonFlags(letters string) (Flag, error) {
+	var flags Flag
+	for _, letter := range letters {
+		switch letter {
+		case 'i':
+			flags |= FlagCaseInsensitive
+		case 'm':
+			flags |= FlagMultiLine
+		case 's':
+			flags |= FlagMatchNewLine
+		case 'U':
+			flags |= FlagUngreedy
+		default:
+			return 0, fmt.Errorf("regen: unknown flag %q", letter)
+		}
+	}
+	return flags, nil
+}
+
+// marshalJSON encodes the structure of re, and implements json.Marshaler for all of the expressions in
+// this package (see ParseJSON)
+func marshalJSON(re Regexp) ([]byte, error) {
+	return json.Marshal(toJSONNode(re))
+}
+
+func (l literalRegexp) MarshalJSON() ([]byte, error) {
+	return marshalJSON(l)
+}
+
+func (a anyRegexp) MarshalJSON() ([]byte, error) {
+	return marshalJSON(a)
+}
+
+func (a anchorRegexp) MarshalJSON() ([]byte, error) {
+	return marshalJSON(a)
+}
+
+func (m multiRegexp) MarshalJSON() ([]byte, error) {
+	return marshalJSON(m)
+}
+
+func (g groupedRegexp) MarshalJSON() ([]byte, error) {
+	return marshalJSON(g)
+}
+
+func (r repeatedRegexp) MarshalJSON() ([]byte, error) {
+	return marshalJSON(r)
+}
+
+func (a annotatedRegexp) MarshalJSON() ([]byte, error) {
+	return marshalJSON(a)
+}
+
+func (c charSetRegexp) MarshalJSON() ([]byte, error) {
+	return marshalJSON(c)
+}
+
+func (c charRangeRegexp) MarshalJSON() ([]byte, error) {
+	return marshalJSON(c)
+}
+
+func (a asciiCharClassRegexp) MarshalJSON() ([]byte, error) {
+	return marshalJSON(a)
+}
+
+func (u unicodeCharClassRegexp) MarshalJSON() ([]byte, error) {
+	return marshalJSON(u)
+}
+
+func (p perlCharClassRegexp) MarshalJSON() ([]byte, error) {
+	return marshalJSON(p)
+}
+
+func (u unionCharClassRegexp) MarshalJSON() ([]byte, error) {
+	return marshalJSON(u)
+}
@@ -0,0 +1,85 @@
+package regen_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestJSON(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		re          regen.Regexp
+		expected    string
+	}{
+		{
+			description: "literals and classes",
+			re:          regen.Sequence(regen.String("a.b"), regen.Raw(`\pL`), regen.Digit.Negate(), regen.Any),
+			expected:    `{"kind":"Sequence","children":[{"kind":"String","value":"a.b"},{"kind":"Raw","value":"\\pL"},{"kind":"PerlClass","class":"d","negated":true},{"kind":"Any"}]}`,
+		},
+		{
+			description: "groups and repetitions",
+			re:          regen.String("ab").Group().CaptureAs("x").SetFlags(regen.FlagCaseInsensitive).Repeat().Min(1).Max(3).Ungreedy(),
+			expected:    `{"kind":"Repeat","min":1,"max":3,"ungreedy":true,"children":[{"kind":"Group","name":"x","setFlags":"i","children":[{"kind":"String","value":"ab"}]}]}`,
+		},
+		{
+			description: "alternations",
+			re:          regen.OneOf(regen.LineStart, regen.String("x")).Group().NoCapture(),
+			expected:    `{"kind":"Group","noCapture":true,"children":[{"kind":"OneOf","children":[{"kind":"Anchor","value":"^"},{"kind":"String","value":"x"}]}]}`,
+		},
+		{
+			description: "unions of classes",
+			re:          regen.Union(regen.CharSet('_', '-'), regen.CharRange('a', 'z'), regen.ASCIICharClass("digit"), regen.UnicodeCharClass("Greek")),
+			expected:    `{"kind":"Union","children":[{"kind":"CharSet","chars":"_-"},{"kind":"CharRange","start":"a","end":"z"},{"kind":"ASCIIClass","class":"digit"},{"kind":"UnicodeClass","class":"Greek"}]}`,
+		},
+		{
+			description: "annotations and balancing groups",
+			re:          regen.Annotate(regen.String(")").Group().CaptureAs("inner").Balance("open").UnsetFlags(regen.FlagMultiLine), "close"),
+			expected:    `{"kind":"Annotation","comment":"close","children":[{"kind":"Group","name":"inner","balance":"open","unsetFlags":"m","children":[{"kind":"String","value":")"}]}]}`,
+		},
+		{
+			description: "optional expressions",
+			re:          regen.WordCharacter.Optional(),
+			expected:    `{"kind":"Repeat","min":0,"max":1,"children":[{"kind":"PerlClass","class":"w"}]}`,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			data, err := json.Marshal(tt.re)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, data)
+			}
+			parsed, err := regen.ParseJSON(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !regen.Equal(parsed, tt.re) || parsed.Regexp() != tt.re.Regexp() {
+				t.Errorf("expected %s, got %s", tt.re.Regexp(), parsed.Regexp())
+			}
+		})
+	}
+}
+
+func TestParseJSON_Invalid(t *testing.T) {
+	for _, tt := range []struct {
+		json     string
+		expected string
+	}{
+		{`{"kind":"Loop"}`, `regen: unknown kind of node "Loop"`},
+		{`{"kind":"Anchor","value":"^^"}`, `regen: unknown anchor "^^"`},
+		{`{"kind":"Group"}`, `regen: Group must have exactly one child, got 0`},
+		{`{"kind":"Group","setFlags":"x","children":[{"kind":"Any"}]}`, `regen: unknown flag 'x'`},
+		{`{"kind":"Repeat","min":2,"max":1,"children":[{"kind":"Any"}]}`, `regen: Repeat has a max of 1, which is less than its min of 2`},
+		{`{"kind":"CharRange","start":"ab","end":"z"}`, `regen: the start and end of a CharRange must be single characters`},
+		{`{"kind":"Union","children":[{"kind":"String","value":"a"}]}`, `regen: Union can only contain character classes, got String`},
+		{`{"kind":"Sequence","children":[null]}`, `regen: Sequence has a null child`},
+	} {
+		_, err := regen.ParseJSON([]byte(tt.json))
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("%s: expected error %q, got %v", tt.json, tt.expected, err)
+		}
+	}
+}
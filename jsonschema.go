@@ -0,0 +1,27 @@
+package regen
+
+// JSONSchemaPattern renders re for use as the pattern keyword of a JSON Schema (or an OpenAPI
+// schema), which uses the ECMA-262 syntax (see DialectECMAScript). Named groups are rendered as
+// unnamed groups, since schema validators do not report captures.
+// As with Regexp, the result is not implicitly anchored: validators search for a match anywhere
+// in the value, so re should include TextStart and TextEnd if the entire value must match.
+//
+// Validators aren't required to match with the u flag, in which case patterns match UTF-16 code
+// units. Unicode classes are therefore expanded into ranges, and code points outside of the Basic
+// Multilingual Plane in character classes (including those of Unicode classes such as \p{Lu}) and raw
+// expressions with Unicode escapes are unsupported. Note that . and negated classes may still match
+// half of a surrogate pair.
+func JSONSchemaPattern(re Regexp) (string, error) {
+	return jsonSchemaDialect.Render(re)
+}
+
+var jsonSchemaDialect = func() Dialect {
+	d := DialectECMAScript.Without(FeatureNamedGroups | FeatureUnicodeClasses)
+	d.name = "JSON Schema"
+	d.unicodeBraces = false
+	d.bmpOnly = true
+	d.codeUnits = true
+	d.noUnicodeEscapes = true
+	d.codePointFormat = `\u%04X`
+	return d
+}()
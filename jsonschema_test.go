@@ -0,0 +1,101 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestJSONSchemaPattern(t *testing.T) {
+	re := regen.Sequence(
+		regen.TextStart,
+		regen.WordCharacter.Repeat().Min(1).Group().CaptureAs("user"),
+		regen.String("@"),
+		regen.OneOfStrings("example.com", "example.org").Group().CaptureAs("domain"),
+		regen.TextEnd,
+	)
+	actual, err := regen.JSONSchemaPattern(re)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `^(\w+)@(example\.(?:com|org))$`
+	if actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	if _, err := regen.JSONSchemaPattern(regen.LineEnd.Group().NoCapture().SetFlags(regen.FlagMultiLine)); err == nil {
+		t.Errorf("expected an error for an unsupported construct")
+	}
+}
+
+func TestJSONSchemaPattern_CodeUnits(t *testing.T) {
+	// validators may match without the u flag, against UTF-16 code units
+	for _, tt := range []struct {
+		description string
+		re          regen.Regexp
+		expected    string
+		err         string
+	}{
+		{
+			description: "Unicode classes within the BMP are expanded",
+			re:          regen.UnicodeCharClass("Cherokee"),
+			expected:    `[Ꭰ-Ᏽᏸ-ᏽꭰ-ꮿ]`,
+		},
+		{
+			description: "code points are escaped without braces",
+			re:          regen.CharSet('\x01', 'a'),
+			expected:    `[\u0001a]`,
+		},
+		{
+			description: "characters outside of the BMP are repeated with both of their surrogates",
+			re:          regen.String("😀").Repeat(),
+			expected:    `(?:😀)*`,
+		},
+		{
+			description: "Unicode classes outside of the BMP are unsupported",
+			re:          regen.UnicodeCharClass("Lu"),
+			err:         "regen: code point U+10400 in a character class is not supported by the JSON Schema dialect",
+		},
+		{
+			description: "classes of characters outside of the BMP are unsupported",
+			re:          regen.CharSet('a', '😀'),
+			err:         "regen: code point U+1F600 in a character class is not supported by the JSON Schema dialect",
+		},
+		{
+			description: "case-insensitive characters outside of the BMP are unsupported",
+			re:          regen.String("𐐀").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+			err:         "regen: code point U+10400 in a character class is not supported by the JSON Schema dialect",
+		},
+		{
+			description: "raw Unicode escapes are unsupported",
+			re:          regen.Raw(`\p{L}+`),
+			err:         `regen: raw regular expression \p{L}+ with a Unicode escape is not supported by the JSON Schema dialect`,
+		},
+		{
+			description: "raw code point escapes are unsupported",
+			re:          regen.NotFollowedByLiteral(regen.String("a"), ""),
+			err:         `regen: raw regular expression [^\x00-\x{10FFFF}] with a Unicode escape is not supported by the JSON Schema dialect`,
+		},
+		{
+			description: "escaped backslashes aren't Unicode escapes",
+			re:          regen.Raw(`\\p`),
+			expected:    `\\p`,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			actual, err := regen.JSONSchemaPattern(tt.re)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
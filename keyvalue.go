@@ -0,0 +1,137 @@
+package regen
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// KeyValueOption configures the lines matched by KeyValues
+type KeyValueOption func(*keyValueConfig)
+
+type keyValueConfig struct {
+	// pairSeparator separates pairs. If it's empty, pairs are separated by whitespace
+	pairSeparator string
+	keySeparator  string
+	keyChars      CharClass
+	quote         rune
+}
+
+// WithPairSeparator sets the literal that separates pairs, such as "&" or ", ", which may also follow the
+// last pair. By default, pairs are separated by any amount of whitespace, which may also surround the line.
+func WithPairSeparator(sep string) KeyValueOption {
+	return func(c *keyValueConfig) {
+		c.pairSeparator = sep
+	}
+}
+
+// WithKeyValueSeparator sets the literal between each key and its value (= by default)
+func WithKeyValueSeparator(sep string) KeyValueOption {
+	return func(c *keyValueConfig) {
+		c.keySeparator = sep
+	}
+}
+
+// WithKeyChars sets the characters that keys are made of. By default, keys are made of any characters
+// other than whitespace, the quote and the first characters of the separators.
+func WithKeyChars(class CharClass) KeyValueOption {
+	return func(c *keyValueConfig) {
+		c.keyChars = class
+	}
+}
+
+// WithValueQuote sets the character that quotes values containing whitespace or separators (" by
+// default), within which a backslash escapes the next character. If quote is 0, values can't be quoted.
+func WithValueQuote(quote rune) KeyValueOption {
+	return func(c *keyValueConfig) {
+		c.quote = quote
+	}
+}
+
+// KeyValues returns a Regexp that matches an entire line of key=value pairs, such as a logfmt line, with
+// the value of each of the given keys captured by a named group. Pairs may appear in any order, and other
+// keys are allowed (including keys without a value, e.g. debug), so lines can be parsed without writing
+// a pattern for each of their layouts:
+//
+//	re, err := regen.KeyValues([]string{"level", "msg", "http.status"})
+//	regen.MustCompile(re).MatchFields(`level=info msg="request done" http.status=200`)
+//	// map[http_status:200 level:info msg:"request done"]
+//
+// Groups are named after their keys, with characters other than letters, digits and underscores replaced
+// by underscores. Quoted values are captured along with their quotes, so they should be unquoted (e.g.
+// with strconv.Unquote) before use. Unquoted values can't contain whitespace, quotes or the first
+// character of the pair separator. If a key appears more than once, the last value is captured.
+//
+// An error is returned if the keys are not made of the key characters (see WithKeyChars) or their groups
+// would have the same name.
+func KeyValues(keys []string, opts ...KeyValueOption) (Regexp, error) {
+	c := keyValueConfig{keySeparator: "=", quote: '"'}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.keySeparator == "" {
+		return nil, fmt.Errorf("regen: the separator between keys and values can't be empty")
+	}
+
+	var excluded []rune
+	if c.quote != 0 {
+		excluded = append(excluded, c.quote)
+	}
+	if c.pairSeparator != "" {
+		r, _ := utf8.DecodeRuneInString(c.pairSeparator)
+		excluded = append(excluded, r)
+	}
+	value := unionCharClassRegexp{charClasses: []CharClass{Whitespace, CharSet(excluded...)}, negated: true}.Repeat()
+	values := Regexp(value)
+	if c.quote != 0 {
+		values = OneOf(Quoted(WithQuoteChars(c.quote)), value).Group().NoCapture()
+	}
+	keyChars := c.keyChars
+	if keyChars == nil {
+		r, _ := utf8.DecodeRuneInString(c.keySeparator)
+		keyChars = unionCharClassRegexp{charClasses: []CharClass{Whitespace, CharSet(append(excluded[:len(excluded):len(excluded)], r)...)}, negated: true}
+	}
+	validKey, err := CompileCached(Sequence(TextStart, keyChars.Repeat().Min(1), TextEnd))
+	if err != nil {
+		return nil, err
+	}
+
+	choices := make([]Regexp, 0, len(keys)+1)
+	names := make(map[string]string)
+	for _, key := range keys {
+		if !validKey.MatchString(key) {
+			return nil, fmt.Errorf("regen: key %q is not made of the characters %s", key, keyChars.Regexp())
+		}
+		name := groupNameFor(key)
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("regen: keys %q and %q would both be captured by the group %s", other, key, name)
+		}
+		names[name] = key
+		choices = append(choices, Sequence(String(key+c.keySeparator), values.Group().CaptureAs(name)))
+	}
+	choices = append(choices, Sequence(keyChars.Repeat().Min(1), Sequence(String(c.keySeparator), values).Group().NoCapture().Optional()))
+	pair := OneOf(choices...).Group().NoCapture()
+
+	// Each pair is followed by a separator or the end of the line, so that the groups only appear once
+	start, sep := Regexp(LineStart), String(c.pairSeparator)
+	if c.pairSeparator == "" {
+		start, sep = Sequence(LineStart, Whitespace.Repeat()), Whitespace.Repeat().Min(1)
+	}
+	pairs := Sequence(pair, OneOf(sep, LineEnd).Group().NoCapture()).Group().NoCapture().Repeat().Min(1)
+	return Sequence(start, pairs, LineEnd), nil
+}
+
+// groupNameFor returns the name of a group that captures the value of field, replacing the characters
+// that can't appear in the names of groups with underscores
+func groupNameFor(field string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || r < utf8.RuneSelf && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, field)
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
@@ -0,0 +1,122 @@
+package regen_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestKeyValues(t *testing.T) {
+	tests := []struct {
+		description string
+		keys        []string
+		opts        []regen.KeyValueOption
+		input       string
+		expected    map[string]string
+	}{
+		{
+			description: "captures the requested keys in any order",
+			keys:        []string{"level", "msg"},
+			input:       `ts=2021-01-01T00:00:00Z msg=hello level=info`,
+			expected:    map[string]string{"level": "info", "msg": "hello"},
+		},
+		{
+			description: "captures quoted values with their quotes",
+			keys:        []string{"msg", "http.status"},
+			input:       `  msg="request \"done\"" debug http.status=200 `,
+			expected:    map[string]string{"msg": `"request \"done\""`, "http_status": "200"},
+		},
+		{
+			description: "allows missing keys and empty values",
+			keys:        []string{"user", "err"},
+			input:       `err= retries=3`,
+			expected:    map[string]string{"err": ""},
+		},
+		{
+			description: "does not match keys that only end with a requested key",
+			keys:        []string{"id"},
+			input:       `request_id=7`,
+			expected:    map[string]string{},
+		},
+		{
+			description: "supports other separators",
+			keys:        []string{"q", "page"},
+			opts:        []regen.KeyValueOption{regen.WithPairSeparator("&"), regen.WithKeyValueSeparator(":")},
+			input:       `q:regen&lang:go&page:2`,
+			expected:    map[string]string{"q": "regen", "page": "2"},
+		},
+		{
+			description: "quotes are not special if they are disabled",
+			keys:        []string{"msg"},
+			opts:        []regen.KeyValueOption{regen.WithValueQuote(0)},
+			input:       `msg="a b"`,
+			expected:    map[string]string{"msg": `"a`},
+		},
+		{
+			description: "allows a trailing separator",
+			keys:        []string{"a"},
+			opts:        []regen.KeyValueOption{regen.WithPairSeparator(", ")},
+			input:       `b=2, a=1, `,
+			expected:    map[string]string{"a": "1"},
+		},
+		{
+			description: "does not match keys that are not made of the key characters",
+			keys:        []string{"a"},
+			opts:        []regen.KeyValueOption{regen.WithKeyChars(regen.ASCIICharClass("lower"))},
+			input:       `a=1 B=2`,
+		},
+	}
+	for _, tt := range tests {
+		re, err := regen.KeyValues(tt.keys, tt.opts...)
+		if err != nil {
+			t.Errorf(`key values test "%s" failed: unexpected error: %v`, tt.description, err)
+			continue
+		}
+		m := regen.MustCompile(re)
+		if names := m.GroupNames(); len(names) != len(tt.keys) {
+			t.Errorf(`key values test "%s" failed: expected a group per key, got %v`, tt.description, names)
+		}
+		actual, ok := m.MatchFields(tt.input)
+		if tt.expected == nil {
+			if ok {
+				t.Errorf(`key values test "%s" failed: expected %s not to match, got %v`, tt.description, re.Regexp(), actual)
+			}
+			continue
+		}
+		if !ok || !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf(`key values test "%s" failed: got %v (matched: %v), expected %v`, tt.description, actual, ok, tt.expected)
+		}
+	}
+}
+
+func TestKeyValues_Errors(t *testing.T) {
+	tests := []struct {
+		description string
+		keys        []string
+		opts        []regen.KeyValueOption
+		expected    string
+	}{
+		{
+			description: "keys must be made of the key characters",
+			keys:        []string{"a b"},
+			expected:    `regen: key "a b" is not made of the characters [^\s"=]`,
+		},
+		{
+			description: "keys must have distinct group names",
+			keys:        []string{"a.b", "a-b"},
+			expected:    `regen: keys "a.b" and "a-b" would both be captured by the group a_b`,
+		},
+		{
+			description: "the key separator can't be empty",
+			opts:        []regen.KeyValueOption{regen.WithKeyValueSeparator("")},
+			expected:    "regen: the separator between keys and values can't be empty",
+		},
+	}
+	for _, tt := range tests {
+		_, err := regen.KeyValues(tt.keys, tt.opts...)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf(`key values error test "%s" failed: expected %q, got %v`, tt.description, tt.expected, err)
+		}
+	}
+}
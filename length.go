@@ -0,0 +1,197 @@
+package regen
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// MatchLenBounds returns the minimum and maximum length, in bytes, of any text matched by re,
+// computed without compiling it. If bounded is false, matches can be arbitrarily long and max is
+// meaningless. Since every match is at least min bytes long, inputs shorter than min can be rejected
+// without matching them, and a min of 0 means that re can match the empty string.
+//
+// The bounds are conservative where the exact length is expensive to determine: for instance, a
+// character class matched case-insensitively may be counted as matching between 1 and 4 bytes.
+// Raw expressions are parsed (see Parse) to find their bounds. If re can never match, MatchLenBounds
+// returns 0, 0, true.
+func MatchLenBounds(re Regexp) (min int, max int, bounded bool) {
+	b := matchLenBounds(re, 0)
+	if b.never {
+		return 0, 0, true
+	}
+	return b.min, b.max, b.bounded
+}
+
+type lenBounds struct {
+	min, max int
+	bounded  bool
+	// never is true if the expression can never match
+	never bool
+}
+
+// charLenBounds are the bounds of an arbitrary character
+var charLenBounds = lenBounds{min: 1, max: utf8.UTFMax, bounded: true}
+
+// matchLenBounds returns the bounds of re, where active are the flags set by enclosing groups
+func matchLenBounds(re Regexp, active Flag) lenBounds {
+	switch re := re.(type) {
+	case literalRegexp:
+		if !re.literal {
+			parsed, err := Parse(re.re)
+			if err != nil {
+				return lenBounds{}
+			}
+			if l, ok := parsed.(literalRegexp); ok && !l.literal {
+				// the only raw expression returned by Parse is one that never matches
+				return lenBounds{never: true}
+			}
+			return matchLenBounds(parsed, active)
+		}
+		if active&FlagCaseInsensitive == 0 {
+			return lenBounds{min: len(re.value), max: len(re.value), bounded: true}
+		}
+		b := lenBounds{bounded: true}
+		for _, char := range re.value {
+			b = concatLenBounds(b, runeLenBounds(foldRunes([]rune{char})))
+		}
+		return b
+	case anchorRegexp:
+		return lenBounds{bounded: true}
+	case anyRegexp:
+		return charLenBounds
+	case annotatedRegexp:
+		return matchLenBounds(re.re, active)
+	case groupedRegexp:
+		return matchLenBounds(re.re, active&^re.unsetFlags|re.setFlags)
+	case multiRegexp:
+		if re.separator == "" {
+			b := lenBounds{bounded: true}
+			for _, sub := range re.res {
+				b = concatLenBounds(b, matchLenBounds(sub, active))
+			}
+			return b
+		}
+		b := lenBounds{never: true}
+		for _, sub := range re.res {
+			b = altLenBounds(b, matchLenBounds(sub, active))
+		}
+		return b
+	case repeatedRegexp:
+		sub := matchLenBounds(re.re, active)
+		if sub.never {
+			if re.min == 0 {
+				return lenBounds{bounded: true}
+			}
+			return sub
+		}
+		b := lenBounds{min: sub.min * int(re.min), bounded: true}
+		switch {
+		case sub.bounded && sub.max == 0:
+		case !re.hasMax || !sub.bounded:
+			b.bounded = false
+		default:
+			b.max = sub.max * int(re.max)
+		}
+		return b
+	case CharClass:
+		return classLenBounds(re, active)
+	}
+	return lenBounds{}
+}
+
+// concatLenBounds returns the bounds of a followed by b
+func concatLenBounds(a, b lenBounds) lenBounds {
+	return lenBounds{min: a.min + b.min, max: a.max + b.max, bounded: a.bounded && b.bounded, never: a.never || b.never}
+}
+
+// altLenBounds returns the bounds of a or b
+func altLenBounds(a, b lenBounds) lenBounds {
+	switch {
+	case a.never:
+		return b
+	case b.never:
+		return a
+	}
+	if b.min < a.min {
+		a.min = b.min
+	}
+	if b.max > a.max {
+		a.max = b.max
+	}
+	a.bounded = a.bounded && b.bounded
+	return a
+}
+
+// runeLenBounds returns the bounds of a character that is one of runes
+func runeLenBounds(runes []rune) lenBounds {
+	if len(runes) == 0 {
+		return lenBounds{never: true}
+	}
+	b := lenBounds{min: utf8.UTFMax, bounded: true}
+	for _, char := range runes {
+		n := utf8.RuneLen(char)
+		if n < 0 {
+			// invalid code points are matched as single bytes
+			n = 1
+		}
+		if n < b.min {
+			b.min = n
+		}
+		if n > b.max {
+			b.max = n
+		}
+	}
+	return b
+}
+
+// classLenBounds returns the bounds of a character matched by the class
+func classLenBounds(c CharClass, active Flag) lenBounds {
+	if c.IsNegated() {
+		return charLenBounds
+	}
+	if active&FlagCaseInsensitive != 0 {
+		if set, ok := c.(charSetRegexp); ok {
+			return runeLenBounds(foldRunes(set.chars))
+		}
+		if affectedBy(c, FlagCaseInsensitive) {
+			return charLenBounds
+		}
+	}
+	switch c := c.(type) {
+	case charSetRegexp:
+		return runeLenBounds(c.chars)
+	case charRangeRegexp:
+		if c.start > c.end {
+			return lenBounds{never: true}
+		}
+		return runeLenBounds([]rune{c.start, c.end})
+	case asciiCharClassRegexp, perlCharClassRegexp:
+		return lenBounds{min: 1, max: 1, bounded: true}
+	case unicodeCharClassRegexp:
+		table, ok := unicode.Categories[c.name]
+		if !ok {
+			table, ok = unicode.Scripts[c.name]
+		}
+		if !ok {
+			return charLenBounds
+		}
+		var lo, hi rune = utf8.MaxRune, 0
+		if len(table.R16) > 0 {
+			lo, hi = rune(table.R16[0].Lo), rune(table.R16[len(table.R16)-1].Hi)
+		}
+		if len(table.R32) > 0 {
+			if len(table.R16) == 0 {
+				lo = rune(table.R32[0].Lo)
+			}
+			hi = rune(table.R32[len(table.R32)-1].Hi)
+		}
+		return runeLenBounds([]rune{lo, hi})
+	case unionCharClassRegexp:
+		b := lenBounds{never: true}
+		for _, member := range c.charClasses {
+			b = altLenBounds(b, classLenBounds(member, active))
+		}
+		return b
+	}
+	return charLenBounds
+}
@@ -0,0 +1,96 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestMatchLenBounds(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		re      regen.Regexp
+		min     int
+		max     int
+		bounded bool
+	}{
+		{
+			desc:    "string",
+			re:      regen.String("héllo"),
+			min:     6,
+			max:     6,
+			bounded: true,
+		},
+		{
+			desc:    "anchors are empty",
+			re:      regen.Sequence(regen.TextStart, regen.String("a"), regen.ASCIIBoundary, regen.TextEnd),
+			min:     1,
+			max:     1,
+			bounded: true,
+		},
+		{
+			desc:    "classes",
+			re:      regen.Sequence(regen.Digit, regen.CharSet('a', 'é'), regen.CharRange('a', '€'), regen.UnicodeCharClass("Greek")),
+			min:     5, // Greek begins at U+0370
+			max:     1 + 2 + 3 + 4,
+			bounded: true,
+		},
+		{
+			desc:    "negated classes and any",
+			re:      regen.Sequence(regen.Digit.Negate(), regen.Any),
+			min:     2,
+			max:     8,
+			bounded: true,
+		},
+		{
+			desc:    "alternation",
+			re:      regen.OneOfStrings("a", "abc", "ab"),
+			min:     1,
+			max:     3,
+			bounded: true,
+		},
+		{
+			desc:    "bounded repetition",
+			re:      regen.Sequence(regen.String("ab").Group().Repeat().Min(2).Max(3), regen.Digit.Optional()),
+			min:     4,
+			max:     7,
+			bounded: true,
+		},
+		{
+			desc: "unbounded repetition",
+			re:   regen.Sequence(regen.String("id-"), regen.Digit.Repeat().Min(1)),
+			min:  4,
+		},
+		{
+			desc:    "repetition of an empty expression",
+			re:      regen.LineStart.Repeat(),
+			bounded: true,
+		},
+		{
+			desc:    "case-insensitive",
+			re:      regen.Sequence(regen.String("k1").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive)),
+			min:     2,
+			max:     4, // K (Kelvin sign) is 3 bytes
+			bounded: true,
+		},
+		{
+			desc:    "raw",
+			re:      regen.Raw(`\d{2,4}|[a-f]`),
+			min:     1,
+			max:     4,
+			bounded: true,
+		},
+		{
+			desc:    "never matches",
+			re:      regen.Sequence(regen.String("a"), regen.Raw(`[^\x00-\x{10FFFF}]`)),
+			bounded: true,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			min, max, bounded := regen.MatchLenBounds(tt.re)
+			if min != tt.min || bounded != tt.bounded || (bounded && max != tt.max) {
+				t.Errorf("expected (%d, %d, %v), got (%d, %d, %v)", tt.min, tt.max, tt.bounded, min, max, bounded)
+			}
+		})
+	}
+}
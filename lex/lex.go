@@ -0,0 +1,213 @@
+// Package lex builds lexers whose token types are defined by regen expressions. At each position of
+// the input, the rule with the longest match produces the next token, with ties going to the rule that
+// was given first (so keywords can be listed before identifiers):
+//
+//	l, err := lex.New(
+//		lex.Rule{Kind: "space", Pattern: regen.Whitespace.Repeat().Min(1), Skip: true},
+//		lex.Rule{Kind: "if", Pattern: regen.String("if")},
+//		lex.Rule{Kind: "ident", Pattern: regen.WordCharacter.Repeat().Min(1)},
+//	)
+//	tokens, err := l.Tokenize("if iffy") // if, ident
+package lex
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"unicode/utf8"
+
+	"github.com/aoldershaw/regen"
+)
+
+// Rule defines a type of token
+type Rule struct {
+	// Kind identifies the type of the token
+	Kind string
+	// Pattern matches the text of the token. Its longest match is used, whatever the order of its
+	// alternatives, and empty matches are ignored. Assertions such as \b see the text around the token
+	Pattern regen.Regexp
+	// Skip drops the tokens of the rule (e.g. whitespace or comments), rather than producing them
+	Skip bool
+}
+
+// Token is a token of the input
+type Token struct {
+	Kind string
+	Text string
+	// Offset is the position of the token in the input, in bytes
+	Offset int
+	// Line and Column are the position of the token, starting at 1. Columns are counted in characters
+	Line   int
+	Column int
+}
+
+// Error is returned when none of the rules match the input at a position
+type Error struct {
+	Offset int
+	Line   int
+	Column int
+	// Char is the character that couldn't be matched
+	Char rune
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("lex: %d:%d: unexpected character %q", e.Line, e.Column, e.Char)
+}
+
+// Lexer splits input into tokens according to its rules
+type Lexer struct {
+	rules []Rule
+	// first matches the rules at the start of the input, and next matches them after the character
+	// preceding the position, so that assertions such as \b see it. Each rule is captured by a group.
+	first *regexp.Regexp
+	next  *regexp.Regexp
+	// groups are the indexes of the groups that capture the rules
+	groups []int
+}
+
+// New returns a Lexer for the rules, in order of priority. An error is returned if a rule has no kind or
+// its pattern doesn't compile. The rules are compiled into a single leftmost-longest pattern, so the
+// input is scanned once per token, however many rules there are.
+func New(rules ...Rule) (*Lexer, error) {
+	l := &Lexer{rules: rules, groups: make([]int, len(rules))}
+	choices := make([]regen.Regexp, len(rules))
+	group := 1
+	for i, rule := range rules {
+		if rule.Kind == "" {
+			return nil, fmt.Errorf("lex: rule %d has no kind", i)
+		}
+		if rule.Pattern == nil {
+			return nil, fmt.Errorf("lex: rule %s has no pattern", rule.Kind)
+		}
+		if _, err := regexp.Compile(regen.Sequence(regen.TextStart, rule.Pattern.Group().NoCapture()).Regexp()); err != nil {
+			return nil, fmt.Errorf("lex: rule %s: %v", rule.Kind, err)
+		}
+		choices[i] = regen.Sequence(rule.Pattern).Group()
+		// The rendering of the rule within the choice may have different groups (e.g. for alternations)
+		choice, err := syntax.Parse(choices[i].Regexp(), syntax.Perl)
+		if err != nil {
+			return nil, fmt.Errorf("lex: rule %s: %v", rule.Kind, err)
+		}
+		l.groups[i] = group
+		group += choice.MaxCap()
+	}
+	tokens := regen.OneOf(choices...).Group().NoCapture()
+	var err error
+	if l.first, err = regexp.Compile(regen.Sequence(regen.TextStart, tokens).Regexp()); err != nil {
+		return nil, fmt.Errorf("lex: %v", err)
+	}
+	previous := regen.Any.Group().NoCapture().SetFlags(regen.FlagMatchNewLine)
+	if l.next, err = regexp.Compile(regen.Sequence(regen.TextStart, previous, tokens).Regexp()); err != nil {
+		return nil, fmt.Errorf("lex: %v", err)
+	}
+	l.first.Longest()
+	l.next.Longest()
+	return l, nil
+}
+
+// MustNew is like New, but panics if the Lexer cannot be created
+func MustNew(rules ...Rule) *Lexer {
+	l, err := New(rules...)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// Tokenize returns all of the tokens of input that aren't skipped
+func (l *Lexer) Tokenize(input string) ([]Token, error) {
+	var tokens []Token
+	s := l.Scanner(input)
+	for s.Scan() {
+		tokens = append(tokens, s.Token())
+	}
+	return tokens, s.Err()
+}
+
+// Scanner returns a Scanner for the tokens of input
+func (l *Lexer) Scanner(input string) *Scanner {
+	return &Scanner{lexer: l, input: input, line: 1, column: 1}
+}
+
+// Scanner reads the tokens of an input one at a time, like bufio.Scanner:
+//
+//	s := l.Scanner(input)
+//	for s.Scan() {
+//		fmt.Println(s.Token())
+//	}
+//	if err := s.Err(); err != nil {
+//		...
+//	}
+type Scanner struct {
+	lexer  *Lexer
+	input  string
+	offset int
+	line   int
+	column int
+	token  Token
+	err    error
+}
+
+// Scan advances to the next token that isn't skipped, returning false at the end of the input or if
+// there is an error
+func (s *Scanner) Scan() bool {
+	for s.err == nil && s.offset < len(s.input) {
+		rule, length := s.lexer.longestMatch(s.input, s.offset)
+		if rule < 0 {
+			r, _ := utf8.DecodeRuneInString(s.input[s.offset:])
+			s.err = &Error{Offset: s.offset, Line: s.line, Column: s.column, Char: r}
+			return false
+		}
+		text := s.input[s.offset : s.offset+length]
+		token := Token{Kind: s.lexer.rules[rule].Kind, Text: text, Offset: s.offset, Line: s.line, Column: s.column}
+		s.advance(text)
+		if !s.lexer.rules[rule].Skip {
+			s.token = token
+			return true
+		}
+	}
+	return false
+}
+
+// Token returns the token found by the last call to Scan
+func (s *Scanner) Token() Token {
+	return s.token
+}
+
+// Err returns the error that stopped scanning, if any
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// advance moves the position of the scanner past text
+func (s *Scanner) advance(text string) {
+	s.offset += len(text)
+	for _, r := range text {
+		if r == '\n' {
+			s.line++
+			s.column = 1
+			continue
+		}
+		s.column++
+	}
+}
+
+// longestMatch returns the index of the rule with the longest non-empty match at offset within input
+// (preferring earlier rules), and the length of the match. The index is -1 if no rule matches.
+func (l *Lexer) longestMatch(input string, offset int) (int, int) {
+	compiled, start := l.first, offset
+	if offset > 0 {
+		_, size := utf8.DecodeLastRuneInString(input[:offset])
+		compiled, start = l.next, offset-size
+	}
+	match := compiled.FindStringSubmatchIndex(input[start:])
+	if match == nil || match[1] == offset-start {
+		return -1, 0
+	}
+	for i, group := range l.groups {
+		if match[2*group] >= 0 {
+			return i, match[1] - (offset - start)
+		}
+	}
+	return -1, 0
+}
@@ -0,0 +1,109 @@
+package lex_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+	"github.com/aoldershaw/regen/lex"
+)
+
+var rules = []lex.Rule{
+	{Kind: "space", Pattern: regen.Whitespace.Repeat().Min(1), Skip: true},
+	{Kind: "comment", Pattern: regen.Sequence(regen.String("//"), regen.CharSet('\n').Negate().Repeat()), Skip: true},
+	{Kind: "if", Pattern: regen.String("if")},
+	{Kind: "ident", Pattern: regen.Union(regen.CharRange('a', 'z'), regen.CharSet('_', 'é')).Repeat().Min(1)},
+	{Kind: "number", Pattern: regen.Digit.Repeat().Min(1)},
+	{Kind: "op", Pattern: regen.OneOfStrings("=", "==", "<", "<=")},
+}
+
+func TestLexer(t *testing.T) {
+	l := lex.MustNew(rules...)
+	tokens, err := l.Tokenize("if iffy == 42 // compare\n  café<=7")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []lex.Token{
+		{Kind: "if", Text: "if", Offset: 0, Line: 1, Column: 1},
+		{Kind: "ident", Text: "iffy", Offset: 3, Line: 1, Column: 4},
+		{Kind: "op", Text: "==", Offset: 8, Line: 1, Column: 9},
+		{Kind: "number", Text: "42", Offset: 11, Line: 1, Column: 12},
+		{Kind: "ident", Text: "café", Offset: 27, Line: 2, Column: 3},
+		{Kind: "op", Text: "<=", Offset: 32, Line: 2, Column: 7},
+		{Kind: "number", Text: "7", Offset: 34, Line: 2, Column: 9},
+	}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("expected:\n%+v\ngot:\n%+v", expected, tokens)
+	}
+}
+
+func TestLexer_LongestMatch(t *testing.T) {
+	l := lex.MustNew(
+		lex.Rule{Kind: "if", Pattern: regen.Sequence(regen.ASCIIBoundary, regen.String("if"), regen.ASCIIBoundary)},
+		lex.Rule{Kind: "ident", Pattern: regen.Union(regen.CharRange('a', 'z'), regen.CharSet('_')).Repeat().Min(1)},
+		lex.Rule{Kind: "op", Pattern: regen.OneOf(regen.String("="), regen.String("=="))},
+		lex.Rule{Kind: "shebang", Pattern: regen.Sequence(regen.TextStart, regen.String("#!"), regen.Any.Repeat())},
+		lex.Rule{Kind: "comment", Pattern: regen.Sequence(regen.String("#"), regen.Any.Repeat())},
+	)
+	for _, tt := range []struct {
+		input    string
+		expected []string
+	}{
+		{"a==b", []string{"ident:a", "op:==", "ident:b"}},
+		{"xif", []string{"ident:xif"}},
+		{"x=if", []string{"ident:x", "op:=", "if:if"}},
+		{"#!a", []string{"shebang:#!a"}},
+		{"a#!b", []string{"ident:a", "comment:#!b"}},
+	} {
+		tokens, err := l.Tokenize(tt.input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.input, err)
+			continue
+		}
+		var got []string
+		for _, token := range tokens {
+			got = append(got, token.Kind+":"+token.Text)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%q: expected tokens %v, got %v", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestLexer_Errors(t *testing.T) {
+	l := lex.MustNew(rules...)
+	s := l.Scanner("x = 1\n  y ? 2")
+	var kinds []string
+	for s.Scan() {
+		kinds = append(kinds, s.Token().Kind)
+	}
+	if expected := []string{"ident", "op", "number", "ident"}; !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("expected tokens %v before the error, got %v", expected, kinds)
+	}
+	err, ok := s.Err().(*lex.Error)
+	if !ok {
+		t.Fatalf("expected a *lex.Error, got %v", s.Err())
+	}
+	if expected := (lex.Error{Offset: 10, Line: 2, Column: 5, Char: '?'}); *err != expected {
+		t.Errorf("expected %+v, got %+v", expected, *err)
+	}
+	if expected := `lex: 2:5: unexpected character '?'`; err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err)
+	}
+
+	for _, tt := range []struct {
+		description string
+		rule        lex.Rule
+		expected    string
+	}{
+		{"no kind", lex.Rule{Pattern: regen.Any}, "lex: rule 0 has no kind"},
+		{"no pattern", lex.Rule{Kind: "x"}, "lex: rule x has no pattern"},
+		{"invalid pattern", lex.Rule{Kind: "x", Pattern: regen.Raw(`(`)}, "lex: rule x: error parsing regexp: missing closing ): `\\A(?:()`"},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			if _, err := lex.New(tt.rule); err == nil || err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package regen
+
+import "fmt"
+
+// Warning is a potential problem with a Regexp that was found by Lint
+type Warning struct {
+	// Rule identifies the check that produced the warning, e.g. unnamed-capture
+	Rule string
+	// Message describes the problem
+	Message string
+	// Node is the sub-expression that the warning refers to
+	Node Regexp
+}
+
+func (w Warning) String() string {
+	return w.Rule + ": " + w.Message
+}
+
+// lintContext describes where a node appears within the expression being linted
+type lintContext struct {
+	// active is the set of flags in effect
+	active Flag
+	// explicit is the set of flags that are set or unset by an enclosing group
+	explicit Flag
+	// repeated is true if the node is within a repetition that can match more than once
+	repeated bool
+}
+
+// lintRule checks a single node of an expression, returning any warnings about it
+type lintRule func(re Regexp, ctx lintContext) []Warning
+
+var lintRules = []lintRule{
+	lintUnnamedCapture,
+	lintEmptyAlternative,
+	lintAmbiguousDot,
+	lintRepeatedAnchor,
+	lintRedundantGroup,
+}
+
+// Lint checks re for constructs that are likely to be mistakes or that make the expression harder
+// to maintain, returning a Warning for each one that is found. The following rules are checked:
+//
+//	unnamed-capture      a capturing group without a name
+//	empty-alternative    an empty alternative of OneOf
+//	ambiguous-dot        Any in multi-line mode, where FlagMatchNewLine is neither set nor unset
+//	repeated-anchor      a start or end anchor within a repetition, which can only match once
+//	redundant-group      a non-capturing group (without flags) around an expression that is
+//	                     already a group, a character class, Any or an anchor
+func Lint(re Regexp) []Warning {
+	var warnings []Warning
+	lint(re, lintContext{}, &warnings)
+	return warnings
+}
+
+func lint(re Regexp, ctx lintContext, warnings *[]Warning) {
+	for _, rule := range lintRules {
+		*warnings = append(*warnings, rule(re, ctx)...)
+	}
+	switch node := re.(type) {
+	case groupedRegexp:
+		ctx.active = ctx.active&^node.unsetFlags | node.setFlags
+		ctx.explicit |= node.setFlags | node.unsetFlags
+	case repeatedRegexp:
+		if !node.hasMax || node.max > 1 {
+			ctx.repeated = true
+		}
+	case CharClass:
+		return
+	}
+	for _, child := range Children(re) {
+		lint(child, ctx, warnings)
+	}
+}
+
+func lintUnnamedCapture(re Regexp, _ lintContext) []Warning {
+	g, ok := re.(groupedRegexp)
+	if !ok || g.noCapture || g.name != "" || g.balance != "" {
+		return nil
+	}
+	return []Warning{{
+		Rule:    "unnamed-capture",
+		Message: fmt.Sprintf("capturing group %s is not named; use CaptureAs or NoCapture", g.Regexp()),
+		Node:    re,
+	}}
+}
+
+func lintEmptyAlternative(re Regexp, _ lintContext) []Warning {
+	m, ok := re.(multiRegexp)
+	if !ok || m.separator == "" {
+		return nil
+	}
+	var warnings []Warning
+	for i, choice := range m.res {
+		if choice.Regexp() == "" {
+			warnings = append(warnings, Warning{
+				Rule:    "empty-alternative",
+				Message: fmt.Sprintf("alternative %d of %s is empty; use Optional instead", i+1, m.Regexp()),
+				Node:    re,
+			})
+		}
+	}
+	return warnings
+}
+
+func lintAmbiguousDot(re Regexp, ctx lintContext) []Warning {
+	if _, ok := re.(anyRegexp); !ok || ctx.active&FlagMultiLine == 0 || ctx.explicit&FlagMatchNewLine != 0 {
+		return nil
+	}
+	return []Warning{{
+		Rule:    "ambiguous-dot",
+		Message: "multi-line mode does not make . match newlines; set or unset FlagMatchNewLine to make the intent clear",
+		Node:    re,
+	}}
+}
+
+func lintRepeatedAnchor(re Regexp, ctx lintContext) []Warning {
+	a, ok := re.(anchorRegexp)
+	if !ok || !ctx.repeated {
+		return nil
+	}
+	switch a.re {
+	case `^`, `$`:
+		if ctx.active&FlagMultiLine != 0 {
+			return nil
+		}
+	case `\A`, `\z`:
+	default:
+		return nil
+	}
+	return []Warning{{
+		Rule:    "repeated-anchor",
+		Message: fmt.Sprintf("anchor %s is repeated, but can only match at one position", a.re),
+		Node:    re,
+	}}
+}
+
+func lintRedundantGroup(re Regexp, _ lintContext) []Warning {
+	g, ok := re.(groupedRegexp)
+	if !ok || !g.noCapture || g.setFlags != 0 || g.unsetFlags != 0 {
+		return nil
+	}
+	switch g.re.(type) {
+	case groupedRegexp, CharClass, anyRegexp, anchorRegexp:
+	default:
+		return nil
+	}
+	return []Warning{{
+		Rule:    "redundant-group",
+		Message: fmt.Sprintf("%s is already a single unit, so NoCapture has no effect; remove the group", g.re.Regexp()),
+		Node:    re,
+	}}
+}
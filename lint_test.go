@@ -0,0 +1,75 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		description string
+		re          regen.Regexp
+		rules       []string
+	}{
+		{
+			description: "no warnings",
+			re:          regen.Sequence(regen.String("a").Group().CaptureAs("a"), regen.String("b").Group().NoCapture()),
+		},
+		{
+			description: "unnamed capturing group",
+			re:          regen.Sequence(regen.String("a"), regen.String("b").Group()),
+			rules:       []string{"unnamed-capture"},
+		},
+		{
+			description: "empty alternative",
+			re:          regen.OneOf(regen.String("a"), regen.String("")).Group().NoCapture(),
+			rules:       []string{"empty-alternative"},
+		},
+		{
+			description: "dot in multi-line mode",
+			re:          regen.Sequence(regen.LineStart, regen.Any.Repeat()).Group().NoCapture().SetFlags(regen.FlagMultiLine),
+			rules:       []string{"ambiguous-dot"},
+		},
+		{
+			description: "dot in multi-line mode with explicit newline flag",
+			re:          regen.Sequence(regen.LineStart, regen.Any.Repeat()).Group().NoCapture().SetFlags(regen.FlagMultiLine).UnsetFlags(regen.FlagMatchNewLine),
+		},
+		{
+			description: "anchor within repetition",
+			re:          regen.Sequence(regen.LineStart, regen.Digit).Group().NoCapture().Repeat(),
+			rules:       []string{"repeated-anchor"},
+		},
+		{
+			description: "line anchor within repetition in multi-line mode",
+			re:          regen.Sequence(regen.LineStart, regen.Digit).Group().NoCapture().Repeat().Group().NoCapture().SetFlags(regen.FlagMultiLine),
+		},
+		{
+			description: "anchor within optional expression",
+			re:          regen.Sequence(regen.LineStart, regen.Digit).Group().NoCapture().Repeat().Max(1),
+		},
+		{
+			description: "redundant non-capturing group",
+			re:          regen.Sequence(regen.Digit.Group().NoCapture(), regen.String("ab").Group().NoCapture().Repeat()),
+			rules:       []string{"redundant-group"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			warnings := regen.Lint(tt.re)
+			var rules []string
+			for _, w := range warnings {
+				rules = append(rules, w.Rule)
+			}
+			if len(rules) != len(tt.rules) {
+				t.Fatalf("expected rules %v, got %v", tt.rules, warnings)
+			}
+			for i := range rules {
+				if rules[i] != tt.rules[i] {
+					t.Errorf("expected rules %v, got %v", tt.rules, warnings)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package regen
+
+// NotFollowedByLiteral returns a Regexp that matches re when it is not immediately followed by lit,
+// emulating the negative lookahead re(?!lit) in dialects (such as RE2) that don't support lookaround.
+//
+// The lookahead is expanded into alternations at each position of lit, e.g. NotFollowedByLiteral(foo, "bar")
+// becomes foo(?:\z|[^b]|b(?:\z|[^a]|a(?:\z|[^r]))). Unlike a real lookahead, the match includes the
+// characters that follow re (up to the first that differs from lit), so re should be captured if it
+// must be extracted on its own, and consecutive matches can't share those characters.
+//
+// The alternations are nested once for each character of lit, so engines that limit nesting (such as
+// Go's regexp package, which allows a depth of 1000) reject literals of hundreds of characters when the
+// result is compiled. An empty lit follows every position, so the result never matches.
+func NotFollowedByLiteral(re Regexp, lit string) Regexp {
+	runes := []rune(lit)
+	if len(runes) == 0 {
+		return Sequence(re, noMatch)
+	}
+	return Sequence(re, notPrefixOf(runes))
+}
+
+// noMatch is a Regexp that never matches
+var noMatch = Raw(`[^\x00-\x{10FFFF}]`)
+
+// notPrefixOf returns a Regexp that matches the end of the text, or the shortest string that
+// diverges from runes
+func notPrefixOf(runes []rune) Regexp {
+	choices := []Regexp{TextEnd, CharSet(runes[0]).Negate()}
+	if len(runes) > 1 {
+		choices = append(choices, Sequence(String(string(runes[0])), notPrefixOf(runes[1:])))
+	}
+	return OneOf(choices...).Group().NoCapture()
+}
@@ -0,0 +1,71 @@
+package regen_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestNotFollowedByLiteral(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		re       regen.Regexp
+		expected string
+		matches  []string
+		rejects  []string
+	}{
+		{
+			desc:     "multiple characters",
+			re:       regen.NotFollowedByLiteral(regen.String("foo"), "bar"),
+			expected: `foo(?:\z|[^b]|b(?:\z|[^a]|a(?:\z|[^r])))`,
+			matches:  []string{"foo", "foob", "fooba", "foobaz", "foo bar", "foobbar"},
+			rejects:  []string{"foobar", "foobarbaz", "bar"},
+		},
+		{
+			desc:     "single character",
+			re:       regen.NotFollowedByLiteral(regen.Digit.Repeat().Min(1).Group().CaptureAs("n"), "%"),
+			expected: `(?P<n>\d+)(?:\z|[^%])`,
+			matches:  []string{"10", "10 ", "10%5"},
+			rejects:  []string{"5%"},
+		},
+		{
+			desc:     "empty literal",
+			re:       regen.NotFollowedByLiteral(regen.String("foo"), ""),
+			expected: `foo[^\x00-\x{10FFFF}]`,
+			rejects:  []string{"foo", "foobar"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			if actual := tt.re.Regexp(); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+			compiled := regexp.MustCompile(tt.re.Regexp())
+			for _, s := range tt.matches {
+				if !compiled.MatchString(s) {
+					t.Errorf("expected %q to match", s)
+				}
+			}
+			for _, s := range tt.rejects {
+				if compiled.MatchString(s) {
+					t.Errorf("expected %q not to match", s)
+				}
+			}
+		})
+	}
+}
+
+func TestNotFollowedByLiteral_LongLiteral(t *testing.T) {
+	lit := strings.Repeat("ab", 50)
+	compiled, err := regexp.Compile(regen.NotFollowedByLiteral(regen.String("foo"), lit).Regexp())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if compiled.MatchString("foo" + lit) {
+		t.Errorf("expected foo followed by the literal not to match")
+	}
+	if !compiled.MatchString("foo" + lit[:len(lit)-1]) {
+		t.Errorf("expected foo followed by a prefix of the literal to match")
+	}
+}
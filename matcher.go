@@ -0,0 +1,64 @@
+package regen
+
+import "regexp"
+
+// Matcher is a compiled Regexp that extracts the text captured by its named groups
+type Matcher struct {
+	re       Regexp
+	compiled *regexp.Regexp
+}
+
+// Compile compiles re into a Matcher
+func Compile(re Regexp) (*Matcher, error) {
+	compiled, err := regexp.Compile(re.Regexp())
+	if err != nil {
+		return nil, err
+	}
+	return &Matcher{re: re, compiled: compiled}, nil
+}
+
+// MustCompile is like Compile, but panics if re cannot be compiled
+func MustCompile(re Regexp) *Matcher {
+	m, err := Compile(re)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Pattern returns the Regexp that the Matcher was compiled from
+func (m *Matcher) Pattern() Regexp {
+	return m.re
+}
+
+// Compiled returns the compiled regular expression
+func (m *Matcher) Compiled() *regexp.Regexp {
+	return m.compiled
+}
+
+// GroupNames returns the names of the named groups, in order
+func (m *Matcher) GroupNames() []string {
+	var names []string
+	for _, name := range m.compiled.SubexpNames() {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// MatchString returns true if text contains a match
+func (m *Matcher) MatchString(text string) bool {
+	return m.compiled.MatchString(text)
+}
+
+// MatchFields returns the text captured by each named group in the first match in text. Groups that
+// do not participate in the match are omitted.
+func (m *Matcher) MatchFields(text string) (map[string]string, bool) {
+	return submatchGroups(m.compiled, text)
+}
+
+// Unmarshal is like the Unmarshal function, but uses the compiled expression
+func (m *Matcher) Unmarshal(input string, v interface{}) error {
+	return unmarshal(m.re, m.compiled, input, v)
+}
@@ -0,0 +1,51 @@
+package regen_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestMatcher(t *testing.T) {
+	m := regen.MustCompile(regen.Sequence(
+		regen.WordCharacter.Repeat().Min(1).Group().CaptureAs("key"),
+		regen.String("="),
+		regen.WordCharacter.Repeat().Min(1).Group().CaptureAs("value"),
+		regen.Sequence(regen.String(" #"), regen.Any.Repeat().Group().CaptureAs("comment")).Group().NoCapture().Optional(),
+	))
+	if expected := []string{"key", "value", "comment"}; !reflect.DeepEqual(m.GroupNames(), expected) {
+		t.Errorf("expected group names %v, got %v", expected, m.GroupNames())
+	}
+	if !m.MatchString("a=b") || m.MatchString("a b") {
+		t.Error("unexpected MatchString result")
+	}
+
+	fields, ok := m.MatchFields("x a=b")
+	if !ok {
+		t.Fatal("expected match")
+	}
+	if expected := map[string]string{"key": "a", "value": "b"}; !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %v, got %v", expected, fields)
+	}
+	if _, ok := m.MatchFields("nope"); ok {
+		t.Error("expected no match")
+	}
+
+	var v struct {
+		Key     string  `regen:"key"`
+		Comment *string `regen:"comment"`
+	}
+	if err := m.Unmarshal("a=b #note", &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Key != "a" || v.Comment == nil || *v.Comment != "note" {
+		t.Errorf("unexpected result: %+v", v)
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	if _, err := regen.Compile(regen.Raw("(")); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,131 @@
+package regen
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// MatcherSet matches the patterns of a Set in a single pass, by combining them into one alternation in
+// which each pattern is wrapped in a group that identifies it. This is faster than a Set when there
+// are many patterns, since the text is only scanned once, but the semantics differ: a MatcherSet
+// finds the leftmost match of any pattern, preferring the pattern that was added first when multiple
+// patterns match at the same position, while a Set returns the first pattern that matches anywhere.
+// These are the same for patterns that are anchored to the start of the text.
+type MatcherSet struct {
+	re       Regexp
+	compiled *regexp.Regexp
+	patterns []matcherSetPattern
+}
+
+type matcherSetPattern struct {
+	name string
+	// index is the index of the submatch of the group that identifies the pattern, which is followed
+	// by the pattern's own groups
+	index int
+	// groups is the number of groups of the pattern
+	groups int
+}
+
+// NewMatcherSet combines the patterns of s into a MatcherSet. Later changes to s don't affect the
+// MatcherSet. An error is returned if the combined expression doesn't compile.
+func NewMatcherSet(s *Set) (*MatcherSet, error) {
+	m := &MatcherSet{}
+	alternatives := make([]Regexp, len(s.patterns))
+	index := 1
+	for i, p := range s.patterns {
+		alternatives[i] = groupedRegexp{re: p.re}
+		groups := p.compiled.NumSubexp()
+		m.patterns = append(m.patterns, matcherSetPattern{name: p.name, index: index, groups: groups})
+		index += 1 + groups
+	}
+	if len(alternatives) == 0 {
+		// an empty alternation never matches
+		m.re = Raw(`[^\x00-\x{10FFFF}]`)
+	} else {
+		m.re = OneOf(alternatives...).Group().NoCapture()
+	}
+	compiled, err := regexp.Compile(m.re.Regexp())
+	if err != nil {
+		return nil, fmt.Errorf("regen: compiling the combined patterns: %v", err)
+	}
+	if compiled.NumSubexp() != index-1 {
+		return nil, fmt.Errorf("regen: the combined patterns have %d groups, expected %d", compiled.NumSubexp(), index-1)
+	}
+	m.compiled = compiled
+	return m, nil
+}
+
+// Regexp returns the combined expression
+func (m *MatcherSet) Regexp() Regexp {
+	return m.re
+}
+
+// Match returns the name of the pattern of the first match in text
+func (m *MatcherSet) Match(text string) (string, bool) {
+	match := m.compiled.FindStringSubmatchIndex(text)
+	if match == nil {
+		return "", false
+	}
+	return m.patternOf(match).name, true
+}
+
+// MatchFields is like Match, but also returns the text captured by each named group of the pattern
+// that matched. Groups that do not participate in the match are omitted.
+func (m *MatcherSet) MatchFields(text string) (string, map[string]string, bool) {
+	result, ok := m.MatchResult(text)
+	if !ok {
+		return "", nil, false
+	}
+	fields := make(map[string]string, len(result.Captures))
+	for _, c := range result.Captures {
+		fields[c.Name] = c.Text
+	}
+	return result.Pattern, fields, true
+}
+
+// MatchResult returns the first match in text, identified by the name of its pattern. The indexes of
+// the captures are those of the pattern's own groups, as for a Set.
+func (m *MatcherSet) MatchResult(text string) (MatchResult, bool) {
+	match := m.compiled.FindStringSubmatchIndex(text)
+	if match == nil {
+		return MatchResult{}, false
+	}
+	return m.result(text, match), true
+}
+
+// FindAllResults returns successive matches in text. If n >= 0, at most n matches are returned
+func (m *MatcherSet) FindAllResults(text string, n int) []MatchResult {
+	var results []MatchResult
+	for _, match := range m.compiled.FindAllStringSubmatchIndex(text, n) {
+		results = append(results, m.result(text, match))
+	}
+	return results
+}
+
+// patternOf returns the pattern whose identifying group participated in match
+func (m *MatcherSet) patternOf(match []int) matcherSetPattern {
+	for _, p := range m.patterns {
+		if match[2*p.index] >= 0 {
+			return p
+		}
+	}
+	panic("regen: no pattern of the MatcherSet participated in the match")
+}
+
+func (m *MatcherSet) result(text string, match []int) MatchResult {
+	p := m.patternOf(match)
+	result := MatchResult{Pattern: p.name, Span: Span{Start: match[0], End: match[1]}}
+	names := m.compiled.SubexpNames()
+	for i := p.index + 1; i <= p.index+p.groups; i++ {
+		if names[i] == "" || match[2*i] < 0 {
+			continue
+		}
+		result.Captures = append(result.Captures, Capture{
+			Name:  names[i],
+			Index: i - p.index,
+			Span:  Span{Start: match[2*i], End: match[2*i+1]},
+			Text:  text[match[2*i]:match[2*i+1]],
+		})
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package regen_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestMatcherSet(t *testing.T) {
+	word := regen.WordCharacter.Repeat().Min(1)
+	set := regen.NewSet().
+		MustAdd("request", regen.Sequence(
+			regen.LineStart,
+			regen.OneOf(regen.String("GET"), regen.String("POST")).Group().CaptureAs("method"),
+			regen.String(" "),
+			regen.Raw(`(/\S*)`),
+			regen.Sequence(regen.String(" "), regen.Digit.Repeat().Exactly(3).Group().CaptureAs("status")).Optional(),
+		)).
+		MustAdd("assignment", regen.Sequence(word.Group().CaptureAs("key"), regen.String("="), word.Group().CaptureAs("value"))).
+		MustAdd("word", word.Group().CaptureAs("key"))
+	m, err := regen.NewMatcherSet(set)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, tt := range []struct {
+		text     string
+		expected regen.MatchResult
+		ok       bool
+	}{
+		{
+			text: "GET /index.html",
+			expected: regen.MatchResult{Pattern: "request", Span: regen.Span{Start: 0, End: 15}, Captures: []regen.Capture{
+				{Name: "method", Index: 1, Span: regen.Span{Start: 0, End: 3}, Text: "GET"},
+			}},
+			ok: true,
+		},
+		{
+			text: "retries=3",
+			expected: regen.MatchResult{Pattern: "assignment", Span: regen.Span{Start: 0, End: 9}, Captures: []regen.Capture{
+				{Name: "key", Index: 1, Span: regen.Span{Start: 0, End: 7}, Text: "retries"},
+				{Name: "value", Index: 2, Span: regen.Span{Start: 8, End: 9}, Text: "3"},
+			}},
+			ok: true,
+		},
+		{
+			text: "  hello",
+			expected: regen.MatchResult{Pattern: "word", Span: regen.Span{Start: 2, End: 7}, Captures: []regen.Capture{
+				{Name: "key", Index: 1, Span: regen.Span{Start: 2, End: 7}, Text: "hello"},
+			}},
+			ok: true,
+		},
+		{text: "!!", ok: false},
+	} {
+		t.Run(tt.text, func(t *testing.T) {
+			result, ok := m.MatchResult(tt.text)
+			if ok != tt.ok || !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %+v (%t), got %+v (%t)", tt.expected, tt.ok, result, ok)
+			}
+			if name, ok := m.Match(tt.text); ok != tt.ok || name != tt.expected.Pattern {
+				t.Errorf("expected %q (%t), got %q (%t)", tt.expected.Pattern, tt.ok, name, ok)
+			}
+			if set, ok := set.MatchResult(tt.text); ok && set.Pattern == result.Pattern && !reflect.DeepEqual(set, result) {
+				t.Errorf("expected the same result as the Set %+v, got %+v", set, result)
+			}
+		})
+	}
+
+	name, fields, ok := m.MatchFields("POST /login 302")
+	if expected := map[string]string{"method": "POST", "status": "302"}; !ok || name != "request" || !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected request %v, got %q %v (%t)", expected, name, fields, ok)
+	}
+
+	var patterns []string
+	for _, result := range m.FindAllResults("a=b c", -1) {
+		patterns = append(patterns, result.Pattern)
+	}
+	if expected := []string{"assignment", "word"}; !reflect.DeepEqual(patterns, expected) {
+		t.Errorf("expected %v, got %v", expected, patterns)
+	}
+}
+
+func TestMatcherSet_Empty(t *testing.T) {
+	m, err := regen.NewMatcherSet(regen.NewSet())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name, ok := m.Match(""); ok {
+		t.Errorf("expected no match, got %q", name)
+	}
+}
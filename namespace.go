@@ -0,0 +1,136 @@
+package regen
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+)
+
+// NamespaceSeparator separates a namespace from the names registered within it, e.g. payments/card_number
+const NamespaceSeparator = "/"
+
+// Namespace is a portion of a Registry that is owned by a single team or category of patterns.
+// Names within a namespace are prefixed by the namespace's name and NamespaceSeparator, and can
+// only be registered through the Namespace, which enforces the namespace's Policy.
+type Namespace struct {
+	registry *Registry
+	name     string
+	policy   Policy
+}
+
+// Policy restricts the Regexps that can be registered in a Namespace
+type Policy struct {
+	// MaxLength is the maximum length of the rendered (RE2) regular expression. 0 means no limit.
+	MaxLength int
+	// MaxCaptures is the maximum number of capturing groups. 0 means no limit.
+	MaxCaptures int
+	// RequireAnchoredStart requires that the Regexp only matches at the start of the text (e.g. \A)
+	RequireAnchoredStart bool
+	// RequireAnchoredEnd requires that the Regexp only matches at the end of the text (e.g. \z)
+	RequireAnchoredEnd bool
+}
+
+// Namespace claims the namespace with the given name (which may itself contain NamespaceSeparator,
+// e.g. team/category) for registering Regexps that satisfy policy. An error is returned if the
+// namespace overlaps with an existing namespace, or if Regexps have already been registered within it.
+func (r *Registry) Namespace(name string, policy Policy) (*Namespace, error) {
+	if name == "" || strings.HasPrefix(name, NamespaceSeparator) || strings.HasSuffix(name, NamespaceSeparator) {
+		return nil, fmt.Errorf("regen: invalid namespace %q", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for existing := range r.namespaces {
+		if withinNamespace(name, existing) || withinNamespace(existing, name) {
+			return nil, fmt.Errorf("regen: namespace %q collides with namespace %q", name, existing)
+		}
+	}
+	for entry := range r.entries {
+		if withinNamespace(entry, name) {
+			return nil, fmt.Errorf("regen: namespace %q collides with registered Regexp %q", name, entry)
+		}
+	}
+	ns := &Namespace{registry: r, name: name, policy: policy}
+	r.namespaces[name] = ns
+	return ns, nil
+}
+
+// namespaceOf returns the Namespace that name belongs to, or nil if it does not belong to one.
+// The caller must hold r.mu.
+func (r *Registry) namespaceOf(name string) *Namespace {
+	for _, ns := range r.namespaces {
+		if withinNamespace(name, ns.name) {
+			return ns
+		}
+	}
+	return nil
+}
+
+// withinNamespace returns true if name is (or is within) the namespace
+func withinNamespace(name string, namespace string) bool {
+	return name == namespace || strings.HasPrefix(name, namespace+NamespaceSeparator)
+}
+
+// Name returns the name of the namespace
+func (ns *Namespace) Name() string {
+	return ns.name
+}
+
+// Register adds re to the registry under the namespace, i.e. as namespace/name.
+// In addition to the errors returned by Registry.Register, an error is returned if re does
+// not satisfy the namespace's Policy.
+func (ns *Namespace) Register(name string, re Regexp, opts ...EntryOption) error {
+	if name == "" {
+		return fmt.Errorf("regen: cannot register a Regexp without a name")
+	}
+	return ns.registry.register(ns, ns.name+NamespaceSeparator+name, re, opts)
+}
+
+// MustRegister is like Register, but panics if re cannot be registered
+func (ns *Namespace) MustRegister(name string, re Regexp, opts ...EntryOption) Regexp {
+	if err := ns.Register(name, re, opts...); err != nil {
+		panic(err)
+	}
+	return re
+}
+
+// Lookup returns the Entry registered under the given name within the namespace
+func (ns *Namespace) Lookup(name string) (Entry, bool) {
+	return ns.registry.Lookup(ns.name + NamespaceSeparator + name)
+}
+
+// Entries returns the entries registered within the namespace, sorted by name
+func (ns *Namespace) Entries() []Entry {
+	var entries []Entry
+	for _, entry := range ns.registry.Entries() {
+		if withinNamespace(entry.Name, ns.name) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// check returns an error describing the first way that re violates the policy
+func (p Policy) check(re Regexp) error {
+	pattern := re.Regexp()
+	if p.MaxLength > 0 && len(pattern) > p.MaxLength {
+		return fmt.Errorf("length %d exceeds the maximum of %d", len(pattern), p.MaxLength)
+	}
+	if p.MaxCaptures == 0 && !p.RequireAnchoredStart && !p.RequireAnchoredEnd {
+		return nil
+	}
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return err
+	}
+	if p.MaxCaptures > 0 && parsed.MaxCap() > p.MaxCaptures {
+		return fmt.Errorf("%d capturing groups exceeds the maximum of %d", parsed.MaxCap(), p.MaxCaptures)
+	}
+	if p.RequireAnchoredStart && syntaxAnchoring(parsed, false) != AnchoredAtText {
+		return fmt.Errorf("it is not anchored to the start of the text")
+	}
+	if p.RequireAnchoredEnd && syntaxAnchoring(parsed, true) != AnchoredAtText {
+		return fmt.Errorf("it is not anchored to the end of the text")
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestNamespaceCollisions(t *testing.T) {
+	registry := regen.NewRegistry()
+	registry.MustRegister("shared", regen.String("x"))
+
+	payments, err := registry.Namespace("payments", regen.Policy{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := payments.Register("card", regen.Digit.Repeat().Min(1)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := registry.Lookup("payments/card"); !ok {
+		t.Errorf("expected payments/card to be registered")
+	}
+	if _, ok := payments.Lookup("card"); !ok {
+		t.Errorf("expected card to be found in the namespace")
+	}
+	if err := registry.Register("payments/iban", regen.String("x")); err == nil {
+		t.Errorf("expected an error registering within a namespace without using it")
+	}
+
+	for _, name := range []string{"payments", "payments/cards", "shared", "", "/x", "x/"} {
+		if _, err := registry.Namespace(name, regen.Policy{}); err == nil {
+			t.Errorf("expected an error claiming namespace %q", name)
+		}
+	}
+	if _, err := registry.Namespace("payments-eu", regen.Policy{}); err != nil {
+		t.Errorf("unexpected error claiming a namespace that shares a prefix: %v", err)
+	}
+
+	entries := payments.Entries()
+	if len(entries) != 1 || entries[0].Name != "payments/card" {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
+
+func TestNamespacePolicy(t *testing.T) {
+	tests := []struct {
+		description string
+		policy      regen.Policy
+		re          regen.Regexp
+		violates    bool
+	}{
+		{
+			description: "within the maximum length",
+			policy:      regen.Policy{MaxLength: 5},
+			re:          regen.String("hello"),
+		},
+		{
+			description: "exceeds the maximum length",
+			policy:      regen.Policy{MaxLength: 4},
+			re:          regen.String("hello"),
+			violates:    true,
+		},
+		{
+			description: "exceeds the maximum number of captures",
+			policy:      regen.Policy{MaxCaptures: 1},
+			re:          regen.Sequence(regen.String("a").Group(), regen.String("b").Group()),
+			violates:    true,
+		},
+		{
+			description: "anchored at both ends",
+			policy:      regen.Policy{RequireAnchoredStart: true, RequireAnchoredEnd: true},
+			re:          regen.Sequence(regen.TextStart, regen.OneOf(regen.String("a"), regen.String("b")).Group(), regen.TextEnd),
+		},
+		{
+			description: "anchored within each alternative",
+			policy:      regen.Policy{RequireAnchoredStart: true},
+			re:          regen.OneOf(regen.Sequence(regen.LineStart, regen.String("a")), regen.Sequence(regen.TextStart, regen.String("b"))),
+		},
+		{
+			description: "not anchored at the start",
+			policy:      regen.Policy{RequireAnchoredStart: true},
+			re:          regen.Sequence(regen.String("a"), regen.TextEnd),
+			violates:    true,
+		},
+		{
+			description: "not anchored at the end",
+			policy:      regen.Policy{RequireAnchoredEnd: true},
+			re:          regen.Sequence(regen.TextStart, regen.String("a")),
+			violates:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			ns, err := regen.NewRegistry().Namespace("team", tt.policy)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = ns.Register("pattern", tt.re)
+			if tt.violates && err == nil {
+				t.Errorf("expected a policy violation")
+			} else if !tt.violates && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Package otelregen extracts OpenTelemetry attributes from log and span text using the named groups
+// of regen patterns, so that observability pipelines can be configured declaratively.
+package otelregen
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aoldershaw/regen"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Mapping maps the names of groups to the keys of the attributes that they are stored in. Groups
+// that aren't in the mapping are not extracted. A nil Mapping extracts every named group into an
+// attribute with the same name.
+type Mapping map[string]string
+
+// Extractor extracts attributes from text using the named groups of a Matcher
+type Extractor struct {
+	matcher *regen.Matcher
+	// groups and keys are the groups that are extracted and their attribute keys, in the order that
+	// the groups appear in the pattern
+	groups []string
+	keys   []string
+}
+
+// NewExtractor returns an Extractor that stores the groups of m in attributes according to mapping.
+// An error is returned if the mapping refers to a group that is not in the pattern.
+func NewExtractor(m *regen.Matcher, mapping Mapping) (*Extractor, error) {
+	e := &Extractor{matcher: m}
+	names := make(map[string]bool)
+	for _, name := range m.GroupNames() {
+		names[name] = true
+		key := name
+		if mapping != nil {
+			var ok bool
+			if key, ok = mapping[name]; !ok {
+				continue
+			}
+		}
+		e.groups = append(e.groups, name)
+		e.keys = append(e.keys, key)
+	}
+	for name := range mapping {
+		if !names[name] {
+			return nil, fmt.Errorf("otelregen: group %q is not in the pattern %s", name, m.Pattern().Regexp())
+		}
+	}
+	return e, nil
+}
+
+// Attributes returns the attributes extracted from the first match in text, omitting groups that
+// don't participate in the match. It returns nil if text does not match.
+func (e *Extractor) Attributes(text string) []attribute.KeyValue {
+	fields, ok := e.matcher.MatchFields(text)
+	if !ok {
+		return nil
+	}
+	var attrs []attribute.KeyValue
+	for i, group := range e.groups {
+		if value, ok := fields[group]; ok {
+			attrs = append(attrs, attribute.String(e.keys[i], value))
+		}
+	}
+	return attrs
+}
+
+// LogAttributes is like Attributes, but returns log attributes
+func (e *Extractor) LogAttributes(text string) []log.KeyValue {
+	attrs := e.Attributes(text)
+	if attrs == nil {
+		return nil
+	}
+	logAttrs := make([]log.KeyValue, len(attrs))
+	for i, attr := range attrs {
+		logAttrs[i] = log.String(string(attr.Key), attr.Value.AsString())
+	}
+	return logAttrs
+}
+
+// NewLogProcessor returns a log Processor that adds the attributes extracted from the body of each
+// record (if it is a string) before passing the record on to next
+func NewLogProcessor(e *Extractor, next sdklog.Processor) sdklog.Processor {
+	return &logProcessor{extractor: e, next: next}
+}
+
+type logProcessor struct {
+	extractor *Extractor
+	next      sdklog.Processor
+}
+
+func (p *logProcessor) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	if body := record.Body(); body.Kind() == log.KindString {
+		if attrs := p.extractor.LogAttributes(body.AsString()); len(attrs) > 0 {
+			record.AddAttributes(attrs...)
+		}
+	}
+	return p.next.OnEmit(ctx, record)
+}
+
+func (p *logProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *logProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// NewSpanProcessor returns a SpanProcessor that adds the attributes extracted from the name of each
+// span when it is started
+func NewSpanProcessor(e *Extractor) sdktrace.SpanProcessor {
+	return &spanProcessor{extractor: e}
+}
+
+type spanProcessor struct {
+	extractor *Extractor
+}
+
+func (p *spanProcessor) OnStart(_ context.Context, s sdktrace.ReadWriteSpan) {
+	if attrs := p.extractor.Attributes(s.Name()); len(attrs) > 0 {
+		s.SetAttributes(attrs...)
+	}
+}
+
+func (p *spanProcessor) OnEnd(sdktrace.ReadOnlySpan) {}
+
+func (p *spanProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+func (p *spanProcessor) ForceFlush(context.Context) error {
+	return nil
+}
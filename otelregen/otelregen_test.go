@@ -0,0 +1,135 @@
+package otelregen_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+	"github.com/aoldershaw/regen/otelregen"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var requestMatcher = regen.MustCompile(regen.Sequence(
+	regen.OneOfStrings("GET", "POST").Group().CaptureAs("method"),
+	regen.String(" "),
+	regen.Raw(`/\S*`).Group().CaptureAs("path"),
+	regen.Sequence(regen.String(" "), regen.Digit.Repeat().Exactly(3).Group().CaptureAs("status")).Group().NoCapture().Optional(),
+))
+
+func TestExtractor(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		mapping  otelregen.Mapping
+		text     string
+		expected []attribute.KeyValue
+	}{
+		{
+			desc: "all groups",
+			text: "GET /index.html 200",
+			expected: []attribute.KeyValue{
+				attribute.String("method", "GET"),
+				attribute.String("path", "/index.html"),
+				attribute.String("status", "200"),
+			},
+		},
+		{
+			desc:    "mapping",
+			mapping: otelregen.Mapping{"path": "url.path", "status": "http.response.status_code"},
+			text:    "POST /login",
+			expected: []attribute.KeyValue{
+				attribute.String("url.path", "/login"),
+			},
+		},
+		{
+			desc: "no match",
+			text: "DELETE /",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			e, err := otelregen.NewExtractor(requestMatcher, tt.mapping)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual := e.Attributes(tt.text); !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNewExtractor_UnknownGroup(t *testing.T) {
+	if _, err := otelregen.NewExtractor(requestMatcher, otelregen.Mapping{"user": "user.name"}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+type recordingProcessor struct {
+	records []sdklog.Record
+}
+
+func (p *recordingProcessor) OnEmit(_ context.Context, record *sdklog.Record) error {
+	p.records = append(p.records, record.Clone())
+	return nil
+}
+
+func (p *recordingProcessor) Shutdown(context.Context) error   { return nil }
+func (p *recordingProcessor) ForceFlush(context.Context) error { return nil }
+
+func TestNewLogProcessor(t *testing.T) {
+	e, err := otelregen.NewExtractor(requestMatcher, otelregen.Mapping{"method": "http.request.method"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recorder := &recordingProcessor{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(otelregen.NewLogProcessor(e, recorder)))
+	logger := provider.Logger("test")
+
+	var record log.Record
+	record.SetBody(log.StringValue("GET /health 200"))
+	logger.Emit(context.Background(), record)
+	record.SetBody(log.IntValue(1))
+	logger.Emit(context.Background(), record)
+
+	if len(recorder.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(recorder.records))
+	}
+	var attrs []log.KeyValue
+	recorder.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+		attrs = append(attrs, kv)
+		return true
+	})
+	if expected := []log.KeyValue{log.String("http.request.method", "GET")}; !reflect.DeepEqual(attrs, expected) {
+		t.Errorf("expected %v, got %v", expected, attrs)
+	}
+	if n := recorder.records[1].AttributesLen(); n != 0 {
+		t.Errorf("expected no attributes for non-string body, got %d", n)
+	}
+}
+
+func TestNewSpanProcessor(t *testing.T) {
+	e, err := otelregen.NewExtractor(requestMatcher, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(otelregen.NewSpanProcessor(e)),
+		sdktrace.WithSpanProcessor(recorder),
+	)
+	_, span := provider.Tracer("test").Start(context.Background(), "POST /users")
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	expected := []attribute.KeyValue{attribute.String("method", "POST"), attribute.String("path", "/users")}
+	if actual := spans[0].Attributes(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
@@ -0,0 +1,275 @@
+package regen
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"sort"
+	"sync"
+	"unicode"
+)
+
+// Parse converts a regular expression in the syntax of Go's regexp package into a Regexp, allowing
+// existing patterns to be composed with (or migrated to) the builder.
+//
+// The result matches the same strings as pattern, but its structure may differ: for instance,
+// alternations that share a prefix are factored (abc|abd becomes ab[cd]), case-insensitive
+// character classes are expanded, and ^ and \A are both parsed as LineStart (which is equivalent
+// outside of multi-line mode). Flags are applied to the individual expressions that they affect.
+func Parse(pattern string) (Regexp, error) {
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	return fromSyntax(parsed), nil
+}
+
+// fromSyntax converts a parsed regular expression into a Regexp
+func fromSyntax(re *syntax.Regexp) Regexp {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return noMatch
+	case syntax.OpEmptyMatch:
+		return String("")
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			return String(lowerFolded(re.Rune)).Group().NoCapture().SetFlags(FlagCaseInsensitive)
+		}
+		return String(string(re.Rune))
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return noMatch
+		}
+		return classFromRanges(re.Rune)
+	case syntax.OpAnyCharNotNL:
+		return Any
+	case syntax.OpAnyChar:
+		return Any.Group().NoCapture().SetFlags(FlagMatchNewLine)
+	case syntax.OpBeginLine:
+		return LineStart.Group().NoCapture().SetFlags(FlagMultiLine)
+	case syntax.OpEndLine:
+		return LineEnd.Group().NoCapture().SetFlags(FlagMultiLine)
+	case syntax.OpBeginText:
+		return LineStart
+	case syntax.OpEndText:
+		if re.Flags&syntax.WasDollar != 0 {
+			return LineEnd
+		}
+		return TextEnd
+	case syntax.OpWordBoundary:
+		return ASCIIBoundary
+	case syntax.OpNoWordBoundary:
+		return NotASCIIBoundary
+	case syntax.OpCapture:
+		sub := fromSyntax(re.Sub[0])
+		g := groupedRegexp{re: sub}
+		// Alternations are already grouped, so the group can capture directly
+		if inner, ok := sub.(groupedRegexp); ok && inner.noCapture && inner.setFlags == 0 && inner.unsetFlags == 0 {
+			if m, ok := inner.re.(multiRegexp); ok && m.separator != "" {
+				g = inner
+			}
+		}
+		g.noCapture = false
+		g.name = re.Name
+		g.cache = nil
+		return g
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		sub := fromSyntax(re.Sub[0])
+		if requiresParens(sub, sub.Regexp()) {
+			// a repeated expression is otherwise wrapped in a capturing group, which would renumber the
+			// groups of the pattern
+			sub = sub.Group().NoCapture()
+		}
+		r := repeatedRegexp{re: sub, ungreedy: re.Flags&syntax.NonGreedy != 0}
+		switch re.Op {
+		case syntax.OpPlus:
+			r.min, r.hasMin = 1, true
+		case syntax.OpQuest:
+			r.max, r.hasMax = 1, true
+		case syntax.OpRepeat:
+			r.min, r.hasMin = uint(re.Min), true
+			if re.Max >= 0 {
+				r.max, r.hasMax = uint(re.Max), true
+			}
+		}
+		return r
+	case syntax.OpConcat:
+		subs := make([]Regexp, len(re.Sub))
+		for i, sub := range re.Sub {
+			subs[i] = fromSyntax(sub)
+		}
+		return Sequence(subs...)
+	case syntax.OpAlternate:
+		subs := make([]Regexp, len(re.Sub))
+		for i, sub := range re.Sub {
+			subs[i] = fromSyntax(sub)
+		}
+		return OneOf(subs...).Group().NoCapture()
+	}
+	return Raw(re.String())
+}
+
+// lowerFolded returns the runes as a string, using lower case where it is equivalent under case
+// folding (the parser stores the upper case form)
+func lowerFolded(runes []rune) string {
+	lowered := make([]rune, len(runes))
+	for i, r := range runes {
+		lowered[i] = r
+		lower := unicode.ToLower(r)
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f == lower {
+				lowered[i] = lower
+				break
+			}
+		}
+	}
+	return string(lowered)
+}
+
+// classFromRanges returns a CharClass matching the runes in the (sorted) pairs of inclusive ranges,
+// using predefined classes where possible
+func classFromRanges(ranges []rune) Regexp {
+	negated := false
+	if len(ranges) > 0 && ranges[0] == 0 && ranges[len(ranges)-1] == unicode.MaxRune {
+		negated = true
+		ranges = complementRanges(ranges)
+	}
+	class := positiveClassFromRanges(ranges)
+	if negated {
+		return class.Negate()
+	}
+	return class
+}
+
+func positiveClassFromRanges(ranges []rune) CharClass {
+	if class, ok := knownClasses()[fmt.Sprint(ranges)]; ok {
+		return class
+	}
+	var classes []CharClass
+	// Perl classes are commonly combined with other characters, e.g. [\w.-]
+	for _, letter := range []byte{'w', 's', 'd'} {
+		perlRanges := classRanges(`\` + string(letter))
+		if remaining, ok := subtractRanges(ranges, perlRanges); ok {
+			classes = append(classes, perlCharClass(letter))
+			ranges = remaining
+		}
+	}
+	var chars []rune
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		switch {
+		case lo == hi:
+			chars = append(chars, lo)
+		case lo+1 == hi:
+			chars = append(chars, lo, hi)
+		default:
+			classes = append(classes, CharRange(lo, hi))
+		}
+	}
+	if len(chars) > 0 {
+		classes = append([]CharClass{CharSet(chars...)}, classes...)
+	}
+	if len(classes) == 1 {
+		return classes[0]
+	}
+	return unionCharClassRegexp{charClasses: classes}
+}
+
+var (
+	knownClassesOnce sync.Once
+	knownClassesMap  map[string]CharClass
+)
+
+// knownClasses returns the predefined classes, keyed by their ranges (formatted using fmt.Sprint)
+func knownClasses() map[string]CharClass {
+	knownClassesOnce.Do(func() {
+		knownClassesMap = make(map[string]CharClass)
+		add := func(pattern string, class CharClass) {
+			ranges := classRanges(pattern)
+			if len(ranges) == 0 {
+				// e.g. \p{Zl}, which contains a single character and is parsed as a literal
+				return
+			}
+			key := fmt.Sprint(ranges)
+			if _, ok := knownClassesMap[key]; !ok {
+				knownClassesMap[key] = class
+			}
+		}
+		add(`\d`, Digit)
+		add(`\s`, Whitespace)
+		add(`\w`, WordCharacter)
+		var names []string
+		for name := range asciiClassRanges {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			add(`[[:`+name+`:]]`, ASCIICharClass(name))
+		}
+		names = names[:0]
+		for name := range unicode.Categories {
+			names = append(names, name)
+		}
+		for name := range unicode.Scripts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			add(`\p{`+name+`}`, UnicodeCharClass(name))
+		}
+	})
+	return knownClassesMap
+}
+
+// classRanges returns the ranges matched by a character class pattern, e.g. \d
+func classRanges(pattern string) []rune {
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil || parsed.Op != syntax.OpCharClass {
+		return nil
+	}
+	return parsed.Rune
+}
+
+// complementRanges returns the ranges of runes that are not within ranges
+func complementRanges(ranges []rune) []rune {
+	var result []rune
+	next := rune(0)
+	for i := 0; i < len(ranges); i += 2 {
+		if ranges[i] > next {
+			result = append(result, next, ranges[i]-1)
+		}
+		next = ranges[i+1] + 1
+	}
+	if next <= unicode.MaxRune {
+		result = append(result, next, unicode.MaxRune)
+	}
+	return result
+}
+
+// subtractRanges removes the runes in sub from ranges, provided that ranges contains all of them
+func subtractRanges(ranges, sub []rune) ([]rune, bool) {
+	if len(sub) == 0 {
+		return ranges, false
+	}
+	var result []rune
+	j := 0
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		for j < len(sub) && sub[j] <= hi {
+			if sub[j] < lo || sub[j+1] > hi {
+				return nil, false
+			}
+			if sub[j] > lo {
+				result = append(result, lo, sub[j]-1)
+			}
+			lo = sub[j+1] + 1
+			j += 2
+		}
+		if lo <= hi {
+			result = append(result, lo, hi)
+		}
+	}
+	if j < len(sub) {
+		return nil, false
+	}
+	return result, true
+}
@@ -0,0 +1,124 @@
+package regen_test
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		pattern  string
+		expected string
+		matches  []string
+		rejects  []string
+	}{
+		{
+			pattern:  `^abc$`,
+			expected: `^abc$`,
+			matches:  []string{"abc"},
+			rejects:  []string{"abcd", "ab"},
+		},
+		{
+			pattern:  `(?P<year>\d{4})-(?P<month>\d{2})`,
+			expected: `(?P<year>\d{4})-(?P<month>\d{2})`,
+			matches:  []string{"2020-01"},
+			rejects:  []string{"20-01"},
+		},
+		{
+			pattern:  `(foo|bar)+?`,
+			expected: `(foo|bar)+?`,
+			matches:  []string{"foo", "barfoo"},
+			rejects:  []string{"baz"},
+		},
+		{
+			pattern:  `[\w.-]+@[^\s@]+`,
+			expected: `[-.\w]+@[^@\s]+`,
+			matches:  []string{"a.b-c@example.com"},
+			rejects:  []string{"@example.com", "a b@c"},
+		},
+		{
+			pattern:  `a[^\x00-\x{10FFFF}]`,
+			expected: `a[^\x00-\x{10FFFF}]`,
+			rejects:  []string{"a", "ab"},
+		},
+		{
+			pattern:  `[[:alpha:]]\p{Greek}[^0-9]`,
+			expected: `[[:alpha:]]\p{Greek}\D`,
+			matches:  []string{"aαb"},
+			rejects:  []string{"aα1"},
+		},
+		{
+			pattern:  `(?i)ab`,
+			expected: `(?i:ab)`,
+			matches:  []string{"AB", "aB"},
+			rejects:  []string{"AC"},
+		},
+		{
+			pattern:  `(?s).\bx`,
+			expected: `(?s:.)\bx`,
+			matches:  []string{"\nx"},
+		},
+		{
+			pattern:  `\Ax\z`,
+			expected: `^x\z`,
+			matches:  []string{"x"},
+			rejects:  []string{"x\n"},
+		},
+	} {
+		t.Run(tt.pattern, func(t *testing.T) {
+			re, err := regen.Parse(tt.pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual := re.Regexp(); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+			compiled := regexp.MustCompile(`^(?:` + re.Regexp() + `)$`)
+			for _, s := range tt.matches {
+				if !compiled.MatchString(s) {
+					t.Errorf("expected %q to match", s)
+				}
+			}
+			for _, s := range tt.rejects {
+				if compiled.MatchString(s) {
+					t.Errorf("expected %q not to match", s)
+				}
+			}
+		})
+	}
+}
+
+func TestParse_Groups(t *testing.T) {
+	for _, pattern := range []string{
+		`(?:ab)+(x)`,
+		`(?:\d{2})?(?P<y>y)`,
+		`(?:a+)*(b)`,
+		`(?:a?){2,3}(?P<c>c)`,
+		`(?:(?P<d>d)e)*?(f)`,
+		`(?:)*x`,
+	} {
+		t.Run(pattern, func(t *testing.T) {
+			re, err := regen.Parse(pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			original := regexp.MustCompile(pattern)
+			rendered := regexp.MustCompile(re.Regexp())
+			if rendered.NumSubexp() != original.NumSubexp() {
+				t.Errorf("expected %d groups, got %d in %s", original.NumSubexp(), rendered.NumSubexp(), rendered)
+			}
+			if !reflect.DeepEqual(rendered.SubexpNames(), original.SubexpNames()) {
+				t.Errorf("expected groups %q, got %q in %s", original.SubexpNames(), rendered.SubexpNames(), rendered)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	if _, err := regen.Parse(`a(b`); err == nil {
+		t.Error("expected error")
+	}
+}
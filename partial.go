@@ -0,0 +1,37 @@
+package regen
+
+import "unicode/utf8"
+
+// PartialMatch returns the longest substring of text, text[start:end], that is the beginning of a
+// match of re, e.g. to show where matching stopped when re doesn't match text. If re matches text,
+// the result includes the match. It returns an error if re doesn't compile.
+func PartialMatch(re Regexp, text string) (start, end int, err error) {
+	a, err := newAutomaton(re)
+	if err != nil {
+		return 0, 0, err
+	}
+	runes := alphabet(a)
+	prev := rune(-1)
+	for i := 0; i <= len(text); {
+		state := a.startAfter(prev)
+		j := i
+		if a.completable(state, runes) {
+			for _, r := range text[i:] {
+				if state = a.step(state, r); !a.completable(state, runes) {
+					break
+				}
+				j += utf8.RuneLen(r)
+			}
+		}
+		if j-i > end-start {
+			start, end = i, j
+		}
+		if i == len(text) {
+			break
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		prev = r
+		i += size
+	}
+	return start, end, nil
+}
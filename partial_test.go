@@ -0,0 +1,33 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestPartialMatch(t *testing.T) {
+	date := regen.Sequence(regen.Digit.Repeat().Min(4).Max(4), regen.String("-"), regen.Digit.Repeat().Min(2).Max(2))
+	for _, tt := range []struct {
+		desc    string
+		re      regen.Regexp
+		text    string
+		partial string
+	}{
+		{desc: "stops within the text", re: date, text: "on 2023-1x", partial: "2023-1"},
+		{desc: "ends early", re: date, text: "2023-", partial: "2023-"},
+		{desc: "matches", re: date, text: "date: 2023-01!", partial: "2023-01"},
+		{desc: "no partial match", re: date, text: "abc", partial: ""},
+		{desc: "word boundary", re: regen.Sequence(regen.ASCIIBoundary, regen.String("cat")), text: "concat ca", partial: "ca"},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			start, end, err := regen.PartialMatch(tt.re, tt.text)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if partial := tt.text[start:end]; partial != tt.partial {
+				t.Errorf("expected %q, got %q", tt.partial, partial)
+			}
+		})
+	}
+}
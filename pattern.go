@@ -0,0 +1,207 @@
+package regen
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Pattern wraps a Regexp so that it can be used in configuration, e.g. as a field of a struct that is
+// decoded from JSON, YAML or TOML. It implements encoding.TextMarshaler and encoding.TextUnmarshaler:
+// a Pattern is marshaled as its rendered regular expression, and unmarshaled by parsing the text (see
+// Parse) into an expression that can be composed with others. A Pattern that was unmarshaled is
+// marshaled as the exact text that it was parsed from, so that configuration round-trips unchanged.
+// An expression whose rendering Parse does not accept, such as one with a balancing group (see
+// GroupedRegexp.Balance), cannot be marshaled as text, and MarshalText returns an error.
+//
+// When decoding JSON, the structure of an expression (see ParseJSON) is also accepted in place of the
+// text. The zero Pattern has no expression, and is marshaled as an empty string.
+//
+// The methods that match a Pattern compile its expression on each call, so a pattern that is matched
+// repeatedly should be compiled once, with Compile(p.Expr()).
+type Pattern struct {
+	re Regexp
+	// source is the text that the pattern was parsed from, if any
+	source string
+}
+
+// NewPattern returns a Pattern for re
+func NewPattern(re Regexp) Pattern {
+	return Pattern{re: re}
+}
+
+// ParsePattern parses a regular expression in the syntax of Go's regexp package into a Pattern
+func ParsePattern(text string) (Pattern, error) {
+	var p Pattern
+	err := p.UnmarshalText([]byte(text))
+	return p, err
+}
+
+// Expr returns the expression of the pattern, or nil for the zero Pattern
+func (p Pattern) Expr() Regexp {
+	return p.re
+}
+
+// String returns the regular expression of the pattern
+func (p Pattern) String() string {
+	if p.source != "" || p.re == nil {
+		return p.source
+	}
+	return p.re.Regexp()
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns an error if the text could not be
+// unmarshaled again.
+func (p Pattern) MarshalText() ([]byte, error) {
+	text := p.String()
+	if p.source == "" && p.re != nil {
+		if _, err := Parse(text); err != nil {
+			return nil, fmt.Errorf("regen: cannot marshal %s as text: %v", text, err)
+		}
+	}
+	return []byte(text), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Empty text is unmarshaled as the zero Pattern.
+func (p *Pattern) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*p = Pattern{}
+		return nil
+	}
+	re, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*p = Pattern{re: re, source: string(text)}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting either the text of a regular expression (see
+// UnmarshalText) or the structure of an expression (see ParseJSON)
+func (p *Pattern) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		return p.UnmarshalText([]byte(text))
+	}
+	re, err := ParseJSON(data)
+	if err != nil {
+		return err
+	}
+	*p = Pattern{re: re}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, encoding the structure of the expression (see
+// MarshalBinary), so that a Pattern can be decoded without parsing it again. This is also the encoding
+// used by encoding/gob. The zero Pattern is marshaled as no data.
+func (p Pattern) MarshalBinary() ([]byte, error) {
+	if p.re == nil {
+		return nil, nil
+	}
+	return MarshalBinary(p.re)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (p *Pattern) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		*p = Pattern{}
+		return nil
+	}
+	re, err := UnmarshalBinary(data)
+	if err != nil {
+		return err
+	}
+	*p = Pattern{re: re}
+	return nil
+}
+
+// Value implements driver.Valuer, storing the pattern as its regular expression (see String), so that
+// patterns can be persisted in database columns. The zero Pattern is stored as NULL. Like MarshalText,
+// it returns an error if the pattern could not be scanned again.
+func (p Pattern) Value() (driver.Value, error) {
+	if p.re == nil {
+		return nil, nil
+	}
+	text, err := p.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+// Scan implements sql.Scanner, parsing a regular expression that was stored by Value (see
+// UnmarshalText), so that an invalid pattern is reported when it is loaded. NULL is scanned as the zero
+// Pattern.
+func (p *Pattern) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*p = Pattern{}
+		return nil
+	case string:
+		return p.UnmarshalText([]byte(src))
+	case []byte:
+		return p.UnmarshalText(src)
+	default:
+		return fmt.Errorf("regen: cannot scan a %T into a Pattern", src)
+	}
+}
+
+// Set implements flag.Value, parsing the value of a command line flag (see UnmarshalText), so that an
+// invalid pattern is reported when the flags are parsed, e.g.
+//
+//	var match regen.Pattern
+//	flag.Var(&match, "match", "the lines to select")
+func (p *Pattern) Set(text string) error {
+	return p.UnmarshalText([]byte(text))
+}
+
+// Type returns the name of the type of the value of a flag, so that a Pattern also implements the Value
+// interface of github.com/spf13/pflag
+func (p *Pattern) Type() string {
+	return "pattern"
+}
+
+// ReplaceAllFunc replaces each match of the pattern in input with the result of fn, which is passed the
+// text captured by each named group that participated in the match (see Matcher.ReplaceAllFunc). An
+// error is returned if the expression doesn't compile. The zero Pattern doesn't match anything.
+func (p Pattern) ReplaceAllFunc(input string, fn func(groups map[string]string) string) (string, error) {
+	m, err := p.matcher()
+	if err != nil {
+		return "", err
+	}
+	if m == nil {
+		return input, nil
+	}
+	return m.ReplaceAllFunc(input, fn), nil
+}
+
+// MatchReader returns true if the text read from r contains a match of the pattern (see
+// Matcher.MatchReader). An error is returned if the expression doesn't compile.
+func (p Pattern) MatchReader(r io.RuneReader) (bool, error) {
+	m, err := p.matcher()
+	if m == nil {
+		return false, err
+	}
+	return m.MatchReader(r), nil
+}
+
+// FindAllReader calls fn with each successive match of the pattern in the text read from r (see
+// Matcher.FindAllReader)
+func (p Pattern) FindAllReader(r io.Reader, fn func(MatchResult) error, opts ...ReaderOption) error {
+	m, err := p.matcher()
+	if m == nil {
+		return err
+	}
+	return m.FindAllReader(r, fn, opts...)
+}
+
+// matcher returns a Matcher for the expression of the pattern, or nil for the zero Pattern or if the
+// expression doesn't compile
+func (p Pattern) matcher() (*Matcher, error) {
+	if p.re == nil {
+		return nil, nil
+	}
+	// Patterns come from configuration, so they aren't cached (see CompileCached)
+	return Compile(p.re)
+}
@@ -0,0 +1,262 @@
+package regen_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestPattern_Text(t *testing.T) {
+	type config struct {
+		Match regen.Pattern `json:"match"`
+	}
+	for _, tt := range []struct {
+		description string
+		json        string
+		invalid     bool
+		matches     string
+		rejects     string
+	}{
+		{
+			description: "patterns are parsed from text",
+			json:        `{"match":"^(?P\u003clevel\u003eINFO|WARN)\\b"}`,
+			matches:     "WARN disk is full",
+			rejects:     "DEBUG tick",
+		},
+		{
+			description: "patterns are marshaled as the exact text they were parsed from",
+			json:        `{"match":"[\\w.-]+@[^\\s@]+"}`,
+			matches:     "a.b-c@example.com",
+			rejects:     "@example.com",
+		},
+		{
+			description: "empty text is the zero pattern",
+			json:        `{"match":""}`,
+		},
+		{
+			description: "invalid patterns are rejected",
+			json:        `{"match":"(unclosed"}`,
+			invalid:     true,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			var c config
+			err := json.Unmarshal([]byte(tt.json), &c)
+			if tt.invalid {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.matches != "" && !regexp.MustCompile(c.Match.Expr().Regexp()).MatchString(tt.matches) {
+				t.Errorf("expected %s to match %q", c.Match, tt.matches)
+			}
+			if tt.rejects != "" && regexp.MustCompile(c.Match.Expr().Regexp()).MatchString(tt.rejects) {
+				t.Errorf("expected %s not to match %q", c.Match, tt.rejects)
+			}
+			marshaled, err := json.Marshal(c)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(marshaled) != tt.json {
+				t.Errorf("expected %s, got %s", tt.json, marshaled)
+			}
+		})
+	}
+}
+
+func TestPattern_Builder(t *testing.T) {
+	p := regen.NewPattern(regen.Sequence(regen.String("id-"), regen.Digit.Repeat().Min(1)))
+	text, err := p.MarshalText()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(text) != `id-\d+` {
+		t.Errorf("expected id-\\d+, got %s", text)
+	}
+	parsed, err := regen.ParsePattern(string(text))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.String() != p.String() {
+		t.Errorf("expected %s, got %s", p, parsed)
+	}
+}
+
+func TestPattern_RoundTrip(t *testing.T) {
+	parsed, err := regen.Parse(`(?:ab)+(?P<x>x)`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tt := range []struct {
+		description string
+		re          regen.Regexp
+		invalid     bool
+	}{
+		{
+			description: "parsed expressions keep their groups",
+			re:          parsed,
+		},
+		{
+			description: "balancing groups cannot be marshaled",
+			re:          regen.Sequence(regen.String("(").Group().CaptureAs("open"), regen.String(")").Group().CaptureAs("close").Balance("open")),
+			invalid:     true,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			p := regen.NewPattern(tt.re)
+			text, err := p.MarshalText()
+			if tt.invalid {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", text)
+				}
+				if _, err := p.Value(); err == nil {
+					t.Errorf("expected an error from Value")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var unmarshaled regen.Pattern
+			if err := unmarshaled.UnmarshalText(text); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := regexp.MustCompile(tt.re.Regexp()).SubexpNames()
+			actual := regexp.MustCompile(unmarshaled.Expr().Regexp()).SubexpNames()
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("expected groups %q, got %q", expected, actual)
+			}
+		})
+	}
+}
+
+func TestPattern_JSONStructure(t *testing.T) {
+	var p regen.Pattern
+	if err := json.Unmarshal([]byte(`{"kind":"Repeat","min":1,"children":[{"kind":"PerlClass","class":"d"}]}`), &p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.String() != `\d+` {
+		t.Errorf("expected \\d+, got %s", p)
+	}
+	if err := json.Unmarshal([]byte(`{"kind":"Loop"}`), &p); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestPattern_Gob(t *testing.T) {
+	type config struct {
+		Patterns []regen.Pattern
+	}
+	in := config{Patterns: []regen.Pattern{
+		regen.NewPattern(regen.Digit.Repeat().Min(1).Group().CaptureAs("n")),
+		{},
+	}}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(in); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out config
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Patterns) != 2 {
+		t.Fatalf("expected 2 patterns, got %d", len(out.Patterns))
+	}
+	if !regen.Equal(out.Patterns[0].Expr(), in.Patterns[0].Expr()) {
+		t.Errorf("expected %s, got %s", in.Patterns[0], out.Patterns[0])
+	}
+	if out.Patterns[1].Expr() != nil {
+		t.Errorf("expected the zero Pattern, got %s", out.Patterns[1])
+	}
+}
+
+func TestPattern_SQL(t *testing.T) {
+	p := regen.NewPattern(regen.Sequence(regen.LineStart, regen.Digit.Repeat().Min(1)))
+	value, err := p.Value()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != `^\d+` {
+		t.Errorf("expected %q, got %v", `^\d+`, value)
+	}
+	if value, err := (regen.Pattern{}).Value(); err != nil || value != nil {
+		t.Errorf("expected NULL for the zero Pattern, got %v (%v)", value, err)
+	}
+
+	for _, tt := range []struct {
+		description string
+		src         interface{}
+		expected    string
+		err         string
+	}{
+		{description: "string", src: `^\d+`, expected: `^\d+`},
+		{description: "bytes", src: []byte(`a|b`), expected: `a|b`},
+		{description: "NULL", src: nil, expected: ``},
+		{description: "invalid pattern", src: `a(b`, err: "error parsing regexp: missing closing ): `a(b`"},
+		{description: "unsupported type", src: 42, err: "regen: cannot scan a int into a Pattern"},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			p := regen.NewPattern(regen.Any)
+			err := p.Scan(tt.src)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, p.String())
+			}
+		})
+	}
+}
+
+func TestPattern_Flag(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		args        []string
+		expected    string
+		err         string
+	}{
+		{description: "valid", args: []string{"-match", `^(\w+)=`}, expected: `^(\w+)=`},
+		{description: "unset", args: nil, expected: ``},
+		{description: "invalid", args: []string{"-match", `[a`}, err: "invalid value \"[a\" for flag -match: error parsing regexp: missing closing ]: `[a`"},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags.SetOutput(ioutil.Discard)
+			var match regen.Pattern
+			flags.Var(&match, "match", "the lines to select")
+			err := flags.Parse(tt.args)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if match.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, match.String())
+			}
+			if tt.expected != "" && !regexp.MustCompile(match.Expr().Regexp()).MatchString("key=") {
+				t.Errorf("expected %s to match", match)
+			}
+		})
+	}
+}
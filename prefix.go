@@ -0,0 +1,165 @@
+package regen
+
+import (
+	"strings"
+	"unicode"
+)
+
+// LiteralPrefix returns a literal string that every match of re begins with, without compiling it.
+// The boolean complete is true if the literal string comprises the entirety of the expression, like
+// (*regexp.Regexp).LiteralPrefix. Since a match can only occur where the prefix occurs, this is
+// useful as a fast pre-filter (e.g. using strings.Index) before running the full expression.
+//
+// Raw expressions are parsed (see Parse) to find their prefix, and case-insensitive literals only
+// contribute characters that are unaffected by case folding.
+func LiteralPrefix(re Regexp) (prefix string, complete bool) {
+	a := literalAffix(re, 0, false)
+	return a.s, a.literal
+}
+
+// LiteralSuffix returns a literal string that every match of re ends with. See LiteralPrefix
+func LiteralSuffix(re Regexp) (suffix string, complete bool) {
+	a := literalAffix(re, 0, true)
+	return a.s, a.literal
+}
+
+// affix is the literal prefix or suffix of an expression
+type affix struct {
+	s string
+	// closed is true if every match of the expression is exactly s, so the affix may be extended by
+	// the affixes of adjacent expressions
+	closed bool
+	// literal is true if the expression is exactly the literal s, without any zero-width assertions
+	literal bool
+}
+
+// literalAffix returns the literal prefix (or suffix) of re, where active are the flags set by
+// enclosing groups
+func literalAffix(re Regexp, active Flag, suffix bool) affix {
+	switch re := re.(type) {
+	case literalRegexp:
+		if !re.literal {
+			parsed, err := Parse(re.re)
+			if err != nil {
+				return affix{}
+			}
+			if l, ok := parsed.(literalRegexp); ok && !l.literal {
+				// e.g. a class that matches nothing
+				return affix{}
+			}
+			return literalAffix(parsed, active, suffix)
+		}
+		return foldedAffix([]rune(re.value), active, suffix)
+	case charSetRegexp:
+		if !re.negated && len(re.chars) > 0 && strings.Count(string(re.chars), string(re.chars[0])) == len(re.chars) {
+			return foldedAffix(re.chars[:1], active, suffix)
+		}
+	case anchorRegexp:
+		return affix{closed: true}
+	case annotatedRegexp:
+		return literalAffix(re.re, active, suffix)
+	case groupedRegexp:
+		return literalAffix(re.re, (active|re.setFlags)&^re.unsetFlags, suffix)
+	case multiRegexp:
+		if re.separator != "" {
+			return alternationAffix(re.res, active, suffix)
+		}
+		return sequenceAffix(re.res, active, suffix)
+	case repeatedRegexp:
+		if re.hasMax && re.max == 0 {
+			return affix{closed: true, literal: true}
+		}
+		if re.min == 0 {
+			return affix{}
+		}
+		sub := literalAffix(re.re, active, suffix)
+		if !sub.closed {
+			return sub
+		}
+		exact := re.hasMax && re.min == re.max
+		return affix{s: strings.Repeat(sub.s, int(re.min)), closed: exact, literal: exact && sub.literal}
+	}
+	return affix{}
+}
+
+// foldedAffix returns the affix of a literal. If case folding is enabled, the affix ends at the
+// first character that is affected by it
+func foldedAffix(runes []rune, active Flag, suffix bool) affix {
+	if active&FlagCaseInsensitive == 0 {
+		return affix{s: string(runes), closed: true, literal: true}
+	}
+	n := 0
+	for n < len(runes) {
+		r := runes[n]
+		if suffix {
+			r = runes[len(runes)-1-n]
+		}
+		if unicode.SimpleFold(r) != r {
+			break
+		}
+		n++
+	}
+	if n == len(runes) {
+		return affix{s: string(runes), closed: true, literal: true}
+	}
+	if suffix {
+		return affix{s: string(runes[len(runes)-n:])}
+	}
+	return affix{s: string(runes[:n])}
+}
+
+// sequenceAffix returns the affix of a sequence, combining the affixes of its elements until one is
+// not closed
+func sequenceAffix(res []Regexp, active Flag, suffix bool) affix {
+	result := affix{closed: true, literal: true}
+	for i := range res {
+		re := res[i]
+		if suffix {
+			re = res[len(res)-1-i]
+		}
+		a := literalAffix(re, active, suffix)
+		if suffix {
+			result.s = a.s + result.s
+		} else {
+			result.s += a.s
+		}
+		result.literal = result.literal && a.literal
+		if !a.closed {
+			result.closed, result.literal = false, false
+			break
+		}
+	}
+	return result
+}
+
+// alternationAffix returns the longest affix that is common to every choice
+func alternationAffix(res []Regexp, active Flag, suffix bool) affix {
+	if len(res) == 0 {
+		return affix{}
+	}
+	result := literalAffix(res[0], active, suffix)
+	for _, re := range res[1:] {
+		a := literalAffix(re, active, suffix)
+		if a.s != result.s || !a.closed {
+			result.closed = false
+		}
+		result.literal = result.literal && result.closed && a.literal
+		result.s = commonAffix([]rune(result.s), []rune(a.s), suffix)
+	}
+	return result
+}
+
+// commonAffix returns the longest common prefix (or suffix) of a and b
+func commonAffix(a, b []rune, suffix bool) string {
+	n := 0
+	for n < len(a) && n < len(b) {
+		if suffix && a[len(a)-1-n] != b[len(b)-1-n] || !suffix && a[n] != b[n] {
+			break
+		}
+		n++
+	}
+	if suffix {
+		return string(a[len(a)-n:])
+	}
+	return string(a[:n])
+}
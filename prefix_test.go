@@ -0,0 +1,121 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestLiteralPrefixAndSuffix(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		re       regen.Regexp
+		prefix   string
+		suffix   string
+		complete bool
+	}{
+		{
+			desc:     "string",
+			re:       regen.String("a.b"),
+			prefix:   "a.b",
+			suffix:   "a.b",
+			complete: true,
+		},
+		{
+			desc:   "sequence",
+			re:     regen.Sequence(regen.String("GET /"), regen.WordCharacter.Repeat(), regen.String(".json")),
+			prefix: "GET /",
+			suffix: ".json",
+		},
+		{
+			desc:   "anchors are skipped",
+			re:     regen.Sequence(regen.LineStart, regen.String("id="), regen.Digit, regen.String(";"), regen.LineEnd),
+			prefix: "id=",
+			suffix: ";",
+		},
+		{
+			desc:     "nested sequences and groups",
+			re:       regen.Sequence(regen.String("ab").Group(), regen.Sequence(regen.CharSet('c'), regen.String("d"))),
+			prefix:   "abcd",
+			suffix:   "abcd",
+			complete: true,
+		},
+		{
+			desc:   "alternation",
+			re:     regen.OneOfStrings("foobar", "football"),
+			prefix: "foo",
+			suffix: "",
+		},
+		{
+			desc:   "alternation with common suffix",
+			re:     regen.Sequence(regen.OneOfStrings("img.png", "logo.png"), regen.String("!")),
+			prefix: "",
+			suffix: ".png!",
+		},
+		{
+			desc:     "identical choices",
+			re:       regen.Sequence(regen.OneOfStrings("x", "x"), regen.String("y")),
+			prefix:   "xy",
+			suffix:   "xy",
+			complete: true,
+		},
+		{
+			desc:   "repetitions",
+			re:     regen.Sequence(regen.String("ab").Group().NoCapture().Repeat().Min(2), regen.String("c")),
+			prefix: "abab",
+			suffix: "ababc",
+		},
+		{
+			desc:     "exact repetitions",
+			re:       regen.Sequence(regen.String("-").Repeat().Exactly(3), regen.String(">")),
+			prefix:   "--->",
+			suffix:   "--->",
+			complete: true,
+		},
+		{
+			desc: "optional",
+			re:   regen.Sequence(regen.String("a").Optional(), regen.String("b")),
+			// the match may begin with b
+			prefix: "",
+			suffix: "b",
+		},
+		{
+			desc:   "case-insensitive",
+			re:     regen.String("123abc456").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+			prefix: "123",
+			suffix: "456",
+		},
+		{
+			desc: "case-insensitive unset",
+			re: regen.Sequence(
+				regen.String("a"),
+				regen.String("b").Group().NoCapture().UnsetFlags(regen.FlagCaseInsensitive),
+			).Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+			prefix: "",
+			suffix: "b",
+		},
+		{
+			desc:   "raw",
+			re:     regen.Sequence(regen.Raw(`ab+`), regen.String("c")),
+			prefix: "ab",
+			suffix: "bc",
+		},
+		{
+			desc:   "class",
+			re:     regen.Sequence(regen.String("a"), regen.CharSet('b', 'c'), regen.String("d")),
+			prefix: "a",
+			suffix: "d",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			prefix, complete := regen.LiteralPrefix(tt.re)
+			if prefix != tt.prefix || complete != tt.complete {
+				t.Errorf("expected prefix (%q, %v), got (%q, %v)", tt.prefix, tt.complete, prefix, complete)
+			}
+			suffix, complete := regen.LiteralSuffix(tt.re)
+			if suffix != tt.suffix || complete != tt.complete {
+				t.Errorf("expected suffix (%q, %v), got (%q, %v)", tt.suffix, tt.complete, suffix, complete)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package regen
+
+import (
+	"math/rand"
+	"reflect"
+)
+
+// Generator produces random strings that match an expression (see Generate), for use in property
+// based tests. Its Generate method has the signature of quick.Generator, and Values can be used as
+// the Values of a quick.Config, so that properties are checked against the whole language of the
+// expression rather than hand-picked samples.
+type Generator struct {
+	g *generator
+}
+
+// NewGenerator returns a Generator of strings matching re, returning an error if re doesn't compile
+func NewGenerator(re Regexp, opts ...GenerateOption) (*Generator, error) {
+	g, err := newGenerator(re, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Generator{g: g}, nil
+}
+
+// String returns a random string that the expression matches in its entirety
+func (g *Generator) String(r *rand.Rand) (string, error) {
+	return g.g.generate(r)
+}
+
+// Generate returns a reflect.Value holding a random matching string, in which unbounded repetitions
+// are repeated at most size times beyond their minimum (or the maximum set by WithMaxRepeat, if that
+// is lower). It panics if no matching string could be generated.
+func (g *Generator) Generate(r *rand.Rand, size int) reflect.Value {
+	sized := *g.g
+	if size < sized.config.maxRepeat {
+		sized.config.maxRepeat = size
+	}
+	s, err := sized.generate(r)
+	if err != nil {
+		panic(err)
+	}
+	return reflect.ValueOf(s)
+}
+
+// Values sets each of args to a random matching string, so the property function passed to
+// quick.Check must only take string arguments:
+//
+//	quick.Check(func(email string) bool { ... }, &quick.Config{Values: g.Values})
+//
+// It panics if no matching string could be generated.
+func (g *Generator) Values(args []reflect.Value, r *rand.Rand) {
+	for i := range args {
+		args[i] = g.Generate(r, g.g.config.maxRepeat)
+	}
+}
+
+// QuickValues returns a function for the Values of a quick.Config that sets the i-th argument of the
+// property function to a string generated by the i-th Generator. The property function must take
+// exactly one string argument for each Generator.
+func QuickValues(generators ...*Generator) func([]reflect.Value, *rand.Rand) {
+	return func(args []reflect.Value, r *rand.Rand) {
+		for i, g := range generators {
+			args[i] = g.Generate(r, g.g.config.maxRepeat)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package regen_test
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestGenerator(t *testing.T) {
+	key := regen.Sequence(regen.CharRange('a', 'z'), regen.WordCharacter.Repeat())
+	value := regen.Digit.Repeat().Min(1)
+	keys, err := regen.NewGenerator(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	values, err := regen.NewGenerator(value, regen.WithMaxRepeat(3))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	keyMatcher := regexp.MustCompile(`\A(?:` + key.Regexp() + `)\z`)
+	if err := quick.Check(func(k string) bool {
+		return keyMatcher.MatchString(k)
+	}, &quick.Config{Values: keys.Values}); err != nil {
+		t.Error(err)
+	}
+
+	pair := regexp.MustCompile(`\A(?:` + regen.Sequence(key, regen.String("="), value).Regexp() + `)\z`)
+	if err := quick.Check(func(k, v string) bool {
+		return pair.MatchString(k+"="+v) && len(v) <= 4
+	}, &quick.Config{Values: regen.QuickValues(keys, values)}); err != nil {
+		t.Error(err)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		if s := keys.Generate(r, 2).String(); len(s) > 3 || !keyMatcher.MatchString(s) {
+			t.Errorf("expected a matching string of at most 3 characters, got %q", s)
+		}
+	}
+	if s, err := values.String(r); err != nil || strings.Trim(s, "0123456789") != "" {
+		t.Errorf("expected digits, got %q (%v)", s, err)
+	}
+
+	if _, err := regen.NewGenerator(regen.Raw(`(`)); err == nil {
+		t.Errorf("expected an error for an invalid expression")
+	}
+}
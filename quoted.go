@@ -0,0 +1,105 @@
+package regen
+
+// QuotedOption configures the strings matched by Quoted
+type QuotedOption func(*quotedConfig)
+
+type quotedConfig struct {
+	quotes         []rune
+	escape         rune
+	doubledQuotes  bool
+	newlines       bool
+	escapedNewline bool
+}
+
+// WithQuoteChars sets the characters that can quote the string (" by default). The string must end with
+// the character that it starts with, so with both " and ' as quotes, "it's" and 'a "b"' are matched.
+func WithQuoteChars(quotes ...rune) QuotedOption {
+	return func(c *quotedConfig) {
+		if len(quotes) > 0 {
+			c.quotes = quotes
+		}
+	}
+}
+
+// WithEscapeChar sets the character that escapes the character after it, including the quote (\ by
+// default). If escape is 0, there are no escape sequences.
+func WithEscapeChar(escape rune) QuotedOption {
+	return func(c *quotedConfig) {
+		c.escape = escape
+	}
+}
+
+// WithDoubledQuotes allows the quote to appear within the string by doubling it, as in SQL and CSV:
+//
+//	'it''s'
+func WithDoubledQuotes() QuotedOption {
+	return func(c *quotedConfig) {
+		c.doubledQuotes = true
+	}
+}
+
+// WithNewlines allows strings to span multiple lines. By default, strings can't contain newlines
+func WithNewlines() QuotedOption {
+	return func(c *quotedConfig) {
+		c.newlines = true
+	}
+}
+
+// WithEscapedNewlines allows strings to continue onto the next line if the newline is escaped, as in C
+// and JavaScript, while other newlines are still not allowed
+func WithEscapedNewlines() QuotedOption {
+	return func(c *quotedConfig) {
+		c.escapedNewline = true
+	}
+}
+
+// Quoted returns a Regexp that matches a quoted string, including its quotes, such as "a \"b\"". By
+// default, strings are quoted by ", a backslash escapes the next character, and strings end at the end
+// of the line. The options change this for other syntaxes, e.g. SQL strings:
+//
+//	regen.Quoted(regen.WithQuoteChars('\''), regen.WithEscapeChar(0), regen.WithDoubledQuotes(), regen.WithNewlines())
+//
+// Each character of the string can only be matched in one way, so the pattern doesn't backtrack
+// excessively (see ReDoS) when a string isn't terminated.
+func Quoted(opts ...QuotedOption) Regexp {
+	c := quotedConfig{quotes: []rune{'"'}, escape: '\\'}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	choices := make([]Regexp, len(c.quotes))
+	for i, quote := range c.quotes {
+		choices[i] = c.quoted(quote)
+	}
+	if len(choices) == 1 {
+		return choices[0]
+	}
+	return OneOf(choices...).Group().NoCapture()
+}
+
+// quoted returns a Regexp that matches a string quoted by quote
+func (c quotedConfig) quoted(quote rune) Regexp {
+	excluded := []rune{quote}
+	if c.escape != 0 && c.escape != quote {
+		excluded = append(excluded, c.escape)
+	}
+	if !c.newlines {
+		excluded = append(excluded, '\n')
+	}
+	chars := []Regexp{CharSet(excluded...).Negate()}
+	if c.escape != 0 && c.escape != quote {
+		escaped := Any
+		if c.newlines || c.escapedNewline {
+			escaped = Any.Group().NoCapture().SetFlags(FlagMatchNewLine)
+		}
+		chars = append(chars, Sequence(String(string(c.escape)), escaped))
+	}
+	q := String(string(quote))
+	if c.doubledQuotes || c.escape == quote {
+		chars = append(chars, String(string(quote)+string(quote)))
+	}
+	var char Regexp = chars[0]
+	if len(chars) > 1 {
+		char = OneOf(chars...).Group().NoCapture()
+	}
+	return Sequence(q, char.Repeat(), q)
+}
@@ -0,0 +1,69 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestQuoted(t *testing.T) {
+	tests := []struct {
+		description string
+		opts        []regen.QuotedOption
+		expected    string
+		matches     []string
+		nonMatches  []string
+	}{
+		{
+			description: "double quotes with backslash escapes by default",
+			expected:    `"(?:[^"\\\x{A}]|\\.)*"`,
+			matches:     []string{`""`, `"a \"b\" \\"`},
+			nonMatches:  []string{`"a`, `"a\"`, "\"a\nb\"", "\"a\\\nb\""},
+		},
+		{
+			description: "strings end with the quote that they start with",
+			opts:        []regen.QuotedOption{regen.WithQuoteChars('"', '\'')},
+			matches:     []string{`"it's"`, `'a "b"'`},
+			nonMatches:  []string{`"a'`, `'a"`},
+		},
+		{
+			description: "SQL strings double their quotes and span lines",
+			opts:        []regen.QuotedOption{regen.WithQuoteChars('\''), regen.WithEscapeChar(0), regen.WithDoubledQuotes(), regen.WithNewlines()},
+			expected:    `'(?:[^']|'')*'`,
+			matches:     []string{`'it''s'`, "'a\nb'", `'\'`},
+			nonMatches:  []string{`'it's'`},
+		},
+		{
+			description: "an escape character that is the quote doubles it",
+			opts:        []regen.QuotedOption{regen.WithEscapeChar('"')},
+			matches:     []string{`"a ""b"""`},
+			nonMatches:  []string{`"a "b""`},
+		},
+		{
+			description: "escaped newlines continue the string",
+			opts:        []regen.QuotedOption{regen.WithEscapedNewlines()},
+			matches:     []string{"\"a\\\nb\""},
+			nonMatches:  []string{"\"a\nb\""},
+		},
+	}
+	for _, tt := range tests {
+		re := regen.Quoted(tt.opts...)
+		if tt.expected != "" && re.Regexp() != tt.expected {
+			t.Errorf(`quoted test "%s" failed: got %s, expected %s`, tt.description, re.Regexp(), tt.expected)
+		}
+		if warnings := regen.ReDoS(re, regen.DialectECMAScript); len(warnings) > 0 {
+			t.Errorf(`quoted test "%s" failed: unexpected ReDoS warnings: %v`, tt.description, warnings)
+		}
+		m := regen.MustCompile(regen.Sequence(regen.TextStart, re, regen.TextEnd))
+		for _, s := range tt.matches {
+			if !m.MatchString(s) {
+				t.Errorf(`quoted test "%s" failed: expected %s to match %q`, tt.description, re.Regexp(), s)
+			}
+		}
+		for _, s := range tt.nonMatches {
+			if m.MatchString(s) {
+				t.Errorf(`quoted test "%s" failed: expected %s not to match %q`, tt.description, re.Regexp(), s)
+			}
+		}
+	}
+}
@@ -0,0 +1,474 @@
+package regen
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// railKind is the kind of element in a railroad diagram
+type railKind int
+
+const (
+	// railTerminal is a rounded box containing literal text
+	railTerminal railKind = iota
+	// railNonTerminal is a square box describing a class of text (e.g. a character class or anchor)
+	railNonTerminal
+	railSequence
+	// railChoice branches into each of its children, the first of which is on the main line
+	railChoice
+	// railLoop returns from the end of its child to its start, optionally labelled (e.g. 2-3 times)
+	railLoop
+	// railSkip is an empty path
+	railSkip
+	// railBox is a labelled box around its child, such as a capturing group
+	railBox
+)
+
+type railNode struct {
+	kind     railKind
+	text     string
+	children []*railNode
+}
+
+// railroad converts re into the elements of a railroad diagram
+func railroad(re Regexp) *railNode {
+	r := renderer{dialect: DialectRE2}
+	return railroadNode(&r, re)
+}
+
+func railroadNode(r *renderer, re Regexp) *railNode {
+	switch re := re.(type) {
+	case literalRegexp:
+		if re.literal {
+			if re.value == "" {
+				return &railNode{kind: railSkip}
+			}
+			return &railNode{kind: railTerminal, text: re.value}
+		}
+		return &railNode{kind: railNonTerminal, text: re.re}
+	case anyRegexp:
+		return &railNode{kind: railNonTerminal, text: "any character"}
+	case anchorRegexp:
+		return &railNode{kind: railNonTerminal, text: anchorDescriptions[re.re]}
+	case annotatedRegexp:
+		return &railNode{kind: railBox, text: re.comment, children: []*railNode{railroadNode(r, re.re)}}
+	case multiRegexp:
+		node := &railNode{kind: railSequence}
+		if re.separator != "" {
+			node.kind = railChoice
+		}
+		for _, sub := range re.res {
+			node.children = append(node.children, railroadNode(r, sub))
+		}
+		if len(node.children) == 1 {
+			return node.children[0]
+		}
+		return node
+	case groupedRegexp:
+		flags := r.activeFlags
+		r.activeFlags = flags&^re.unsetFlags | re.setFlags
+		child := railroadNode(r, re.re)
+		r.activeFlags = flags
+		var labels []string
+		switch {
+		case re.balance != "":
+			labels = append(labels, fmt.Sprintf("balance %s-%s", re.name, re.balance))
+		case re.name != "":
+			labels = append(labels, re.name)
+		case !re.noCapture:
+			labels = append(labels, "group")
+		}
+		if re.setFlags != 0 {
+			labels = append(labels, "flags "+r.flags(re.setFlags))
+		}
+		if re.unsetFlags != 0 {
+			labels = append(labels, "unset "+r.flags(re.unsetFlags))
+		}
+		if len(labels) == 0 {
+			return child
+		}
+		return &railNode{kind: railBox, text: strings.Join(labels, ", "), children: []*railNode{child}}
+	case repeatedRegexp:
+		child := railroadNode(r, re.re)
+		if re.hasMax && re.max == 1 {
+			if re.min == 1 {
+				return child
+			}
+			return &railNode{kind: railChoice, children: []*railNode{{kind: railSkip}, child}}
+		}
+		var label string
+		switch {
+		case !re.hasMax && re.min <= 1:
+		case !re.hasMax:
+			label = fmt.Sprintf("%d+ times", re.min)
+		case re.min == re.max:
+			label = fmt.Sprintf("%d times", re.min)
+		default:
+			label = fmt.Sprintf("%d-%d times", re.min, re.max)
+		}
+		if re.ungreedy {
+			label = strings.TrimSpace(label + " lazy")
+		}
+		loop := &railNode{kind: railLoop, text: label, children: []*railNode{child}}
+		if re.min == 0 {
+			return &railNode{kind: railChoice, children: []*railNode{{kind: railSkip}, loop}}
+		}
+		return loop
+	}
+	return &railNode{kind: railNonTerminal, text: r.regexp(re)}
+}
+
+// anchorDescriptions contains the text used to describe each anchor in diagrams
+var anchorDescriptions = map[string]string{
+	`^`:  "start of line",
+	`$`:  "end of line",
+	`\A`: "start of text",
+	`\z`: "end of text",
+	`\b`: "word boundary",
+	`\B`: "not word boundary",
+}
+
+const (
+	railCharWidth   = 8
+	railTextPadding = 10
+	railHalfHeight  = 12
+	railGap         = 10
+	railArc         = 10
+	railVerticalGap = 8
+	railBoxPadding  = 8
+	railLabelHeight = 14
+	railMargin      = 10
+)
+
+// ToRailroadSVG returns an SVG image of a railroad (syntax) diagram for re, in which literal text
+// is shown in rounded boxes, other constructs in square boxes, and capturing groups as labelled
+// dashed boxes. Alternatives, optional elements and repetitions are shown as branches and loops.
+func ToRailroadSVG(re Regexp) string {
+	root := railroad(re)
+	width, up, down := root.measure()
+	totalWidth := width + 2*railMargin + 4*railGap
+	totalHeight := up + down + 2*railMargin
+	y := railMargin + up
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		totalWidth, totalHeight, totalWidth, totalHeight)
+	sb.WriteString(`<style>path,rect,circle{fill:none;stroke:black;stroke-width:1.5}` +
+		`text{font-family:monospace;font-size:13px;text-anchor:middle}` +
+		`text.label{font-size:11px;text-anchor:start}rect.box{stroke-dasharray:4;stroke:gray}</style>`)
+	fmt.Fprintf(&sb, `<circle cx="%d" cy="%d" r="4"/>`, railMargin+4, y)
+	railLine(&sb, railMargin+8, railMargin+2*railGap, y)
+	root.draw(&sb, railMargin+2*railGap, y)
+	end := railMargin + 2*railGap + width
+	railLine(&sb, end, end+2*railGap-8, y)
+	fmt.Fprintf(&sb, `<circle cx="%d" cy="%d" r="4"/>`, end+2*railGap-4, y)
+	sb.WriteString(`</svg>`)
+	return sb.String()
+}
+
+// measure returns the width of the node, and its height above and below the main line
+func (n *railNode) measure() (width, up, down int) {
+	switch n.kind {
+	case railTerminal, railNonTerminal:
+		return railTextWidth(n.text) + 2*railTextPadding, railHalfHeight, railHalfHeight
+	case railSequence:
+		for i, child := range n.children {
+			w, u, d := child.measure()
+			width += w
+			if i > 0 {
+				width += railGap
+			}
+			up, down = maxInt(up, u), maxInt(down, d)
+		}
+		return width, up, down
+	case railChoice:
+		for i, child := range n.children {
+			w, u, d := child.measure()
+			width = maxInt(width, w)
+			if i == 0 {
+				up, down = u, d
+			} else {
+				down += railVerticalGap + u + d
+			}
+		}
+		return width + 4*railArc, up, down
+	case railLoop:
+		w, u, d := n.children[0].measure()
+		down = n.loopDepth(d)
+		if n.text != "" {
+			down += railLabelHeight
+		}
+		return w + 4*railArc, u, down
+	case railBox:
+		w, u, d := n.children[0].measure()
+		width = maxInt(w, railTextWidth(n.text)) + 2*railBoxPadding
+		return width, u + railBoxPadding + railLabelHeight, d + railBoxPadding
+	}
+	return 0, 0, 0
+}
+
+// loopDepth returns the distance between the main line and the return path of a loop
+func (n *railNode) loopDepth(childDown int) int {
+	return maxInt(childDown+railVerticalGap, 2*railArc)
+}
+
+// draw writes the node to sb, with its main line entering at (x, y)
+func (n *railNode) draw(sb *strings.Builder, x, y int) {
+	width, up, down := n.measure()
+	switch n.kind {
+	case railTerminal, railNonTerminal:
+		radius := 0
+		if n.kind == railTerminal {
+			radius = railHalfHeight
+		}
+		fmt.Fprintf(sb, `<rect x="%d" y="%d" width="%d" height="%d" rx="%d"/>`, x, y-railHalfHeight, width, 2*railHalfHeight, radius)
+		fmt.Fprintf(sb, `<text x="%d" y="%d">%s</text>`, x+width/2, y+4, html.EscapeString(n.text))
+	case railSequence:
+		for i, child := range n.children {
+			if i > 0 {
+				railLine(sb, x, x+railGap, y)
+				x += railGap
+			}
+			w, _, _ := child.measure()
+			child.draw(sb, x, y)
+			x += w
+		}
+	case railChoice:
+		inner := width - 4*railArc
+		left, right := x+railArc, x+width-railArc
+		branchY := y
+		for i, child := range n.children {
+			w, u, d := child.measure()
+			if i == 0 {
+				railLine(sb, x, x+2*railArc, y)
+				railLine(sb, x+2*railArc+w, x+width, y)
+			} else {
+				branchY += u
+				fmt.Fprintf(sb, `<path d="M%d %dQ%d %d %d %dL%d %dQ%d %d %d %d"/>`,
+					x, y, left, y, left, y+railArc, left, branchY-railArc, left, branchY, left+railArc, branchY)
+				railLine(sb, x+2*railArc+w, x+2*railArc+inner, branchY)
+				fmt.Fprintf(sb, `<path d="M%d %dQ%d %d %d %dL%d %dQ%d %d %d %d"/>`,
+					right-railArc, branchY, right, branchY, right, branchY-railArc, right, y+railArc, right, y, x+width, y)
+			}
+			child.draw(sb, x+2*railArc, branchY)
+			branchY += d + railVerticalGap
+		}
+	case railLoop:
+		child := n.children[0]
+		w, _, d := child.measure()
+		loopY := y + n.loopDepth(d)
+		start, end := x+2*railArc, x+2*railArc+w
+		railLine(sb, x, start, y)
+		child.draw(sb, start, y)
+		railLine(sb, end, x+width, y)
+		fmt.Fprintf(sb, `<path d="M%d %dQ%d %d %d %dL%d %dQ%d %d %d %dL%d %dQ%d %d %d %dL%d %dQ%d %d %d %d"/>`,
+			end, y, end+railArc, y, end+railArc, y+railArc,
+			end+railArc, loopY-railArc, end+railArc, loopY, end, loopY,
+			start, loopY, start-railArc, loopY, start-railArc, loopY-railArc,
+			start-railArc, y+railArc, start-railArc, y, start, y)
+		if n.text != "" {
+			fmt.Fprintf(sb, `<text x="%d" y="%d">%s</text>`, x+width/2, loopY+railLabelHeight-2, html.EscapeString(n.text))
+		}
+	case railBox:
+		child := n.children[0]
+		w, _, _ := child.measure()
+		fmt.Fprintf(sb, `<rect class="box" x="%d" y="%d" width="%d" height="%d"/>`, x, y-up, width, up+down)
+		fmt.Fprintf(sb, `<text class="label" x="%d" y="%d">%s</text>`, x+railBoxPadding, y-up+railLabelHeight-2, html.EscapeString(n.text))
+		railLine(sb, x, x+railBoxPadding, y)
+		child.draw(sb, x+railBoxPadding, y)
+		railLine(sb, x+railBoxPadding+w, x+width, y)
+	}
+}
+
+func railLine(sb *strings.Builder, x1, x2, y int) {
+	if x1 < x2 {
+		fmt.Fprintf(sb, `<path d="M%d %dH%d"/>`, x1, y, x2)
+	}
+}
+
+func railTextWidth(s string) int {
+	return utf8.RuneCountInString(s) * railCharWidth
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// ToRailroadASCII returns a railroad diagram for re as plain text (see ToRailroadSVG). Literal text is
+// quoted, and other constructs are shown in square brackets.
+func ToRailroadASCII(re Regexp) string {
+	block := railroad(re).ascii()
+	lines := make([]string, len(block.lines))
+	for i, line := range block.lines {
+		if i == block.baseline {
+			line = "o-" + line + "-o"
+		} else {
+			line = "  " + line
+		}
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// textBlock is a rectangular block of text, in which the main line is on the baseline row
+type textBlock struct {
+	lines    []string
+	baseline int
+	width    int
+}
+
+func newTextBlock(lines []string, baseline int) textBlock {
+	width := 0
+	for _, line := range lines {
+		width = maxInt(width, utf8.RuneCountInString(line))
+	}
+	b := textBlock{lines: lines, baseline: baseline}
+	return b.padRight(width)
+}
+
+// padRight extends each line to the given width, continuing the main line
+func (b textBlock) padRight(width int) textBlock {
+	lines := make([]string, len(b.lines))
+	for i, line := range b.lines {
+		fill := " "
+		if i == b.baseline {
+			fill = "-"
+		}
+		lines[i] = line + strings.Repeat(fill, width-utf8.RuneCountInString(line))
+	}
+	return textBlock{lines: lines, baseline: b.baseline, width: width}
+}
+
+// padVertical adds blank lines above and below the block
+func (b textBlock) padVertical(above, below int) textBlock {
+	blank := strings.Repeat(" ", b.width)
+	var lines []string
+	for i := 0; i < above; i++ {
+		lines = append(lines, blank)
+	}
+	lines = append(lines, b.lines...)
+	for i := 0; i < below; i++ {
+		lines = append(lines, blank)
+	}
+	return textBlock{lines: lines, baseline: b.baseline + above, width: b.width}
+}
+
+func (n *railNode) ascii() textBlock {
+	switch n.kind {
+	case railTerminal:
+		return newTextBlock([]string{`"` + n.text + `"`}, 0)
+	case railNonTerminal:
+		return newTextBlock([]string{"[" + n.text + "]"}, 0)
+	case railSequence:
+		blocks := make([]textBlock, len(n.children))
+		up, down := 0, 0
+		for i, child := range n.children {
+			blocks[i] = child.ascii()
+			up = maxInt(up, blocks[i].baseline)
+			down = maxInt(down, len(blocks[i].lines)-blocks[i].baseline-1)
+		}
+		lines := make([]string, up+down+1)
+		for i, block := range blocks {
+			block = block.padVertical(up-block.baseline, down-(len(block.lines)-block.baseline-1))
+			for row := range lines {
+				if i > 0 {
+					if row == up {
+						lines[row] += "--"
+					} else {
+						lines[row] += "  "
+					}
+				}
+				lines[row] += block.lines[row]
+			}
+		}
+		return newTextBlock(lines, up)
+	case railChoice:
+		blocks := make([]textBlock, len(n.children))
+		width := 0
+		for i, child := range n.children {
+			blocks[i] = child.ascii()
+			width = maxInt(width, blocks[i].width)
+		}
+		var lines []string
+		first, last := 0, 0
+		for i, block := range blocks {
+			block = block.padRight(width)
+			for row, line := range block.lines {
+				if row == block.baseline {
+					last = len(lines)
+					if i == 0 {
+						first = last
+					}
+				}
+				lines = append(lines, line)
+			}
+		}
+		for row := range lines {
+			left, right := "   ", "   "
+			switch {
+			case row == first:
+				left, right = "-+-", "-+-"
+			case row > first && row < last && railBranchRow(blocks, row):
+				left, right = " +-", "-+ "
+			case row == last:
+				left, right = " +-", "-+ "
+			case row > first && row < last:
+				left, right = " | ", " | "
+			}
+			lines[row] = left + lines[row] + right
+		}
+		return newTextBlock(lines, first)
+	case railLoop:
+		child := n.children[0].ascii()
+		var lines []string
+		for row, line := range child.lines {
+			switch {
+			case row == child.baseline:
+				line = "-+-" + line + "-+-"
+			case row > child.baseline:
+				line = " | " + line + " | "
+			default:
+				line = "   " + line + "   "
+			}
+			lines = append(lines, line)
+		}
+		lines = append(lines, " +<"+strings.Repeat("-", child.width)+"-+ ")
+		if n.text != "" {
+			lines = append(lines, "   "+n.text)
+		}
+		return newTextBlock(lines, child.baseline)
+	case railBox:
+		child := n.children[0].ascii()
+		child = child.padRight(maxInt(child.width, utf8.RuneCountInString(n.text)+2))
+		top := ".- " + n.text + " "
+		top += strings.Repeat("-", child.width+3-utf8.RuneCountInString(top)) + "."
+		lines := []string{top}
+		for row, line := range child.lines {
+			if row == child.baseline {
+				lines = append(lines, "--"+line+"--")
+			} else {
+				lines = append(lines, ": "+line+" :")
+			}
+		}
+		lines = append(lines, "'"+strings.Repeat("-", child.width+2)+"'")
+		return newTextBlock(lines, child.baseline+1)
+	}
+	return newTextBlock([]string{""}, 0)
+}
+
+// railBranchRow returns true if row (in the stacked blocks of a choice) is the baseline of a block
+func railBranchRow(blocks []textBlock, row int) bool {
+	offset := 0
+	for _, block := range blocks {
+		if row == offset+block.baseline {
+			return true
+		}
+		offset += len(block.lines)
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package regen_test
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestToRailroadASCII(t *testing.T) {
+	tests := []struct {
+		description string
+		re          regen.Regexp
+		expected    string
+	}{
+		{
+			description: "sequence",
+			re:          regen.Sequence(regen.LineStart, regen.String("ab"), regen.Digit),
+			expected:    `o-[start of line]--"ab"--[\d]-o`,
+		},
+		{
+			description: "alternatives",
+			re:          regen.OneOf(regen.String("a"), regen.String("bc")).Group().NoCapture(),
+			expected: strings.Join([]string{
+				`o--+-"a"--+--o`,
+				`   +-"bc"-+`,
+			}, "\n"),
+		},
+		{
+			description: "optional",
+			re:          regen.String("a").Optional(),
+			expected: strings.Join([]string{
+				`o--+-----+--o`,
+				`   +-"a"-+`,
+			}, "\n"),
+		},
+		{
+			description: "counted repetition",
+			re:          regen.Digit.Repeat().Min(2).Max(3),
+			expected: strings.Join([]string{
+				`o--+-[\d]-+----o`,
+				`   +<-----+`,
+				`     2-3 times`,
+			}, "\n"),
+		},
+		{
+			description: "named group",
+			re:          regen.String("abc").Group().CaptureAs("name"),
+			expected: strings.Join([]string{
+				`  .- name -.`,
+				`o---"abc"----o`,
+				`  '--------'`,
+			}, "\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			actual := regen.ToRailroadASCII(tt.re)
+			if actual != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestToRailroadSVG(t *testing.T) {
+	re := regen.Sequence(
+		regen.LineStart,
+		regen.OneOf(regen.String("a<b"), regen.Digit.Repeat().Min(1)).Group().CaptureAs("value"),
+		regen.Annotate(regen.WordCharacter.Optional(), "suffix"),
+	)
+	svg := regen.ToRailroadSVG(re)
+
+	var texts []string
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG %s: %v", svg, err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			inText = token.Name.Local == "text"
+		case xml.CharData:
+			if inText {
+				texts = append(texts, string(token))
+			}
+		case xml.EndElement:
+			inText = false
+		}
+	}
+	expected := []string{"start of line", "value", "a<b", `\d`, "suffix", `\w`}
+	if strings.Join(texts, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected text %q, got %q", expected, texts)
+	}
+}
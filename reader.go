@@ -0,0 +1,126 @@
+package regen
+
+import (
+	"io"
+)
+
+const (
+	// readerChunkSize is the number of bytes read from a reader at a time by FindAllReader
+	readerChunkSize = 32 << 10
+	// defaultMaxMatchLength is the longest match that FindAllReader finds by default, for expressions
+	// whose matches can be arbitrarily long
+	defaultMaxMatchLength = 64 << 10
+)
+
+// ReaderOption configures FindAllReader
+type ReaderOption func(*readerConfig)
+
+type readerConfig struct {
+	maxMatchLength int
+	chunkSize      int
+}
+
+// WithMaxMatchLength sets the length of the longest match, in bytes, that FindAllReader is guaranteed to
+// find. Text is kept in memory until it can't be the start of a match of this length, so this bounds
+// the memory used. The default is the longest match of the expression if that is bounded (see
+// MatchLenBounds), or 64KiB otherwise.
+func WithMaxMatchLength(n int) ReaderOption {
+	return func(c *readerConfig) {
+		c.maxMatchLength = n
+	}
+}
+
+// WithChunkSize sets the number of bytes that FindAllReader reads at a time (32KiB by default)
+func WithChunkSize(n int) ReaderOption {
+	return func(c *readerConfig) {
+		c.chunkSize = n
+	}
+}
+
+// MatchReader returns true if the text read from r contains a match
+func (m *Matcher) MatchReader(r io.RuneReader) bool {
+	return m.compiled.MatchReader(r)
+}
+
+// FindAllReader calls fn with each successive match in the text read from r, which may be arbitrarily
+// long, stopping at the first error. Unlike MatchReader, this reports the text captured by groups.
+//
+// The text is matched in a window that slides over the input, so matches must be no longer than the
+// maximum match length (see WithMaxMatchLength). Anchors and word boundaries are evaluated relative to
+// the window, so expressions that match at the start of the text, such as \A, may also match where the
+// window starts, and should be avoided. The Offset of each MatchResult is the position of the match in
+// the input, and its spans are relative to the start of the match.
+func (m *Matcher) FindAllReader(r io.Reader, fn func(MatchResult) error, opts ...ReaderOption) error {
+	c := readerConfig{chunkSize: readerChunkSize, maxMatchLength: defaultMaxMatchLength}
+	if _, max, bounded := MatchLenBounds(m.re); bounded {
+		c.maxMatchLength = max
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.chunkSize <= 0 {
+		c.chunkSize = readerChunkSize
+	}
+
+	var buf []byte
+	var offset int64
+	// afterMatch is true if a match ended at the start of the buffer, in which case an empty match
+	// there has already been reported (or was skipped, as by FindAllSubmatchIndex)
+	afterMatch := false
+	for eof := false; !eof; {
+		start := len(buf)
+		buf = append(buf, make([]byte, c.chunkSize)...)
+		n, err := io.ReadFull(r, buf[start:])
+		buf = buf[:start+n]
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			eof = true
+		default:
+			return err
+		}
+
+		// matches that start before safe can't be affected by the text that follows the buffer
+		safe := len(buf) - c.maxMatchLength
+		if eof {
+			safe = len(buf) + 1
+		}
+		keep := 0
+		for _, match := range m.compiled.FindAllSubmatchIndex(buf, -1) {
+			if match[0] >= safe {
+				break
+			}
+			if afterMatch && match[1] == 0 {
+				continue
+			}
+			if err := fn(readerMatchResult(m, buf, offset, match)); err != nil {
+				return err
+			}
+			// the text up to the end of the match is discarded, so it starts the buffer
+			keep, afterMatch = match[1], true
+		}
+		if safe > keep {
+			keep, afterMatch = safe, false
+		}
+		if keep > len(buf) {
+			keep = len(buf)
+		}
+		offset += int64(keep)
+		buf = append(buf[:0], buf[keep:]...)
+	}
+	return nil
+}
+
+// readerMatchResult returns the result for a match in buf, which is at offset in the input
+func readerMatchResult(m *Matcher, buf []byte, offset int64, match []int) MatchResult {
+	relative := make([]int, len(match))
+	for i, index := range match {
+		relative[i] = index
+		if index >= 0 {
+			relative[i] -= match[0]
+		}
+	}
+	result := newMatchResult("", m.compiled, string(buf[match[0]:match[1]]), relative)
+	result.Offset = offset + int64(match[0])
+	return result
+}
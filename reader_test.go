@@ -0,0 +1,120 @@
+package regen_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestFindAllReader(t *testing.T) {
+	keyValue := regen.Sequence(
+		regen.WordCharacter.Repeat().Min(1).Group().CaptureAs("key"),
+		regen.String("="),
+		regen.Digit.Repeat().Min(1).Max(5).Group().CaptureAs("value"),
+	)
+	text := strings.Repeat("abc=1 x defgh=12345 ij=", 20) + "7"
+	for _, tt := range []struct {
+		description string
+		re          regen.Regexp
+		opts        []regen.ReaderOption
+	}{
+		{
+			description: "bounded",
+			re:          regen.Sequence(regen.WordCharacter.Repeat().Min(1).Max(10).Group().CaptureAs("key"), regen.String("="), regen.Digit.Repeat().Min(1).Max(5).Group().CaptureAs("value")),
+			opts:        []regen.ReaderOption{regen.WithChunkSize(7)},
+		},
+		{
+			description: "unbounded with a max match length",
+			re:          keyValue,
+			opts:        []regen.ReaderOption{regen.WithChunkSize(5), regen.WithMaxMatchLength(12)},
+		},
+		{
+			description: "empty matches",
+			re:          regen.Digit.Repeat(),
+			opts:        []regen.ReaderOption{regen.WithChunkSize(3), regen.WithMaxMatchLength(5)},
+		},
+		{
+			description: "default options",
+			re:          keyValue,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			m := regen.MustCompile(tt.re)
+			var expected []regen.MatchResult
+			for _, result := range m.FindAllResults(text, -1) {
+				// the spans of results read from a reader are relative to the match
+				result.Offset = int64(result.Span.Start)
+				for i := range result.Captures {
+					result.Captures[i].Span.Start -= result.Span.Start
+					result.Captures[i].Span.End -= result.Span.Start
+				}
+				result.Span = regen.Span{Start: 0, End: result.Span.End - result.Span.Start}
+				expected = append(expected, result)
+			}
+
+			var actual []regen.MatchResult
+			err := m.FindAllReader(iotest.HalfReader(strings.NewReader(text)), func(result regen.MatchResult) error {
+				actual = append(actual, result)
+				return nil
+			}, tt.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("expected %d matches:\n%+v\ngot %d:\n%+v", len(expected), expected, len(actual), actual)
+			}
+		})
+	}
+}
+
+func TestFindAllReader_Errors(t *testing.T) {
+	m := regen.MustCompile(regen.Digit)
+	errStop := errors.New("stop")
+	calls := 0
+	err := m.FindAllReader(strings.NewReader("123"), func(regen.MatchResult) error {
+		calls++
+		return errStop
+	})
+	if err != errStop || calls != 1 {
+		t.Errorf("expected to stop after the first match, got %v after %d calls", err, calls)
+	}
+
+	errRead := errors.New("read")
+	err = m.FindAllReader(io.MultiReader(strings.NewReader("1"), failingReader{errRead}), func(regen.MatchResult) error { return nil })
+	if err != errRead {
+		t.Errorf("expected the read error, got %v", err)
+	}
+}
+
+func TestPattern_Reader(t *testing.T) {
+	p := regen.NewPattern(regen.String("needle").Group().CaptureAs("n"))
+	ok, err := p.MatchReader(strings.NewReader("haystack with a needle"))
+	if err != nil || !ok {
+		t.Errorf("expected a match, got %t, %v", ok, err)
+	}
+	var offsets []int64
+	err = p.FindAllReader(strings.NewReader("needle, needle"), func(result regen.MatchResult) error {
+		offsets = append(offsets, result.Offset)
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(offsets, []int64{0, 8}) {
+		t.Errorf("expected offsets [0 8], got %v, %v", offsets, err)
+	}
+	if ok, err := (regen.Pattern{}).MatchReader(strings.NewReader("x")); ok || err != nil {
+		t.Errorf("expected the zero Pattern not to match, got %t, %v", ok, err)
+	}
+}
+
+// failingReader fails to read with err
+type failingReader struct {
+	err error
+}
+
+func (r failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
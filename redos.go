@@ -0,0 +1,145 @@
+package regen
+
+import "fmt"
+
+// ReDoS returns warnings for constructs in re that can make the engines of the dialect take
+// exponential (or high polynomial) time to match some inputs, allowing regular expression denial of
+// service. It returns nil for dialects whose engines match in linear time, such as DialectRE2.
+//
+// The following rules are checked, where bodies overlap if they match a common non-empty string:
+//
+//	redos-nested-quantifier         an unbounded repetition within another whose body it overlaps,
+//	                                e.g. (a+)+ or (\w+\s?)*
+//	redos-overlapping-alternation   an unbounded repetition of alternatives that overlap, e.g. (a|ab?)*
+//	redos-adjacent-quantifiers      adjacent unbounded repetitions whose bodies overlap, e.g. \d+\d*,
+//	                                which take polynomial time
+//
+// Overlaps are found as described by Equivalent, and the warnings include an example of a common string.
+func ReDoS(re Regexp, d Dialect) []Warning {
+	if !d.backtracking {
+		return nil
+	}
+	var warnings []Warning
+	walkWithFlags(re, 0, func(node Regexp, active Flag) {
+		switch node := node.(type) {
+		case repeatedRegexp:
+			if node.hasMax {
+				return
+			}
+			warnings = append(warnings, nestedQuantifiers(node, active)...)
+			if m, ok := unwrapGroups(node.re).(multiRegexp); ok && m.separator != "" {
+				warnings = append(warnings, overlappingAlternation(node, m, active)...)
+			}
+		case multiRegexp:
+			if node.separator == "" {
+				warnings = append(warnings, adjacentQuantifiers(node, active)...)
+			}
+		}
+	})
+	return warnings
+}
+
+func nestedQuantifiers(outer repeatedRegexp, active Flag) []Warning {
+	var warnings []Warning
+	Walk(outer.re, func(node Regexp) bool {
+		inner, ok := node.(repeatedRegexp)
+		if !ok || inner.hasMax {
+			return true
+		}
+		if example, ok := commonString(inner.re, outer.re, active); ok {
+			warnings = append(warnings, Warning{
+				Rule:    "redos-nested-quantifier",
+				Message: fmt.Sprintf("%s is repeated within %s, and both can match %q, so the number of ways to match grows exponentially", inner.Regexp(), outer.Regexp(), example),
+				Node:    outer,
+			})
+		}
+		return true
+	})
+	return warnings
+}
+
+func overlappingAlternation(r repeatedRegexp, m multiRegexp, active Flag) []Warning {
+	for i := range m.res {
+		for j := i + 1; j < len(m.res); j++ {
+			if example, ok := commonString(m.res[i], m.res[j], active); ok {
+				return []Warning{{
+					Rule:    "redos-overlapping-alternation",
+					Message: fmt.Sprintf("alternatives %d and %d of %s can both match %q, so the number of ways to match grows exponentially", i+1, j+1, r.Regexp(), example),
+					Node:    r,
+				}}
+			}
+		}
+	}
+	return nil
+}
+
+func adjacentQuantifiers(m multiRegexp, active Flag) []Warning {
+	var warnings []Warning
+	for i := 0; i+1 < len(m.res); i++ {
+		first, ok := unwrapGroups(m.res[i]).(repeatedRegexp)
+		if !ok || first.hasMax {
+			continue
+		}
+		second, ok := unwrapGroups(m.res[i+1]).(repeatedRegexp)
+		if !ok || second.hasMax {
+			continue
+		}
+		if example, ok := commonString(first.re, second.re, active); ok {
+			warnings = append(warnings, Warning{
+				Rule:    "redos-adjacent-quantifiers",
+				Message: fmt.Sprintf("adjacent repetitions %s and %s can both match %q, so matching can take polynomial time", first.Regexp(), second.Regexp(), example),
+				Node:    m,
+			})
+		}
+	}
+	return warnings
+}
+
+// commonString returns one of the shortest non-empty strings that a and b both match
+func commonString(a, b Regexp, active Flag) (string, bool) {
+	var automata []*automaton
+	for _, re := range []Regexp{a, b, Any.Repeat().Min(1).Group().NoCapture().SetFlags(FlagMatchNewLine)} {
+		if active != 0 {
+			re = groupedRegexp{re: re, noCapture: true, setFlags: active}
+		}
+		automaton, err := newAutomaton(re)
+		if err != nil {
+			return "", false
+		}
+		automata = append(automata, automaton)
+	}
+	return findString(func(accepted []bool) bool {
+		return accepted[0] && accepted[1] && accepted[2]
+	}, automata...)
+}
+
+// unwrapGroups returns the expression within any groups (without flags) and annotations around re
+func unwrapGroups(re Regexp) Regexp {
+	for {
+		switch node := re.(type) {
+		case groupedRegexp:
+			if node.setFlags != 0 || node.unsetFlags != 0 {
+				return re
+			}
+			re = node.re
+		case annotatedRegexp:
+			re = node.re
+		default:
+			return re
+		}
+	}
+}
+
+// walkWithFlags calls fn with each node of re in pre-order, along with the flags in effect
+func walkWithFlags(re Regexp, active Flag, fn func(node Regexp, active Flag)) {
+	fn(re, active)
+	switch node := re.(type) {
+	case groupedRegexp:
+		active = active&^node.unsetFlags | node.setFlags
+	case CharClass:
+		return
+	}
+	for _, child := range Children(re) {
+		walkWithFlags(child, active, fn)
+	}
+}
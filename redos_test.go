@@ -0,0 +1,92 @@
+package regen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestReDoS(t *testing.T) {
+	tests := []struct {
+		description string
+		re          regen.Regexp
+		dialect     regen.Dialect
+		rules       []string
+		example     string
+	}{
+		{
+			description: "nested quantifiers",
+			re:          regen.String("a").Repeat().Min(1).Group().Repeat().Min(1),
+			dialect:     regen.DialectECMAScript,
+			rules:       []string{"redos-nested-quantifier"},
+			example:     `"a"`,
+		},
+		{
+			description: "nested quantifiers with optional separator",
+			re:          regen.Sequence(regen.WordCharacter.Repeat().Min(1), regen.Whitespace.Repeat().Max(1)).Group().NoCapture().Repeat(),
+			dialect:     regen.DialectDotNet,
+			rules:       []string{"redos-nested-quantifier"},
+		},
+		{
+			description: "nested quantifiers with required separator",
+			re:          regen.Sequence(regen.String("a").Repeat().Min(1), regen.String("b")).Group().NoCapture().Repeat(),
+			dialect:     regen.DialectECMAScript,
+		},
+		{
+			description: "overlapping alternation",
+			re:          regen.OneOf(regen.WordCharacter, regen.Digit).Repeat().Min(1),
+			dialect:     regen.DialectRuby,
+			rules:       []string{"redos-overlapping-alternation"},
+			example:     `"0"`,
+		},
+		{
+			description: "disjoint alternation",
+			re:          regen.OneOf(regen.String("a"), regen.String("b")).Repeat(),
+			dialect:     regen.DialectRuby,
+		},
+		{
+			description: "overlapping under case-insensitive flag",
+			re:          regen.OneOf(regen.String("a"), regen.String("A")).Repeat().Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+			dialect:     regen.DialectRuby,
+			rules:       []string{"redos-overlapping-alternation"},
+		},
+		{
+			description: "adjacent quantifiers",
+			re:          regen.Sequence(regen.Digit.Repeat().Min(1), regen.Digit.Repeat()),
+			dialect:     regen.DialectMySQL,
+			rules:       []string{"redos-adjacent-quantifiers"},
+		},
+		{
+			description: "bounded repetition",
+			re:          regen.String("a").Repeat().Min(1).Group().Repeat().Max(3),
+			dialect:     regen.DialectECMAScript,
+		},
+		{
+			description: "linear-time dialect",
+			re:          regen.String("a").Repeat().Min(1).Group().Repeat().Min(1),
+			dialect:     regen.DialectRE2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			warnings := regen.ReDoS(tt.re, tt.dialect)
+			var rules []string
+			for _, w := range warnings {
+				rules = append(rules, w.Rule)
+			}
+			if len(rules) != len(tt.rules) {
+				t.Fatalf("expected rules %v, got %v", tt.rules, warnings)
+			}
+			for i := range rules {
+				if rules[i] != tt.rules[i] {
+					t.Errorf("expected rules %v, got %v", tt.rules, warnings)
+				}
+			}
+			if tt.example != "" && !strings.Contains(warnings[0].Message, tt.example) {
+				t.Errorf("expected message to contain example %s, got %q", tt.example, warnings[0].Message)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Regexp is a representation of an uncompiled regular expression
@@ -32,7 +34,8 @@ type CharClass interface {
 	Negate() CharClass
 	// IsNegated returns true if Negate has been called an odd number of times, else false
 	IsNegated() bool
-	charSetRegexp() string
+	// charSetRegexp returns the regular expression for the class as it appears within square brackets
+	charSetRegexp(r *renderer) string
 }
 
 // GroupedRegexp is a Regexp that is wrapped in parentheses. It may or may not be a capturing group,
@@ -56,6 +59,12 @@ type GroupedRegexp interface {
 	// another GroupedRegexp B, where B has a flag set, that flag will also apply to A (unless explicitly unset).
 	// Multiple Flags can be passed in by joining them with the bitwise or |
 	UnsetFlags(flags Flag) GroupedRegexp
+	// Balance returns a new GroupedRegexp that is a .NET balancing group (e.g. (?<close-open>...)), which
+	// removes the most recent capture of the group named pop when it matches. If the group is named using
+	// CaptureAs, the text between the popped capture and the current match is captured under that name;
+	// otherwise, nothing is captured (e.g. (?<-open>...)).
+	// Balancing groups are only supported by DialectDotNet.
+	Balance(pop string) GroupedRegexp
 }
 
 // RepeatedRegexp is a Regexp that can be repeated some number of times
@@ -81,41 +90,74 @@ type groupedRegexp struct {
 	setFlags   Flag
 	unsetFlags Flag
 	noCapture  bool
+	balance    string
+	cache      *renderCache
 }
 
 func (g groupedRegexp) Regexp() string {
+	return renderRE2(g)
+}
+
+func (g groupedRegexp) appendTo(sb *strings.Builder, r *renderer) {
+	g.cache.appendTo(sb, r, func() {
+		g.appendOpen(sb, r)
+		flags := r.activeFlags
+		r.activeFlags = flags&^g.unsetFlags | g.setFlags
+		r.appendRegexp(sb, g.re)
+		r.activeFlags = flags
+		sb.WriteByte(')')
+	})
+}
+
+// open returns the opening parenthesis of the group, including its name and flags
+func (g groupedRegexp) open(r *renderer) string {
 	var sb strings.Builder
+	g.appendOpen(&sb, r)
+	return sb.String()
+}
+
+// appendOpen appends the opening parenthesis of the group to sb
+func (g groupedRegexp) appendOpen(sb *strings.Builder, r *renderer) {
 	sb.WriteByte('(')
-	if g.name != "" {
-		sb.WriteString("?P<")
+	if g.balance != "" {
+		if !r.dialect.balancingGroups {
+			r.unsupported("balancing group")
+		}
+		sb.WriteString("?<")
+		sb.WriteString(g.name)
+		sb.WriteByte('-')
+		sb.WriteString(g.balance)
+		sb.WriteByte('>')
+	} else if g.name != "" && r.dialect.supports(FeatureNamedGroups) {
+		sb.WriteString(r.dialect.namedGroupPrefix)
 		sb.WriteString(g.name)
 		sb.WriteByte('>')
 	}
 
-	var flagsb strings.Builder
-	if g.setFlags != 0 || g.unsetFlags != 0 {
-		if g.setFlags != 0 {
-			flagsb.WriteString(g.setFlags.String())
-		}
+	// Dialects without flag groups have flags emulated by the nodes that they affect. There are at
+	// most 4 flags to set and 4 to unset, so the letters fit on the stack.
+	var buf [16]byte
+	flags := buf[:0]
+	if r.dialect.supports(FeatureFlagGroups) {
+		flags = r.appendFlags(flags, g.setFlags)
 		if g.unsetFlags != 0 {
-			flagsb.WriteByte('-')
-			flagsb.WriteString(g.unsetFlags.String())
+			n := len(flags)
+			if flags = r.appendFlags(append(flags, '-'), g.unsetFlags); len(flags) == n+1 {
+				// the dialect's rendering accounts for each of the unset flags
+				flags = flags[:n]
+			}
 		}
 	}
 
-	if g.noCapture {
+	if g.noCapture && g.balance == "" && !(r.dialect.noNonCapturingGroups && len(flags) == 0) {
 		sb.WriteByte('?')
-		sb.WriteString(flagsb.String())
+		sb.Write(flags)
 		sb.WriteByte(':')
-	} else if flagsb.Len() > 0 {
+	} else if len(flags) > 0 {
 		sb.WriteString("(?")
-		sb.WriteString(flagsb.String())
+		sb.Write(flags)
 		sb.WriteString(")")
 	}
-
-	sb.WriteString(g.re.Regexp())
-	sb.WriteByte(')')
-	return sb.String()
 }
 
 func (g groupedRegexp) Group() GroupedRegexp {
@@ -131,33 +173,44 @@ func (g groupedRegexp) Optional() Regexp {
 }
 
 func (g groupedRegexp) Capture() GroupedRegexp {
+	g.cache = new(renderCache)
 	g.noCapture = false
 	g.name = ""
 	return g
 }
 
 func (g groupedRegexp) CaptureAs(name string) GroupedRegexp {
+	g.cache = new(renderCache)
 	g.noCapture = false
 	g.name = name
 	return g
 }
 
 func (g groupedRegexp) NoCapture() GroupedRegexp {
+	g.cache = new(renderCache)
 	g.noCapture = true
 	g.name = ""
 	return g
 }
 
 func (g groupedRegexp) SetFlags(flags Flag) GroupedRegexp {
+	g.cache = new(renderCache)
 	g.setFlags = flags
 	return g
 }
 
 func (g groupedRegexp) UnsetFlags(flags Flag) GroupedRegexp {
+	g.cache = new(renderCache)
 	g.unsetFlags = flags
 	return g
 }
 
+func (g groupedRegexp) Balance(pop string) GroupedRegexp {
+	g.cache = new(renderCache)
+	g.balance = pop
+	return g
+}
+
 type repeatedRegexp struct {
 	re       Regexp
 	min      uint
@@ -165,31 +218,59 @@ type repeatedRegexp struct {
 	max      uint
 	hasMax   bool
 	ungreedy bool
+	cache    *renderCache
 }
 
 func (r repeatedRegexp) Regexp() string {
-	subRe := r.re.Regexp()
-	requiresParens := true
-	if _, ok := r.re.(GroupedRegexp); ok {
-		requiresParens = false
-	}
-	if _, ok := r.re.(CharClass); ok {
-		requiresParens = false
-	}
-	if len(subRe) == 1 {
-		requiresParens = false
+	return renderRE2(r)
+}
+
+func (r repeatedRegexp) appendTo(sb *strings.Builder, rr *renderer) {
+	r.cache.appendTo(sb, rr, func() {
+		// whether the repeated expression needs parentheses depends on its rendering
+		subRe := rr.regexp(r.re)
+		requiresParens := requiresParens(r.re, subRe)
+		if !requiresParens && rr.dialect.codeUnits && outsideBMP(r.re) {
+			// the surrogates of the character are repeated together
+			subRe = "(?:" + subRe + ")"
+		}
+		ungreedy := r.isUngreedy(rr)
+		if r.requiresExpansion(rr) {
+			sb.WriteString(r.expand(rr, subRe, requiresParens, ungreedy))
+			return
+		}
+		if requiresParens {
+			sb.WriteByte('(')
+		}
+		sb.WriteString(subRe)
+		if requiresParens {
+			sb.WriteByte(')')
+		}
+		sb.WriteString(r.quantifier(ungreedy))
+	})
+}
+
+// isUngreedy returns true if the repetition must be rendered with a lazy quantifier, accounting for
+// an emulated FlagUngreedy
+func (r repeatedRegexp) isUngreedy(rr *renderer) bool {
+	ungreedy := r.ungreedy
+	if rr.emulating(FlagUngreedy) {
+		ungreedy = !ungreedy
 	}
-	if len(subRe) == 2 && subRe[0] == '\\' {
-		requiresParens = false
+	if ungreedy && !rr.dialect.supports(FeatureLazyQuantifiers) {
+		rr.unsupported("ungreedy repetition")
 	}
+	return ungreedy
+}
+
+// requiresExpansion returns true if the repetition can only be rendered in the dialect by expanding it
+func (r repeatedRegexp) requiresExpansion(rr *renderer) bool {
+	return (r.min > 1 || r.hasMax && r.max > 1) && !rr.dialect.supports(FeatureCountedRepetition)
+}
+
+// quantifier returns the quantifier that follows the repeated expression, e.g. *, {2,3} or +?
+func (r repeatedRegexp) quantifier(ungreedy bool) string {
 	var sb strings.Builder
-	if requiresParens {
-		sb.WriteByte('(')
-	}
-	sb.WriteString(subRe)
-	if requiresParens {
-		sb.WriteByte(')')
-	}
 	if !r.hasMax {
 		if r.min == 0 {
 			sb.WriteByte('*')
@@ -215,12 +296,52 @@ func (r repeatedRegexp) Regexp() string {
 			sb.WriteByte('}')
 		}
 	}
-	if r.ungreedy {
+	if ungreedy {
 		sb.WriteByte('?')
 	}
 	return sb.String()
 }
 
+// requiresParens returns true if re (rendered as subRe) must be wrapped in parentheses to be repeated
+func requiresParens(re Regexp, subRe string) bool {
+	if a, ok := re.(annotatedRegexp); ok {
+		return requiresParens(a.re, subRe)
+	}
+	if _, ok := re.(GroupedRegexp); ok {
+		return false
+	}
+	if _, ok := re.(CharClass); ok {
+		return false
+	}
+	if _, ok := re.(anyRegexp); ok {
+		return false
+	}
+	if l, ok := re.(literalRegexp); ok && l.literal && utf8.RuneCountInString(l.value) == 1 {
+		return false
+	}
+	if len(subRe) == 1 {
+		return false
+	}
+	if len(subRe) == 2 && subRe[0] == '\\' {
+		return false
+	}
+	return true
+}
+
+// outsideBMP returns true if re is a literal character outside of the Basic Multilingual Plane, which
+// is a pair of surrogates in UTF-16
+func outsideBMP(re Regexp) bool {
+	if a, ok := re.(annotatedRegexp); ok {
+		return outsideBMP(a.re)
+	}
+	l, ok := re.(literalRegexp)
+	if !ok || !l.literal {
+		return false
+	}
+	char, size := utf8.DecodeRuneInString(l.value)
+	return size == len(l.value) && char > 0xFFFF
+}
+
 func (r repeatedRegexp) Group() GroupedRegexp {
 	return groupedRegexp{re: r}
 }
@@ -234,18 +355,21 @@ func (r repeatedRegexp) Optional() Regexp {
 }
 
 func (r repeatedRegexp) Min(min uint) RepeatedRegexp {
+	r.cache = new(renderCache)
 	r.min = min
 	r.hasMin = true
 	return r
 }
 
 func (r repeatedRegexp) Max(max uint) RepeatedRegexp {
+	r.cache = new(renderCache)
 	r.max = max
 	r.hasMax = true
 	return r
 }
 
 func (r repeatedRegexp) Exactly(num uint) RepeatedRegexp {
+	r.cache = new(renderCache)
 	r.min = num
 	r.hasMin = true
 	r.max = num
@@ -254,11 +378,13 @@ func (r repeatedRegexp) Exactly(num uint) RepeatedRegexp {
 }
 
 func (r repeatedRegexp) Greedy() RepeatedRegexp {
+	r.cache = new(renderCache)
 	r.ungreedy = false
 	return r
 }
 
 func (r repeatedRegexp) Ungreedy() RepeatedRegexp {
+	r.cache = new(renderCache)
 	r.ungreedy = true
 	return r
 }
@@ -266,35 +392,44 @@ func (r repeatedRegexp) Ungreedy() RepeatedRegexp {
 type multiRegexp struct {
 	res       []Regexp
 	separator string
+	cache     *renderCache
 }
 
 // OneOf returns a new Regexp that matches any of choices, preferring the choices specified earlier
 func OneOf(choices ...Regexp) Regexp {
 	return groupedRegexp{
 		re: multiRegexp{
-			res:       choices,
+			res:       append([]Regexp(nil), choices...),
 			separator: "|",
+			cache:     new(renderCache),
 		},
+		cache: new(renderCache),
 	}
 }
 
 // Sequence returns a new Regexp that expects each sub-Regexp to appear in order
 func Sequence(subseqs ...Regexp) Regexp {
 	return multiRegexp{
-		res:       subseqs,
+		res:       append([]Regexp(nil), subseqs...),
 		separator: "",
+		cache:     new(renderCache),
 	}
 }
 
 func (m multiRegexp) Regexp() string {
-	var sb strings.Builder
-	for i, re := range m.res {
-		sb.WriteString(re.Regexp())
-		if i < len(m.res)-1 {
-			sb.WriteString(m.separator)
+	return renderRE2(m)
+}
+
+func (m multiRegexp) appendTo(sb *strings.Builder, r *renderer) {
+	m.cache.appendTo(sb, r, func() {
+		for i, re := range m.res {
+			r.appendRegexp(sb, re)
+			if i < len(m.res)-1 {
+				sb.WriteString(m.separator)
+			}
+			r.flush(sb, false)
 		}
-	}
-	return sb.String()
+	})
 }
 
 func (m multiRegexp) Group() GroupedRegexp {
@@ -311,6 +446,9 @@ func (m multiRegexp) Optional() Regexp {
 
 type literalRegexp struct {
 	re string
+	// value is the unescaped string matched by the Regexp, if it was created by String
+	value   string
+	literal bool
 }
 
 // Raw returns a Regexp that represents the literal regular expression string passed in.
@@ -324,13 +462,40 @@ func Raw(s string) Regexp {
 // String returns a Regexp that matches the literal string.
 // Regular expression metacharacters are escaped.
 func String(s string) Regexp {
-	return Raw(regexp.QuoteMeta(s))
+	return literalRegexp{
+		re:      regexp.QuoteMeta(s),
+		value:   s,
+		literal: true,
+	}
 }
 
 func (l literalRegexp) Regexp() string {
+	return renderRE2(l)
+}
+
+func (l literalRegexp) appendTo(sb *strings.Builder, r *renderer) {
+	sb.WriteString(l.render(r))
+}
+
+func (l literalRegexp) render(r *renderer) string {
+	if r.emulating(FlagCaseInsensitive) {
+		if l.literal {
+			return r.foldLiteral(l.value)
+		}
+		if !caseInsensitiveInvariant(l.re) {
+			r.unsupported("case-insensitive raw regular expression " + l.re)
+		}
+	}
+	if !l.literal && r.dialect.noUnicodeEscapes && unicodeEscape.MatchString(l.re) {
+		r.unsupported("raw regular expression " + l.re + " with a Unicode escape")
+	}
 	return l.re
 }
 
+// unicodeEscape matches the escapes of Unicode classes and code points that use braces, other than
+// escaped backslashes
+var unicodeEscape = regexp.MustCompile(`(?:^|[^\\])(?:\\\\)*\\(?:[pP]|[ux]\{)`)
+
 func (l literalRegexp) Group() GroupedRegexp {
 	return groupedRegexp{re: l}
 }
@@ -343,6 +508,86 @@ func (l literalRegexp) Optional() Regexp {
 	return repeatedRegexp{re: l}.Min(0).Max(1)
 }
 
+type anchorRegexp struct {
+	re string
+}
+
+func (a anchorRegexp) Regexp() string {
+	return renderRE2(a)
+}
+
+func (a anchorRegexp) appendTo(sb *strings.Builder, r *renderer) {
+	sb.WriteString(a.render(r))
+}
+
+func (a anchorRegexp) render(r *renderer) string {
+	multiLine := r.activeFlags&FlagMultiLine != 0
+	if r.emulating(FlagMultiLine) && !r.dialect.lineAnchorsOnly && (a.re == `^` || a.re == `$`) {
+		r.unsupported("multi-line anchor " + a.re)
+	}
+	switch {
+	case a.re == `^` && !multiLine && r.dialect.lineAnchorsOnly:
+		return `\A`
+	case a.re == `$` && !multiLine && (r.dialect.lineAnchorsOnly || r.dialect.dollarBeforeFinalNewline):
+		return `\z`
+	case a.re == `\A` && r.dialect.noTextAnchors:
+		return `^`
+	case a.re == `\z` && r.dialect.noTextAnchors:
+		return `$`
+	}
+	if escape, ok := r.dialect.boundaryEscapes[a.re]; ok {
+		if escape == "" {
+			r.unsupported("anchor " + a.re)
+		}
+		return escape
+	}
+	return a.re
+}
+
+func (a anchorRegexp) Group() GroupedRegexp {
+	return groupedRegexp{re: a}
+}
+
+func (a anchorRegexp) Repeat() RepeatedRegexp {
+	return repeatedRegexp{re: a}
+}
+
+func (a anchorRegexp) Optional() Regexp {
+	return repeatedRegexp{re: a}.Min(0).Max(1)
+}
+
+type anyRegexp struct{}
+
+func (a anyRegexp) Regexp() string {
+	return renderRE2(a)
+}
+
+func (a anyRegexp) appendTo(sb *strings.Builder, r *renderer) {
+	sb.WriteString(a.render(r))
+}
+
+func (a anyRegexp) render(r *renderer) string {
+	if r.emulating(FlagMatchNewLine) {
+		return `[\s\S]`
+	}
+	if (r.dialect.dotExcludesLineTerminators || r.dialect.dotMatchesNewLine) && r.activeFlags&FlagMatchNewLine == 0 {
+		return `[^\n]`
+	}
+	return `.`
+}
+
+func (a anyRegexp) Group() GroupedRegexp {
+	return groupedRegexp{re: a}
+}
+
+func (a anyRegexp) Repeat() RepeatedRegexp {
+	return repeatedRegexp{re: a}
+}
+
+func (a anyRegexp) Optional() Regexp {
+	return repeatedRegexp{re: a}.Min(0).Max(1)
+}
+
 type unionCharClassRegexp struct {
 	charClasses []CharClass
 	negated     bool
@@ -403,7 +648,15 @@ func Union(classes ...CharClass) Regexp {
 }
 
 func (u unionCharClassRegexp) Regexp() string {
-	return "[" + u.charSetRegexp() + "]"
+	return renderRE2(u)
+}
+
+func (u unionCharClassRegexp) appendTo(sb *strings.Builder, r *renderer) {
+	sb.WriteString(u.render(r))
+}
+
+func (u unionCharClassRegexp) render(r *renderer) string {
+	return "[" + u.charSetRegexp(r) + "]"
 }
 
 func (u unionCharClassRegexp) Group() GroupedRegexp {
@@ -418,14 +671,19 @@ func (u unionCharClassRegexp) Optional() Regexp {
 	return repeatedRegexp{re: u}.Min(0).Max(1)
 }
 
-func (u unionCharClassRegexp) charSetRegexp() string {
+func (u unionCharClassRegexp) charSetRegexp(r *renderer) string {
 	var sb strings.Builder
 	if u.negated {
 		sb.WriteString("^")
 	}
-	for _, c := range u.charClasses {
-		sb.WriteString(c.charSetRegexp())
+	nested, preceded, followed := r.nested, r.classPreceded, r.classFollowed
+	r.nested = true
+	for i, c := range u.charClasses {
+		r.classPreceded = preceded || i > 0
+		r.classFollowed = followed || i < len(u.charClasses)-1
+		sb.WriteString(c.charSetRegexp(r))
 	}
+	r.nested, r.classPreceded, r.classFollowed = nested, preceded, followed
 	return sb.String()
 }
 
@@ -451,7 +709,15 @@ func CharSet(chars ...rune) CharClass {
 }
 
 func (c charSetRegexp) Regexp() string {
-	return "[" + c.charSetRegexp() + "]"
+	return renderRE2(c)
+}
+
+func (c charSetRegexp) appendTo(sb *strings.Builder, r *renderer) {
+	sb.WriteString(c.render(r))
+}
+
+func (c charSetRegexp) render(r *renderer) string {
+	return "[" + c.charSetRegexp(r) + "]"
 }
 
 func (c charSetRegexp) Group() GroupedRegexp {
@@ -467,19 +733,30 @@ func (c charSetRegexp) Optional() Regexp {
 }
 
 func writeCharSetRune(sb *strings.Builder, r rune) {
-	if r == '\\' || r == '^' {
+	if r == '\\' || r == '^' || r == '[' || r == ']' {
 		sb.WriteByte('\\')
 	}
 	sb.WriteRune(r)
 }
 
-func (c charSetRegexp) charSetRegexp() string {
+func (c charSetRegexp) charSetRegexp(r *renderer) string {
 	var sb strings.Builder
 	if c.negated {
 		sb.WriteString("^")
 	}
-	for _, c := range c.chars {
-		writeCharSetRune(&sb, c)
+	chars := c.chars
+	if r.emulating(FlagCaseInsensitive) {
+		chars = foldRunes(chars)
+	}
+	for i, char := range chars {
+		// A hyphen is only literal at the start or end of the brackets; elsewhere it would form a range.
+		// Within a union, the other members may precede or follow the set.
+		first := i == 0 && !r.classPreceded
+		last := i == len(chars)-1 && !r.classFollowed
+		if char == '-' && !first && !last {
+			sb.WriteByte('\\')
+		}
+		r.writeClassRune(&sb, char)
 	}
 	return sb.String()
 }
@@ -508,7 +785,15 @@ func CharRange(start, end rune) CharClass {
 }
 
 func (c charRangeRegexp) Regexp() string {
-	return "[" + c.charSetRegexp() + "]"
+	return renderRE2(c)
+}
+
+func (c charRangeRegexp) appendTo(sb *strings.Builder, r *renderer) {
+	sb.WriteString(c.render(r))
+}
+
+func (c charRangeRegexp) render(r *renderer) string {
+	return "[" + c.charSetRegexp(r) + "]"
 }
 
 func (c charRangeRegexp) Group() GroupedRegexp {
@@ -523,14 +808,23 @@ func (c charRangeRegexp) Optional() Regexp {
 	return repeatedRegexp{re: c}.Min(0).Max(1)
 }
 
-func (c charRangeRegexp) charSetRegexp() string {
+func (c charRangeRegexp) charSetRegexp(r *renderer) string {
 	var sb strings.Builder
 	if c.negated {
 		sb.WriteString("^")
 	}
-	writeCharSetRune(&sb, c.start)
+	if c.start == '-' && r.classPreceded {
+		// the hyphen would end a range with the character before it
+		sb.WriteByte('\\')
+	}
+	r.writeClassRune(&sb, c.start)
 	sb.WriteByte('-')
-	writeCharSetRune(&sb, c.end)
+	r.writeClassRune(&sb, c.end)
+	if r.emulating(FlagCaseInsensitive) {
+		for _, char := range foldRange(c.start, c.end) {
+			r.writeClassRune(&sb, char)
+		}
+	}
 	return sb.String()
 }
 
@@ -557,7 +851,15 @@ func ASCIICharClass(name string) CharClass {
 }
 
 func (a asciiCharClassRegexp) Regexp() string {
-	return "[" + a.charSetRegexp() + "]"
+	return renderRE2(a)
+}
+
+func (a asciiCharClassRegexp) appendTo(sb *strings.Builder, r *renderer) {
+	sb.WriteString(a.render(r))
+}
+
+func (a asciiCharClassRegexp) render(r *renderer) string {
+	return "[" + a.charSetRegexp(r) + "]"
 }
 
 func (a asciiCharClassRegexp) Group() GroupedRegexp {
@@ -572,7 +874,13 @@ func (a asciiCharClassRegexp) Optional() Regexp {
 	return repeatedRegexp{re: a}.Min(0).Max(1)
 }
 
-func (a asciiCharClassRegexp) charSetRegexp() string {
+func (a asciiCharClassRegexp) charSetRegexp(r *renderer) string {
+	if r.emulating(FlagCaseInsensitive) {
+		return r.expandClass("case-insensitive ASCII character class [:"+a.name+":]", r.foldedClassRanges("[[:"+a.name+":]]"), a.negated)
+	}
+	if !r.dialect.supports(FeatureASCIIClasses) {
+		return r.expandClass("ASCII character class [:"+a.name+":]", asciiClassRanges[a.name], a.negated)
+	}
 	negate := ""
 	if a.negated {
 		negate = "^"
@@ -602,12 +910,26 @@ func UnicodeCharClass(name string) CharClass {
 }
 
 func (u unicodeCharClassRegexp) Regexp() string {
+	return renderRE2(u)
+}
+
+func (u unicodeCharClassRegexp) appendTo(sb *strings.Builder, r *renderer) {
+	sb.WriteString(u.render(r))
+}
+
+func (u unicodeCharClassRegexp) render(r *renderer) string {
+	if !u.native(r) {
+		return "[" + u.charSetRegexp(r) + "]"
+	}
 	prefix := `\p`
 	if u.negated {
 		prefix = `\P`
 	}
 	name := u.name
-	if len(name) > 1 {
+	if _, isScript := unicode.Scripts[name]; isScript {
+		name = r.dialect.unicodeScriptPrefix + name
+	}
+	if len(name) > 1 || r.dialect.unicodeBraces {
 		name = "{" + name + "}"
 	}
 	return prefix + name
@@ -625,8 +947,23 @@ func (u unicodeCharClassRegexp) Optional() Regexp {
 	return repeatedRegexp{re: u}.Min(0).Max(1)
 }
 
-func (u unicodeCharClassRegexp) charSetRegexp() string {
-	return u.Regexp()
+// native returns true if the dialect has an escape sequence for the class
+func (u unicodeCharClassRegexp) native(r *renderer) bool {
+	if r.emulating(FlagCaseInsensitive) {
+		return false
+	}
+	_, isCategory := unicode.Categories[u.name]
+	return r.dialect.supports(FeatureUnicodeClasses) && (isCategory || r.dialect.unicodeScripts)
+}
+
+func (u unicodeCharClassRegexp) charSetRegexp(r *renderer) string {
+	if r.emulating(FlagCaseInsensitive) {
+		return r.expandClass("case-insensitive Unicode class "+u.name, r.foldedClassRanges(`\p{`+u.name+`}`), u.negated)
+	}
+	if !u.native(r) {
+		return r.expandClass("Unicode class "+u.name, r.unicodeClassRanges(u.name), u.negated)
+	}
+	return u.render(r)
 }
 
 func (u unicodeCharClassRegexp) Negate() CharClass {
@@ -650,6 +987,17 @@ func perlCharClass(letter byte) CharClass {
 }
 
 func (p perlCharClassRegexp) Regexp() string {
+	return renderRE2(p)
+}
+
+func (p perlCharClassRegexp) appendTo(sb *strings.Builder, r *renderer) {
+	sb.WriteString(p.render(r))
+}
+
+func (p perlCharClassRegexp) render(r *renderer) string {
+	if !p.native(r) {
+		return "[" + p.charSetRegexp(r) + "]"
+	}
 	b := []byte{'\\', p.letter}
 	if p.negated {
 		b = bytes.ToUpper(b)
@@ -669,8 +1017,19 @@ func (p perlCharClassRegexp) Optional() Regexp {
 	return repeatedRegexp{re: p}.Min(0).Max(1)
 }
 
-func (p perlCharClassRegexp) charSetRegexp() string {
-	return p.Regexp()
+// native returns true if the dialect has an escape sequence matching the same characters as the class
+func (p perlCharClassRegexp) native(r *renderer) bool {
+	return strings.IndexByte(r.dialect.perlClasses, p.letter) >= 0 && !r.emulating(FlagCaseInsensitive)
+}
+
+func (p perlCharClassRegexp) charSetRegexp(r *renderer) string {
+	if r.emulating(FlagCaseInsensitive) {
+		return r.expandClass(`case-insensitive Perl character class \`+string(p.letter), r.foldedClassRanges("["+perlClassRanges[p.letter]+"]"), p.negated)
+	}
+	if !p.native(r) {
+		return r.expandClass(`Perl character class \`+string(p.letter), perlClassRanges[p.letter], p.negated)
+	}
+	return p.render(r)
 }
 
 func (p perlCharClassRegexp) Negate() CharClass {
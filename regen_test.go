@@ -1,9 +1,11 @@
 package regen_test
 
 import (
+	"errors"
 	"fmt"
 	"github.com/aoldershaw/regen"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -18,6 +20,21 @@ func TestRegen(t *testing.T) {
 			re:          regen.OneOf(regen.String("a"), regen.String("bc")),
 			expected:    "(a|bc)",
 		},
+		{
+			description: "OneOfStrings factors common prefixes",
+			re:          regen.OneOfStrings("foo", "fizz", "foobar"),
+			expected:    "f(?:izz|oo(?:bar)?)",
+		},
+		{
+			description: "OneOfStrings merges single runes into a CharSet",
+			re:          regen.OneOfStrings("ab", "abc", "abd", "x", "y"),
+			expected:    "(?:ab[cd]?|[xy])",
+		},
+		{
+			description: "OneOfStrings escapes metacharacters",
+			re:          regen.OneOfStrings("a.b", "a-", "a+", "a/"),
+			expected:    `a(?:\.b|[+\-/])`,
+		},
 		{
 			description: "Raw returns the raw regexp",
 			re:          regen.Raw(`\zhello\z`),
@@ -43,6 +60,26 @@ func TestRegen(t *testing.T) {
 			re:          regen.CharSet('h', 'こ', 'é').Negate(),
 			expected:    `[^hこé]`,
 		},
+		{
+			description: "CharSet escapes hyphens that would otherwise form a range",
+			re:          regen.CharSet('a', '-', 'z', '-'),
+			expected:    `[a\-z-]`,
+		},
+		{
+			description: "CharSet escapes hyphens that would form a range within a union",
+			re:          regen.Union(regen.CharSet('_', '-'), regen.CharRange('a', 'z')),
+			expected:    `[_\-a-z]`,
+		},
+		{
+			description: "CharSet doesn't escape hyphens at the end of a union",
+			re:          regen.Union(regen.CharRange('a', 'z'), regen.CharSet('_', '-')),
+			expected:    `[a-z_-]`,
+		},
+		{
+			description: "CharRange escapes a starting hyphen within a union",
+			re:          regen.Union(regen.CharSet('a'), regen.CharRange('-', '.')),
+			expected:    `[a\--.]`,
+		},
 		{
 			description: "CharSet escapes special characters",
 			re:          regen.CharSet('^', '\\'),
@@ -293,6 +330,152 @@ func TestFlags(t *testing.T) {
 	}
 }
 
+func TestRegexp_Memoized(t *testing.T) {
+	word := regen.WordCharacter.Repeat().Min(1)
+	pair := regen.Sequence(word, regen.String("="), word)
+	list := regen.Sequence(pair, regen.Sequence(regen.String(","), pair).Group().NoCapture().Repeat())
+	if expected, actual := `\w+=\w+(?:,\w+=\w+)*`, list.Regexp(); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	// Modified copies of rendered expressions must be rendered again
+	for _, tt := range []struct {
+		re       regen.Regexp
+		expected string
+	}{
+		{re: word.Max(3), expected: `\w{1,3}`},
+		{re: word.Ungreedy(), expected: `\w+?`},
+		{re: pair.Group().CaptureAs("pair").SetFlags(regen.FlagCaseInsensitive), expected: `(?P<pair>(?i)\w+=\w+)`},
+		{re: regen.Simplify(regen.Sequence(pair, regen.String("x"))), expected: `\w+=\w+x`},
+	} {
+		if actual := tt.re.Regexp(); actual != tt.expected {
+			t.Errorf("expected %s, got %s", tt.expected, actual)
+		}
+	}
+
+	// Changes to the arguments of Sequence and OneOf after it is called don't affect the expression
+	parts := []regen.Regexp{regen.String("a"), regen.String("b")}
+	seq, alt := regen.Sequence(parts...), regen.OneOf(parts...)
+	parts[0] = regen.String("c")
+	if seq.Regexp() != "ab" || alt.Regexp() != "(a|b)" {
+		t.Errorf("expected ab and (a|b), got %s and %s", seq.Regexp(), alt.Regexp())
+	}
+
+	// Rendering is safe for concurrent use
+	shared := regen.OneOf(pair, word.Group().CaptureAs("word"))
+	results := make(chan string)
+	for i := 0; i < 8; i++ {
+		go func() {
+			results <- regen.Sequence(shared, shared).Regexp()
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		if expected, actual := `(\w+=\w+|(?P<word>\w+))(\w+=\w+|(?P<word>\w+))`, <-results; actual != expected {
+			t.Errorf("expected %s, got %s", expected, actual)
+		}
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	// deeply nested expressions are rendered in a single pass
+	re := regen.String("a")
+	for i := 0; i < 1000; i++ {
+		re = regen.Sequence(regen.String("b"), re.Group().NoCapture())
+	}
+	var sb strings.Builder
+	n, err := regen.WriteTo(&sb, re)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := strings.Repeat("b(?:", 1000) + "a" + strings.Repeat(")", 1000)
+	if actual := sb.String(); actual != expected || n != int64(len(expected)) {
+		t.Errorf("expected %d bytes, got %d (%d written)", len(expected), len(actual), n)
+	}
+	if actual := re.Regexp(); actual != expected {
+		t.Errorf("expected Regexp to match WriteTo")
+	}
+}
+
+func TestWriteTo_Streaming(t *testing.T) {
+	words := make([]string, 20000)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%05d", i*7)
+	}
+	re := regen.OneOfStrings(words...)
+	var w chunkWriter
+	n, err := regen.WriteTo(&w, re)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := re.Regexp()
+	if actual := w.String(); actual != expected || n != int64(len(expected)) {
+		t.Errorf("expected %d bytes, got %d (%d written)", len(expected), len(actual), n)
+	}
+	if w.writes < 2 {
+		t.Errorf("expected the expression to be written in chunks, got %d writes", w.writes)
+	}
+
+	if _, err := regen.WriteTo(failingWriter{}, re); err != errWrite {
+		t.Errorf("expected the write error, got %v", err)
+	}
+}
+
+// chunkWriter counts the writes made to it
+type chunkWriter struct {
+	strings.Builder
+	writes int
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Builder.Write(p)
+}
+
+func (w *chunkWriter) WriteString(s string) (int, error) {
+	w.writes++
+	return w.Builder.WriteString(s)
+}
+
+var errWrite = errors.New("write failed")
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestRender_Allocations(t *testing.T) {
+	re := regen.Sequence(
+		regen.String("key").Group().CaptureAs("k").SetFlags(regen.FlagCaseInsensitive).UnsetFlags(regen.FlagMultiLine),
+		regen.String("=").Group().NoCapture().SetFlags(regen.FlagMatchNewLine),
+		regen.Digit.Repeat().Min(1),
+	)
+	// the builder is sized up front, and flags are rendered without intermediate strings
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := regen.DialectRE2.Render(re); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if allocs > 8 {
+		t.Errorf("expected at most 8 allocations, got %v", allocs)
+	}
+}
+
+// namedSubmatches returns the named capture groups of the first match of re in s
+func namedSubmatches(re *regexp.Regexp, s string) (map[string]string, bool) {
+	match := re.FindStringSubmatch(s)
+	if match == nil {
+		return nil, false
+	}
+	groups := make(map[string]string)
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			groups[name] = match[i]
+		}
+	}
+	return groups, true
+}
+
 func Example() {
 	japaneseWord := regen.Union(
 		regen.UnicodeCharClass("Hiragana"),
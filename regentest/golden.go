@@ -0,0 +1,87 @@
+package regentest
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+var update = flag.Bool("regentest.update", false, "update the golden files checked by regentest.Golden")
+
+// Golden asserts that the rendered patterns match those recorded in the golden file at path (a JSON
+// object mapping each name to its rendered pattern), guarding systems that persist or ship the
+// rendered strings against unexpected changes. Run the tests with -regentest.update to write the
+// golden file instead, and commit it alongside the patterns. It returns true if the patterns match.
+func Golden(t testing.TB, path string, patterns map[string]regen.Regexp) bool {
+	t.Helper()
+	rendered := make(map[string]string, len(patterns))
+	for name, re := range patterns {
+		rendered[name] = re.Regexp()
+	}
+	if *update {
+		data, err := json.MarshalIndent(rendered, "", "  ")
+		if err == nil {
+			err = os.MkdirAll(filepath.Dir(path), 0755)
+		}
+		if err == nil {
+			err = ioutil.WriteFile(path, append(data, '\n'), 0644)
+		}
+		if err != nil {
+			t.Errorf("regentest: updating golden file: %v", err)
+			return false
+		}
+		return true
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("regentest: reading golden file (run with -regentest.update to create it): %v", err)
+		return false
+	}
+	var golden map[string]string
+	if err := json.Unmarshal(data, &golden); err != nil {
+		t.Errorf("regentest: parsing golden file %s: %v", path, err)
+		return false
+	}
+	var diffs []string
+	for _, name := range sortedNames(rendered) {
+		want, ok := golden[name]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("added %s: %s", name, rendered[name]))
+		case want != rendered[name]:
+			diffs = append(diffs, fmt.Sprintf("changed %s:\n\t\t- %s\n\t\t+ %s", name, want, rendered[name]))
+		}
+	}
+	var removed []string
+	for name := range golden {
+		if _, ok := rendered[name]; !ok {
+			removed = append(removed, "removed "+name)
+		}
+	}
+	sort.Strings(removed)
+	diffs = append(diffs, removed...)
+	if len(diffs) == 0 {
+		return true
+	}
+	t.Errorf("regentest: rendered patterns differ from golden file %s (run with -regentest.update if this is expected)\n%s", path, indent(diffs))
+	return false
+}
+
+// GoldenRegistry asserts that the rendered patterns of every entry in the registry match those
+// recorded in the golden file at path (see Golden)
+func GoldenRegistry(t testing.TB, path string, r *regen.Registry) bool {
+	t.Helper()
+	patterns := make(map[string]regen.Regexp)
+	for _, entry := range r.Entries() {
+		patterns[entry.Name] = entry.Regexp
+	}
+	return Golden(t, path, patterns)
+}
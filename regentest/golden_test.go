@@ -0,0 +1,64 @@
+package regentest_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+	"github.com/aoldershaw/regen/regentest"
+)
+
+func TestGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "regentest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "testdata", "patterns.golden")
+
+	patterns := map[string]regen.Regexp{
+		"date": date,
+		"word": regen.WordCharacter.Repeat().Min(1),
+		"old":  regen.String("old"),
+	}
+
+	r := &recorder{}
+	if regentest.Golden(r, path, patterns) {
+		t.Errorf("expected a missing golden file to fail")
+	}
+
+	if err := flag.Set("regentest.update", "true"); err != nil {
+		t.Fatal(err)
+	}
+	ok := regentest.Golden(t, path, patterns)
+	flag.Set("regentest.update", "false")
+	if !ok {
+		t.Fatalf("expected the golden file to be written")
+	}
+
+	r = &recorder{}
+	if !regentest.Golden(r, path, patterns) {
+		t.Errorf("expected unchanged patterns to pass, got %v", r.errors)
+	}
+
+	registry := regen.NewRegistry()
+	registry.MustRegister("date", date)
+	registry.MustRegister("word", regen.WordCharacter.Repeat())
+	registry.MustRegister("new", regen.String("new"))
+	r = &recorder{}
+	if regentest.GoldenRegistry(r, path, registry) {
+		t.Fatalf("expected changed patterns to fail")
+	}
+	expected := "regentest: rendered patterns differ from golden file " + path + " (run with -regentest.update if this is expected)\n" +
+		"\tadded new: new\n" +
+		"\tchanged word:\n" +
+		"\t\t- \\w+\n" +
+		"\t\t+ \\w*\n" +
+		"\tremoved old"
+	if len(r.errors) != 1 || r.errors[0] != expected {
+		t.Errorf("expected error:\n%s\ngot:\n%v", expected, r.errors)
+	}
+}
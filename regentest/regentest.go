@@ -0,0 +1,151 @@
+// Package regentest provides assertions for testing expressions built using regen. Failure messages
+// include the rendered pattern and, when an expression doesn't match, highlight where matching
+// stopped.
+//
+// Like regexp.MatchString, the assertions search for a match anywhere within the input; anchor the
+// expression (e.g. using regen.TextStart and regen.TextEnd) to require the whole input to match.
+package regentest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+// Matches asserts that re matches input, returning true if it does
+func Matches(t testing.TB, re regen.Regexp, input string) bool {
+	t.Helper()
+	compiled, ok := compile(t, re)
+	if !ok {
+		return false
+	}
+	if compiled.MatchString(input) {
+		return true
+	}
+	t.Errorf("regentest: expected a match\n%s", noMatchDetails(re, input))
+	return false
+}
+
+// NotMatches asserts that re doesn't match input, returning true if it doesn't
+func NotMatches(t testing.TB, re regen.Regexp, input string) bool {
+	t.Helper()
+	compiled, ok := compile(t, re)
+	if !ok {
+		return false
+	}
+	match := compiled.FindStringSubmatchIndex(input)
+	if match == nil {
+		return true
+	}
+	details := []string{
+		"pattern: " + re.Regexp(),
+		fmt.Sprintf("input:   %q", input),
+		fmt.Sprintf("match:   %q at offset %d", input[match[0]:match[1]], match[0]),
+	}
+	captures := namedCaptures(compiled, input, match)
+	for _, name := range sortedNames(captures) {
+		details = append(details, fmt.Sprintf("  %s: %q", name, captures[name]))
+	}
+	t.Errorf("regentest: expected no match\n%s", indent(details))
+	return false
+}
+
+// CapturesEqual asserts that re matches input, and that the first match captures the expected text
+// in each named group. Named groups that aren't in expected are ignored. It returns true if the
+// assertion holds.
+func CapturesEqual(t testing.TB, re regen.Regexp, input string, expected map[string]string) bool {
+	t.Helper()
+	compiled, ok := compile(t, re)
+	if !ok {
+		return false
+	}
+	match := compiled.FindStringSubmatchIndex(input)
+	if match == nil {
+		t.Errorf("regentest: expected a match\n%s", noMatchDetails(re, input))
+		return false
+	}
+	captures := namedCaptures(compiled, input, match)
+	known := make(map[string]bool)
+	for _, name := range compiled.SubexpNames() {
+		known[name] = name != ""
+	}
+	var diffs []string
+	for _, name := range sortedNames(expected) {
+		got, participated := captures[name]
+		switch {
+		case !known[name]:
+			diffs = append(diffs, fmt.Sprintf("%s: no such group", name))
+		case !participated:
+			diffs = append(diffs, fmt.Sprintf("%s: expected %q, but the group didn't participate in the match", name, expected[name]))
+		case got != expected[name]:
+			diffs = append(diffs, fmt.Sprintf("%s: expected %q, got %q", name, expected[name], got))
+		}
+	}
+	if len(diffs) == 0 {
+		return true
+	}
+	details := append([]string{
+		"pattern: " + re.Regexp(),
+		fmt.Sprintf("input:   %q", input),
+		fmt.Sprintf("match:   %q at offset %d", input[match[0]:match[1]], match[0]),
+	}, diffs...)
+	t.Errorf("regentest: unexpected captures\n%s", indent(details))
+	return false
+}
+
+func compile(t testing.TB, re regen.Regexp) (*regexp.Regexp, bool) {
+	t.Helper()
+	compiled, err := regexp.Compile(re.Regexp())
+	if err != nil {
+		t.Errorf("regentest: invalid pattern %s: %v", re.Regexp(), err)
+		return nil, false
+	}
+	return compiled, true
+}
+
+// noMatchDetails describes where matching stopped
+func noMatchDetails(re regen.Regexp, input string) string {
+	details := []string{
+		"pattern: " + re.Regexp(),
+		fmt.Sprintf("input:   %q", input),
+	}
+	start, end, err := regen.PartialMatch(re, input)
+	switch {
+	case err != nil:
+	case start == end:
+		details = append(details, "stopped: no part of the input starts a match")
+	case end == len(input):
+		details = append(details, fmt.Sprintf("stopped: %q at offset %d, at the end of the input", input[start:end], start))
+	default:
+		details = append(details, fmt.Sprintf("stopped: %q at offset %d, before %q", input[start:end], start, input[end:]))
+	}
+	return indent(details)
+}
+
+// namedCaptures returns the text captured by each named group that participated in the match
+func namedCaptures(compiled *regexp.Regexp, input string, match []int) map[string]string {
+	captures := make(map[string]string)
+	for i, name := range compiled.SubexpNames() {
+		if name != "" && match[2*i] >= 0 {
+			captures[name] = input[match[2*i]:match[2*i+1]]
+		}
+	}
+	return captures
+}
+
+func sortedNames(m map[string]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func indent(lines []string) string {
+	return "\t" + strings.Join(lines, "\n\t")
+}
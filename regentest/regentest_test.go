@@ -0,0 +1,107 @@
+package regentest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+	"github.com/aoldershaw/regen/regentest"
+)
+
+// recorder records the errors reported by an assertion
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+var date = regen.Sequence(
+	regen.Digit.Repeat().Min(4).Max(4).Group().CaptureAs("year"),
+	regen.String("-"),
+	regen.Digit.Repeat().Min(2).Max(2).Group().CaptureAs("month"),
+	regen.Sequence(regen.String("-"), regen.Digit.Repeat().Min(2).Max(2).Group().CaptureAs("day")).Group().NoCapture().Optional(),
+)
+
+func TestAssertions(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		assert   func(t testing.TB) bool
+		expected string
+	}{
+		{
+			desc:   "matches",
+			assert: func(t testing.TB) bool { return regentest.Matches(t, date, "on 2023-01") },
+		},
+		{
+			desc:   "doesn't match",
+			assert: func(t testing.TB) bool { return regentest.Matches(t, date, "on 2023-1x") },
+			expected: "regentest: expected a match\n" +
+				"\tpattern: (?P<year>\\d{4})-(?P<month>\\d{2})(?:-(?P<day>\\d{2}))?\n" +
+				"\tinput:   \"on 2023-1x\"\n" +
+				"\tstopped: \"2023-1\" at offset 3, before \"x\"",
+		},
+		{
+			desc:   "not matches",
+			assert: func(t testing.TB) bool { return regentest.NotMatches(t, date, "23-01") },
+		},
+		{
+			desc:   "unexpectedly matches",
+			assert: func(t testing.TB) bool { return regentest.NotMatches(t, date, "v2023-01") },
+			expected: "regentest: expected no match\n" +
+				"\tpattern: (?P<year>\\d{4})-(?P<month>\\d{2})(?:-(?P<day>\\d{2}))?\n" +
+				"\tinput:   \"v2023-01\"\n" +
+				"\tmatch:   \"2023-01\" at offset 1\n" +
+				"\t  month: \"01\"\n" +
+				"\t  year: \"2023\"",
+		},
+		{
+			desc: "captures equal",
+			assert: func(t testing.TB) bool {
+				return regentest.CapturesEqual(t, date, "2023-01-15", map[string]string{"year": "2023", "day": "15"})
+			},
+		},
+		{
+			desc: "captures differ",
+			assert: func(t testing.TB) bool {
+				return regentest.CapturesEqual(t, date, "2023-01", map[string]string{"year": "2024", "day": "15", "hour": "12"})
+			},
+			expected: "regentest: unexpected captures\n" +
+				"\tpattern: (?P<year>\\d{4})-(?P<month>\\d{2})(?:-(?P<day>\\d{2}))?\n" +
+				"\tinput:   \"2023-01\"\n" +
+				"\tmatch:   \"2023-01\" at offset 0\n" +
+				"\tday: expected \"15\", but the group didn't participate in the match\n" +
+				"\thour: no such group\n" +
+				"\tyear: expected \"2024\", got \"2023\"",
+		},
+		{
+			desc: "captures without a match",
+			assert: func(t testing.TB) bool {
+				return regentest.CapturesEqual(t, date, "nothing", map[string]string{"year": "2023"})
+			},
+			expected: "regentest: expected a match\n" +
+				"\tpattern: (?P<year>\\d{4})-(?P<month>\\d{2})(?:-(?P<day>\\d{2}))?\n" +
+				"\tinput:   \"nothing\"\n" +
+				"\tstopped: no part of the input starts a match",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := &recorder{}
+			ok := tt.assert(r)
+			if ok != (tt.expected == "") {
+				t.Errorf("expected the assertion to return %v, got %v", tt.expected == "", ok)
+			}
+			var got string
+			if len(r.errors) > 0 {
+				got = r.errors[0]
+			}
+			if got != tt.expected {
+				t.Errorf("expected error:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
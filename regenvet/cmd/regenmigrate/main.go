@@ -0,0 +1,12 @@
+// Command regenmigrate suggests regen builder code for regexp.MustCompile calls with constant
+// patterns. Run it with -fix to apply the suggestions. See regenvet.MigrateAnalyzer for details.
+package main
+
+import (
+	"github.com/aoldershaw/regen/regenvet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(regenvet.MigrateAnalyzer)
+}
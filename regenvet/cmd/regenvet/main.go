@@ -0,0 +1,12 @@
+// Command regenvet checks that package-level regen expressions compile under RE2 and their target
+// dialects. See the regenvet package for details.
+package main
+
+import (
+	"github.com/aoldershaw/regen/regenvet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(regenvet.Analyzer)
+}
@@ -0,0 +1,15 @@
+module github.com/aoldershaw/regen/regenvet
+
+go 1.23.0
+
+require (
+	github.com/aoldershaw/regen v0.0.0
+	golang.org/x/tools v0.31.0
+)
+
+require (
+	golang.org/x/mod v0.24.0 // indirect
+	golang.org/x/sync v0.12.0 // indirect
+)
+
+replace github.com/aoldershaw/regen => ../
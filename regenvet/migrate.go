@@ -0,0 +1,140 @@
+package regenvet
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+
+	"github.com/aoldershaw/regen"
+	"golang.org/x/tools/go/analysis"
+)
+
+// MigrateAnalyzer finds calls to regexp.MustCompile with a constant pattern, and suggests replacing
+// the pattern with equivalent regen builder code (see regen.GoCode), e.g.
+//
+//	regexp.MustCompile(`^v\d+$`)
+//
+// becomes
+//
+//	regexp.MustCompile(regen.Sequence(regen.LineStart, ...).Regexp())
+//
+// so that the type of the result doesn't change, and each call can be migrated separately. A fix is
+// only suggested if the rendered expression parses to the same syntax tree as the original pattern,
+// and has the same groups, so that callers using submatch indices or SubexpNames are unaffected.
+// Files that import regen under a different name are skipped.
+var MigrateAnalyzer = &analysis.Analyzer{
+	Name: "regenmigrate",
+	Doc:  "suggest regen builder code for regexp.MustCompile calls with constant patterns",
+	Run:  runMigrate,
+}
+
+func runMigrate(pass *analysis.Pass) (interface{}, error) {
+	for _, file := range pass.Files {
+		name, imported := regenImportName(file)
+		if name != "regen" {
+			continue
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 1 {
+				return true
+			}
+			fun, ok := pass.TypesInfo.Uses[identOf(call.Fun)].(*types.Func)
+			if !ok || fun.Pkg() == nil || fun.Pkg().Path() != "regexp" || fun.Name() != "MustCompile" {
+				return true
+			}
+			tv, ok := pass.TypesInfo.Types[call.Args[0]]
+			if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+				return true
+			}
+			pattern := constant.StringVal(tv.Value)
+			re, err := regen.Parse(pattern)
+			if err != nil || !equivalent(pattern, re.Regexp()) || !sameGroups(pattern, re.Regexp()) {
+				return true
+			}
+			edits := []analysis.TextEdit{{
+				Pos:     call.Args[0].Pos(),
+				End:     call.Args[0].End(),
+				NewText: []byte(regen.GoCode(re) + ".Regexp()"),
+			}}
+			if !imported {
+				edits = append(edits, importEdit(file))
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:     call.Pos(),
+				End:     call.End(),
+				Message: fmt.Sprintf("regexp.MustCompile(%s) can be built using regen", strconv.Quote(pattern)),
+				SuggestedFixes: []analysis.SuggestedFix{{
+					Message:   "Replace the pattern with regen builder code",
+					TextEdits: edits,
+				}},
+			})
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// regenImportName returns the name that file imports regen as, and whether it is imported. The name
+// is regen if it isn't imported.
+func regenImportName(file *ast.File) (string, bool) {
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || path != regenPath {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name, true
+		}
+		return "regen", true
+	}
+	return "regen", false
+}
+
+// importEdit returns an edit that adds an import of regen to file
+func importEdit(file *ast.File) analysis.TextEdit {
+	line := strconv.Quote(regenPath)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Rparen.IsValid() {
+			return analysis.TextEdit{Pos: gen.Rparen, End: gen.Rparen, NewText: []byte("\t" + line + "\n")}
+		}
+		return analysis.TextEdit{Pos: gen.End(), End: gen.End(), NewText: []byte("\nimport " + line)}
+	}
+	return analysis.TextEdit{Pos: file.Name.End(), End: file.Name.End(), NewText: []byte("\n\nimport " + line)}
+}
+
+// equivalent returns true if the patterns parse to the same syntax tree
+func equivalent(a, b string) bool {
+	parsedA, err := syntax.Parse(a, syntax.Perl)
+	if err != nil {
+		return false
+	}
+	parsedB, err := syntax.Parse(b, syntax.Perl)
+	if err != nil {
+		return false
+	}
+	return parsedA.Simplify().Equal(parsedB.Simplify())
+}
+
+// sameGroups returns true if the patterns have the same number of groups, with the same names
+func sameGroups(a, b string) bool {
+	compiledA, err := regexp.Compile(a)
+	if err != nil {
+		return false
+	}
+	compiledB, err := regexp.Compile(b)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(compiledA.SubexpNames(), compiledB.SubexpNames())
+}
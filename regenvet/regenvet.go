@@ -0,0 +1,403 @@
+// Package regenvet provides a go/analysis Analyzer that checks package-level regen expressions. It
+// evaluates the builder calls that initialize each package-level variable, renders the result, and
+// reports expressions that don't compile under RE2 or can't be rendered in their target dialects.
+// Diagnostics are reported at the innermost builder call that fails, e.g. a Raw call with an invalid
+// pattern.
+//
+// Target dialects are declared using a directive in the variable's doc comment:
+//
+//	//regen:dialects ECMAScript Ruby
+//	var Username = regen.Sequence(...)
+//
+// or for every expression using the -dialects flag. Dialects are named after their regen variables,
+// without the Dialect prefix (e.g. DotNet for regen.DialectDotNet).
+//
+// Only calls to the regen package (and its methods) with constant arguments, along with references to
+// other package-level variables initialized in the same way, can be evaluated. Other expressions are
+// skipped.
+package regenvet
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/aoldershaw/regen"
+	"golang.org/x/tools/go/analysis"
+)
+
+const regenPath = "github.com/aoldershaw/regen"
+
+// Analyzer checks that package-level regen expressions compile under RE2, and can be rendered in their
+// declared target dialects
+var Analyzer = &analysis.Analyzer{
+	Name: "regenvet",
+	Doc:  "check that package-level regen expressions compile under RE2 and their target dialects",
+	Run:  run,
+}
+
+var dialectsFlag string
+
+func init() {
+	Analyzer.Flags.StringVar(&dialectsFlag, "dialects", "", "comma-separated dialects that every expression must be rendered in, e.g. ECMAScript,Ruby")
+}
+
+// dialects are the dialects that can be declared as targets
+var dialects = map[string]regen.Dialect{
+	"RE2":        regen.DialectRE2,
+	"DotNet":     regen.DialectDotNet,
+	"Ruby":       regen.DialectRuby,
+	"ECMAScript": regen.DialectECMAScript,
+	"PCRE":       regen.DialectPCRE,
+	"Hyperscan":  regen.DialectHyperscan,
+	"MySQL":      regen.DialectMySQL,
+	"PostgreSQL": regen.DialectPostgreSQL,
+	"SQLite":     regen.DialectSQLite,
+}
+
+// funcs are the functions of the regen package that can be evaluated
+var funcs = map[string]reflect.Value{
+	"String":               reflect.ValueOf(regen.String),
+	"Raw":                  reflect.ValueOf(regen.Raw),
+	"Sequence":             reflect.ValueOf(regen.Sequence),
+	"OneOf":                reflect.ValueOf(regen.OneOf),
+	"OneOfStrings":         reflect.ValueOf(regen.OneOfStrings),
+	"Union":                reflect.ValueOf(regen.Union),
+	"CharSet":              reflect.ValueOf(regen.CharSet),
+	"CharRange":            reflect.ValueOf(regen.CharRange),
+	"ASCIICharClass":       reflect.ValueOf(regen.ASCIICharClass),
+	"UnicodeCharClass":     reflect.ValueOf(regen.UnicodeCharClass),
+	"Annotate":             reflect.ValueOf(regen.Annotate),
+	"BalancedUpTo":         reflect.ValueOf(regen.BalancedUpTo),
+	"NotFollowedByLiteral": reflect.ValueOf(regen.NotFollowedByLiteral),
+	"FromLike":             reflect.ValueOf(regen.FromLike),
+	"Simplify":             reflect.ValueOf(regen.Simplify),
+	"Canonicalize":         reflect.ValueOf(regen.Canonicalize),
+}
+
+// vars are the variables of the regen package that can be evaluated
+var vars = map[string]reflect.Value{
+	"Any":              reflect.ValueOf(&regen.Any).Elem(),
+	"Digit":            reflect.ValueOf(&regen.Digit).Elem(),
+	"Whitespace":       reflect.ValueOf(&regen.Whitespace).Elem(),
+	"WordCharacter":    reflect.ValueOf(&regen.WordCharacter).Elem(),
+	"HexDigit":         reflect.ValueOf(&regen.HexDigit).Elem(),
+	"LineStart":        reflect.ValueOf(&regen.LineStart).Elem(),
+	"LineEnd":          reflect.ValueOf(&regen.LineEnd).Elem(),
+	"TextStart":        reflect.ValueOf(&regen.TextStart).Elem(),
+	"TextEnd":          reflect.ValueOf(&regen.TextEnd).Elem(),
+	"ASCIIBoundary":    reflect.ValueOf(&regen.ASCIIBoundary).Elem(),
+	"NotASCIIBoundary": reflect.ValueOf(&regen.NotASCIIBoundary).Elem(),
+}
+
+var regexpType = reflect.TypeOf((*regen.Regexp)(nil)).Elem()
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	global, err := parseDialects(dialectsFlag)
+	if err != nil {
+		return nil, err
+	}
+	e := &evaluator{
+		pass:       pass,
+		inits:      make(map[*types.Var]ast.Expr),
+		values:     make(map[*types.Var]*node),
+		evaluating: make(map[*types.Var]bool),
+	}
+	type target struct {
+		obj      *types.Var
+		dialects []string
+		doc      *ast.CommentGroup
+	}
+	var targets []target
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				spec := spec.(*ast.ValueSpec)
+				if len(spec.Values) != len(spec.Names) {
+					continue
+				}
+				doc := spec.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				for i, name := range spec.Names {
+					obj, ok := pass.TypesInfo.Defs[name].(*types.Var)
+					if !ok {
+						continue
+					}
+					e.inits[obj] = spec.Values[i]
+					targets = append(targets, target{obj: obj, doc: doc})
+				}
+			}
+		}
+	}
+
+	for _, t := range targets {
+		n := e.variable(t.obj)
+		if n == nil {
+			continue
+		}
+		names, err := directiveDialects(t.doc)
+		if err != nil {
+			pass.Reportf(t.doc.Pos(), "%v", err)
+		}
+		if problem := culprit(n, compileRE2); problem != nil {
+			pass.Reportf(problem.expr.Pos(), "%s does not compile: %v", t.obj.Name(), compileRE2(problem.value))
+			continue
+		}
+		for _, name := range append(append([]string{}, global...), names...) {
+			d := dialects[name]
+			render := func(re regen.Regexp) error {
+				_, err := d.Render(re)
+				return err
+			}
+			if problem := culprit(n, render); problem != nil {
+				pass.Reportf(problem.expr.Pos(), "%s can't be rendered in %s: %v", t.obj.Name(), name, render(problem.value))
+			}
+		}
+	}
+	return nil, nil
+}
+
+func compileRE2(re regen.Regexp) error {
+	_, err := regexp.Compile(re.Regexp())
+	return err
+}
+
+// directiveDialects returns the dialects declared by a //regen:dialects directive in doc
+func directiveDialects(doc *ast.CommentGroup) ([]string, error) {
+	if doc == nil {
+		return nil, nil
+	}
+	for _, c := range doc.List {
+		if rest := strings.TrimPrefix(c.Text, "//regen:dialects"); rest != c.Text {
+			return parseDialects(rest)
+		}
+	}
+	return nil, nil
+}
+
+// parseDialects parses a list of dialect names, separated by commas or spaces
+func parseDialects(s string) ([]string, error) {
+	names := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	for _, name := range names {
+		if _, ok := dialects[name]; !ok {
+			return nil, fmt.Errorf("regenvet: unknown dialect %q", name)
+		}
+	}
+	return names, nil
+}
+
+// node is an evaluated expression, along with the evaluated expressions that it was built from
+type node struct {
+	expr     ast.Expr
+	value    regen.Regexp
+	children []*node
+}
+
+// culprit returns the innermost node for which check fails, or nil if it passes for n
+func culprit(n *node, check func(regen.Regexp) error) *node {
+	if check(n.value) == nil {
+		return nil
+	}
+	for _, child := range n.children {
+		if c := culprit(child, check); c != nil {
+			return c
+		}
+	}
+	return n
+}
+
+// evaluator evaluates regen expressions by calling the regen package
+type evaluator struct {
+	pass *analysis.Pass
+	// inits are the initializers of the package-level variables
+	inits      map[*types.Var]ast.Expr
+	values     map[*types.Var]*node
+	evaluating map[*types.Var]bool
+}
+
+// variable returns the evaluated initializer of a package-level variable, or nil if it can't be
+// evaluated
+func (e *evaluator) variable(obj *types.Var) *node {
+	if n, ok := e.values[obj]; ok {
+		return n
+	}
+	init, ok := e.inits[obj]
+	if !ok || e.evaluating[obj] {
+		return nil
+	}
+	e.evaluating[obj] = true
+	n := e.eval(init)
+	delete(e.evaluating, obj)
+	e.values[obj] = n
+	return n
+}
+
+// eval evaluates expr as a regen expression, returning nil if it can't be evaluated
+func (e *evaluator) eval(expr ast.Expr) *node {
+	v, children, ok := e.evalValue(expr)
+	if !ok || !v.IsValid() || !v.Type().Implements(regexpType) {
+		return nil
+	}
+	if v.Kind() == reflect.Interface && v.IsNil() {
+		return nil
+	}
+	return &node{expr: expr, value: v.Interface().(regen.Regexp), children: children}
+}
+
+// evalValue evaluates an expression of a regen type, returning the nodes of the regen expressions
+// that it was built from
+func (e *evaluator) evalValue(expr ast.Expr) (reflect.Value, []*node, bool) {
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return e.evalValue(expr.X)
+	case *ast.Ident, *ast.SelectorExpr:
+		obj, ok := e.pass.TypesInfo.Uses[identOf(expr)].(*types.Var)
+		if !ok {
+			return reflect.Value{}, nil, false
+		}
+		if obj.Pkg() != nil && obj.Pkg().Path() == regenPath {
+			v, ok := vars[obj.Name()]
+			return v, nil, ok
+		}
+		if n := e.variable(obj); n != nil {
+			return reflect.ValueOf(n.value), []*node{n}, true
+		}
+	case *ast.CallExpr:
+		return e.call(expr)
+	}
+	return reflect.Value{}, nil, false
+}
+
+func (e *evaluator) call(call *ast.CallExpr) (result reflect.Value, children []*node, ok bool) {
+	if call.Ellipsis.IsValid() {
+		return reflect.Value{}, nil, false
+	}
+	fun, isFunc := e.pass.TypesInfo.Uses[identOf(call.Fun)].(*types.Func)
+	if !isFunc || fun.Pkg() == nil || fun.Pkg().Path() != regenPath {
+		return reflect.Value{}, nil, false
+	}
+	// Registering has side effects, so only the registered expression is evaluated
+	if fun.Name() == "MustRegister" && len(call.Args) >= 2 {
+		if n := e.eval(call.Args[1]); n != nil {
+			return reflect.ValueOf(n.value), []*node{n}, true
+		}
+		return reflect.Value{}, nil, false
+	}
+
+	var f reflect.Value
+	if fun.Type().(*types.Signature).Recv() != nil {
+		sel, isSel := call.Fun.(*ast.SelectorExpr)
+		if !isSel {
+			return reflect.Value{}, nil, false
+		}
+		receiver, receiverChildren, ok := e.evalValue(sel.X)
+		if !ok {
+			return reflect.Value{}, nil, false
+		}
+		if receiver.Type().Implements(regexpType) {
+			children = append(children, &node{expr: sel.X, value: receiver.Interface().(regen.Regexp), children: receiverChildren})
+		}
+		if receiver.Kind() == reflect.Interface {
+			receiver = receiver.Elem()
+		}
+		f = receiver.MethodByName(fun.Name())
+	} else {
+		f = funcs[fun.Name()]
+	}
+	if !f.IsValid() {
+		return reflect.Value{}, nil, false
+	}
+
+	fType := f.Type()
+	args := make([]reflect.Value, len(call.Args))
+	for i, arg := range call.Args {
+		var paramType reflect.Type
+		switch {
+		case fType.IsVariadic() && i >= fType.NumIn()-1:
+			paramType = fType.In(fType.NumIn() - 1).Elem()
+		case i < fType.NumIn():
+			paramType = fType.In(i)
+		default:
+			return reflect.Value{}, nil, false
+		}
+		if tv, ok := e.pass.TypesInfo.Types[arg]; ok && tv.Value != nil {
+			v, ok := constantValue(tv.Value, paramType)
+			if !ok {
+				return reflect.Value{}, nil, false
+			}
+			args[i] = v
+			continue
+		}
+		n := e.eval(arg)
+		if n == nil {
+			return reflect.Value{}, nil, false
+		}
+		v := reflect.ValueOf(n.value)
+		if !v.Type().AssignableTo(paramType) {
+			return reflect.Value{}, nil, false
+		}
+		args[i] = v
+		children = append(children, n)
+	}
+
+	defer func() {
+		// The builder panics for some invalid arguments, which would otherwise only be found at run time
+		if r := recover(); r != nil {
+			e.pass.Reportf(call.Pos(), "%s panics: %v", fun.Name(), r)
+			result, children, ok = reflect.Value{}, nil, false
+		}
+	}()
+	results := f.Call(args)
+	if len(results) != 1 {
+		return reflect.Value{}, nil, false
+	}
+	return results[0], children, true
+}
+
+// constantValue converts a constant to a value of type t
+func constantValue(c constant.Value, t reflect.Type) (reflect.Value, bool) {
+	v := reflect.New(t).Elem()
+	switch {
+	case c.Kind() == constant.String && t.Kind() == reflect.String:
+		v.SetString(constant.StringVal(c))
+	case c.Kind() == constant.Int && v.CanInt():
+		n, exact := constant.Int64Val(c)
+		if !exact {
+			return reflect.Value{}, false
+		}
+		v.SetInt(n)
+	case c.Kind() == constant.Int && v.CanUint():
+		n, exact := constant.Uint64Val(c)
+		if !exact {
+			return reflect.Value{}, false
+		}
+		v.SetUint(n)
+	default:
+		return reflect.Value{}, false
+	}
+	return v, true
+}
+
+// identOf returns the identifier that names the object referred to by expr
+func identOf(expr ast.Expr) *ast.Ident {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr
+	case *ast.SelectorExpr:
+		return expr.Sel
+	case *ast.ParenExpr:
+		return identOf(expr.X)
+	}
+	return nil
+}
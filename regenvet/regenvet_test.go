@@ -0,0 +1,16 @@
+package regenvet_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen/regenvet"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), regenvet.Analyzer, "./a")
+}
+
+func TestMigrateAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), regenvet.MigrateAnalyzer, "./migrate", "./imported", "./aliased")
+}
@@ -0,0 +1,36 @@
+package a
+
+import "github.com/aoldershaw/regen"
+
+var Valid = regen.Sequence(regen.String("id-"), regen.Digit.Repeat().Min(1))
+
+var Invalid = regen.Sequence(
+	regen.String("id-"),
+	regen.Raw(`(\d+`), // want `Invalid does not compile: error parsing regexp: missing closing \)`
+)
+
+var digits = regen.Digit.Repeat().Min(1).Max(2000) // want `Derived does not compile: error parsing regexp: invalid repeat count` `digits does not compile`
+
+var Derived = regen.Sequence(regen.String("n="), digits)
+
+//regen:dialects ECMAScript
+var Flags = regen.Sequence(
+	regen.LineStart.Group().NoCapture().SetFlags(regen.FlagMultiLine), // want `Flags can't be rendered in ECMAScript: regen: multi-line anchor \^ is not supported by the ECMAScript dialect`
+	regen.String("b"),
+)
+
+//regen:dialects Ruby
+var RubyOK = regen.LineStart.Group().NoCapture().SetFlags(regen.FlagMultiLine)
+
+var Registered = regen.MustRegister("registered", regen.Raw(`[`)) // want `Registered does not compile: error parsing regexp: missing closing \]`
+
+//regen:dialects Perl // want `regenvet: unknown dialect "Perl"`
+var Unknown = regen.String("a")
+
+var Unbalanced = regen.BalancedUpTo('|', '|', 2) // want `BalancedUpTo panics: regen: BalancedUpTo requires different delimiters, got '\|' twice`
+
+var notRegen = "abc"
+
+func helper() regen.Regexp { return regen.Raw(`(`) }
+
+var Skipped = regen.Sequence(helper(), regen.String("a"))
@@ -0,0 +1,11 @@
+package aliased
+
+import (
+	"regexp"
+
+	re "github.com/aoldershaw/regen"
+)
+
+var Existing = re.String("a")
+
+var Skipped = regexp.MustCompile(`a+`)
@@ -0,0 +1,7 @@
+module example.com/testdata
+
+go 1.23
+
+require github.com/aoldershaw/regen v0.0.0
+
+replace github.com/aoldershaw/regen => ../..
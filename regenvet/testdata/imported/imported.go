@@ -0,0 +1,11 @@
+package imported
+
+import (
+	"regexp"
+
+	"github.com/aoldershaw/regen"
+)
+
+var Existing = regen.String("a")
+
+var Hex = regexp.MustCompile(`0x[0-9a-f]+`) // want "can be built using regen"
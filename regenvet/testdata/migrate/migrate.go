@@ -0,0 +1,15 @@
+package migrate
+
+import "regexp"
+
+var Version = regexp.MustCompile(`^v\d+$`) // want "regexp.MustCompile\\(\"\\^v\\\\\\\\d\\+\\$\"\\) can be built using regen"
+
+var Word = regexp.MustCompile("[a-z]+") // want "can be built using regen"
+
+var Pair = regexp.MustCompile(`(?:ab)+(?P<x>x)`) // want "can be built using regen"
+
+func Dynamic(pattern string) *regexp.Regexp {
+	return regexp.MustCompile(pattern)
+}
+
+var POSIX = regexp.MustCompilePOSIX(`a+`)
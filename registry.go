@@ -0,0 +1,190 @@
+package regen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sort"
+	"sync"
+)
+
+// Registry is a collection of named Regexps, allowing patterns to be shared between packages and
+// audited in one place. A Registry is safe for concurrent use.
+type Registry struct {
+	mu         sync.RWMutex
+	entries    map[string]Entry
+	namespaces map[string]*Namespace
+}
+
+// Entry is a Regexp that has been registered in a Registry
+type Entry struct {
+	Name       string
+	Regexp     Regexp
+	Provenance Provenance
+	// Deprecation is set if the Regexp is deprecated (see WithSunset)
+	Deprecation *Deprecation
+	// Examples are set if the Regexp is documented by examples (see WithExamples)
+	Examples *Examples
+}
+
+// Provenance describes where a registered Regexp came from
+type Provenance struct {
+	// SourceFile is the file that the Regexp is defined in
+	SourceFile string `json:"source_file,omitempty"`
+	// Author is the person or team responsible for the Regexp
+	Author string `json:"author,omitempty"`
+	// Ticket references the issue or change request that introduced or last modified the Regexp
+	Ticket string `json:"ticket,omitempty"`
+	// Version is the version of the Regexp
+	Version string `json:"version,omitempty"`
+}
+
+// EntryOption configures an Entry when it is registered
+type EntryOption func(*Entry)
+
+// WithProvenance attaches provenance metadata to an Entry
+func WithProvenance(p Provenance) EntryOption {
+	return func(e *Entry) {
+		e.Provenance = p
+	}
+}
+
+// DefaultRegistry is the Registry used by Register and MustRegister
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		entries:    make(map[string]Entry),
+		namespaces: make(map[string]*Namespace),
+	}
+}
+
+// Register adds re to DefaultRegistry under the given name. See Registry.Register
+func Register(name string, re Regexp, opts ...EntryOption) error {
+	return DefaultRegistry.Register(name, re, opts...)
+}
+
+// MustRegister is like Register, but panics if re cannot be registered. It is intended for use
+// in package-level variable initialization.
+func MustRegister(name string, re Regexp, opts ...EntryOption) Regexp {
+	return DefaultRegistry.MustRegister(name, re, opts...)
+}
+
+// Register adds re to the registry under the given name.
+// An error is returned if the name is empty, another Regexp is already registered under it, it
+// belongs to a Namespace (in which case it must be registered using the Namespace), or re fails
+// the examples attached using WithExamples.
+func (r *Registry) Register(name string, re Regexp, opts ...EntryOption) error {
+	return r.register(nil, name, re, opts)
+}
+
+func (r *Registry) register(ns *Namespace, name string, re Regexp, opts []EntryOption) error {
+	if name == "" {
+		return fmt.Errorf("regen: cannot register a Regexp without a name")
+	}
+	entry := Entry{Name: name, Regexp: re}
+	for _, opt := range opts {
+		opt(&entry)
+	}
+	if entry.Examples != nil {
+		if err := entry.Examples.Verify(re); err != nil {
+			return fmt.Errorf("regen: checking the examples of %q: %v", name, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if owner := r.namespaceOf(name); owner != ns {
+		return fmt.Errorf("regen: %q belongs to namespace %q", name, owner.name)
+	}
+	if _, ok := r.entries[name]; ok {
+		return fmt.Errorf("regen: a Regexp is already registered as %q", name)
+	}
+	if ns != nil {
+		if err := ns.policy.check(re); err != nil {
+			return fmt.Errorf("regen: %q violates the policy of namespace %q: %v", name, ns.name, err)
+		}
+	}
+	r.entries[name] = entry
+	return nil
+}
+
+// MustRegister is like Register, but panics if re cannot be registered. re is returned so that
+// it can be assigned to a variable.
+func (r *Registry) MustRegister(name string, re Regexp, opts ...EntryOption) Regexp {
+	if err := r.Register(name, re, opts...); err != nil {
+		panic(err)
+	}
+	return re
+}
+
+// Lookup returns the Entry registered under the given name
+func (r *Registry) Lookup(name string) (Entry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.entries[name]
+	return entry, ok
+}
+
+// Entries returns all of the registered entries, sorted by name
+func (r *Registry) Entries() []Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entries := make([]Entry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// Manifest is a machine-readable inventory of the patterns in a Registry, suitable for auditing
+// which matching rules are compiled into a binary
+type Manifest struct {
+	// Module and Version identify the main module of the binary, if known
+	Module   string          `json:"module,omitempty"`
+	Version  string          `json:"version,omitempty"`
+	Patterns []ManifestEntry `json:"patterns"`
+}
+
+// ManifestEntry describes a single pattern in a Manifest
+type ManifestEntry struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	// SHA256 is the hex-encoded SHA-256 digest of Pattern
+	SHA256      string       `json:"sha256"`
+	Provenance  Provenance   `json:"provenance"`
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+}
+
+// Manifest returns a Manifest of the registered patterns, rendered in DialectRE2
+func (r *Registry) Manifest() Manifest {
+	m := Manifest{Patterns: []ManifestEntry{}}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		m.Module = info.Main.Path
+		m.Version = info.Main.Version
+	}
+	for _, entry := range r.Entries() {
+		pattern := entry.Regexp.Regexp()
+		digest := sha256.Sum256([]byte(pattern))
+		m.Patterns = append(m.Patterns, ManifestEntry{
+			Name:        entry.Name,
+			Pattern:     pattern,
+			SHA256:      hex.EncodeToString(digest[:]),
+			Provenance:  entry.Provenance,
+			Deprecation: entry.Deprecation,
+		})
+	}
+	return m
+}
+
+// WriteManifest writes the Manifest of the registered patterns to w as JSON
+func (r *Registry) WriteManifest(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Manifest())
+}
@@ -0,0 +1,65 @@
+package regen_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := regen.NewRegistry()
+	provenance := regen.Provenance{SourceFile: "patterns.go", Author: "platform", Ticket: "PLAT-1", Version: "1.0.0"}
+	if err := registry.Register("greeting", regen.String("hello"), regen.WithProvenance(provenance)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	registry.MustRegister("digits", regen.Digit.Repeat().Min(1))
+
+	if err := registry.Register("greeting", regen.String("hi")); err == nil {
+		t.Errorf("expected an error registering a duplicate name")
+	}
+	if err := registry.Register("", regen.String("hi")); err == nil {
+		t.Errorf("expected an error registering an empty name")
+	}
+
+	entry, ok := registry.Lookup("greeting")
+	if !ok {
+		t.Fatalf("expected greeting to be registered")
+	}
+	if entry.Regexp.Regexp() != "hello" || entry.Provenance != provenance {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if _, ok := registry.Lookup("missing"); ok {
+		t.Errorf("expected missing not to be registered")
+	}
+
+	entries := registry.Entries()
+	if len(entries) != 2 || entries[0].Name != "digits" || entries[1].Name != "greeting" {
+		t.Errorf("expected entries to be sorted by name, got %+v", entries)
+	}
+}
+
+func TestRegistryManifest(t *testing.T) {
+	registry := regen.NewRegistry()
+	provenance := regen.Provenance{SourceFile: "patterns.go", Ticket: "PLAT-1"}
+	registry.MustRegister("greeting", regen.String("hello"), regen.WithProvenance(provenance))
+
+	var buf bytes.Buffer
+	if err := registry.WriteManifest(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var manifest regen.Manifest
+	if err := json.Unmarshal(buf.Bytes(), &manifest); err != nil {
+		t.Fatalf("invalid manifest %s: %v", buf.String(), err)
+	}
+	expected := regen.ManifestEntry{
+		Name:       "greeting",
+		Pattern:    "hello",
+		SHA256:     "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		Provenance: provenance,
+	}
+	if len(manifest.Patterns) != 1 || manifest.Patterns[0] != expected {
+		t.Errorf("expected patterns [%+v], got %+v", expected, manifest.Patterns)
+	}
+}
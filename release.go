@@ -0,0 +1,66 @@
+package regen
+
+// ReleaseAsset returns a Regexp that matches the filename of a release artifact following the common
+// name-version-os-arch.extension convention (e.g. regen_1.2.3_linux_amd64.tar.gz or regen-v1.2.3-darwin-arm64.zip).
+// Components may be separated by either '-' or '_'. If project is empty, any project name is matched.
+// The following named groups are captured: name, version (without any leading 'v'), os, arch and ext
+// (including the leading '.', and empty for bare binaries).
+func ReleaseAsset(project string) Regexp {
+	var name Regexp = Union(CharRange('a', 'z'), CharRange('A', 'Z'), CharRange('0', '9'), CharSet('.', '_', '-')).Repeat().Min(1).Ungreedy()
+	if project != "" {
+		name = String(project)
+	}
+	separator := CharSet('-', '_')
+	return Sequence(
+		name.Group().CaptureAs("name"),
+		separator,
+		String("v").Optional(),
+		ReleaseVersion().Group().CaptureAs("version"),
+		separator,
+		ReleaseOS().Group().CaptureAs("os"),
+		separator,
+		ReleaseArch().Group().CaptureAs("arch"),
+		ReleaseExtension().Group().NoCapture().Optional().Group().CaptureAs("ext"),
+	)
+}
+
+// ReleaseVersion returns a Regexp that matches a dotted version number with an optional
+// pre-release or build suffix (e.g. 1.2.3, 1.2.3-rc.1 or 2.0+build.5)
+func ReleaseVersion() Regexp {
+	identifier := Union(CharRange('a', 'z'), CharRange('A', 'Z'), CharRange('0', '9'), CharSet('.')).Repeat().Min(1)
+	return Sequence(
+		Digit.Repeat().Min(1),
+		Sequence(String("."), Digit.Repeat().Min(1)).Group().NoCapture().Repeat(),
+		Sequence(CharSet('-', '+'), identifier).Group().NoCapture().Repeat(),
+	)
+}
+
+// ReleaseOS returns a Regexp that matches the operating system component of a release artifact
+// (e.g. linux, darwin or windows)
+func ReleaseOS() Regexp {
+	return OneOfStrings(
+		"linux", "darwin", "macos", "osx", "windows", "win",
+		"freebsd", "openbsd", "netbsd", "dragonfly", "solaris", "illumos", "aix", "android", "ios",
+	)
+}
+
+// ReleaseArch returns a Regexp that matches the CPU architecture component of a release artifact
+// (e.g. amd64, x86_64 or arm64)
+func ReleaseArch() Regexp {
+	return OneOfStrings(
+		"amd64", "x86_64", "x64", "386", "i386", "i686", "x86",
+		"arm64", "aarch64", "arm", "armv5", "armv6", "armv7", "armhf", "armel",
+		"ppc64", "ppc64le", "s390x", "riscv64", "mips", "mipsle", "mips64", "mips64le", "loong64",
+		"universal", "all",
+	)
+}
+
+// ReleaseExtension returns a Regexp that matches the file extension of a release artifact,
+// including the leading '.' (e.g. .tar.gz, .zip or .exe)
+func ReleaseExtension() Regexp {
+	return OneOfStrings(
+		".tar.gz", ".tgz", ".tar.xz", ".txz", ".tar.bz2", ".tbz2", ".tar.zst", ".tar",
+		".zip", ".gz", ".xz", ".zst", ".7z",
+		".exe", ".msi", ".dmg", ".pkg", ".deb", ".rpm", ".apk", ".AppImage",
+	)
+}
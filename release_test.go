@@ -0,0 +1,55 @@
+package regen_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestReleaseAsset(t *testing.T) {
+	tests := []struct {
+		description string
+		re          regen.Regexp
+		input       string
+		expected    map[string]string
+	}{
+		{
+			description: "ReleaseAsset matches underscore-separated assets",
+			re:          regen.ReleaseAsset("regen"),
+			input:       "regen_1.2.3_linux_amd64.tar.gz",
+			expected:    map[string]string{"name": "regen", "version": "1.2.3", "os": "linux", "arch": "amd64", "ext": ".tar.gz"},
+		},
+		{
+			description: "ReleaseAsset strips the leading v from versions and allows pre-release suffixes",
+			re:          regen.ReleaseAsset("my-tool"),
+			input:       "my-tool-v1.2.3-rc.1-darwin-arm64.zip",
+			expected:    map[string]string{"name": "my-tool", "version": "1.2.3-rc.1", "os": "darwin", "arch": "arm64", "ext": ".zip"},
+		},
+		{
+			description: "ReleaseAsset matches any project name if none is provided",
+			re:          regen.ReleaseAsset(""),
+			input:       "some_tool_0.1_windows_386.exe",
+			expected:    map[string]string{"name": "some_tool", "version": "0.1", "os": "windows", "arch": "386", "ext": ".exe"},
+		},
+		{
+			description: "ReleaseAsset matches bare binaries",
+			re:          regen.ReleaseAsset(""),
+			input:       "foo-2.0-linux-x86_64",
+			expected:    map[string]string{"name": "foo", "version": "2.0", "os": "linux", "arch": "x86_64", "ext": ""},
+		},
+	}
+	for _, tt := range tests {
+		re := regexp.MustCompile(`^` + tt.re.Regexp() + `$`)
+		groups, ok := namedSubmatches(re, tt.input)
+		if !ok {
+			t.Errorf(`release test "%s" failed: "%s" did not match "%s"`, tt.description, re, tt.input)
+			continue
+		}
+		for name, expected := range tt.expected {
+			if actual := groups[name]; actual != expected {
+				t.Errorf(`release test "%s" failed: group "%s" got "%s", expected "%s"`, tt.description, name, actual, expected)
+			}
+		}
+	}
+}
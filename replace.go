@@ -0,0 +1,222 @@
+package regen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Replacement is a template for the text that replaces matches, which refers to the capturing groups of
+// a pattern. Unlike the template strings passed to ReplaceAllString, the groups that a Replacement
+// refers to are checked against the pattern (see Validate), rather than silently being replaced with
+// empty text when they don't exist.
+type Replacement struct {
+	pieces []ReplacementPart
+	// err describes a malformed reference in the template that the Replacement was parsed from
+	err error
+}
+
+// ReplacementPart is a piece of a Replacement: either literal text or a reference to a group (by name
+// or number)
+type ReplacementPart struct {
+	literal string
+	group   string
+	isGroup bool
+}
+
+// Repl parses a template in the syntax of regexp.Regexp.Expand, in which $name or ${name} refers to
+// the group named name (or numbered, if name is a number), and $$ is a literal $. For instance,
+// Repl("${user}@${host}"). A malformed reference, such as a $ that isn't followed by a name, is
+// reported by Validate.
+func Repl(template string) Replacement {
+	var r Replacement
+	var literal strings.Builder
+	for i := 0; i < len(template); {
+		dollar := strings.IndexByte(template[i:], '$')
+		if dollar < 0 {
+			literal.WriteString(template[i:])
+			break
+		}
+		literal.WriteString(template[i : i+dollar])
+		i += dollar + 1
+		if strings.HasPrefix(template[i:], "$") {
+			literal.WriteByte('$')
+			i++
+			continue
+		}
+		name, size := replacementName(template[i:])
+		if size == 0 {
+			if r.err == nil {
+				r.err = fmt.Errorf("regen: malformed group reference at offset %d of replacement %q", i-1, template)
+			}
+			literal.WriteByte('$')
+			continue
+		}
+		r.pieces = appendLiteral(r.pieces, literal.String())
+		literal.Reset()
+		r.pieces = append(r.pieces, ReplacementPart{group: name, isGroup: true})
+		i += size
+	}
+	r.pieces = appendLiteral(r.pieces, literal.String())
+	return r
+}
+
+// ReplOf returns a Replacement made up of parts, in order. Unlike Repl, this doesn't interpret any of
+// the text, so literal text (e.g. supplied by a user) can't refer to groups by accident:
+//
+//	regen.ReplOf(regen.ReplGroup("user"), regen.ReplLiteral(suffix))
+func ReplOf(parts ...ReplacementPart) Replacement {
+	var r Replacement
+	for _, part := range parts {
+		if part.isGroup {
+			r.pieces = append(r.pieces, part)
+			continue
+		}
+		r.pieces = appendLiteral(r.pieces, part.literal)
+	}
+	return r
+}
+
+// ReplLiteral returns a part of a Replacement that is the literal text, in which $ is escaped (as by
+// regexp.QuoteReplacement)
+func ReplLiteral(text string) ReplacementPart {
+	return ReplacementPart{literal: text}
+}
+
+// ReplGroup returns a part of a Replacement that is the text captured by the group named name
+func ReplGroup(name string) ReplacementPart {
+	return ReplacementPart{group: name, isGroup: true}
+}
+
+// ReplIndex returns a part of a Replacement that is the text captured by the group numbered n, or the
+// entire match if n is 0
+func ReplIndex(n int) ReplacementPart {
+	return ReplacementPart{group: strconv.Itoa(n), isGroup: true}
+}
+
+// Append returns a Replacement made up of the parts of r followed by parts
+func (r Replacement) Append(parts ...ReplacementPart) Replacement {
+	appended := ReplOf(append(append([]ReplacementPart(nil), r.pieces...), parts...)...)
+	appended.err = r.err
+	return appended
+}
+
+// replacementName returns the name at the start of s (after a $), either braced or not, and the number
+// of bytes that it takes up. The size is 0 if there is no valid name.
+func replacementName(s string) (string, int) {
+	braced := strings.HasPrefix(s, "{")
+	start := 0
+	if braced {
+		start = 1
+	}
+	end := start
+	for end < len(s) && isReplacementNameByte(s[end]) {
+		end++
+	}
+	if end == start {
+		return "", 0
+	}
+	if !braced {
+		return s[:end], end
+	}
+	if end == len(s) || s[end] != '}' {
+		return "", 0
+	}
+	return s[start:end], end + 1
+}
+
+func isReplacementNameByte(b byte) bool {
+	return b == '_' || '0' <= b && b <= '9' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
+
+func appendLiteral(pieces []ReplacementPart, literal string) []ReplacementPart {
+	if literal == "" {
+		return pieces
+	}
+	if n := len(pieces); n > 0 && !pieces[n-1].isGroup {
+		pieces[n-1].literal += literal
+		return pieces
+	}
+	return append(pieces, ReplacementPart{literal: literal})
+}
+
+// Template returns the replacement in the syntax of regexp.Regexp.Expand, as used by ReplaceAllString.
+// Groups are always referred to as ${name}, so that they aren't extended by the literal text that
+// follows them (e.g. $1x refers to the group named 1x), and $ in literal text is escaped as $$.
+func (r Replacement) Template() string {
+	var sb strings.Builder
+	for _, piece := range r.pieces {
+		if piece.isGroup {
+			sb.WriteString("${" + piece.group + "}")
+			continue
+		}
+		sb.WriteString(strings.Replace(piece.literal, "$", "$$", -1))
+	}
+	return sb.String()
+}
+
+// String returns the template of the replacement
+func (r Replacement) String() string {
+	return r.Template()
+}
+
+// Groups returns the names (or numbers) of the groups that the replacement refers to, in order
+func (r Replacement) Groups() []string {
+	var groups []string
+	for _, piece := range r.pieces {
+		if piece.isGroup {
+			groups = append(groups, piece.group)
+		}
+	}
+	return groups
+}
+
+// Validate returns an error if the replacement is malformed, or refers to a group that re doesn't
+// have
+func (r Replacement) Validate(re Regexp) error {
+	if r.err != nil {
+		return r.err
+	}
+	var groups []Group
+	for _, name := range r.Groups() {
+		if _, size := replacementName("{" + name + "}"); size != len(name)+2 {
+			return fmt.Errorf("regen: replacement refers to group %q, which is not a valid name", name)
+		}
+		if n, err := strconv.Atoi(name); err == nil {
+			if groups == nil {
+				groups = Groups(re)
+			}
+			if n > len(groups) {
+				return fmt.Errorf("regen: replacement refers to group %d, but the pattern has %d groups", n, len(groups))
+			}
+			continue
+		}
+		if _, ok := GroupIndex(re, name); !ok {
+			return fmt.Errorf("regen: replacement refers to group %q, which is not in the pattern", name)
+		}
+	}
+	return nil
+}
+
+// ReplaceAll replaces each match in text with the replacement, after checking that the groups that
+// it refers to exist (see Replacement.Validate)
+func (m *Matcher) ReplaceAll(text string, r Replacement) (string, error) {
+	if err := r.Validate(m.re); err != nil {
+		return "", err
+	}
+	return m.compiled.ReplaceAllString(text, r.Template()), nil
+}
+
+// ReplaceAllFunc replaces each match in text with the result of fn, which is passed the text captured
+// by each named group that participated in the match (see MatchFields)
+func (m *Matcher) ReplaceAllFunc(text string, fn func(groups map[string]string) string) string {
+	var sb strings.Builder
+	last := 0
+	for _, match := range m.compiled.FindAllStringSubmatchIndex(text, -1) {
+		sb.WriteString(text[last:match[0]])
+		sb.WriteString(fn(namedGroups(m.compiled, text, match)))
+		last = match[1]
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
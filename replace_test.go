@@ -0,0 +1,180 @@
+package regen_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+var emailPattern = regen.Sequence(
+	regen.WordCharacter.Repeat().Min(1).Group().CaptureAs("user"),
+	regen.String(" at "),
+	regen.Raw(`[\w.]+`).Group().CaptureAs("host"),
+)
+
+func TestRepl(t *testing.T) {
+	for _, tt := range []struct {
+		template string
+		expected string
+		groups   []string
+		replaced string
+		err      string
+	}{
+		{
+			template: "${user}@${host}",
+			expected: "${user}@${host}",
+			groups:   []string{"user", "host"},
+			replaced: "mail gopher@example.com now",
+		},
+		{
+			template: "$user costs $$5 at $host.",
+			expected: "${user} costs $$5 at ${host}.",
+			groups:   []string{"user", "host"},
+			replaced: "mail gopher costs $5 at example.com. now",
+		},
+		{
+			template: "<$1>",
+			expected: "<${1}>",
+			groups:   []string{"1"},
+			replaced: "mail <gopher> now",
+		},
+		{
+			template: "no groups",
+			expected: "no groups",
+			replaced: "mail no groups now",
+		},
+		{
+			template: "$userx",
+			expected: "${userx}",
+			groups:   []string{"userx"},
+			err:      `regen: replacement refers to group "userx", which is not in the pattern`,
+		},
+		{
+			template: "$3",
+			expected: "${3}",
+			groups:   []string{"3"},
+			err:      `regen: replacement refers to group 3, but the pattern has 2 groups`,
+		},
+		{
+			template: "${user",
+			expected: "$${user",
+			err:      `regen: malformed group reference at offset 0 of replacement "${user"`,
+		},
+		{
+			template: "costs $ 5",
+			expected: "costs $$ 5",
+			err:      `regen: malformed group reference at offset 6 of replacement "costs $ 5"`,
+		},
+	} {
+		t.Run(tt.template, func(t *testing.T) {
+			r := regen.Repl(tt.template)
+			if actual := r.Template(); actual != tt.expected {
+				t.Errorf("expected template %q, got %q", tt.expected, actual)
+			}
+			if actual := r.Groups(); !reflect.DeepEqual(actual, tt.groups) {
+				t.Errorf("expected groups %q, got %q", tt.groups, actual)
+			}
+			replaced, err := regen.MustCompile(emailPattern).ReplaceAll("mail gopher at example.com now", r)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if replaced != tt.replaced {
+				t.Errorf("expected %q, got %q", tt.replaced, replaced)
+			}
+		})
+	}
+}
+
+func TestReplOf(t *testing.T) {
+	m := regen.MustCompile(emailPattern)
+	for _, tt := range []struct {
+		description string
+		r           regen.Replacement
+		expected    string
+		replaced    string
+		err         string
+	}{
+		{
+			description: "literals are escaped",
+			r:           regen.ReplOf(regen.ReplGroup("user"), regen.ReplLiteral(" paid $5 to ${host}")),
+			expected:    "${user} paid $$5 to $${host}",
+			replaced:    "mail gopher paid $5 to ${host} now",
+		},
+		{
+			description: "adjacent literals and indexes",
+			r:           regen.ReplOf(regen.ReplLiteral("["), regen.ReplLiteral("$"), regen.ReplIndex(0), regen.ReplLiteral("]")),
+			expected:    "[$$${0}]",
+			replaced:    "mail [$gopher at example.com] now",
+		},
+		{
+			description: "appended to a template",
+			r:           regen.Repl("$host/").Append(regen.ReplLiteral("~$"), regen.ReplGroup("user")),
+			expected:    "${host}/~$$${user}",
+			replaced:    "mail example.com/~$gopher now",
+		},
+		{
+			description: "invalid name",
+			r:           regen.ReplOf(regen.ReplGroup("user name")),
+			err:         `regen: replacement refers to group "user name", which is not a valid name`,
+		},
+		{
+			description: "negative index",
+			r:           regen.ReplOf(regen.ReplIndex(-1)),
+			err:         `regen: replacement refers to group "-1", which is not a valid name`,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			replaced, err := m.ReplaceAll("mail gopher at example.com now", tt.r)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if actual := tt.r.Template(); actual != tt.expected {
+				t.Errorf("expected template %q, got %q", tt.expected, actual)
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if replaced != tt.replaced {
+				t.Errorf("expected %q, got %q", tt.replaced, replaced)
+			}
+		})
+	}
+}
+
+func TestReplaceAllFunc(t *testing.T) {
+	p := regen.NewPattern(regen.Sequence(
+		regen.WordCharacter.Repeat().Min(1).Group().CaptureAs("key"),
+		regen.String("="),
+		regen.Sequence(regen.String(`"`), regen.Raw(`[^"]*`).Group().CaptureAs("quoted"), regen.String(`"`)).Optional(),
+	))
+	replaced, err := p.ReplaceAllFunc(`a= b="x y" c`, func(groups map[string]string) string {
+		if quoted, ok := groups["quoted"]; ok {
+			return strings.ToUpper(groups["key"]) + ":" + quoted
+		}
+		return strings.ToUpper(groups["key"]) + ":-"
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `A:- B:x y c`; replaced != expected {
+		t.Errorf("expected %q, got %q", expected, replaced)
+	}
+
+	if replaced, err := (regen.Pattern{}).ReplaceAllFunc("abc", nil); err != nil || replaced != "abc" {
+		t.Errorf("expected the zero Pattern not to replace anything, got %q, %v", replaced, err)
+	}
+	if _, err := regen.NewPattern(regen.Raw(`(`)).ReplaceAllFunc("abc", nil); err == nil {
+		t.Error("expected an error for an invalid expression")
+	}
+}
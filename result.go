@@ -0,0 +1,113 @@
+package regen
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// MatchResult is a match of a pattern in a compact form that can be serialized (e.g. as JSON, one
+// result per line) so that services scanning text can stream matches to other services. Offsets are
+// in bytes.
+//
+// For example: {"pattern":"request","span":[0,14],"captures":[{"name":"method","index":1,"span":[0,3],"text":"GET"}]}
+type MatchResult struct {
+	// Pattern identifies the pattern that matched, e.g. its name in a Set. It is empty for a Matcher
+	Pattern string `json:"pattern,omitempty"`
+	// Offset is the position of the matched text within a larger input, such as the offset of a line
+	// within a file. Spans are relative to the matched text, so Offset must be added to them to find
+	// their position in the input. It is 0 unless set by the caller
+	Offset int64 `json:"offset,omitempty"`
+	// Span is the span of the entire match
+	Span Span `json:"span"`
+	// Captures are the named groups that participated in the match, in order
+	Captures []Capture `json:"captures,omitempty"`
+}
+
+// Capture is the text captured by a named group
+type Capture struct {
+	Name string `json:"name"`
+	// Index is the index of the group's submatch, as returned by FindStringSubmatch
+	Index int    `json:"index"`
+	Span  Span   `json:"span"`
+	Text  string `json:"text"`
+}
+
+// Span is the half-open range of bytes [Start, End). It is serialized as a two-element JSON array
+type Span struct {
+	Start int
+	End   int
+}
+
+// MarshalJSON encodes the span as [start, end]
+func (s Span) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]int{s.Start, s.End})
+}
+
+// UnmarshalJSON decodes a span encoded as [start, end]
+func (s *Span) UnmarshalJSON(data []byte) error {
+	var span []int
+	if err := json.Unmarshal(data, &span); err != nil {
+		return err
+	}
+	if len(span) != 2 {
+		return fmt.Errorf("regen: span must have 2 elements, got %d", len(span))
+	}
+	s.Start, s.End = span[0], span[1]
+	return nil
+}
+
+// Capture returns the capture of the named group, if it participated in the match
+func (r MatchResult) Capture(name string) (Capture, bool) {
+	for _, c := range r.Captures {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Capture{}, false
+}
+
+// FindResult returns the first match in text
+func (m *Matcher) FindResult(text string) (MatchResult, bool) {
+	match := m.compiled.FindStringSubmatchIndex(text)
+	if match == nil {
+		return MatchResult{}, false
+	}
+	return newMatchResult("", m.compiled, text, match), true
+}
+
+// FindAllResults returns successive matches in text. If n >= 0, at most n matches are returned
+func (m *Matcher) FindAllResults(text string, n int) []MatchResult {
+	var results []MatchResult
+	for _, match := range m.compiled.FindAllStringSubmatchIndex(text, n) {
+		results = append(results, newMatchResult("", m.compiled, text, match))
+	}
+	return results
+}
+
+// MatchResult is like Match, but returns the match of the first pattern that matches text, identified
+// by its name
+func (s *Set) MatchResult(text string) (MatchResult, bool) {
+	for _, p := range s.patterns {
+		if match := p.compiled.FindStringSubmatchIndex(text); match != nil {
+			return newMatchResult(p.name, p.compiled, text, match), true
+		}
+	}
+	return MatchResult{}, false
+}
+
+func newMatchResult(pattern string, re *regexp.Regexp, text string, match []int) MatchResult {
+	result := MatchResult{Pattern: pattern, Span: Span{Start: match[0], End: match[1]}}
+	for i, name := range re.SubexpNames() {
+		if name == "" || match[2*i] < 0 {
+			continue
+		}
+		result.Captures = append(result.Captures, Capture{
+			Name:  name,
+			Index: i,
+			Span:  Span{Start: match[2*i], End: match[2*i+1]},
+			Text:  text[match[2*i]:match[2*i+1]],
+		})
+	}
+	return result
+}
@@ -0,0 +1,90 @@
+package regen_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+var requestPattern = regen.Sequence(
+	regen.OneOfStrings("GET", "POST").Group().CaptureAs("method"),
+	regen.String(" "),
+	regen.Whitespace.Negate().Repeat().Min(1).Group().CaptureAs("path"),
+	regen.Sequence(regen.String(" "), regen.Digit.Repeat().Min(1).Group().CaptureAs("status")).Group().NoCapture().Optional(),
+)
+
+func TestMatcherFindResult(t *testing.T) {
+	m := regen.MustCompile(requestPattern)
+	result, ok := m.FindResult("> GET /users")
+	if !ok {
+		t.Fatalf("expected a match")
+	}
+	expected := regen.MatchResult{
+		Span: regen.Span{Start: 2, End: 12},
+		Captures: []regen.Capture{
+			{Name: "method", Index: 1, Span: regen.Span{Start: 2, End: 5}, Text: "GET"},
+			{Name: "path", Index: 2, Span: regen.Span{Start: 6, End: 12}, Text: "/users"},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+	if _, ok := result.Capture("status"); ok {
+		t.Errorf("expected status not to participate")
+	}
+
+	results := m.FindAllResults("GET /a 200\nPOST /b", -1)
+	if len(results) != 2 || results[1].Span != (regen.Span{Start: 11, End: 18}) {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if _, ok := m.FindResult("PUT /"); ok {
+		t.Errorf("expected no match")
+	}
+}
+
+func TestSetMatchResult(t *testing.T) {
+	s := regen.NewSet().
+		MustAdd("number", regen.Sequence(regen.TextStart, regen.Digit.Repeat().Min(1).Group().CaptureAs("n"), regen.TextEnd)).
+		MustAdd("request", requestPattern)
+	result, ok := s.MatchResult("POST /x 201")
+	if !ok {
+		t.Fatalf("expected a match")
+	}
+	if result.Pattern != "request" {
+		t.Errorf("expected the request pattern to match, got %q", result.Pattern)
+	}
+	if c, ok := result.Capture("status"); !ok || c.Text != "201" {
+		t.Errorf("expected status 201, got %+v", c)
+	}
+}
+
+func TestMatchResultJSON(t *testing.T) {
+	result := regen.MatchResult{
+		Pattern: "request",
+		Offset:  100,
+		Span:    regen.Span{Start: 0, End: 5},
+		Captures: []regen.Capture{
+			{Name: "method", Index: 1, Span: regen.Span{Start: 0, End: 3}, Text: "GET"},
+		},
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"pattern":"request","offset":100,"span":[0,5],"captures":[{"name":"method","index":1,"span":[0,3],"text":"GET"}]}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+	var decoded regen.MatchResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, result) {
+		t.Errorf("expected %+v, got %+v", result, decoded)
+	}
+	if err := json.Unmarshal([]byte(`{"span":[1]}`), &decoded); err == nil {
+		t.Errorf("expected an error for an invalid span")
+	}
+}
@@ -0,0 +1,100 @@
+package regen
+
+import (
+	"unicode/utf8"
+)
+
+// Position is a location in text, for reporting where a match was found (e.g. by a linter or highlighter)
+type Position struct {
+	// Offset is the position in bytes, starting at 0
+	Offset int
+	// Rune is the position in characters, starting at 0
+	Rune int
+	// Line and Column start at 1. Columns are counted in characters, and lines end with \n
+	Line   int
+	Column int
+}
+
+// ScanMatch is a match found by a Scanner. The spans of the MatchResult are relative to the start of
+// the input.
+type ScanMatch struct {
+	MatchResult
+	// Start and End are the positions of the start and end of the match
+	Start Position
+	End   Position
+}
+
+// Scanner finds the successive matches of a Matcher in an input, along with their positions, like
+// bufio.Scanner:
+//
+//	s := m.Scanner(input)
+//	for s.Scan() {
+//		match := s.Match()
+//		fmt.Printf("%d:%d: %s\n", match.Start.Line, match.Start.Column, input[match.Span.Start:match.Span.End])
+//	}
+//
+// Positions are computed incrementally, so scanning the entire input takes time proportional to its length.
+type Scanner struct {
+	matcher *Matcher
+	input   string
+	matches [][]int
+	match   ScanMatch
+	// pos is the position of the start of the last match
+	pos Position
+}
+
+// Scanner returns a Scanner for the matches in input
+func (m *Matcher) Scanner(input string) *Scanner {
+	return &Scanner{
+		matcher: m,
+		input:   input,
+		matches: m.compiled.FindAllStringSubmatchIndex(input, -1),
+		pos:     Position{Line: 1, Column: 1},
+	}
+}
+
+// Scan advances to the next match, returning false when there are no more matches
+func (s *Scanner) Scan() bool {
+	if len(s.matches) == 0 {
+		return false
+	}
+	match := s.matches[0]
+	s.matches = s.matches[1:]
+	s.pos = advancePosition(s.pos, s.input, match[0])
+	s.match = ScanMatch{
+		MatchResult: newMatchResult("", s.matcher.compiled, s.input, match),
+		Start:       s.pos,
+		End:         advancePosition(s.pos, s.input, match[1]),
+	}
+	return true
+}
+
+// Match returns the match found by the last call to Scan
+func (s *Scanner) Match() ScanMatch {
+	return s.match
+}
+
+// Position returns the position of the byte offset within the input, e.g. the start of a capture of the
+// current match. This is quickest for offsets within the current match.
+func (s *Scanner) Position(offset int) Position {
+	if offset >= s.pos.Offset {
+		return advancePosition(s.pos, s.input, offset)
+	}
+	return advancePosition(Position{Line: 1, Column: 1}, s.input, offset)
+}
+
+// advancePosition returns the position of offset within text, given the position pos before it
+func advancePosition(pos Position, text string, offset int) Position {
+	for pos.Offset < offset {
+		r, size := utf8.DecodeRuneInString(text[pos.Offset:])
+		pos.Offset += size
+		pos.Rune++
+		if r == '\n' {
+			pos.Line++
+			pos.Column = 1
+			continue
+		}
+		pos.Column++
+	}
+	return pos
+}
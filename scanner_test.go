@@ -0,0 +1,94 @@
+package regen_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestScanner(t *testing.T) {
+	word := regen.WordCharacter.Repeat().Min(1).Group().CaptureAs("word")
+	tests := []struct {
+		description string
+		re          regen.Regexp
+		input       string
+		expected    []regen.Position
+	}{
+		{
+			description: "positions are tracked across lines",
+			re:          word,
+			input:       "ab cd\nef",
+			expected: []regen.Position{
+				{Offset: 0, Rune: 0, Line: 1, Column: 1},
+				{Offset: 3, Rune: 3, Line: 1, Column: 4},
+				{Offset: 6, Rune: 6, Line: 2, Column: 1},
+			},
+		},
+		{
+			description: "runes and columns are counted in characters",
+			re:          word,
+			input:       "é a\n→ x",
+			expected: []regen.Position{
+				{Offset: 3, Rune: 2, Line: 1, Column: 3},
+				{Offset: 9, Rune: 6, Line: 2, Column: 3},
+			},
+		},
+		{
+			description: "empty matches are found between characters",
+			re:          regen.String("x").Optional(),
+			input:       "a\nx",
+			expected: []regen.Position{
+				{Offset: 0, Rune: 0, Line: 1, Column: 1},
+				{Offset: 1, Rune: 1, Line: 1, Column: 2},
+				{Offset: 2, Rune: 2, Line: 2, Column: 1},
+			},
+		},
+		{
+			description: "there are no positions without matches",
+			re:          word,
+			input:       "  \n",
+		},
+	}
+	for _, tt := range tests {
+		s := regen.MustCompile(tt.re).Scanner(tt.input)
+		var actual []regen.Position
+		for s.Scan() {
+			match := s.Match()
+			actual = append(actual, match.Start)
+			if match.Start.Offset != match.Span.Start || match.End.Offset != match.Span.End {
+				t.Errorf(`scanner test "%s" failed: positions %v-%v don't match the span %v`, tt.description, match.Start, match.End, match.Span)
+			}
+		}
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf(`scanner test "%s" failed: got %v, expected %v`, tt.description, actual, tt.expected)
+		}
+	}
+}
+
+func TestScanner_Captures(t *testing.T) {
+	re := regen.Sequence(
+		regen.String("key="),
+		regen.WordCharacter.Repeat().Min(1).Group().CaptureAs("value"),
+	)
+	s := regen.MustCompile(re).Scanner("a\n  key=x\nkey=yz")
+	var ends []regen.Position
+	var values []regen.Position
+	for s.Scan() {
+		match := s.Match()
+		ends = append(ends, match.End)
+		capture, _ := match.Capture("value")
+		values = append(values, s.Position(capture.Span.Start))
+	}
+	expectedEnds := []regen.Position{{Offset: 9, Rune: 9, Line: 2, Column: 8}, {Offset: 16, Rune: 16, Line: 3, Column: 7}}
+	if !reflect.DeepEqual(ends, expectedEnds) {
+		t.Errorf("expected ends %v, got %v", expectedEnds, ends)
+	}
+	expectedValues := []regen.Position{{Offset: 8, Rune: 8, Line: 2, Column: 7}, {Offset: 14, Rune: 14, Line: 3, Column: 5}}
+	if !reflect.DeepEqual(values, expectedValues) {
+		t.Errorf("expected values at %v, got %v", expectedValues, values)
+	}
+	if pos := s.Position(2); pos != (regen.Position{Offset: 2, Rune: 2, Line: 2, Column: 1}) {
+		t.Errorf("unexpected position of an earlier offset: %v", pos)
+	}
+}
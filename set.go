@@ -0,0 +1,93 @@
+package regen
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Set is an ordered collection of named Regexps that are matched against the same input, such as
+// the rules of a log parser. When multiple patterns match, the one that was added first wins.
+type Set struct {
+	patterns []setPattern
+	index    map[string]int
+}
+
+type setPattern struct {
+	name     string
+	re       Regexp
+	compiled *regexp.Regexp
+}
+
+// NewSet returns an empty Set
+func NewSet() *Set {
+	return &Set{index: make(map[string]int)}
+}
+
+// Add compiles re and adds it to the end of the set under the given name.
+// An error is returned if the name is already in use or re fails to compile.
+func (s *Set) Add(name string, re Regexp) error {
+	if _, ok := s.index[name]; ok {
+		return fmt.Errorf("regen: %q is already in the set", name)
+	}
+	compiled, err := regexp.Compile(re.Regexp())
+	if err != nil {
+		return fmt.Errorf("regen: compiling %q: %v", name, err)
+	}
+	s.index[name] = len(s.patterns)
+	s.patterns = append(s.patterns, setPattern{name: name, re: re, compiled: compiled})
+	return nil
+}
+
+// MustAdd is like Add, but panics if re cannot be added. The Set is returned to allow chaining.
+func (s *Set) MustAdd(name string, re Regexp) *Set {
+	if err := s.Add(name, re); err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Names returns the names of the patterns in the order they were added
+func (s *Set) Names() []string {
+	names := make([]string, len(s.patterns))
+	for i, p := range s.patterns {
+		names[i] = p.name
+	}
+	return names
+}
+
+// Regexp returns the pattern added under the given name
+func (s *Set) Regexp(name string) (Regexp, bool) {
+	i, ok := s.index[name]
+	if !ok {
+		return nil, false
+	}
+	return s.patterns[i].re, true
+}
+
+// Match returns the name of the first pattern that matches text
+func (s *Set) Match(text string) (string, bool) {
+	for _, p := range s.patterns {
+		if p.compiled.MatchString(text) {
+			return p.name, true
+		}
+	}
+	return "", false
+}
+
+// MatchFields is like Match, but also returns the text captured by each named group of the matching pattern
+func (s *Set) MatchFields(text string) (string, map[string]string, bool) {
+	for _, p := range s.patterns {
+		match := p.compiled.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		fields := make(map[string]string)
+		for i, name := range p.compiled.SubexpNames() {
+			if name != "" {
+				fields[name] = match[i]
+			}
+		}
+		return p.name, fields, true
+	}
+	return "", nil, false
+}
@@ -0,0 +1,38 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestSet(t *testing.T) {
+	set := regen.NewSet().
+		MustAdd("error", regen.Sequence(regen.String("ERROR "), regen.Any.Repeat().Group().CaptureAs("message"))).
+		MustAdd("any", regen.Any)
+
+	if err := set.Add("any", regen.String("x")); err == nil {
+		t.Errorf("expected an error adding a duplicate name")
+	}
+	if err := set.Add("invalid", regen.Raw("(")); err == nil {
+		t.Errorf("expected an error adding an invalid Regexp")
+	}
+
+	if names := set.Names(); len(names) != 2 || names[0] != "error" || names[1] != "any" {
+		t.Errorf("unexpected names %v", names)
+	}
+	if _, ok := set.Regexp("error"); !ok {
+		t.Errorf("expected error to be in the set")
+	}
+
+	name, fields, ok := set.MatchFields("ERROR disk full")
+	if !ok || name != "error" || fields["message"] != "disk full" {
+		t.Errorf("unexpected match %s %v %v", name, fields, ok)
+	}
+	if name, ok := set.Match("INFO ok"); !ok || name != "any" {
+		t.Errorf("expected the second pattern to match, got %s", name)
+	}
+	if _, ok := set.Match(""); ok {
+		t.Errorf("expected no match")
+	}
+}
@@ -0,0 +1,114 @@
+package regen
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sync"
+)
+
+// ShadowSet matches input against an active Set, while also evaluating a candidate Set against the
+// same input and recording where their outcomes diverge. This allows an updated Set to be rolled out
+// progressively: the candidate sees live traffic, but only the active Set's results are returned.
+//
+// ShadowSet implements expvar.Var, so its statistics and samples can be published for debugging
+// (e.g. using expvar.Publish). It is safe for concurrent use.
+type ShadowSet struct {
+	active     *Set
+	candidate  *Set
+	sampleSize int
+
+	mu          sync.Mutex
+	evaluations uint64
+	divergences uint64
+	samples     []Divergence
+}
+
+// Divergence is an input that the active and candidate Sets of a ShadowSet match differently
+type Divergence struct {
+	Text      string  `json:"text"`
+	Active    Outcome `json:"active"`
+	Candidate Outcome `json:"candidate"`
+}
+
+// ShadowStats contains counters describing the inputs that a ShadowSet has evaluated
+type ShadowStats struct {
+	Evaluations uint64 `json:"evaluations"`
+	Divergences uint64 `json:"divergences"`
+}
+
+// ShadowOption configures a ShadowSet
+type ShadowOption func(*ShadowSet)
+
+// WithSampleSize sets the maximum number of divergences that a ShadowSet retains, which defaults to 100.
+// Once the limit is reached, divergences are sampled uniformly at random.
+func WithSampleSize(n int) ShadowOption {
+	return func(s *ShadowSet) {
+		s.sampleSize = n
+	}
+}
+
+// Shadow returns a ShadowSet that matches input against s, and evaluates candidate against the same input
+func (s *Set) Shadow(candidate *Set, opts ...ShadowOption) *ShadowSet {
+	shadow := &ShadowSet{active: s, candidate: candidate, sampleSize: 100}
+	for _, opt := range opts {
+		opt(shadow)
+	}
+	return shadow
+}
+
+// Match returns the name of the first pattern in the active Set that matches text (see Set.Match)
+func (s *ShadowSet) Match(text string) (string, bool) {
+	name, _, ok := s.MatchFields(text)
+	return name, ok
+}
+
+// MatchFields returns the result of matching text against the active Set (see Set.MatchFields),
+// recording a Divergence if the candidate Set's outcome differs
+func (s *ShadowSet) MatchFields(text string) (string, map[string]string, bool) {
+	name, fields, ok := s.active.MatchFields(text)
+	active := Outcome{Pattern: name, Fields: fields}
+	candidate := outcome(s.candidate, text)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evaluations++
+	if !active.equal(candidate) {
+		s.divergences++
+		s.sample(Divergence{Text: text, Active: active, Candidate: candidate})
+	}
+	return name, fields, ok
+}
+
+// sample adds d to the samples using reservoir sampling. The caller must hold s.mu.
+func (s *ShadowSet) sample(d Divergence) {
+	if len(s.samples) < s.sampleSize {
+		s.samples = append(s.samples, d)
+		return
+	}
+	if i := rand.Int63n(int64(s.divergences)); i < int64(s.sampleSize) {
+		s.samples[i] = d
+	}
+}
+
+// Stats returns the number of inputs that have been evaluated, and how many of them diverged
+func (s *ShadowSet) Stats() ShadowStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ShadowStats{Evaluations: s.evaluations, Divergences: s.divergences}
+}
+
+// Samples returns the divergences that have been retained (see WithSampleSize)
+func (s *ShadowSet) Samples() []Divergence {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Divergence(nil), s.samples...)
+}
+
+// String returns the statistics and samples as JSON, implementing expvar.Var
+func (s *ShadowSet) String() string {
+	out, _ := json.Marshal(struct {
+		ShadowStats
+		Samples []Divergence `json:"samples"`
+	}{s.Stats(), s.Samples()})
+	return string(out)
+}
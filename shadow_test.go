@@ -0,0 +1,54 @@
+package regen_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestShadow(t *testing.T) {
+	active := regen.NewSet().MustAdd("number", regen.Digit.Repeat().Min(1).Group().CaptureAs("n"))
+	candidate := regen.NewSet().MustAdd("number", regen.Digit.Repeat().Min(2).Group().CaptureAs("n"))
+	shadow := active.Shadow(candidate, regen.WithSampleSize(3))
+	var _ expvar.Var = shadow
+
+	name, fields, ok := shadow.MatchFields("7")
+	if !ok || name != "number" || fields["n"] != "7" {
+		t.Errorf("expected the active set's result, got %s %v %v", name, fields, ok)
+	}
+	if _, ok := shadow.Match("42"); !ok {
+		t.Errorf("expected a match")
+	}
+	for i := 0; i < 10; i++ {
+		shadow.Match(fmt.Sprint(i))
+	}
+
+	stats := shadow.Stats()
+	if stats.Evaluations != 12 || stats.Divergences != 11 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	samples := shadow.Samples()
+	if len(samples) != 3 {
+		t.Fatalf("expected 3 samples, got %d", len(samples))
+	}
+	for _, sample := range samples {
+		if sample.Active.Pattern != "number" || sample.Candidate.Pattern != "" || len(sample.Text) != 1 {
+			t.Errorf("unexpected sample %+v", sample)
+		}
+	}
+
+	var published struct {
+		Evaluations uint64
+		Divergences uint64
+		Samples     []regen.Divergence
+	}
+	if err := json.Unmarshal([]byte(shadow.String()), &published); err != nil {
+		t.Fatalf("invalid JSON %s: %v", shadow.String(), err)
+	}
+	if published.Evaluations != 12 || len(published.Samples) != 3 {
+		t.Errorf("unexpected published stats %s", shadow.String())
+	}
+}
@@ -0,0 +1,165 @@
+package regen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RenderShared is like Render, but composite expressions that are used more than once (the same value,
+// such as a building block shared by several patterns) are defined once, at the start of the pattern,
+// and called as subroutines wherever they are used. This is only supported by dialects with subroutine
+// calls (currently DialectRuby and DialectPCRE); in other dialects, the result is the same as Render. e.g.
+//
+//	hex := regen.CharRange('0', '9').Repeat().Exactly(4)
+//	regen.DialectRuby.RenderShared(regen.Sequence(hex, regen.String("-"), hex))
+//	// (?<_1>[0-9]{4}){0}\g<_1>-\g<_1>
+//
+// Expressions are only shared if they are always used with the same flags, contain no capturing groups,
+// and a call is shorter than their rendering. Since the definitions are named groups (_1, _2, etc.),
+// which capture the text matched by the most recent call, nothing is shared if the pattern has unnamed
+// capturing groups, as Ruby doesn't capture those alongside named groups (and they would be numbered
+// differently in PCRE).
+func (d Dialect) RenderShared(re Regexp) (string, error) {
+	if d.subroutineCallFormat == "" || !d.supports(FeatureNamedGroups) {
+		return d.Render(re)
+	}
+	compiled, err := regexp.Compile(re.Regexp())
+	if err != nil {
+		return d.Render(re)
+	}
+	names := make(map[string]bool)
+	for _, name := range compiled.SubexpNames()[1:] {
+		if name == "" {
+			return d.Render(re)
+		}
+		names[name] = true
+	}
+
+	uses, order := sharedUses(re)
+	r := renderer{dialect: d, subroutines: make(map[*renderCache]string)}
+	var definitions []*renderCache
+	n := 0
+	for _, c := range order {
+		use := uses[c]
+		if use.count < 2 || use.mixed || !capturesNothing(use.node) {
+			continue
+		}
+		name := fmt.Sprintf("_%d", n+1)
+		for names[name] {
+			n++
+			name = fmt.Sprintf("_%d", n+1)
+		}
+		inline := renderer{dialect: d, activeFlags: use.flags}
+		if len(inline.regexp(use.node)) <= len(fmt.Sprintf(d.subroutineCallFormat, name)) {
+			continue
+		}
+		n++
+		r.subroutines[c] = name
+		definitions = append(definitions, c)
+	}
+
+	var sb strings.Builder
+	for _, c := range definitions {
+		use := uses[c]
+		body := use.node
+		if use.flags != 0 {
+			// subroutines are evaluated with the flags in effect where they're defined, rather than
+			// those of the caller
+			body = groupedRegexp{re: use.node, noCapture: true, setFlags: use.flags}
+		}
+		r.defining = c
+		sb.WriteString(fmt.Sprintf(d.subroutineDefinitionFormat, r.subroutines[c], r.regexp(body)))
+	}
+	r.defining = nil
+	r.appendRegexp(&sb, re)
+	if r.err != nil {
+		return "", r.err
+	}
+	return sb.String(), nil
+}
+
+// SubroutineCall returns an expression that calls the group named name as a subroutine when it is
+// rendered in d (e.g. \g<name> in DialectRuby), or false if d doesn't support subroutine calls
+func (d Dialect) SubroutineCall(name string) (Regexp, bool) {
+	if d.subroutineCallFormat == "" {
+		return nil, false
+	}
+	return Raw(fmt.Sprintf(d.subroutineCallFormat, name)), true
+}
+
+// RenderSubroutine renders re as the definition of a subroutine named name, which doesn't match
+// anything itself, so that patterns can call it (see SubroutineCall) after the definition. A call
+// matches with the flags in effect where the subroutine is defined, rather than where it's called.
+// An UnsupportedError is returned if d doesn't support subroutine calls.
+func (d Dialect) RenderSubroutine(name string, re Regexp) (string, error) {
+	if d.subroutineDefinitionFormat == "" {
+		return "", &UnsupportedError{Dialect: d.name, Construct: "subroutine definition"}
+	}
+	body, err := d.Render(re)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(d.subroutineDefinitionFormat, name, body), nil
+}
+
+// sharedUse describes the uses of a composite expression within a pattern
+type sharedUse struct {
+	node  Regexp
+	count int
+	// flags are the flags in effect where the expression is used, and mixed is true if they differ
+	// between uses
+	flags Flag
+	mixed bool
+}
+
+// sharedUses counts the uses of each composite expression in re, identified by their render caches,
+// which are returned in the order that they're first used
+func sharedUses(re Regexp) (map[*renderCache]*sharedUse, []*renderCache) {
+	uses := make(map[*renderCache]*sharedUse)
+	var order []*renderCache
+	var visit func(node Regexp, active Flag)
+	visit = func(node Regexp, active Flag) {
+		if c := renderCacheOf(node); c != nil {
+			if use, ok := uses[c]; ok {
+				use.count++
+				if use.flags == active {
+					// the expression's contents have already been counted in this context
+					return
+				}
+				use.mixed = true
+			} else {
+				uses[c] = &sharedUse{node: node, count: 1, flags: active}
+				order = append(order, c)
+			}
+		}
+		if g, ok := node.(groupedRegexp); ok {
+			active = active&^g.unsetFlags | g.setFlags
+		}
+		for _, child := range Children(node) {
+			visit(child, active)
+		}
+	}
+	visit(re, 0)
+	return uses, order
+}
+
+// renderCacheOf returns the render cache of a composite expression, which identifies the value
+func renderCacheOf(re Regexp) *renderCache {
+	switch re := re.(type) {
+	case multiRegexp:
+		return re.cache
+	case groupedRegexp:
+		return re.cache
+	case repeatedRegexp:
+		return re.cache
+	}
+	return nil
+}
+
+// capturesNothing returns true if re has no capturing groups (including the groups that are added
+// around repeated expressions)
+func capturesNothing(re Regexp) bool {
+	compiled, err := regexp.Compile(re.Regexp())
+	return err == nil && compiled.NumSubexp() == 0
+}
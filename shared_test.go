@@ -0,0 +1,159 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestRenderShared(t *testing.T) {
+	hex := regen.CharRange('0', '9').Repeat().Exactly(4)
+	word := regen.Sequence(regen.CharRange('a', 'z'), regen.WordCharacter.Repeat())
+	for _, tt := range []struct {
+		description string
+		dialect     regen.Dialect
+		re          regen.Regexp
+		expected    string
+	}{
+		{
+			description: "expressions used more than once are defined once and called",
+			dialect:     regen.DialectRuby,
+			re:          regen.Sequence(hex, regen.String("-"), hex),
+			expected:    `(?<_1>[0-9]{4}){0}\g<_1>-\g<_1>`,
+		},
+		{
+			description: "expressions used once are rendered in place",
+			dialect:     regen.DialectRuby,
+			re:          regen.Sequence(hex, regen.String("-"), word),
+			expected:    `[0-9]{4}-[a-z]\w*`,
+		},
+		{
+			description: "shared expressions can be nested in other shared expressions",
+			dialect:     regen.DialectRuby,
+			re: func() regen.Regexp {
+				pairs := regen.Sequence(hex, hex).Group().NoCapture().Repeat().Min(1)
+				return regen.Sequence(pairs, regen.String(":"), hex, pairs)
+			}(),
+			expected: `(?<_1>(?:\g<_2>\g<_2>)+){0}(?<_2>[0-9]{4}){0}\g<_1>:\g<_2>\g<_1>`,
+		},
+		{
+			description: "expressions that are shorter than a call aren't shared",
+			dialect:     regen.DialectRuby,
+			re:          regen.Sequence(regen.Digit.Repeat().Min(1), regen.String("."), regen.Digit.Repeat().Min(1)),
+			expected:    `\d+\.\d+`,
+		},
+		{
+			description: "the same value is shared wherever it is used",
+			dialect:     regen.DialectRuby,
+			re: func() regen.Regexp {
+				number := regen.Digit.Repeat().Min(1)
+				version := regen.Sequence(number, regen.String("."), number, regen.String("."), number)
+				return regen.Sequence(version, regen.String(" < "), version)
+			}(),
+			expected: `(?<_1>\d+\.\d+\.\d+){0}\g<_1> < \g<_1>`,
+		},
+		{
+			description: "definitions are evaluated with the flags of their uses",
+			dialect:     regen.DialectRuby,
+			re:          regen.Sequence(regen.String("id-"), word, regen.String(":"), word).Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+			expected:    `(?<_1>(?i:[a-z]\w*)){0}(?i:id-\g<_1>:\g<_1>)`,
+		},
+		{
+			description: "expressions used with different flags aren't shared",
+			dialect:     regen.DialectRuby,
+			re:          regen.Sequence(word, word.Group().NoCapture().SetFlags(regen.FlagCaseInsensitive)),
+			expected:    `[a-z]\w*(?i:[a-z]\w*)`,
+		},
+		{
+			description: "expressions with capturing groups aren't shared",
+			dialect:     regen.DialectRuby,
+			re: func() regen.Regexp {
+				key := regen.Sequence(regen.String("key="), word.Group().CaptureAs("key"))
+				return regen.OneOf(key, regen.Sequence(key, regen.String(";")).Group().NoCapture()).Group().NoCapture()
+			}(),
+			expected: `(?:key=(?<key>[a-z]\w*)|(?:key=(?<key>[a-z]\w*);))`,
+		},
+		{
+			description: "definitions aren't named after existing groups",
+			dialect:     regen.DialectRuby,
+			re:          regen.Sequence(hex.Group().CaptureAs("_1"), hex),
+			expected:    `(?<_2>[0-9]{4}){0}(?<_1>\g<_2>)\g<_2>`,
+		},
+		{
+			description: "nothing is shared if there are unnamed capturing groups",
+			dialect:     regen.DialectRuby,
+			re:          regen.Sequence(hex.Group(), hex),
+			expected:    `([0-9]{4})[0-9]{4}`,
+		},
+		{
+			description: "PCRE defines expressions in DEFINE groups",
+			dialect:     regen.DialectPCRE,
+			re:          regen.Sequence(hex, regen.String("-"), hex),
+			expected:    `(?(DEFINE)(?<_1>[0-9]{4}))(?&_1)-(?&_1)`,
+		},
+		{
+			description: "dialects without subroutine calls render the expression in place",
+			dialect:     regen.DialectRE2,
+			re:          regen.Sequence(hex, regen.String("-"), hex),
+			expected:    `[0-9]{4}-[0-9]{4}`,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			actual, err := tt.dialect.RenderShared(tt.re)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSubroutines(t *testing.T) {
+	call, ok := regen.DialectPCRE.SubroutineCall("pair")
+	if !ok {
+		t.Fatal("expected PCRE to support subroutine calls")
+	}
+	definition, err := regen.DialectPCRE.RenderSubroutine("pair", regen.Digit.Repeat().Exactly(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pattern, err := regen.DialectPCRE.Render(regen.Sequence(call, regen.String(":"), call))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `(?(DEFINE)(?<pair>\d{2}))(?&pair):(?&pair)`; definition+pattern != expected {
+		t.Errorf("expected %s, got %s", expected, definition+pattern)
+	}
+
+	if _, ok := regen.DialectRE2.SubroutineCall("pair"); ok {
+		t.Error("expected RE2 not to support subroutine calls")
+	}
+	_, err = regen.DialectRE2.RenderSubroutine("pair", regen.Digit)
+	if expected := "regen: subroutine definition is not supported by the RE2 dialect"; err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
+
+func TestRender_SharedExpressions(t *testing.T) {
+	// more distinct expressions than fit in the array of those that have been seen
+	var words []regen.Regexp
+	for i := 0; i < 20; i++ {
+		words = append(words, regen.Sequence(regen.String(string(rune('a'+i))), regen.Digit).Repeat())
+	}
+	shared := regen.OneOf(words...).Group().NoCapture()
+	re := regen.Sequence(
+		shared,
+		regen.Sequence(shared, regen.String("x")).Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+		shared,
+		words[3],
+	)
+	actual, err := regen.DialectPCRE.Render(re)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := re.Regexp(); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
@@ -0,0 +1,82 @@
+package regen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// shrinkRunes are the characters that the shrinker tries to use in place of others, simplest first
+const shrinkRunes = "0aA "
+
+// Shrink returns a minimal version of s, which re matches in its entirety and for which failing
+// returns true, e.g. to report a small example when a property test fails for a generated string.
+// Parts of s are removed where possible (which reduces repetitions and removes optional parts), and
+// the remaining characters are replaced with simpler ones (such as 0 or a), as long as the result
+// still matches re and still fails. It returns an error if re doesn't compile, or if s doesn't
+// match re or doesn't fail.
+func Shrink(re Regexp, s string, failing func(string) bool) (string, error) {
+	g, err := newGenerator(re, nil)
+	if err != nil {
+		return "", err
+	}
+	return g.shrink(s, failing)
+}
+
+// Shrink returns a minimal version of s that matches the expression and for which failing returns
+// true (see the Shrink function)
+func (g *Generator) Shrink(s string, failing func(string) bool) (string, error) {
+	return g.g.shrink(s, failing)
+}
+
+func (g *generator) shrink(s string, failing func(string) bool) (string, error) {
+	if !g.matcher.MatchString(s) {
+		return "", fmt.Errorf("regen: %q doesn't match %s", s, g.pattern)
+	}
+	if !failing(s) {
+		return "", fmt.Errorf("regen: %q doesn't fail", s)
+	}
+	keep := func(runes []rune) bool {
+		candidate := string(runes)
+		return g.matcher.MatchString(candidate) && failing(candidate)
+	}
+	runes := []rune(s)
+	for changed := true; changed; {
+		changed = false
+		// Remove runs of characters, starting with the longest
+		for size := len(runes); size > 0; size /= 2 {
+			for i := 0; i+size <= len(runes); {
+				candidate := append(append([]rune{}, runes[:i]...), runes[i+size:]...)
+				if keep(candidate) {
+					runes = candidate
+					changed = true
+				} else {
+					i++
+				}
+			}
+		}
+		// Simplify the remaining characters
+		for i, c := range runes {
+			for _, simpler := range shrinkRunes {
+				if shrinkRank(simpler) >= shrinkRank(c) {
+					break
+				}
+				candidate := append([]rune{}, runes...)
+				candidate[i] = simpler
+				if keep(candidate) {
+					runes = candidate
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return string(runes), nil
+}
+
+// shrinkRank orders characters from the simplest
+func shrinkRank(c rune) int {
+	if i := strings.IndexRune(shrinkRunes, c); i >= 0 {
+		return i
+	}
+	return len(shrinkRunes) + int(c)
+}
@@ -0,0 +1,71 @@
+package regen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestShrink(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		re       regen.Regexp
+		s        string
+		failing  func(string) bool
+		expected string
+		err      string
+	}{
+		{
+			desc:     "repetitions",
+			re:       regen.Sequence(regen.WordCharacter.Repeat().Min(1), regen.String("@"), regen.WordCharacter.Repeat().Min(1)),
+			s:        "someone_else@example",
+			failing:  func(s string) bool { return strings.Contains(s, "_") },
+			expected: "_@0",
+		},
+		{
+			desc:     "optional parts",
+			re:       regen.Sequence(regen.Digit.Repeat().Min(1), regen.Sequence(regen.String("."), regen.Digit.Repeat().Min(1)).Group().NoCapture().Optional()),
+			s:        "1234.5678",
+			failing:  func(s string) bool { return len(s) > 0 },
+			expected: "0",
+		},
+		{
+			desc:     "counted repetition",
+			re:       regen.CharRange('a', 'z').Repeat().Min(3).Max(8),
+			s:        "property",
+			failing:  func(s string) bool { return strings.ContainsRune(s, 'y') },
+			expected: "aay",
+		},
+		{
+			desc:    "not matching",
+			re:      regen.Digit.Repeat().Min(1),
+			s:       "abc",
+			failing: func(string) bool { return true },
+			err:     `regen: "abc" doesn't match \d+`,
+		},
+		{
+			desc:    "not failing",
+			re:      regen.Digit.Repeat().Min(1),
+			s:       "123",
+			failing: func(string) bool { return false },
+			err:     `regen: "123" doesn't fail`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			shrunk, err := regen.Shrink(tt.re, tt.s, tt.failing)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if shrunk != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, shrunk)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package regen
+
+import "unicode"
+
+// Simplify returns an equivalent Regexp with a shorter rendering, which also compiles into a
+// smaller program. The following rewrites are applied:
+//
+//   - non-capturing groups without flags are removed where the parentheses are not required, e.g.
+//     (?:ab)c becomes abc
+//   - adjacent Strings in a Sequence are merged, as are adjacent Raw expressions, e.g.
+//     Sequence(String("foo"), String("bar")) becomes String("foobar")
+//   - nested quantifiers are merged, e.g. (?:a+)? becomes a* and (?:a{2}){3} becomes a{6}
+//   - adjacent alternatives that match a single character are merged into a class, e.g. a|b|\d
+//     becomes [ab\d]
+//   - flags that have no effect are removed, e.g. the i in (?i:\d+), or flags that are already set
+//     by an enclosing group
+//
+// Capturing groups (and the numbering of groups) are never changed.
+func Simplify(re Regexp) Regexp {
+	return simplify(re, 0, simplifyTop)
+}
+
+// simplifyContext describes the parent of the node being simplified, which determines whether a
+// group around it can be removed
+type simplifyContext int
+
+const (
+	simplifyTop simplifyContext = iota
+	simplifySequence
+	simplifyAlternation
+	simplifyRepeat
+	simplifyGroup
+)
+
+// simplify simplifies re, where active are the flags set by enclosing groups
+func simplify(re Regexp, active Flag, ctx simplifyContext) Regexp {
+	switch re := re.(type) {
+	case annotatedRegexp:
+		re.re = simplify(re.re, active, ctx)
+		return re
+	case multiRegexp:
+		if re.separator != "" {
+			return simplifyAlternative(re, active)
+		}
+		if len(re.res) == 1 {
+			return simplify(re.res[0], active, ctx)
+		}
+		var res []Regexp
+		for _, sub := range re.res {
+			sub = simplify(sub, active, simplifySequence)
+			if m, ok := sub.(multiRegexp); ok && m.separator == "" {
+				res = append(res, m.res...)
+			} else {
+				res = append(res, sub)
+			}
+		}
+		res = coalesceLiterals(res)
+		if len(res) == 1 {
+			return res[0]
+		}
+		re.res, re.cache = res, nil
+		return re
+	case groupedRegexp:
+		inner := (active | re.setFlags) &^ re.unsetFlags
+		re.re, re.cache = simplify(re.re, inner, simplifyGroup), nil
+		re.setFlags = effectiveFlags(re.setFlags&^active, re.re)
+		re.unsetFlags &= active
+		if re.noCapture && re.name == "" && re.balance == "" && re.setFlags == 0 && re.unsetFlags == 0 && canUnwrap(re.re, ctx) {
+			return re.re
+		}
+		return re
+	case repeatedRegexp:
+		re.re, re.cache = simplify(re.re, active, simplifyRepeat), nil
+		if merged, ok := mergeQuantifiers(re); ok {
+			return simplify(merged, active, ctx)
+		}
+		return re
+	}
+	return re
+}
+
+// coalesceLiterals merges adjacent Strings into a single String, and adjacent Raw expressions into
+// a single Raw expression. Empty Strings are removed.
+func coalesceLiterals(res []Regexp) []Regexp {
+	var coalesced []Regexp
+	for _, re := range res {
+		l, ok := re.(literalRegexp)
+		if !ok {
+			coalesced = append(coalesced, re)
+			continue
+		}
+		if l.literal && l.value == "" {
+			continue
+		}
+		if len(coalesced) > 0 {
+			if prev, ok := coalesced[len(coalesced)-1].(literalRegexp); ok && prev.literal == l.literal {
+				if l.literal {
+					coalesced[len(coalesced)-1] = String(prev.value + l.value)
+				} else {
+					coalesced[len(coalesced)-1] = Raw(prev.re + l.re)
+				}
+				continue
+			}
+		}
+		coalesced = append(coalesced, re)
+	}
+	if len(coalesced) == 0 {
+		return []Regexp{String("")}
+	}
+	return coalesced
+}
+
+// simplifyAlternative simplifies the choices of an alternation, merging adjacent choices that match
+// a single character into a class
+func simplifyAlternative(m multiRegexp, active Flag) Regexp {
+	var res []Regexp
+	var run []CharClass
+	flush := func() {
+		switch len(run) {
+		case 0:
+		case 1:
+			res = append(res, run[0])
+		default:
+			res = append(res, mergeClasses(run))
+		}
+		run = nil
+	}
+	for _, choice := range m.res {
+		choice = simplify(choice, active, simplifyAlternation)
+		if class, ok := singleCharClass(choice); ok {
+			run = append(run, class)
+			continue
+		}
+		flush()
+		res = append(res, choice)
+	}
+	flush()
+	if len(res) == 1 {
+		return res[0]
+	}
+	m.res, m.cache = res, nil
+	return m
+}
+
+// singleCharClass returns a class matching the same single character as re, if re is a one-character
+// String or a class that can be merged with others
+func singleCharClass(re Regexp) (CharClass, bool) {
+	switch re := re.(type) {
+	case literalRegexp:
+		runes := []rune(re.value)
+		if re.literal && len(runes) == 1 {
+			return CharSet(runes[0]), true
+		}
+	case CharClass:
+		if !re.IsNegated() {
+			return re, true
+		}
+	}
+	return nil, false
+}
+
+// mergeClasses returns a single class that matches any of the (non-negated) classes
+func mergeClasses(classes []CharClass) CharClass {
+	var chars []rune
+	var others []CharClass
+	for _, class := range classes {
+		if set, ok := class.(charSetRegexp); ok {
+			chars = append(chars, set.chars...)
+		} else {
+			others = append(others, class)
+		}
+	}
+	if len(chars) > 0 {
+		others = append([]CharClass{CharSet(chars...)}, others...)
+	}
+	if len(others) == 1 {
+		return others[0]
+	}
+	return unionCharClassRegexp{charClasses: others}
+}
+
+// canUnwrap returns true if a non-capturing group around re can be removed in the given context
+func canUnwrap(re Regexp, ctx simplifyContext) bool {
+	if a, ok := re.(annotatedRegexp); ok {
+		return canUnwrap(a.re, ctx)
+	}
+	switch ctx {
+	case simplifyGroup:
+		return true
+	case simplifySequence, simplifyAlternation:
+		if m, ok := re.(multiRegexp); ok && m.separator != "" {
+			return false
+		}
+		if l, ok := re.(literalRegexp); ok && !l.literal {
+			return false
+		}
+		return true
+	}
+	return !requiresParens(re, re.Regexp())
+}
+
+// mergeQuantifiers merges a greedy repetition of a greedy repetition into a single repetition, if
+// the result is equivalent
+func mergeQuantifiers(outer repeatedRegexp) (Regexp, bool) {
+	// A repetition that is repeated directly is wrapped in a capturing group when rendered, which
+	// must be preserved
+	g, ok := outer.re.(groupedRegexp)
+	if !ok || !g.noCapture || g.name != "" || g.balance != "" || g.setFlags != 0 || g.unsetFlags != 0 {
+		return nil, false
+	}
+	inner, ok := g.re.(repeatedRegexp)
+	if !ok || inner.ungreedy || outer.ungreedy {
+		return nil, false
+	}
+	isSimple := func(r repeatedRegexp) bool {
+		return r.min <= 1 && (!r.hasMax || r.max == 1)
+	}
+	switch {
+	case isSimple(inner) && isSimple(outer):
+		// *, + and ? combine into another of them
+		merged := repeatedRegexp{re: inner.re, min: inner.min * outer.min}
+		if inner.hasMax && outer.hasMax {
+			merged.max, merged.hasMax = 1, true
+		}
+		return merged, true
+	case inner.hasMax && inner.min == inner.max && outer.hasMax && outer.min == outer.max:
+		return inner.Exactly(inner.min * outer.min), true
+	}
+	return nil, false
+}
+
+// effectiveFlags returns the flags that affect how re matches
+func effectiveFlags(flags Flag, re Regexp) Flag {
+	var effective Flag
+	for _, flag := range []Flag{FlagCaseInsensitive, FlagMultiLine, FlagMatchNewLine, FlagUngreedy} {
+		if flags&flag != 0 && affectedBy(re, flag) {
+			effective |= flag
+		}
+	}
+	return effective
+}
+
+// affectedBy returns true if flag may change how re matches
+func affectedBy(re Regexp, flag Flag) bool {
+	affected := false
+	Walk(re, func(node Regexp) bool {
+		switch node := node.(type) {
+		case literalRegexp:
+			if !node.literal {
+				affected = true
+			} else if flag == FlagCaseInsensitive {
+				for _, r := range node.value {
+					if unicode.SimpleFold(r) != r {
+						affected = true
+					}
+				}
+			}
+		case anchorRegexp:
+			affected = affected || flag == FlagMultiLine && (node.re == "^" || node.re == "$")
+		case anyRegexp:
+			affected = affected || flag == FlagMatchNewLine
+		case repeatedRegexp:
+			affected = affected || flag == FlagUngreedy
+		case perlCharClassRegexp:
+			// Perl classes are closed under case folding
+		case CharClass:
+			affected = affected || flag == FlagCaseInsensitive
+		}
+		return !affected
+	})
+	return affected
+}
@@ -0,0 +1,125 @@
+package regen_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestSimplify(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		re       regen.Regexp
+		expected string
+		inputs   []string
+	}{
+		{
+			desc:     "single-child groups",
+			re:       regen.Sequence(regen.String("ab").Group().NoCapture(), regen.Sequence(regen.String("c")).Group().NoCapture().Group().NoCapture()),
+			expected: `abc`,
+			inputs:   []string{"abc", "ab"},
+		},
+		{
+			desc:     "groups that are required",
+			re:       regen.Sequence(regen.String("ab").Group().NoCapture().Repeat(), regen.Raw("c|d").Group().NoCapture(), regen.String("x").Group()),
+			expected: `(?:ab)*(?:c|d)(x)`,
+		},
+		{
+			desc: "literal coalescing",
+			re: regen.Sequence(
+				regen.String("foo"),
+				regen.String(""),
+				regen.Sequence(regen.String("."), regen.String("bar")),
+				regen.Raw(`\d+`),
+				regen.Raw(`\s`),
+				regen.String("x").Group().NoCapture(),
+				regen.Annotate(regen.String("y"), "kept"),
+				regen.String("z"),
+			),
+			expected: `foo\.bar\d+\sxyz`,
+			inputs:   []string{"foo.bar12 xyz", "foo.bar xyz"},
+		},
+		{
+			desc:     "repeated literals",
+			re:       regen.Sequence(regen.String("a"), regen.String("b")).Group().NoCapture().Repeat(),
+			expected: `(?:ab)*`,
+			inputs:   []string{"", "abab", "aba"},
+		},
+		{
+			desc:     "nested quantifiers",
+			re:       regen.Sequence(regen.String("a").Repeat().Min(1).Group().NoCapture().Optional(), regen.String("b").Optional().Group().NoCapture().Optional(), regen.Digit.Repeat().Exactly(2).Group().NoCapture().Repeat().Exactly(3)),
+			expected: `a*b?\d{6}`,
+			inputs:   []string{"", "aab", "123456", "b12345", "aaaab123456"},
+		},
+		{
+			desc: "quantifiers that can't be merged",
+			re: regen.Sequence(
+				regen.String("a").Repeat().Min(2).Max(3).Group().NoCapture().Repeat(),
+				regen.String("b").Repeat().Ungreedy().Group().NoCapture().Optional(),
+				regen.String("c").Optional().Repeat(),
+			),
+			expected: `(?:a{2,3})*(?:b*?)?(c?)*`,
+		},
+		{
+			desc:     "single-character alternatives",
+			re:       regen.OneOf(regen.String("a"), regen.String("b"), regen.Digit, regen.String("cd"), regen.String("e"), regen.CharRange('x', 'z')).Group().NoCapture(),
+			expected: `(?:[ab\d]|cd|[ex-z])`,
+			inputs:   []string{"a", "5", "cd", "c", "y", "e"},
+		},
+		{
+			desc:     "alternation becomes a class",
+			re:       regen.Sequence(regen.OneOf(regen.String("a"), regen.String("b")).Group().NoCapture(), regen.OneOf(regen.String("-"), regen.String("_")).Group().CaptureAs("sep")),
+			expected: `[ab](?P<sep>[-_])`,
+			inputs:   []string{"a-", "b_", "c-"},
+		},
+		{
+			desc: "no-op flags",
+			re: regen.Sequence(
+				regen.Digit.Repeat().Min(1).Group().NoCapture().SetFlags(regen.FlagCaseInsensitive|regen.FlagMultiLine),
+				regen.String("x").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive|regen.FlagMatchNewLine),
+				regen.Sequence(regen.String("y").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive), regen.Any.Group().NoCapture().UnsetFlags(regen.FlagMatchNewLine)).Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+			),
+			expected: `\d+(?i:x)(?i:y.)`,
+			inputs:   []string{"1X", "1xY\n", "1xyz"},
+		},
+		{
+			desc: "unset flags",
+			re: regen.Sequence(
+				regen.Any.Group().NoCapture().UnsetFlags(regen.FlagMatchNewLine),
+				regen.String("a").Group().NoCapture().UnsetFlags(regen.FlagMultiLine),
+				regen.Any,
+			).Group().CaptureAs("x").SetFlags(regen.FlagMatchNewLine),
+			expected: `(?P<x>(?s)(?-s:.)a.)`,
+			inputs:   []string{"\na\n", "xa\n", "\n\n\n"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			simplified := regen.Simplify(tt.re)
+			if actual := simplified.Regexp(); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+			original := regexp.MustCompile(`^(?:` + tt.re.Regexp() + `)$`)
+			compiled := regexp.MustCompile(`^(?:` + simplified.Regexp() + `)$`)
+			if original.NumSubexp() != compiled.NumSubexp() {
+				t.Errorf("expected %d groups, got %d", original.NumSubexp(), compiled.NumSubexp())
+			}
+			for _, input := range tt.inputs {
+				if original.MatchString(input) != compiled.MatchString(input) {
+					t.Errorf("expected %s and %s to agree on %q", tt.re.Regexp(), simplified.Regexp(), input)
+				}
+			}
+		})
+	}
+}
+
+func TestSimplify_CoalescesLiterals(t *testing.T) {
+	simplified := regen.Simplify(regen.Sequence(regen.String("foo"), regen.String("bar")))
+	if regen.Kind(simplified) != regen.KindString {
+		t.Errorf("expected a String, got %s", regen.Kind(simplified))
+	}
+	simplified = regen.Simplify(regen.Sequence(regen.Raw(`a+`), regen.Raw(`b`)))
+	if regen.Kind(simplified) != regen.KindRaw || simplified.Regexp() != `a+b` {
+		t.Errorf("expected Raw a+b, got %s %s", regen.Kind(simplified), simplified.Regexp())
+	}
+}
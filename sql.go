@@ -0,0 +1,378 @@
+package regen
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ToLike converts re into an SQL LIKE pattern that uses '\' as its escape character (i.e. ESCAPE '\').
+// Since LIKE patterns must match the entire value, unanchored expressions are surrounded by '%'.
+// Only sequences of String literals, Any (which becomes '_') and repetitions of Any are supported;
+// any other construct results in an *UnsupportedError. Note that unlike Any, '_' also matches newlines.
+func ToLike(re Regexp) (string, error) {
+	nodes, anchoredStart, anchoredEnd := sqlSequence(re)
+	var sb strings.Builder
+	if !anchoredStart {
+		sb.WriteByte('%')
+	}
+	for _, node := range nodes {
+		switch node := node.(type) {
+		case literalRegexp:
+			if !node.literal {
+				if node.re == "" {
+					continue
+				}
+				return "", sqlUnsupported("LIKE", "raw regular expression "+node.re)
+			}
+			for _, char := range node.value {
+				if char == '%' || char == '_' || char == '\\' {
+					sb.WriteByte('\\')
+				}
+				sb.WriteRune(char)
+			}
+		case anyRegexp:
+			sb.WriteByte('_')
+		case repeatedRegexp:
+			if _, ok := node.re.(anyRegexp); !ok || node.ungreedy {
+				return "", sqlUnsupported("LIKE", "repetition of "+node.re.Regexp())
+			}
+			if node.hasMax && node.max != node.min {
+				return "", sqlUnsupported("LIKE", "bounded repetition "+node.Regexp())
+			}
+			sb.WriteString(strings.Repeat("_", int(node.min)))
+			if !node.hasMax {
+				sb.WriteByte('%')
+			}
+		default:
+			return "", sqlUnsupported("LIKE", node.Regexp())
+		}
+	}
+	if !anchoredEnd {
+		sb.WriteByte('%')
+	}
+	return collapseWildcards(sb.String()), nil
+}
+
+// FromLike returns a Regexp equivalent to the SQL LIKE pattern. '%' matches any sequence of characters,
+// '_' matches any single character, and escape (typically '\') causes the following character to be
+// matched literally. Pass 0 as the escape if the pattern has no escape character.
+// Since LIKE patterns match the entire value, the returned Regexp is anchored with TextStart and TextEnd.
+func FromLike(pattern string, escape rune) Regexp {
+	parts := []Regexp{TextStart}
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			parts = append(parts, String(literal.String()))
+			literal.Reset()
+		}
+	}
+	escaped := false
+	for _, char := range pattern {
+		switch {
+		case escaped:
+			literal.WriteRune(char)
+			escaped = false
+		case escape != 0 && char == escape:
+			escaped = true
+		case char == '%':
+			flush()
+			parts = append(parts, Any.Repeat())
+		case char == '_':
+			flush()
+			parts = append(parts, Any)
+		default:
+			literal.WriteRune(char)
+		}
+	}
+	flush()
+	parts = append(parts, TextEnd)
+	return Sequence(parts...).Group().NoCapture().SetFlags(FlagMatchNewLine)
+}
+
+// ToSimilarTo converts re into an SQL SIMILAR TO pattern that uses '\' as its escape character.
+// Since SIMILAR TO patterns must match the entire value, unanchored expressions are surrounded by '%'.
+// Groups, alternations, repetitions and character classes are supported, although group names are
+// discarded. Flags, ungreedy repetitions, anchors other than at the start or end of the expression,
+// and Raw expressions result in an *UnsupportedError. Note that unlike Any, '_' also matches newlines.
+func ToSimilarTo(re Regexp) (string, error) {
+	nodes, anchoredStart, anchoredEnd := sqlSequence(re)
+	c := similarToConverter{renderer: renderer{dialect: similarToDialect}}
+	var sb strings.Builder
+	if !anchoredStart {
+		sb.WriteByte('%')
+	}
+	for _, node := range nodes {
+		sb.WriteString(c.convert(node))
+	}
+	if !anchoredEnd {
+		sb.WriteByte('%')
+	}
+	if c.err != nil {
+		return "", c.err
+	}
+	return collapseWildcards(sb.String()), nil
+}
+
+// similarToDialect is used to render character classes in SIMILAR TO patterns, which are based on
+// POSIX bracket expressions
+var similarToDialect = Dialect{
+	name:            "SQL SIMILAR TO",
+	missing:         FeatureUnicodeClasses,
+	codePointFormat: `\x%X`,
+}
+
+type similarToConverter struct {
+	renderer
+}
+
+func (c *similarToConverter) convert(re Regexp) string {
+	switch re := re.(type) {
+	case literalRegexp:
+		if !re.literal {
+			if re.re != "" {
+				c.unsupported("raw regular expression " + re.re)
+			}
+			return ""
+		}
+		var sb strings.Builder
+		for _, char := range re.value {
+			if strings.ContainsRune(`\%_|*+?{}()[]`, char) {
+				sb.WriteByte('\\')
+			}
+			sb.WriteRune(char)
+		}
+		return sb.String()
+	case anyRegexp:
+		return "_"
+	case annotatedRegexp:
+		return c.convert(re.re)
+	case anchorRegexp:
+		c.unsupported("anchor " + re.re)
+		return ""
+	case CharClass:
+		return "[" + re.charSetRegexp(&c.renderer) + "]"
+	case multiRegexp:
+		parts := make([]string, len(re.res))
+		for i, sub := range re.res {
+			parts[i] = c.convert(sub)
+		}
+		return strings.Join(parts, re.separator)
+	case groupedRegexp:
+		if re.setFlags != 0 || re.unsetFlags != 0 {
+			c.unsupported("flags")
+		}
+		if re.balance != "" {
+			c.unsupported("balancing group")
+		}
+		return "(" + c.convert(re.re) + ")"
+	case repeatedRegexp:
+		if re.ungreedy {
+			c.unsupported("ungreedy repetition")
+		}
+		sub := c.convert(re.re)
+		if _, ok := re.re.(groupedRegexp); !ok && !isSimilarToAtom(re.re) {
+			sub = "(" + sub + ")"
+		}
+		return sub + repetitionSuffix(re)
+	}
+	c.unsupported(re.Regexp())
+	return ""
+}
+
+// isSimilarToAtom returns true if re converts into a single SIMILAR TO atom
+func isSimilarToAtom(re Regexp) bool {
+	switch re := re.(type) {
+	case anyRegexp, CharClass:
+		return true
+	case literalRegexp:
+		return re.literal && len([]rune(re.value)) == 1
+	}
+	return false
+}
+
+// repetitionSuffix returns the quantifier for a repetition, e.g. *, + or {2,3}
+func repetitionSuffix(r repeatedRegexp) string {
+	switch {
+	case !r.hasMax && r.min == 0:
+		return "*"
+	case !r.hasMax && r.min == 1:
+		return "+"
+	case !r.hasMax:
+		return "{" + strconv.Itoa(int(r.min)) + ",}"
+	case r.min == 0 && r.max == 1:
+		return "?"
+	case r.min == r.max:
+		return "{" + strconv.Itoa(int(r.min)) + "}"
+	}
+	return "{" + strconv.Itoa(int(r.min)) + "," + strconv.Itoa(int(r.max)) + "}"
+}
+
+// sqlSequence flattens re into a sequence of nodes, removing any anchors at its start and end
+func sqlSequence(re Regexp) (nodes []Regexp, anchoredStart bool, anchoredEnd bool) {
+	nodes = flattenSequence(re)
+	if len(nodes) > 0 && (nodes[0] == TextStart || nodes[0] == LineStart) {
+		nodes = nodes[1:]
+		anchoredStart = true
+	}
+	if len(nodes) > 0 && (nodes[len(nodes)-1] == TextEnd || nodes[len(nodes)-1] == LineEnd) {
+		nodes = nodes[:len(nodes)-1]
+		anchoredEnd = true
+	}
+	return nodes, anchoredStart, anchoredEnd
+}
+
+// flattenSequence returns the elements of re if it is a Sequence (recursively), or re itself otherwise
+func flattenSequence(re Regexp) []Regexp {
+	if a, ok := re.(annotatedRegexp); ok {
+		return flattenSequence(a.re)
+	}
+	m, ok := re.(multiRegexp)
+	if !ok || m.separator != "" {
+		return []Regexp{re}
+	}
+	var nodes []Regexp
+	for _, sub := range m.res {
+		nodes = append(nodes, flattenSequence(sub)...)
+	}
+	return nodes
+}
+
+// collapseWildcards removes redundant unescaped '%' wildcards
+func collapseWildcards(pattern string) string {
+	var sb strings.Builder
+	escaped := false
+	wildcard := false
+	for _, char := range pattern {
+		if !escaped && char == '%' {
+			if wildcard {
+				continue
+			}
+			wildcard = true
+		} else {
+			wildcard = false
+		}
+		escaped = !escaped && char == '\\'
+		sb.WriteRune(char)
+	}
+	return sb.String()
+}
+
+func sqlUnsupported(syntax string, construct string) error {
+	return &UnsupportedError{Dialect: "SQL " + syntax, Construct: construct}
+}
+
+var (
+	// DialectMySQL is the syntax accepted by the REGEXP operator and REGEXP_ functions of MySQL 8.0 and
+	// later, which use ICU. Since \d, \w, \s and ASCII classes match Unicode characters in ICU, they are
+	// expanded into the ASCII ranges that they match in RE2. As in .NET, $ also matches before a final
+	// newline, so LineEnd is rendered as \z, and . does not match \r, U+2028 or U+2029, so Any is
+	// rendered as [^\n]. FlagUngreedy is unsupported.
+	//
+	// Note that MySQL matches case-insensitively when the input has a case-insensitive collation (the
+	// default), and that word boundaries use Unicode word characters. See Notes.
+	DialectMySQL = Dialect{
+		name:             "MySQL",
+		namedGroupPrefix: "?<",
+		flagLetters: map[Flag]byte{
+			FlagCaseInsensitive: 'i',
+			FlagMultiLine:       'm',
+			FlagMatchNewLine:    's',
+		},
+		missing:                    FeatureASCIIClasses,
+		unicodeScripts:             true,
+		unicodeBraces:              true,
+		codePointFormat:            `\x{%X}`,
+		dollarBeforeFinalNewline:   true,
+		freeSpacing:                true,
+		dotExcludesLineTerminators: true,
+		sqlBackslashEscapes:        true,
+		notes:                      mysqlNotes,
+		backtracking:               true,
+	}
+	// DialectPostgreSQL is the syntax of PostgreSQL's advanced regular expressions, as used by the ~
+	// operator and the regexp_ functions. PostgreSQL has no flag groups (so flags are emulated, and
+	// FlagMultiLine is unsupported), no named groups and no Unicode classes. \d, \w, \s and ASCII classes
+	// depend on the database's locale, so they are expanded. \A and \z are rendered as ^ and $, which only
+	// match at the start and end of the text, and since . matches newlines in PostgreSQL, Any is rendered
+	// as [^\n]. Word boundaries are rendered as \y and \Y.
+	DialectPostgreSQL = Dialect{
+		name:              "PostgreSQL",
+		flagLetters:       map[Flag]byte{},
+		missing:           FeatureFlagGroups | FeatureNamedGroups | FeatureUnicodeClasses | FeatureASCIIClasses,
+		codePointFormat:   `\U%08X`,
+		noTextAnchors:     true,
+		dotMatchesNewLine: true,
+		boundaryEscapes:   map[string]string{`\b`: `\y`, `\B`: `\Y`},
+		notes:             postgresNotes,
+	}
+	// DialectSQLite is the syntax of the regexp extension distributed with SQLite (ext/misc/regexp.c),
+	// which implements the REGEXP operator. It has no flags (so flags are emulated, and FlagMultiLine is
+	// unsupported), no non-capturing, named or lazy groups, no \B, and no ASCII or Unicode classes. Since
+	// REGEXP does not report submatches, all groups are rendered as (...). \A and \z are rendered as ^
+	// and $, and Any is rendered as [^\n]. Code points above U+FFFF cannot be used in classes.
+	DialectSQLite = Dialect{
+		name:                 "SQLite",
+		flagLetters:          map[Flag]byte{},
+		missing:              FeatureFlagGroups | FeatureNamedGroups | FeatureUnicodeClasses | FeatureASCIIClasses | FeatureLazyQuantifiers,
+		perlClasses:          "dw",
+		bmpOnly:              true,
+		codePointFormat:      `\u%04X`,
+		noTextAnchors:        true,
+		dotMatchesNewLine:    true,
+		boundaryEscapes:      map[string]string{`\B`: ""},
+		noNonCapturingGroups: true,
+		notes:                sqliteNotes,
+	}
+)
+
+// ToSQLLiteral renders re in the dialect and quotes it as an SQL string literal, e.g. for use as the
+// pattern of REGEXP_LIKE. Single quotes are doubled, as are backslashes for DialectMySQL (whose string
+// literals use backslash escapes, unless the NO_BACKSLASH_ESCAPES SQL mode is enabled).
+func ToSQLLiteral(re Regexp, d Dialect) (string, error) {
+	pattern, err := d.Render(re)
+	if err != nil {
+		return "", err
+	}
+	if d.sqlBackslashEscapes {
+		pattern = strings.Replace(pattern, `\`, `\\`, -1)
+	}
+	return "'" + strings.Replace(pattern, "'", "''", -1) + "'", nil
+}
+
+func mysqlNotes(node Regexp, active Flag) string {
+	switch node := node.(type) {
+	case literalRegexp, CharClass:
+		if l, ok := node.(literalRegexp); ok && !l.literal {
+			return ""
+		}
+		if active&FlagCaseInsensitive == 0 && affectedBy(node, FlagCaseInsensitive) {
+			return "matches case-insensitively if the input has a case-insensitive collation, unless 'c' is passed as the match type"
+		}
+	case anchorRegexp:
+		if node.re == `\b` || node.re == `\B` {
+			return "ICU word boundaries treat Unicode letters and digits as word characters"
+		}
+	}
+	return ""
+}
+
+func postgresNotes(node Regexp, active Flag) string {
+	switch node := node.(type) {
+	case anchorRegexp:
+		if node.re == `\b` || node.re == `\B` {
+			return "word boundaries use the word characters of the database's locale"
+		}
+	case repeatedRegexp:
+		if node.ungreedy != (active&FlagUngreedy != 0) {
+			return "the first quantifier determines the greediness of the entire expression, which can change the submatches (but not whether it matches)"
+		}
+	}
+	return ""
+}
+
+func sqliteNotes(node Regexp, active Flag) string {
+	if l, ok := node.(literalRegexp); ok && !l.literal {
+		return "raw regular expressions must only use the syntax supported by the regexp extension"
+	}
+	return ""
+}
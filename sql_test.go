@@ -0,0 +1,289 @@
+package regen_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestToLike(t *testing.T) {
+	tests := []struct {
+		description string
+		re          regen.Regexp
+		expected    string
+		unsupported bool
+	}{
+		{
+			description: "Unanchored expressions are surrounded by %",
+			re:          regen.String("foo"),
+			expected:    `%foo%`,
+		},
+		{
+			description: "Anchors are removed",
+			re:          regen.Sequence(regen.TextStart, regen.String("foo"), regen.Any.Repeat(), regen.TextEnd),
+			expected:    `foo%`,
+		},
+		{
+			description: "Any becomes _",
+			re:          regen.Sequence(regen.LineStart, regen.String("a"), regen.Any, regen.Any.Repeat().Exactly(2), regen.Any.Repeat().Min(1)),
+			expected:    `a____%`,
+		},
+		{
+			description: "Wildcards in literals are escaped",
+			re:          regen.Sequence(regen.TextStart, regen.String(`50%_\`), regen.TextEnd),
+			expected:    `50\%\_\\`,
+		},
+		{
+			description: "Character classes are unsupported",
+			re:          regen.Digit,
+			unsupported: true,
+		},
+	}
+	for _, tt := range tests {
+		actual, err := regen.ToLike(tt.re)
+		checkSQLResult(t, "LIKE", tt.description, actual, err, tt.expected, tt.unsupported)
+	}
+}
+
+func TestToSimilarTo(t *testing.T) {
+	tests := []struct {
+		description string
+		re          regen.Regexp
+		expected    string
+		unsupported bool
+	}{
+		{
+			description: "Alternations, groups and repetitions are supported",
+			re: regen.Sequence(
+				regen.TextStart,
+				regen.OneOf(regen.String("a.b"), regen.String("c")).Repeat().Min(2),
+				regen.String("xy").Optional(),
+				regen.TextEnd,
+			),
+			expected: `(a.b|c){2,}(xy)?`,
+		},
+		{
+			description: "Character classes are supported, and Perl classes are expanded",
+			re:          regen.Sequence(regen.Union(regen.CharRange('a', 'z'), regen.Digit).Repeat().Min(1), regen.Whitespace.Negate()),
+			expected:    `%[a-z0-9]+[^\t\n\f\r ]%`,
+		},
+		{
+			description: "Metacharacters are escaped",
+			re:          regen.Sequence(regen.TextStart, regen.String("(100%)*"), regen.TextEnd),
+			expected:    `\(100\%\)\*`,
+		},
+		{
+			description: "Flags are unsupported",
+			re:          regen.String("a").Group().SetFlags(regen.FlagCaseInsensitive),
+			unsupported: true,
+		},
+		{
+			description: "Anchors in the middle of an expression are unsupported",
+			re:          regen.Sequence(regen.String("a"), regen.LineEnd, regen.String("b")),
+			unsupported: true,
+		},
+	}
+	for _, tt := range tests {
+		actual, err := regen.ToSimilarTo(tt.re)
+		checkSQLResult(t, "SIMILAR TO", tt.description, actual, err, tt.expected, tt.unsupported)
+	}
+}
+
+func checkSQLResult(t *testing.T, syntax, description, actual string, err error, expected string, unsupported bool) {
+	t.Helper()
+	if unsupported {
+		var unsupportedErr *regen.UnsupportedError
+		if !errors.As(err, &unsupportedErr) {
+			t.Errorf(`%s test "%s" failed: expected an UnsupportedError, got %v`, syntax, description, err)
+		}
+		return
+	}
+	if err != nil {
+		t.Errorf(`%s test "%s" failed: unexpected error: %v`, syntax, description, err)
+		return
+	}
+	if actual != expected {
+		t.Errorf(`%s test "%s" failed: got "%s", expected "%s"`, syntax, description, actual, expected)
+	}
+}
+
+func TestFromLike(t *testing.T) {
+	tests := []struct {
+		description string
+		pattern     string
+		escape      rune
+		expected    string
+		matches     []string
+		nonMatches  []string
+	}{
+		{
+			description: "Wildcards are converted",
+			pattern:     `a%b_`,
+			escape:      '\\',
+			expected:    `(?s:\Aa.*b.\z)`,
+			matches:     []string{"ab!", "a\nxyzbc"},
+			nonMatches:  []string{"ab", "xab!"},
+		},
+		{
+			description: "Escaped wildcards are literals",
+			pattern:     `100\%`,
+			escape:      '\\',
+			expected:    `(?s:\A100%\z)`,
+			matches:     []string{"100%"},
+			nonMatches:  []string{"1000"},
+		},
+		{
+			description: "Escape characters are literal without an escape",
+			pattern:     `a\b`,
+			expected:    `(?s:\Aa\\b\z)`,
+			matches:     []string{`a\b`},
+		},
+	}
+	for _, tt := range tests {
+		re := regen.FromLike(tt.pattern, tt.escape)
+		if actual := re.Regexp(); actual != tt.expected {
+			t.Errorf(`FromLike test "%s" failed: got "%s", expected "%s"`, tt.description, actual, tt.expected)
+			continue
+		}
+		compiled := regexp.MustCompile(re.Regexp())
+		for _, s := range tt.matches {
+			if !compiled.MatchString(s) {
+				t.Errorf(`FromLike test "%s" failed: expected "%s" to match`, tt.description, s)
+			}
+		}
+		for _, s := range tt.nonMatches {
+			if compiled.MatchString(s) {
+				t.Errorf(`FromLike test "%s" failed: expected "%s" not to match`, tt.description, s)
+			}
+		}
+	}
+}
+
+func TestSQLDialects(t *testing.T) {
+	tests := []struct {
+		description string
+		dialect     regen.Dialect
+		re          regen.Regexp
+		expected    string
+		unsupported bool
+	}{
+		{
+			description: "MySQL expands Perl classes and keeps named groups and flags",
+			dialect:     regen.DialectMySQL,
+			re: regen.Sequence(
+				regen.TextStart,
+				regen.Digit.Repeat().Min(1).Group().CaptureAs("id"),
+				regen.String("x").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+				regen.Any,
+				regen.LineEnd,
+			),
+			expected: `\A(?<id>[0-9]+)(?i:x)[^\n]\z`,
+		},
+		{
+			description: "MySQL does not support FlagUngreedy",
+			dialect:     regen.DialectMySQL,
+			re:          regen.String("a").Repeat().Group().SetFlags(regen.FlagUngreedy),
+			unsupported: true,
+		},
+		{
+			description: "PostgreSQL emulates flags and drops group names",
+			dialect:     regen.DialectPostgreSQL,
+			re: regen.Sequence(
+				regen.TextStart,
+				regen.String("ab").Group().CaptureAs("name").SetFlags(regen.FlagCaseInsensitive),
+				regen.Any.Repeat(),
+				regen.ASCIIBoundary,
+				regen.WordCharacter,
+				regen.TextEnd,
+			),
+			expected: `^([Aa][Bb])[^\n]*\y[0-9A-Za-z_]$`,
+		},
+		{
+			description: "PostgreSQL does not support multi-line anchors",
+			dialect:     regen.DialectPostgreSQL,
+			re:          regen.LineStart.Group().NoCapture().SetFlags(regen.FlagMultiLine),
+			unsupported: true,
+		},
+		{
+			description: "SQLite renders all groups as capturing groups",
+			dialect:     regen.DialectSQLite,
+			re: regen.Sequence(
+				regen.LineStart,
+				regen.Sequence(regen.Digit, regen.String("-")).Group().NoCapture().Repeat().Exactly(2),
+				regen.String("z").Group().CaptureAs("z"),
+				regen.CharSet('é', '\t'),
+			),
+			expected: `^(\d-){2}(z)[é\u0009]`,
+		},
+		{
+			description: "SQLite does not support lazy quantifiers",
+			dialect:     regen.DialectSQLite,
+			re:          regen.Any.Repeat().Ungreedy(),
+			unsupported: true,
+		},
+		{
+			description: "SQLite does not support \\B",
+			dialect:     regen.DialectSQLite,
+			re:          regen.NotASCIIBoundary,
+			unsupported: true,
+		},
+	}
+	for _, tt := range tests {
+		actual, err := tt.dialect.Render(tt.re)
+		checkSQLResult(t, tt.dialect.String(), tt.description, actual, err, tt.expected, tt.unsupported)
+	}
+}
+
+func TestToSQLLiteral(t *testing.T) {
+	re := regen.Sequence(regen.String("it's"), regen.Digit)
+	for dialect, expected := range map[string]string{
+		"MySQL":      `'it''s[0-9]'`,
+		"PostgreSQL": `'it''s[0-9]'`,
+		"SQLite":     `'it''s\d'`,
+	} {
+		d := map[string]regen.Dialect{
+			"MySQL":      regen.DialectMySQL,
+			"PostgreSQL": regen.DialectPostgreSQL,
+			"SQLite":     regen.DialectSQLite,
+		}[dialect]
+		actual, err := regen.ToSQLLiteral(re, d)
+		checkSQLResult(t, dialect, "string literal", actual, err, expected, false)
+	}
+	actual, err := regen.ToSQLLiteral(regen.String("a.b"), regen.DialectMySQL)
+	checkSQLResult(t, "MySQL", "backslashes are escaped", actual, err, `'a\\.b'`, false)
+}
+
+func TestSQLDialectNotes(t *testing.T) {
+	lazy := regen.Digit.Repeat().Ungreedy()
+	literal := regen.String("id")
+	re := regen.Sequence(
+		literal,
+		regen.String("42"),
+		regen.String("ok").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+		regen.ASCIIBoundary,
+		lazy,
+		regen.Raw(`\d`),
+	)
+	for _, tt := range []struct {
+		dialect regen.Dialect
+		nodes   []regen.Regexp
+	}{
+		{dialect: regen.DialectMySQL, nodes: []regen.Regexp{literal, regen.ASCIIBoundary}},
+		{dialect: regen.DialectPostgreSQL, nodes: []regen.Regexp{regen.ASCIIBoundary, lazy}},
+		{dialect: regen.DialectSQLite, nodes: []regen.Regexp{regen.Raw(`\d`)}},
+		{dialect: regen.DialectRE2},
+	} {
+		notes := tt.dialect.Notes(re)
+		if len(notes) != len(tt.nodes) {
+			t.Errorf("%s: expected %d notes, got %v", tt.dialect, len(tt.nodes), notes)
+			continue
+		}
+		for i, note := range notes {
+			if note.Node.Regexp() != tt.nodes[i].Regexp() || note.Note == "" {
+				t.Errorf("%s: expected note %d to be for %s, got %s (%q)", tt.dialect, i, tt.nodes[i].Regexp(), note.Node.Regexp(), note.Note)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package regen
+
+// The expressions of this package implement fmt.Stringer and fmt.GoStringer, so that they are printed
+// meaningfully by %v and %#v (e.g. in logs and debuggers): String returns the rendered regular
+// expression, and GoString returns the builder code that constructs the expression (see GoCode) on a
+// single line.
+
+// goString returns the builder code for re on a single line
+func goString(re Regexp) string {
+	return goExpr(re, 0, false)
+}
+
+func (l literalRegexp) String() string {
+	return l.Regexp()
+}
+
+func (l literalRegexp) GoString() string {
+	return goString(l)
+}
+
+func (a anyRegexp) String() string {
+	return a.Regexp()
+}
+
+func (a anyRegexp) GoString() string {
+	return goString(a)
+}
+
+func (a anchorRegexp) String() string {
+	return a.Regexp()
+}
+
+func (a anchorRegexp) GoString() string {
+	return goString(a)
+}
+
+func (m multiRegexp) String() string {
+	return m.Regexp()
+}
+
+func (m multiRegexp) GoString() string {
+	return goString(m)
+}
+
+func (g groupedRegexp) String() string {
+	return g.Regexp()
+}
+
+func (g groupedRegexp) GoString() string {
+	return goString(g)
+}
+
+func (r repeatedRegexp) String() string {
+	return r.Regexp()
+}
+
+func (r repeatedRegexp) GoString() string {
+	return goString(r)
+}
+
+func (a annotatedRegexp) String() string {
+	return a.Regexp()
+}
+
+func (a annotatedRegexp) GoString() string {
+	return goString(a)
+}
+
+func (c charSetRegexp) String() string {
+	return c.Regexp()
+}
+
+func (c charSetRegexp) GoString() string {
+	return goString(c)
+}
+
+func (c charRangeRegexp) String() string {
+	return c.Regexp()
+}
+
+func (c charRangeRegexp) GoString() string {
+	return goString(c)
+}
+
+func (a asciiCharClassRegexp) String() string {
+	return a.Regexp()
+}
+
+func (a asciiCharClassRegexp) GoString() string {
+	return goString(a)
+}
+
+func (u unicodeCharClassRegexp) String() string {
+	return u.Regexp()
+}
+
+func (u unicodeCharClassRegexp) GoString() string {
+	return goString(u)
+}
+
+func (p perlCharClassRegexp) String() string {
+	return p.Regexp()
+}
+
+func (p perlCharClassRegexp) GoString() string {
+	return goString(p)
+}
+
+func (u unionCharClassRegexp) String() string {
+	return u.Regexp()
+}
+
+func (u unionCharClassRegexp) GoString() string {
+	return goString(u)
+}
@@ -0,0 +1,55 @@
+package regen_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestStringers(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		re          regen.Regexp
+		v           string
+		goSyntax    string
+	}{
+		{
+			description: "literals",
+			re:          regen.String("a.b"),
+			v:           `a\.b`,
+			goSyntax:    `regen.String("a.b")`,
+		},
+		{
+			description: "anchors",
+			re:          regen.LineStart,
+			v:           `^`,
+			goSyntax:    `regen.LineStart`,
+		},
+		{
+			description: "classes",
+			re:          regen.Union(regen.CharSet('_'), regen.CharRange('a', 'z')).Repeat().Min(1),
+			v:           `[_a-z]+`,
+			goSyntax:    `regen.Union(regen.CharSet('_'), regen.CharRange('a', 'z')).Repeat().Min(1)`,
+		},
+		{
+			description: "long expressions are written on a single line",
+			re: regen.Sequence(
+				regen.TextStart,
+				regen.OneOf(regen.String("alpha"), regen.String("beta"), regen.String("gamma")).Group().CaptureAs("word"),
+				regen.Annotate(regen.Whitespace.Negate().Repeat(), "the rest"),
+			),
+			v:        `\A(?P<word>alpha|beta|gamma)\S*`,
+			goSyntax: `regen.Sequence(regen.TextStart, regen.OneOf(regen.String("alpha"), regen.String("beta"), regen.String("gamma")).Group().CaptureAs("word"), regen.Annotate(regen.Whitespace.Negate().Repeat(), "the rest"))`,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			if actual := fmt.Sprintf("%v", tt.re); actual != tt.v {
+				t.Errorf("expected %%v to be %s, got %s", tt.v, actual)
+			}
+			if actual := fmt.Sprintf("%#v", tt.re); actual != tt.goSyntax {
+				t.Errorf("expected %%#v to be %s, got %s", tt.goSyntax, actual)
+			}
+		})
+	}
+}
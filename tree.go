@@ -0,0 +1,155 @@
+package regen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Walk traverses re in depth-first order, calling fn for each node, starting with re itself.
+// If fn returns false, the children of that node are not visited.
+func Walk(re Regexp, fn func(node Regexp) bool) {
+	if !fn(re) {
+		return
+	}
+	for _, child := range Children(re) {
+		Walk(child, fn)
+	}
+}
+
+// Transform rewrites re from the bottom up: the children of each node are transformed first, and
+// then rewriter is called with the node (updated with its transformed children), returning the node
+// that replaces it. rewriter should return its argument for nodes that it doesn't change. For example,
+// to remove all capturing groups:
+//
+//	regen.Transform(re, func(node regen.Regexp) regen.Regexp {
+//		if g, ok := node.(regen.GroupedRegexp); ok {
+//			return g.NoCapture()
+//		}
+//		return node
+//	})
+//
+// If a member of a Union is replaced with a Regexp that is not a CharClass, the Union is replaced
+// with a OneOf of its members. Transform panics if the Union is negated, since this can't be represented.
+func Transform(re Regexp, rewriter func(Regexp) Regexp) Regexp {
+	if kids := Children(re); len(kids) > 0 {
+		transformed := make([]Regexp, len(kids))
+		for i, child := range kids {
+			transformed[i] = Transform(child, rewriter)
+		}
+		re = withChildren(re, transformed)
+	}
+	return rewriter(re)
+}
+
+// withChildren returns a copy of re with its children replaced
+func withChildren(re Regexp, kids []Regexp) Regexp {
+	switch re := re.(type) {
+	case multiRegexp:
+		re.res, re.cache = kids, nil
+		return re
+	case groupedRegexp:
+		re.re, re.cache = kids[0], nil
+		return re
+	case repeatedRegexp:
+		re.re, re.cache = kids[0], nil
+		return re
+	case annotatedRegexp:
+		re.re = kids[0]
+		return re
+	case unionCharClassRegexp:
+		classes := make([]CharClass, len(kids))
+		for i, kid := range kids {
+			class, ok := kid.(CharClass)
+			if !ok {
+				if re.negated {
+					panic("regen: a member of a negated Union was transformed into " + kid.Regexp() + ", which is not a CharClass")
+				}
+				return OneOf(kids...).Group().NoCapture()
+			}
+			classes[i] = class
+		}
+		re.charClasses = classes
+		return re
+	}
+	return re
+}
+
+// Equal returns true if a and b have the same structure: the same kinds of nodes, with the same
+// literals, groups, flags and quantifiers, in the same order. Annotations are ignored, since they do
+// not affect matching. Expressions that are structured differently are not equal, even if they match
+// the same strings (see Equivalent).
+func Equal(a, b Regexp) bool {
+	return structure(a) == structure(b)
+}
+
+// Hash returns a hash of the structure of re, such that Equal expressions have the same hash. The hash
+// does not depend on the process, so it can be persisted, e.g. as part of a cache key.
+func Hash(re Regexp) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(structure(re)))
+	return h.Sum64()
+}
+
+// structure returns an unambiguous encoding of the structure of re
+func structure(re Regexp) string {
+	var sb strings.Builder
+	writeStructure(&sb, re)
+	return sb.String()
+}
+
+// structuralHash returns a hex-encoded SHA-256 digest of the structure of re. Expressions with the
+// same structure have the same hash, regardless of annotations.
+func structuralHash(re Regexp) string {
+	digest := sha256.Sum256([]byte(structure(re)))
+	return hex.EncodeToString(digest[:])
+}
+
+// writeStructure writes an unambiguous encoding of the structure of re to sb
+func writeStructure(sb *strings.Builder, re Regexp) {
+	switch re := re.(type) {
+	case annotatedRegexp:
+		writeStructure(sb, re.re)
+		return
+	case literalRegexp:
+		if re.literal {
+			sb.WriteString("L" + strconv.Quote(re.value))
+		} else {
+			sb.WriteString("R" + strconv.Quote(re.re))
+		}
+		return
+	case anyRegexp:
+		sb.WriteString(".")
+		return
+	case anchorRegexp:
+		sb.WriteString("A" + strconv.Quote(re.re))
+		return
+	case CharClass:
+		sb.WriteString("C" + strconv.Quote(re.Regexp()))
+		return
+	case multiRegexp:
+		if re.separator == "" {
+			sb.WriteString("S")
+		} else {
+			sb.WriteString("O")
+		}
+	case groupedRegexp:
+		sb.WriteString("G" + strconv.Quote(re.name) + strconv.Quote(re.balance))
+		sb.WriteString(strconv.FormatBool(re.noCapture) + "," + strconv.Itoa(int(re.setFlags)) + "," + strconv.Itoa(int(re.unsetFlags)))
+	case repeatedRegexp:
+		sb.WriteString("Q" + re.quantifier(re.ungreedy))
+	default:
+		sb.WriteString("X" + strconv.Quote(re.Regexp()))
+		return
+	}
+	sb.WriteByte('(')
+	for i, child := range Children(re) {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		writeStructure(sb, child)
+	}
+	sb.WriteByte(')')
+}
@@ -0,0 +1,174 @@
+package regen_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestWalk(t *testing.T) {
+	re := regen.Sequence(
+		regen.String("id=").Group().CaptureAs("prefix"),
+		regen.OneOf(
+			regen.Digit.Repeat().Min(1).Group().CaptureAs("number"),
+			regen.Annotate(regen.String("none"), "missing"),
+		).Group().CaptureAs("id"),
+	)
+
+	var names, literals []string
+	regen.Walk(re, func(node regen.Regexp) bool {
+		if g, ok := node.(regen.GroupedRegexp); ok {
+			if node.Regexp() == `(?P<number>\d+)` {
+				// don't descend
+				return false
+			}
+			names = append(names, g.Regexp())
+		}
+		if node.Regexp() == "none" || node.Regexp() == "id=" {
+			literals = append(literals, node.Regexp())
+		}
+		return true
+	})
+
+	expectedNames := []string{`(?P<prefix>id=)`, `(?P<id>(?P<number>\d+)|none)`}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("expected groups %v, got %v", expectedNames, names)
+	}
+	// the annotation and its contained literal are both visited
+	expectedLiterals := []string{"id=", "none", "none"}
+	if !reflect.DeepEqual(literals, expectedLiterals) {
+		t.Errorf("expected literals %v, got %v", expectedLiterals, literals)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	re := regen.Sequence(
+		regen.String("id=").Group(),
+		regen.OneOf(
+			regen.Digit.Repeat().Min(1).Group().CaptureAs("number"),
+			regen.String("none"),
+		).Group().CaptureAs("id"),
+		regen.Union(regen.CharSet('.'), regen.Whitespace).Optional(),
+	)
+
+	for _, tt := range []struct {
+		desc     string
+		rewriter func(regen.Regexp) regen.Regexp
+		expected string
+	}{
+		{
+			desc: "prefix group names",
+			rewriter: func(node regen.Regexp) regen.Regexp {
+				if g, ok := node.(regen.GroupNode); ok && g.Name() != "" {
+					return g.CaptureAs("req_" + g.Name())
+				}
+				return node
+			},
+			expected: `(id=)(?P<req_id>(?P<req_number>\d+)|none)[.\s]?`,
+		},
+		{
+			desc: "case-insensitive literals",
+			rewriter: func(node regen.Regexp) regen.Regexp {
+				if regen.Kind(node) == regen.KindString {
+					return node.Group().NoCapture().SetFlags(regen.FlagCaseInsensitive)
+				}
+				return node
+			},
+			expected: `((?i:id=))(?P<id>(?P<number>\d+)|(?i:none))[.\s]?`,
+		},
+		{
+			desc: "strip capturing groups",
+			rewriter: func(node regen.Regexp) regen.Regexp {
+				if g, ok := node.(regen.GroupedRegexp); ok {
+					return g.NoCapture()
+				}
+				return node
+			},
+			expected: `(?:id=)(?:(?:\d+)|none)[.\s]?`,
+		},
+		{
+			desc: "union member replaced",
+			rewriter: func(node regen.Regexp) regen.Regexp {
+				if regen.Kind(node) == regen.KindPerlClass {
+					return regen.String(" ")
+				}
+				return node
+			},
+			expected: `(id=)(?P<id>(?P<number> +)|none)(?:[.]| )?`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			if actual := regen.Transform(re, tt.rewriter).Regexp(); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+	if actual := re.Regexp(); actual != `(id=)(?P<id>(?P<number>\d+)|none)[.\s]?` {
+		t.Errorf("expected original to be unchanged, got %s", actual)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	re := func() regen.Regexp {
+		return regen.Sequence(
+			regen.String("id=").Group().CaptureAs("prefix"),
+			regen.Digit.Repeat().Min(1).Ungreedy(),
+		)
+	}
+	for _, tt := range []struct {
+		desc  string
+		a, b  regen.Regexp
+		equal bool
+	}{
+		{
+			desc:  "same structure",
+			a:     re(),
+			b:     re(),
+			equal: true,
+		},
+		{
+			desc:  "annotations are ignored",
+			a:     re(),
+			b:     regen.Annotate(re(), "an identifier"),
+			equal: true,
+		},
+		{
+			desc: "different group names",
+			a:    regen.String("a").Group().CaptureAs("x"),
+			b:    regen.String("a").Group().CaptureAs("y"),
+		},
+		{
+			desc: "different quantifiers",
+			a:    regen.Digit.Repeat().Min(1),
+			b:    regen.Digit.Repeat().Min(1).Ungreedy(),
+		},
+		{
+			desc: "different flags",
+			a:    regen.String("a").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+			b:    regen.String("a").Group().NoCapture().UnsetFlags(regen.FlagCaseInsensitive),
+		},
+		{
+			desc: "same rendering but different structure",
+			a:    regen.String("a+"),
+			b:    regen.Raw(`a\+`),
+		},
+		{
+			desc: "equivalent but different structure",
+			a:    regen.OneOfStrings("a", "b").Group().NoCapture(),
+			b:    regen.CharSet('a', 'b'),
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			if equal := regen.Equal(tt.a, tt.b); equal != tt.equal {
+				t.Errorf("expected Equal to return %v, got %v", tt.equal, equal)
+			}
+			if tt.equal && regen.Hash(tt.a) != regen.Hash(tt.b) {
+				t.Errorf("expected equal expressions to have the same hash")
+			}
+			if !tt.equal && regen.Hash(tt.a) == regen.Hash(tt.b) {
+				t.Errorf("expected expressions to have different hashes")
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package regen
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// OneOfStrings returns a new Regexp that matches any of the literal choices.
+// Unlike OneOf, the choices are factored into a trie so that common prefixes are only matched once
+// (e.g. "foo", "foobar" and "fizz" results in f(?:izz|oo(?:bar)?)), and the result is never a capturing group.
+// Since no two alternatives share a prefix, the order of choices does not matter, but the result prefers
+// the longest choice that matches: "b" and "bb" results in bb?, which matches all of "bb", whereas
+// OneOf(String("b"), String("bb")) only matches its first character. The two only match the same text
+// when what follows the choices decides where they end, e.g. TextEnd.
+func OneOfStrings(choices ...string) Regexp {
+	words := make([]string, len(choices))
+	copy(words, choices)
+	sort.Strings(words)
+	unique := words[:0]
+	for i, word := range words {
+		if i == 0 || word != words[i-1] {
+			unique = append(unique, word)
+		}
+	}
+	if len(unique) == 0 {
+		return Raw(`[^\x00-\x{10FFFF}]`)
+	}
+	return factorStrings(unique, 0)
+}
+
+// factorStrings builds a trie-factored Regexp out of a sorted list of unique words that share their
+// first offset bytes. Each branch of the trie is factored in place as a chunk of words, rather than
+// copying the suffixes of its words, so large word lists don't allocate a slice for every branch.
+func factorStrings(words []string, offset int) Regexp {
+	optional := false
+	if len(words[0]) == offset {
+		optional = true
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return String("")
+	}
+
+	var leaves []rune
+	var alternatives []Regexp
+	for i := 0; i < len(words); {
+		first, size := utf8.DecodeRuneInString(words[i][offset:])
+		j := i + 1
+		for j < len(words) && strings.HasPrefix(words[j][offset:], words[i][offset:offset+size]) {
+			j++
+		}
+		branch := words[i:j]
+		i = j
+		if len(branch) == 1 && len(branch[0]) == offset+size {
+			leaves = append(leaves, first)
+			continue
+		}
+		prefix := commonPrefix(branch, offset)
+		alternatives = append(alternatives, Sequence(String(prefix), factorStrings(branch, offset+len(prefix))))
+	}
+	switch len(leaves) {
+	case 0:
+	case 1:
+		alternatives = append(alternatives, String(string(leaves[0])))
+	default:
+		alternatives = append(alternatives, CharSet(leaves...))
+	}
+
+	var re Regexp
+	if len(alternatives) == 1 {
+		re = alternatives[0]
+	} else {
+		re = OneOf(alternatives...).Group().NoCapture()
+	}
+	if optional {
+		if len(leaves) == len(words) {
+			// A single rune or CharSet can be made optional without being grouped
+			return re.Optional()
+		}
+		return re.Group().NoCapture().Optional()
+	}
+	return re
+}
+
+// commonPrefix returns the longest prefix (on rune boundaries) shared by all of the words after their
+// first offset bytes
+func commonPrefix(words []string, offset int) string {
+	prefix := words[0][offset:]
+	for _, word := range words[1:] {
+		word = word[offset:]
+		n := 0
+		for n < len(prefix) && n < len(word) && prefix[n] == word[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	for len(prefix) > 0 && !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix
+}
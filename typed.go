@@ -0,0 +1,67 @@
+//go:build go1.18
+// +build go1.18
+
+package regen
+
+import "fmt"
+
+// TypedGroup is a named capturing group whose text is converted into a T by a parse function, so that
+// the conversion is defined alongside the pattern rather than wherever the group is extracted
+type TypedGroup[T any] struct {
+	name  string
+	group GroupedRegexp
+	parse func(string) (T, error)
+}
+
+// TypedCapture returns a group named name around re, whose captured text is converted by parse, e.g.
+//
+//	port := regen.TypedCapture("port", regen.Digit.Repeat().Min(1), strconv.Atoi)
+//	m := regen.MustCompile(regen.Sequence(host, regen.String(":"), port.Expr()))
+//	result, _ := m.FindResult("localhost:8080")
+//	n, ok, err := port.Value(result) // n is the int 8080
+func TypedCapture[T any](name string, re Regexp, parse func(string) (T, error)) TypedGroup[T] {
+	return TypedGroup[T]{name: name, group: re.Group().CaptureAs(name), parse: parse}
+}
+
+// Name returns the name of the group
+func (g TypedGroup[T]) Name() string {
+	return g.name
+}
+
+// Expr returns the group, to compose it into larger expressions
+func (g TypedGroup[T]) Expr() Regexp {
+	return g.group
+}
+
+// Parse converts text captured by the group
+func (g TypedGroup[T]) Parse(text string) (T, error) {
+	value, err := g.parse(text)
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("regen: group %s: %w", g.name, err)
+	}
+	return value, nil
+}
+
+// Value returns the converted text captured by the group in r, or false if the group didn't
+// participate in the match
+func (g TypedGroup[T]) Value(r MatchResult) (T, bool, error) {
+	c, ok := r.Capture(g.name)
+	if !ok {
+		var zero T
+		return zero, false, nil
+	}
+	value, err := g.Parse(c.Text)
+	return value, err == nil, err
+}
+
+// Find returns the converted text captured by the group in the first match of m in text. It returns
+// false if there is no match, or the group didn't participate in it.
+func (g TypedGroup[T]) Find(m *Matcher, text string) (T, bool, error) {
+	r, ok := m.FindResult(text)
+	if !ok {
+		var zero T
+		return zero, false, nil
+	}
+	return g.Value(r)
+}
@@ -0,0 +1,48 @@
+//go:build go1.18
+// +build go1.18
+
+package regen_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestTypedCapture(t *testing.T) {
+	port := regen.TypedCapture("port", regen.Digit.Repeat().Min(1), strconv.Atoi)
+	at := regen.TypedCapture("at", regen.Whitespace.Negate().Repeat().Min(1), func(s string) (time.Time, error) {
+		return time.Parse(time.RFC3339, s)
+	})
+	m := regen.MustCompile(regen.Sequence(
+		regen.WordCharacter.Repeat().Min(1),
+		regen.Sequence(regen.String(":"), port.Expr()).Optional(),
+		regen.Sequence(regen.String(" at "), at.Expr()).Optional(),
+	))
+
+	n, ok, err := port.Find(m, "localhost:8080 at 2024-03-07T10:00:00Z")
+	if err != nil || !ok || n != 8080 {
+		t.Errorf("expected 8080, got %d, %t, %v", n, ok, err)
+	}
+	result, _ := m.FindResult("localhost:8080 at 2024-03-07T10:00:00Z")
+	when, ok, err := at.Value(result)
+	if expected := time.Date(2024, time.March, 7, 10, 0, 0, 0, time.UTC); err != nil || !ok || !when.Equal(expected) {
+		t.Errorf("expected %v, got %v, %t, %v", expected, when, ok, err)
+	}
+
+	if _, ok, err := port.Find(m, "localhost"); ok || err != nil {
+		t.Errorf("expected the group not to participate, got %t, %v", ok, err)
+	}
+	if _, ok, err := port.Find(m, "!"); ok || err != nil {
+		t.Errorf("expected no match, got %t, %v", ok, err)
+	}
+	_, ok, err = port.Find(m, "localhost:99999999999999999999")
+	if expected := `regen: group port: strconv.Atoi: parsing "99999999999999999999": value out of range`; ok || err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %t, %v", expected, ok, err)
+	}
+	if port.Name() != "port" || port.Expr().Regexp() != `(?P<port>\d+)` {
+		t.Errorf("unexpected group %s %s", port.Name(), port.Expr().Regexp())
+	}
+}
@@ -0,0 +1,428 @@
+package regen
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoMatch is returned by Unmarshal when the input does not match the expression
+var ErrNoMatch = errors.New("regen: input does not match")
+
+// Unmarshal matches re against input and stores the text captured by each named group in the
+// corresponding field of the struct pointed to by v. Fields are matched to groups using the name in
+// their regen tag, or their field name if they have no tag; fields tagged with regen:"-" are ignored.
+//
+// Fields may be strings, bools, integers, floats, times, durations (in the syntax of
+// time.ParseDuration), types with a converter (see RegisterConverter), or pointers to these. Pointer
+// fields are only set if their group participates in the match, so they can distinguish optional groups
+// that didn't match from empty ones.
+//
+// The tag may include options, separated by commas, that normalize the captured text before it is
+// converted:
+//
+//	trim     removes leading and trailing whitespace
+//	comma    removes the grouping separator ',' from numbers, e.g. 1,234.5
+//	comma=.  removes the grouping separator '.' and treats ',' as the decimal separator, e.g. 1.234,5
+//	lower    converts the text to lower case
+//	upper    converts the text to upper case
+//	layout='LAYOUT'  parses times using LAYOUT (see time.Parse), rather than time.RFC3339
+//
+// For example: Amount float64 `regen:"amount,trim,comma=."`, or
+// At time.Time `regen:"at,layout='Jan _2 15:04:05'"`
+//
+// Struct fields (and pointers to structs) are populated from groups whose names are prefixed by the
+// field's name and an underscore, e.g. the field Src struct{ IP string `regen:"ip"` } `regen:"src"` is
+// populated from the group src_ip. Pointers to structs are only set if at least one of their groups
+// participates in the match.
+//
+// Slice fields are populated from a group containing a repetition, such as items in
+// (?P<items>(?:(?P<items_key>\w+)=(?P<items_value>\w+),?)*). Since only the last iteration of a
+// repeated group is captured, the repeated sub-expression is matched against the text of the group
+// to find every iteration. Elements that are structs are populated from the groups in the repeated
+// sub-expression (using the slice field's name as the prefix, as above), while other elements are
+// converted from the text captured by the first named group in it, or its entire match if it has none.
+func Unmarshal(re Regexp, input string, v interface{}) error {
+	compiled, err := regexp.Compile(re.Regexp())
+	if err != nil {
+		return err
+	}
+	return unmarshal(re, compiled, input, v)
+}
+
+func unmarshal(re Regexp, compiled *regexp.Regexp, input string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("regen: Unmarshal requires a non-nil pointer to a struct, got %T", v)
+	}
+	groups, ok := submatchGroups(compiled, input)
+	if !ok {
+		return ErrNoMatch
+	}
+	u := unmarshaler{re: re}
+	_, err := u.unmarshalStruct(rv.Elem(), groups, "")
+	return err
+}
+
+// submatchGroups returns the text captured by each named group in the first match of re, omitting
+// groups that don't participate
+func submatchGroups(re *regexp.Regexp, input string) (map[string]string, bool) {
+	match := re.FindStringSubmatchIndex(input)
+	if match == nil {
+		return nil, false
+	}
+	return namedGroups(re, input, match), true
+}
+
+func namedGroups(re *regexp.Regexp, input string, match []int) map[string]string {
+	groups := make(map[string]string)
+	for i, name := range re.SubexpNames() {
+		if name != "" && match[2*i] >= 0 {
+			groups[name] = input[match[2*i]:match[2*i+1]]
+		}
+	}
+	return groups
+}
+
+type unmarshaler struct {
+	// re is the expression being matched, which is used to find the repeated sub-expressions of
+	// groups that populate slices
+	re Regexp
+}
+
+// unmarshalStruct populates the fields of v from groups, where the names of the groups for v's
+// fields begin with prefix. It returns true if any field was set.
+func (u unmarshaler) unmarshalStruct(v reflect.Value, groups map[string]string, prefix string) (bool, error) {
+	t := v.Type()
+	found := false
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+		tag, err := parseFieldTag(field)
+		if err != nil {
+			return false, err
+		}
+		if tag.name == "-" {
+			continue
+		}
+		name := prefix + tag.name
+		fv := v.Field(i)
+		switch {
+		case isStruct(fv.Type()):
+			ok, err := u.unmarshalNested(fv, groups, name+"_")
+			if err != nil {
+				return false, err
+			}
+			found = found || ok
+		case fv.Kind() == reflect.Slice && !hasConverter(fv.Type()):
+			value, ok := groups[name]
+			if !ok {
+				continue
+			}
+			if err := u.unmarshalSlice(fv, name, value, tag); err != nil {
+				return false, fmt.Errorf("regen: field %s: %v", field.Name, err)
+			}
+			found = true
+		default:
+			value, ok := groups[name]
+			if !ok {
+				continue
+			}
+			if err := setField(fv, tag.normalize(value), tag.layout); err != nil {
+				return false, fmt.Errorf("regen: field %s: %v", field.Name, err)
+			}
+			found = true
+		}
+	}
+	return found, nil
+}
+
+// isStruct returns true if t is a struct or a pointer to a struct that is populated from multiple
+// groups, rather than converted from a single group
+func isStruct(t reflect.Type) bool {
+	if hasConverter(t) {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// unmarshalNested populates a struct or pointer to a struct, only setting the pointer if one of the
+// struct's fields is set
+func (u unmarshaler) unmarshalNested(v reflect.Value, groups map[string]string, prefix string) (bool, error) {
+	if v.Kind() != reflect.Ptr {
+		return u.unmarshalStruct(v, groups, prefix)
+	}
+	elem := reflect.New(v.Type().Elem())
+	found, err := u.unmarshalStruct(elem.Elem(), groups, prefix)
+	if found && err == nil {
+		v.Set(elem)
+	}
+	return found, err
+}
+
+// unmarshalSlice populates the slice v with each iteration of the repetition within the group name,
+// which captured text
+func (u unmarshaler) unmarshalSlice(v reflect.Value, name, text string, tag fieldTag) error {
+	repeated, err := repeatedWithin(u.re, name)
+	if err != nil {
+		return err
+	}
+	compiled, err := regexp.Compile(repeated.Regexp())
+	if err != nil {
+		return err
+	}
+	var valueGroup string
+	for _, groupName := range compiled.SubexpNames() {
+		if groupName != "" {
+			valueGroup = groupName
+			break
+		}
+	}
+	slice := reflect.MakeSlice(v.Type(), 0, 0)
+	for _, match := range compiled.FindAllStringSubmatchIndex(text, -1) {
+		if match[0] == match[1] {
+			// an empty iteration, e.g. the end of the text
+			continue
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		groups := namedGroups(compiled, text, match)
+		if isStruct(elem.Type()) {
+			if _, err := u.unmarshalNested(elem, groups, name+"_"); err != nil {
+				return err
+			}
+		} else {
+			value := text[match[0]:match[1]]
+			if valueGroup != "" {
+				value = groups[valueGroup]
+			}
+			if err := setField(elem, tag.normalize(value), tag.layout); err != nil {
+				return err
+			}
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	v.Set(slice)
+	return nil
+}
+
+// repeatedWithin returns the sub-expression of the first repetition within the group with the given name
+func repeatedWithin(re Regexp, name string) (Regexp, error) {
+	var group Regexp
+	Walk(re, func(node Regexp) bool {
+		if g, ok := node.(groupedRegexp); ok && g.name == name && group == nil {
+			group = g.re
+		}
+		return group == nil
+	})
+	if group == nil {
+		return nil, fmt.Errorf("group %q is not in the expression", name)
+	}
+	var repeated Regexp
+	Walk(group, func(node Regexp) bool {
+		if r, ok := node.(repeatedRegexp); ok && repeated == nil {
+			repeated = r.re
+		}
+		return repeated == nil
+	})
+	if repeated == nil {
+		return nil, fmt.Errorf("group %q does not contain a repetition", name)
+	}
+	return repeated, nil
+}
+
+// fieldTag is the parsed regen tag of a struct field
+type fieldTag struct {
+	name string
+	trim bool
+	// groupSeparator is removed from numbers, if set
+	groupSeparator string
+	lower          bool
+	upper          bool
+	// layout is used to parse times, if set
+	layout string
+	// pattern, regexp, sep, charset, min and max are used by FromStruct
+	pattern        string
+	regexp         string
+	sep            string
+	hasSep         bool
+	charset        string
+	min, max       uint
+	hasMin, hasMax bool
+}
+
+func parseFieldTag(field reflect.StructField) (fieldTag, error) {
+	tag, ok := field.Tag.Lookup("regen")
+	if !ok {
+		return fieldTag{name: field.Name}, nil
+	}
+	parts := splitTag(tag)
+	ft := fieldTag{name: parts[0]}
+	if ft.name == "" {
+		ft.name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		key, value := opt, ""
+		if i := strings.Index(opt, "="); i >= 0 {
+			key, value = opt[:i], opt[i+1:]
+		}
+		switch key {
+		case "trim":
+			ft.trim = true
+		case "comma":
+			ft.groupSeparator = ","
+			if value != "" {
+				ft.groupSeparator = value
+			}
+		case "lower":
+			ft.lower = true
+		case "upper":
+			ft.upper = true
+		case "layout":
+			ft.layout = value
+		case "pattern":
+			ft.pattern = value
+		case "regexp":
+			ft.regexp = value
+		case "sep":
+			ft.sep, ft.hasSep = value, true
+		case "charset":
+			ft.charset = value
+		case "min", "max":
+			n, err := strconv.ParseUint(value, 10, 0)
+			if err != nil {
+				return fieldTag{}, fmt.Errorf("regen: field %s: %s must be a non-negative integer, got %q", field.Name, key, value)
+			}
+			if key == "min" {
+				ft.min, ft.hasMin = uint(n), true
+			} else {
+				ft.max, ft.hasMax = uint(n), true
+			}
+		default:
+			return fieldTag{}, fmt.Errorf("regen: field %s: unknown tag option %q", field.Name, opt)
+		}
+	}
+	return ft, nil
+}
+
+// splitTag splits a tag into its comma-separated options. Values may be enclosed in single quotes
+// to include commas, e.g. sep=','
+func splitTag(tag string) []string {
+	var parts []string
+	var sb strings.Builder
+	quoted := false
+	for _, r := range tag {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			parts = append(parts, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return append(parts, sb.String())
+}
+
+// normalize applies the options of the tag to the captured text
+func (ft fieldTag) normalize(s string) string {
+	if ft.trim {
+		s = strings.TrimSpace(s)
+	}
+	switch ft.groupSeparator {
+	case "":
+	case ".":
+		s = strings.Replace(s, ".", "", -1)
+		s = strings.Replace(s, ",", ".", -1)
+	default:
+		s = strings.Replace(s, ft.groupSeparator, "", -1)
+	}
+	if ft.lower {
+		s = strings.ToLower(s)
+	}
+	if ft.upper {
+		s = strings.ToUpper(s)
+	}
+	return s
+}
+
+// setField converts s to the type of v and stores it in v. Times are parsed using layout, or
+// time.RFC3339 if it is empty.
+func setField(v reflect.Value, s, layout string) error {
+	if ok, err := convert(v, s); ok {
+		return err
+	}
+	if v.Kind() == reflect.Ptr {
+		elem := reflect.New(v.Type().Elem())
+		if err := setField(elem.Elem(), s, layout); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+	switch v.Type() {
+	case timeType:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
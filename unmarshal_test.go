@@ -0,0 +1,224 @@
+package regen_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aoldershaw/regen"
+)
+
+var transactionPattern = regen.Sequence(
+	regen.LineStart,
+	regen.Raw(`[^;]*`).Group().CaptureAs("account"),
+	regen.String(";"),
+	regen.Raw(`[^;]*`).Group().CaptureAs("amount"),
+	regen.String(";"),
+	regen.Raw(`[^;]*`).Group().CaptureAs("currency"),
+	regen.Sequence(regen.String(";"), regen.Digit.Repeat().Min(1).Group().CaptureAs("count")).Optional(),
+	regen.LineEnd,
+)
+
+func TestUnmarshal(t *testing.T) {
+	type transaction struct {
+		Account  string  `regen:"account,trim,upper"`
+		Amount   float64 `regen:"amount,trim,comma=."`
+		Currency string  `regen:"currency,lower"`
+		Count    *int    `regen:"count"`
+		Ignored  string  `regen:"-"`
+		internal string
+	}
+
+	for _, tt := range []struct {
+		input    string
+		expected transaction
+		count    int
+	}{
+		{
+			input:    " ab-12 ; 1.234,50 ;EUR",
+			expected: transaction{Account: "AB-12", Amount: 1234.5, Currency: "eur"},
+		},
+		{
+			input:    "x;-3;USD;7",
+			expected: transaction{Account: "X", Amount: -3, Currency: "usd"},
+			count:    7,
+		},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			var actual transaction
+			if err := regen.Unmarshal(transactionPattern, tt.input, &actual); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual.Count != nil {
+				if *actual.Count != tt.count {
+					t.Errorf("expected count %d, got %d", tt.count, *actual.Count)
+				}
+				actual.Count = nil
+			} else if tt.count != 0 {
+				t.Errorf("expected count %d, got nil", tt.count)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_Comma(t *testing.T) {
+	var v struct {
+		Amount int64 `regen:"amount,comma"`
+	}
+	if err := regen.Unmarshal(regen.Raw(`.*`).Group().CaptureAs("amount"), "1,234,567", &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Amount != 1234567 {
+		t.Errorf("expected 1234567, got %d", v.Amount)
+	}
+}
+
+func TestUnmarshal_Times(t *testing.T) {
+	re := regen.Sequence(
+		regen.Raw(`\w{3} [ \d]\d \d\d:\d\d:\d\d`).Group().CaptureAs("at"),
+		regen.String(" took "),
+		regen.Raw(`\S+`).Group().CaptureAs("took"),
+		regen.Sequence(regen.String(" until "), regen.Raw(`\S+`).Group().CaptureAs("until")).Optional(),
+	)
+	type entry struct {
+		At    time.Time     `regen:"at,layout='Jan _2 15:04:05'"`
+		Took  time.Duration `regen:"took"`
+		Until *time.Time    `regen:"until"`
+	}
+
+	var v entry
+	if err := regen.Unmarshal(re, "Mar  7 09:15:02 took 1m30s until 2024-03-07T10:00:00Z", &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := time.Date(0, time.March, 7, 9, 15, 2, 0, time.UTC); !v.At.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, v.At)
+	}
+	if v.Took != 90*time.Second {
+		t.Errorf("expected 1m30s, got %v", v.Took)
+	}
+	if expected := time.Date(2024, time.March, 7, 10, 0, 0, 0, time.UTC); v.Until == nil || !v.Until.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, v.Until)
+	}
+
+	v = entry{}
+	if err := regen.Unmarshal(re, "Mar 17 09:15:02 took 5ms", &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Until != nil {
+		t.Errorf("expected the optional time to be nil, got %v", v.Until)
+	}
+
+	if err := regen.Unmarshal(re, "Mar 17 09:15:02 took 5 until tomorrow", &v); err == nil {
+		t.Error("expected an error for an invalid duration")
+	}
+	if err := regen.Unmarshal(re, "Mar 17 09:15:02 took 5s until tomorrow", &v); err == nil {
+		t.Error("expected an error for an invalid time")
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	type amount struct {
+		Amount int `regen:"amount"`
+	}
+	for _, tt := range []struct {
+		desc  string
+		input string
+		v     interface{}
+	}{
+		{desc: "no match", input: "nope", v: &amount{}},
+		{desc: "not a pointer", input: "a;1;USD", v: amount{}},
+		{desc: "conversion", input: "a;1.5;USD", v: &amount{}},
+		{desc: "unknown option", input: "a;1;USD", v: &struct {
+			Amount int `regen:"amount,round"`
+		}{}},
+		{desc: "unsupported type", input: "a;1;USD", v: &struct {
+			Amount map[string]string `regen:"amount"`
+		}{}},
+		{desc: "slice without repetition", input: "a;1;USD", v: &struct {
+			Amount []string `regen:"amount"`
+		}{}},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			if err := regen.Unmarshal(transactionPattern, tt.input, tt.v); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+	if err := regen.Unmarshal(transactionPattern, "nope", &amount{}); err != regen.ErrNoMatch {
+		t.Errorf("expected ErrNoMatch, got %v", err)
+	}
+}
+
+func TestUnmarshal_Nested(t *testing.T) {
+	word := regen.WordCharacter.Repeat().Min(1)
+	re := regen.Sequence(
+		regen.Sequence(
+			word.Group().CaptureAs("src_host"),
+			regen.Sequence(regen.String(":"), regen.Digit.Repeat().Min(1).Group().CaptureAs("src_port")).Optional(),
+		),
+		regen.String(" -> "),
+		regen.Sequence(
+			word.Group().CaptureAs("dst_host"),
+			regen.Sequence(regen.String(":"), regen.Digit.Repeat().Min(1).Group().CaptureAs("dst_port")).Optional(),
+		).Optional(),
+		regen.Sequence(
+			regen.String(" tags="),
+			regen.Sequence(word.Group().CaptureAs("tag"), regen.String(",").Optional()).Repeat().Group().CaptureAs("tags"),
+		).Optional(),
+		regen.Sequence(
+			regen.String(" "),
+			regen.Sequence(
+				word.Group().CaptureAs("attrs_key"),
+				regen.String("="),
+				word.Group().CaptureAs("attrs_value"),
+				regen.String(";").Optional(),
+			).Repeat().Min(1).Group().CaptureAs("attrs"),
+		).Optional(),
+	)
+
+	type endpoint struct {
+		Host string `regen:"host,upper"`
+		Port *int   `regen:"port"`
+	}
+	type attr struct {
+		Key   string `regen:"key"`
+		Value string `regen:"value"`
+	}
+	type flow struct {
+		Src   endpoint  `regen:"src"`
+		Dst   *endpoint `regen:"dst"`
+		Tags  []string  `regen:"tags,upper"`
+		Attrs []attr    `regen:"attrs"`
+	}
+
+	var actual flow
+	if err := regen.Unmarshal(re, "a:80 ->  tags=x,y,z k1=v1;k2=v2", &actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual.Src.Host != "A" || actual.Src.Port == nil || *actual.Src.Port != 80 {
+		t.Errorf("unexpected src: %+v", actual.Src)
+	}
+	if actual.Dst != nil {
+		t.Errorf("expected dst to be nil, got %+v", actual.Dst)
+	}
+	if expected := []string{"X", "Y", "Z"}; !reflect.DeepEqual(actual.Tags, expected) {
+		t.Errorf("expected tags %v, got %v", expected, actual.Tags)
+	}
+	if expected := []attr{{"k1", "v1"}, {"k2", "v2"}}; !reflect.DeepEqual(actual.Attrs, expected) {
+		t.Errorf("expected attrs %v, got %v", expected, actual.Attrs)
+	}
+
+	actual = flow{}
+	if err := regen.Unmarshal(re, "a -> b:443", &actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual.Dst == nil || actual.Dst.Host != "B" || *actual.Dst.Port != 443 {
+		t.Errorf("unexpected dst: %+v", actual.Dst)
+	}
+	if actual.Tags != nil || actual.Attrs != nil {
+		t.Errorf("expected no tags or attrs, got %v and %v", actual.Tags, actual.Attrs)
+	}
+}
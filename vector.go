@@ -0,0 +1,27 @@
+package regen
+
+import "strings"
+
+// vrlDialect is the syntax of Rust's regex crate, which is used by Vector's VRL. It is the same as
+// RE2, except that Perl classes (e.g. \d) match Unicode characters, so they are expanded.
+var vrlDialect = func() Dialect {
+	d := DialectRE2
+	d.name = "VRL"
+	d.perlClasses = ""
+	return d
+}()
+
+// ToVRL returns a VRL expression that parses field (a path such as .message) using re, e.g.
+// parse_regex!(.message, r'^(?P<level>\w+): (?P<msg>.*)$'). The result is an object with a key for each
+// named group, which is typically merged into the event:
+//
+//	. |= parse_regex!(.message, r'...')
+//
+// Note that \b matches at Unicode word boundaries in VRL, rather than ASCII word boundaries.
+func ToVRL(re Regexp, field string) (string, error) {
+	pattern, err := vrlDialect.Render(re)
+	if err != nil {
+		return "", err
+	}
+	return "parse_regex!(" + field + ", r'" + strings.Replace(pattern, "'", `\'`, -1) + "')", nil
+}
@@ -0,0 +1,26 @@
+package regen_test
+
+import (
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestToVRL(t *testing.T) {
+	re := regen.Sequence(
+		regen.TextStart,
+		regen.WordCharacter.Repeat().Min(1).Group().CaptureAs("level"),
+		regen.String(": '"),
+		regen.Any.Repeat().Group().CaptureAs("msg"),
+		regen.String("'"),
+		regen.TextEnd,
+	)
+	actual, err := regen.ToVRL(re, ".message")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `parse_regex!(.message, r'\A(?P<level>[0-9A-Za-z_]+): \'(?P<msg>.*)\'\z')`
+	if actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
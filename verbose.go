@@ -0,0 +1,152 @@
+package regen
+
+import "strings"
+
+// Annotate returns a Regexp that is equivalent to re, with a comment describing it. Comments do not
+// affect Regexp or Render, but are included by RenderVerbose and Format.
+func Annotate(re Regexp, comment string) Regexp {
+	return annotatedRegexp{re: re, comment: comment}
+}
+
+type annotatedRegexp struct {
+	re      Regexp
+	comment string
+}
+
+func (a annotatedRegexp) Regexp() string {
+	return renderRE2(a)
+}
+
+func (a annotatedRegexp) appendTo(sb *strings.Builder, r *renderer) {
+	r.appendRegexp(sb, a.re)
+}
+
+func (a annotatedRegexp) Group() GroupedRegexp {
+	return groupedRegexp{re: a}
+}
+
+func (a annotatedRegexp) Repeat() RepeatedRegexp {
+	return repeatedRegexp{re: a}
+}
+
+func (a annotatedRegexp) Optional() Regexp {
+	return repeatedRegexp{re: a}.Min(0).Max(1)
+}
+
+// RenderVerbose renders re in the dialect (see Render) using free-spacing mode (?x), with each element of
+// a Sequence or OneOf on its own line, nested groups indented, and comments added using Annotate.
+// Whitespace and '#' characters that are matched literally are escaped. An *UnsupportedError is returned
+// for dialects that do not support free-spacing mode, such as DialectRE2 (see Format instead).
+func (d Dialect) RenderVerbose(re Regexp) (string, error) {
+	if !d.freeSpacing {
+		return "", &UnsupportedError{Dialect: d.name, Construct: "free-spacing mode"}
+	}
+	p := verbosePrinter{renderer: renderer{dialect: d}}
+	s := p.print(re)
+	if p.err != nil {
+		return "", p.err
+	}
+	return "(?x)\n" + s, nil
+}
+
+// Format returns the same layout as RenderVerbose in the syntax of DialectRE2. Since RE2 does not support
+// free-spacing mode, the result cannot be compiled; it is intended for display (e.g. during code review).
+func Format(re Regexp) string {
+	p := verbosePrinter{renderer: renderer{dialect: DialectRE2}}
+	return p.print(re)
+}
+
+type verboseLine struct {
+	depth   int
+	text    string
+	comment string
+}
+
+type verbosePrinter struct {
+	renderer
+}
+
+func (p *verbosePrinter) print(re Regexp) string {
+	var sb strings.Builder
+	for i, line := range p.lines(re, 0) {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(strings.Repeat("  ", line.depth))
+		sb.WriteString(line.text)
+		if line.comment != "" {
+			if line.text != "" {
+				sb.WriteString("  ")
+			}
+			sb.WriteString("# ")
+			sb.WriteString(strings.Join(strings.Fields(line.comment), " "))
+		}
+	}
+	return sb.String()
+}
+
+func (p *verbosePrinter) lines(re Regexp, depth int) []verboseLine {
+	switch re := re.(type) {
+	case annotatedRegexp:
+		lines := p.lines(re.re, depth)
+		if lines[0].comment != "" {
+			lines[0].comment = re.comment + "; " + lines[0].comment
+		} else {
+			lines[0].comment = re.comment
+		}
+		return lines
+	case multiRegexp:
+		if len(re.res) == 1 {
+			return p.lines(re.res[0], depth)
+		}
+		var lines []verboseLine
+		for i, sub := range re.res {
+			if i > 0 && re.separator != "" {
+				lines = append(lines, verboseLine{depth: depth, text: re.separator})
+			}
+			lines = append(lines, p.lines(sub, depth)...)
+		}
+		if lines == nil {
+			return p.inline(re, depth)
+		}
+		return lines
+	case groupedRegexp:
+		open := re.open(&p.renderer)
+		flags := p.activeFlags
+		p.activeFlags = flags&^re.unsetFlags | re.setFlags
+		inner := p.lines(re.re, depth+1)
+		p.activeFlags = flags
+		if len(inner) == 1 {
+			return []verboseLine{{depth: depth, text: open + inner[0].text + ")", comment: inner[0].comment}}
+		}
+		lines := append([]verboseLine{{depth: depth, text: open}}, inner...)
+		return append(lines, verboseLine{depth: depth, text: ")"})
+	case repeatedRegexp:
+		if re.requiresExpansion(&p.renderer) {
+			return p.inline(re, depth)
+		}
+		lines := p.lines(re.re, depth)
+		if len(lines) == 1 && lines[0].comment == "" {
+			return p.inline(re, depth)
+		}
+		if requiresParens(re.re, p.regexp(re.re)) {
+			for i := range lines {
+				lines[i].depth++
+			}
+			lines = append([]verboseLine{{depth: depth, text: "("}}, lines...)
+			lines = append(lines, verboseLine{depth: depth, text: ")"})
+		}
+		lines[len(lines)-1].text += re.quantifier(re.isUngreedy(&p.renderer))
+		return lines
+	}
+	return p.inline(re, depth)
+}
+
+// inline renders re on a single line, escaping characters that are ignored in free-spacing mode
+func (p *verbosePrinter) inline(re Regexp, depth int) []verboseLine {
+	tokens := splitTokens(p.regexp(re))
+	for i, token := range tokens {
+		tokens[i] = escapeFreeSpacing(token)
+	}
+	return []verboseLine{{depth: depth, text: strings.Join(tokens, "")}}
+}
@@ -0,0 +1,115 @@
+package regen_test
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		description string
+		re          regen.Regexp
+		expected    string
+	}{
+		{
+			description: "places sequence elements on their own lines",
+			re:          regen.Sequence(regen.TextStart, regen.String("a b"), regen.Digit.Repeat()),
+			expected:    "\\A\na\\ b\n\\d*",
+		},
+		{
+			description: "indents nested groups",
+			re: regen.Sequence(
+				regen.String("key="),
+				regen.Sequence(regen.WordCharacter, regen.String("#")).Group().CaptureAs("value"),
+			),
+			expected: "key=\n(?P<value>\n  \\w\n  \\#\n)",
+		},
+		{
+			description: "keeps single-line groups on one line",
+			re:          regen.String("abc").Group().NoCapture().SetFlags(regen.FlagCaseInsensitive),
+			expected:    "(?i:abc)",
+		},
+		{
+			description: "separates alternatives",
+			re:          regen.OneOf(regen.String("a"), regen.Sequence(regen.String("b"), regen.Digit)),
+			expected:    "(\n  a\n  |\n  b\n  \\d\n)",
+		},
+		{
+			description: "includes comments",
+			re: regen.Sequence(
+				regen.Annotate(regen.TextStart, "start of input"),
+				regen.Annotate(regen.Digit.Repeat().Min(1), "the\nnumber"),
+			),
+			expected: "\\A  # start of input\n\\d+  # the number",
+		},
+		{
+			description: "adds quantifiers after multi-line repetitions",
+			re:          regen.Sequence(regen.Annotate(regen.String("ab"), "letters"), regen.Digit).Repeat().Ungreedy(),
+			expected:    "(\n  ab  # letters\n  \\d\n)*?",
+		},
+		{
+			description: "combines comments on the same line",
+			re:          regen.Annotate(regen.Annotate(regen.String("a"), "inner").Group(), "outer"),
+			expected:    "(a)  # outer; inner",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			actual := regen.Format(tt.re)
+			if actual != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAnnotateDoesNotAffectRegexp(t *testing.T) {
+	re := regen.Annotate(regen.String("ab"), "letters").Repeat()
+	if re.Regexp() != "(ab)*" {
+		t.Errorf("expected (ab)*, got %s", re.Regexp())
+	}
+}
+
+func TestRenderVerbose(t *testing.T) {
+	re := regen.Sequence(regen.String("a "), regen.Annotate(regen.Digit, "digit").Group().CaptureAs("d"))
+	actual, err := regen.DialectDotNet.RenderVerbose(re)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "(?x)\na\\ \n(?<d>[0-9])  # digit"
+	if actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	_, err = regen.DialectRE2.RenderVerbose(re)
+	var unsupported *regen.UnsupportedError
+	if !errors.As(err, &unsupported) {
+		t.Errorf("expected an UnsupportedError, got %v", err)
+	}
+}
+
+func TestFormatPreservesExpression(t *testing.T) {
+	// Removing the layout from the formatted expression should result in the original expression
+	re := regen.Sequence(
+		regen.Annotate(regen.LineStart, "start"),
+		regen.OneOf(regen.String("x y"), regen.Sequence(regen.String("#"), regen.Digit.Repeat().Min(2))).Group().NoCapture(),
+		regen.Sequence(regen.Annotate(regen.WordCharacter, "word"), regen.String("-")).Optional(),
+	)
+	var sb strings.Builder
+	for _, line := range strings.Split(regen.Format(re), "\n") {
+		if i := strings.Index(line, "  # "); i >= 0 {
+			line = line[:i]
+		}
+		sb.WriteString(strings.TrimLeft(line, " "))
+	}
+	unescaped := strings.NewReplacer(`\ `, " ", `\#`, "#").Replace(sb.String())
+	if unescaped != re.Regexp() {
+		t.Errorf("expected %s, got %s", re.Regexp(), unescaped)
+	}
+	regexp.MustCompile(unescaped)
+}
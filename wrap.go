@@ -0,0 +1,195 @@
+package regen
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// RenderSegments renders re in the dialect (see Render), split into segments that are each at most
+// width bytes long, so that the pattern can be stored by consumers with line-length limits and
+// concatenated back together. Segments are only split between tokens (e.g. never within an escape
+// sequence or character class), so a single token longer than width results in a longer segment.
+func (d Dialect) RenderSegments(re Regexp, width int) ([]string, error) {
+	pattern, err := d.Render(re)
+	if err != nil {
+		return nil, err
+	}
+	return wrapTokens(splitTokens(pattern), width), nil
+}
+
+// RenderWrapped renders re in the dialect (see Render) using free-spacing mode (?x), with lines that
+// are each at most width bytes long. Whitespace and '#' characters that are matched literally are
+// escaped, since they would otherwise be ignored in free-spacing mode. As with RenderSegments, lines
+// are only split between tokens. An *UnsupportedError is returned for dialects that do not support
+// free-spacing mode, such as DialectRE2.
+func (d Dialect) RenderWrapped(re Regexp, width int) (string, error) {
+	if !d.freeSpacing {
+		return "", &UnsupportedError{Dialect: d.name, Construct: "free-spacing mode"}
+	}
+	pattern, err := d.Render(re)
+	if err != nil {
+		return "", err
+	}
+	tokens := splitTokens(pattern)
+	for i, token := range tokens {
+		tokens[i] = escapeFreeSpacing(token)
+	}
+	lines := append([]string{"(?x)"}, wrapTokens(tokens, width)...)
+	return strings.Join(lines, "\n"), nil
+}
+
+// freeSpacingEscapes contains the escape sequences for characters that are ignored in free-spacing mode
+var freeSpacingEscapes = map[byte]string{
+	' ':  `\ `,
+	'#':  `\#`,
+	'\t': `\t`,
+	'\n': `\n`,
+	'\v': `\v`,
+	'\f': `\f`,
+	'\r': `\r`,
+}
+
+// escapeFreeSpacing escapes a token that would be interpreted differently in free-spacing mode.
+// Character classes do not need to be escaped, since whitespace is significant within them.
+func escapeFreeSpacing(token string) string {
+	if escaped, ok := freeSpacingEscapes[token[0]]; ok {
+		return escaped + token[1:]
+	}
+	return token
+}
+
+// wrapTokens joins tokens into lines of at most width bytes
+func wrapTokens(tokens []string, width int) []string {
+	var lines []string
+	var line strings.Builder
+	for _, token := range tokens {
+		if line.Len() > 0 && line.Len()+len(token) > width {
+			lines = append(lines, line.String())
+			line.Reset()
+		}
+		line.WriteString(token)
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// splitTokens splits a rendered regular expression into tokens that cannot be split any further
+// without changing their meaning, such as escape sequences, character classes, group prefixes and
+// atoms along with their quantifiers.
+func splitTokens(pattern string) []string {
+	var tokens []string
+	for i := 0; i < len(pattern); {
+		n := tokenLen(pattern[i:])
+		token := pattern[i : i+n]
+		i += n
+		// Quantifiers are kept with the atom that they apply to
+		if len(tokens) > 0 && isQuantifier(token) {
+			tokens[len(tokens)-1] += token
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// tokenLen returns the length in bytes of the token at the start of s
+func tokenLen(s string) int {
+	switch s[0] {
+	case '\\':
+		if len(s) < 2 {
+			return 1
+		}
+		if strings.IndexByte("pPxu", s[1]) >= 0 && len(s) > 2 && s[2] == '{' {
+			if end := strings.IndexByte(s, '}'); end > 0 {
+				return end + 1
+			}
+		}
+		if s[1] == 'u' && len(s) >= 6 {
+			return 6
+		}
+		_, size := utf8.DecodeRuneInString(s[1:])
+		return 1 + size
+	case '[':
+		return classLen(s)
+	case '(':
+		if len(s) > 1 && s[1] == '?' {
+			if end := strings.IndexAny(s, ":)>"); end > 0 {
+				return end + 1
+			}
+		}
+		return 1
+	case '{':
+		if end := strings.IndexByte(s, '}'); end > 0 && isCountedRepetition(s[:end+1]) {
+			if end+1 < len(s) && s[end+1] == '?' {
+				return end + 2
+			}
+			return end + 1
+		}
+	case '*', '+', '?':
+		if len(s) > 1 && s[1] == '?' {
+			return 2
+		}
+		return 1
+	}
+	_, size := utf8.DecodeRuneInString(s)
+	return size
+}
+
+// classLen returns the length in bytes of the character class at the start of s
+func classLen(s string) int {
+	i := 1
+	if i < len(s) && s[i] == '^' {
+		i++
+	}
+	if i < len(s) && s[i] == ']' {
+		i++
+	}
+	for i < len(s) {
+		switch {
+		case s[i] == '\\':
+			i += tokenLen(s[i:])
+		case strings.HasPrefix(s[i:], "[:"):
+			if end := strings.Index(s[i:], ":]"); end > 0 {
+				i += end + 2
+			} else {
+				i++
+			}
+		case s[i] == ']':
+			return i + 1
+		default:
+			i++
+		}
+	}
+	return len(s)
+}
+
+func isQuantifier(token string) bool {
+	switch token[0] {
+	case '*', '+', '?':
+		return true
+	case '{':
+		return len(token) > 1
+	}
+	return false
+}
+
+// isCountedRepetition returns true if s is of the form {n}, {n,} or {n,m}
+func isCountedRepetition(s string) bool {
+	inner := strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	min, max := inner, ""
+	if comma := strings.IndexByte(inner, ','); comma >= 0 {
+		min, max = inner[:comma], inner[comma+1:]
+	}
+	return min != "" && isDigits(min) && isDigits(max)
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
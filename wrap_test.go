@@ -0,0 +1,156 @@
+package regen_test
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+)
+
+func TestRenderSegments(t *testing.T) {
+	tests := []struct {
+		description string
+		re          regen.Regexp
+		width       int
+		expected    []string
+	}{
+		{
+			description: "short expressions are a single segment",
+			re:          regen.String("abc"),
+			width:       10,
+			expected:    []string{"abc"},
+		},
+		{
+			description: "splits between tokens",
+			re:          regen.String("abcdef"),
+			width:       4,
+			expected:    []string{"abcd", "ef"},
+		},
+		{
+			description: "keeps escapes together",
+			re:          regen.String("ab.cd"),
+			width:       3,
+			expected:    []string{"ab", `\.c`, "d"},
+		},
+		{
+			description: "keeps character classes together",
+			re:          regen.Sequence(regen.String("a"), regen.CharSet('b', ']', 'c')),
+			width:       3,
+			expected:    []string{"a", `[b\]c]`},
+		},
+		{
+			description: "keeps quantifiers with their atoms",
+			re:          regen.Sequence(regen.String("ab"), regen.Digit.Repeat().Min(2).Max(3).Ungreedy()),
+			width:       4,
+			expected:    []string{"ab", `\d{2,3}?`},
+		},
+		{
+			description: "keeps group prefixes together",
+			re:          regen.String("xy").Group().CaptureAs("name"),
+			width:       4,
+			expected:    []string{"(?P<name>", "xy)"},
+		},
+		{
+			description: "keeps Unicode classes together",
+			re:          regen.Sequence(regen.String("a"), regen.UnicodeCharClass("Greek")),
+			width:       4,
+			expected:    []string{"a", `\p{Greek}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			segments, err := regen.DialectRE2.RenderSegments(tt.re, tt.width)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(segments, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expected segments %q, got %q", tt.expected, segments)
+			}
+			if strings.Join(segments, "") != tt.re.Regexp() {
+				t.Errorf("segments %q do not concatenate to %q", segments, tt.re.Regexp())
+			}
+		})
+	}
+}
+
+func TestRenderWrapped(t *testing.T) {
+	tests := []struct {
+		description string
+		dialect     regen.Dialect
+		re          regen.Regexp
+		width       int
+		expected    string
+		unsupported bool
+	}{
+		{
+			description: "prefixes free-spacing mode",
+			dialect:     regen.DialectDotNet,
+			re:          regen.String("abcdef"),
+			width:       4,
+			expected:    "(?x)\nabcd\nef",
+		},
+		{
+			description: "escapes whitespace and comments",
+			dialect:     regen.DialectRuby,
+			re:          regen.Sequence(regen.String("a b#"), regen.String(" ").Repeat()),
+			width:       4,
+			expected:    "(?x)\na\\ b\n\\#\n\\ *",
+		},
+		{
+			description: "does not escape whitespace in character classes",
+			dialect:     regen.DialectDotNet,
+			re:          regen.CharSet(' ', '#'),
+			width:       10,
+			expected:    "(?x)\n[ #]",
+		},
+		{
+			description: "RE2 does not support free-spacing mode",
+			dialect:     regen.DialectRE2,
+			re:          regen.String("abc"),
+			width:       10,
+			unsupported: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			actual, err := tt.dialect.RenderWrapped(tt.re, tt.width)
+			if tt.unsupported {
+				var unsupported *regen.UnsupportedError
+				if !errors.As(err, &unsupported) {
+					t.Fatalf("expected an UnsupportedError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRenderWrappedMatchesSameStrings(t *testing.T) {
+	// Go's regexp package does not support free-spacing mode, so remove the line breaks and
+	// unescape the escaped characters to check that the original expression was preserved
+	re := regen.Sequence(regen.String("hello, world # "), regen.Digit.Repeat(), regen.CharSet(' ', 'x'))
+	wrapped, err := regen.DialectDotNet.RenderWrapped(re, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected, err := regen.DialectDotNet.Render(re)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(wrapped, "\n")
+	unwrapped := strings.NewReplacer(`\ `, " ", `\#`, "#").Replace(strings.Join(lines[1:], ""))
+	if unwrapped != expected {
+		t.Errorf("expected %q, got %q", expected, unwrapped)
+	}
+	regexp.MustCompile(unwrapped)
+}
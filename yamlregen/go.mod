@@ -0,0 +1,10 @@
+module github.com/aoldershaw/regen/yamlregen
+
+go 1.23
+
+require (
+	github.com/aoldershaw/regen v0.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/aoldershaw/regen => ../
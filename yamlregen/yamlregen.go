@@ -0,0 +1,425 @@
+// Package yamlregen builds regen expressions from YAML documents, so that patterns (such as the rules
+// for parsing log lines or validating input) can be configured without writing Go. A document defines
+// named patterns, which can refer to each other:
+//
+//	patterns:
+//	  level:
+//	    oneOfStrings: [DEBUG, INFO, WARN, ERROR]
+//	  timestamp:
+//	    sequence:
+//	      - {raw: '\d{4}-\d{2}-\d{2}'}
+//	      - string: T
+//	      - {raw: '\d{2}:\d{2}:\d{2}'}
+//	  line:
+//	    sequence:
+//	      - lineStart
+//	      - {ref: timestamp, name: time}
+//	      - string: " "
+//	      - {ref: level, name: level}
+//	      - string: " "
+//	      - {ref: message, name: message}
+//	  message:
+//	    class: any
+//	    min: 0
+//
+// Each pattern is either the name of a predefined expression (any, digit, whitespace, word, hexDigit,
+// lineStart, lineEnd, textStart, textEnd, boundary or notBoundary) or a mapping with exactly one of
+// the following keys:
+//
+//	string: text          a literal string (see regen.String)
+//	raw: expression       a regular expression, which isn't validated (see regen.Raw)
+//	ref: name             another pattern in the document (or the registry, see WithRegistry)
+//	class: name           a predefined expression, as above
+//	sequence: [...]       patterns that appear in order
+//	oneOf: [...]          any of the patterns, preferring earlier ones (in a non-capturing group)
+//	oneOfStrings: [...]   any of the literal strings (see regen.OneOfStrings)
+//	chars: abc            any of the characters (see regen.CharSet)
+//	range: a-z            any character in the range (see regen.CharRange)
+//	ascii: name           an ASCII class (see regen.ASCIICharClass)
+//	unicode: name         a Unicode class (see regen.UnicodeCharClass)
+//	union: [...]          any character in one of the classes (see regen.Union)
+//
+// along with any of these modifiers, which are applied in order:
+//
+//	negate: true          matches the characters that the class doesn't
+//	min: 1, max: 3        repeats the pattern (exactly: 2 repeats it a fixed number of times)
+//	optional: true        the pattern may not appear
+//	ungreedy: true        the repetition prefers fewer matches
+//	name: id              captures the pattern in a named group
+//	capture: true         captures the pattern in an unnamed group
+//	flags: is             sets flags for the pattern (i, m, s and U, see regen.Flag)
+//	comment: text         annotates the pattern (see regen.Annotate)
+package yamlregen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/aoldershaw/regen"
+	"gopkg.in/yaml.v3"
+)
+
+// builtins are the predefined expressions that patterns can be named after
+var builtins = map[string]regen.Regexp{
+	"any":         regen.Any,
+	"digit":       regen.Digit,
+	"whitespace":  regen.Whitespace,
+	"word":        regen.WordCharacter,
+	"hexDigit":    regen.HexDigit,
+	"lineStart":   regen.LineStart,
+	"lineEnd":     regen.LineEnd,
+	"textStart":   regen.TextStart,
+	"textEnd":     regen.TextEnd,
+	"boundary":    regen.ASCIIBoundary,
+	"notBoundary": regen.NotASCIIBoundary,
+}
+
+// Option configures Load
+type Option func(*loader)
+
+// WithRegistry resolves references to patterns that aren't defined by the document by looking them up
+// in r, so that documents can build on centrally registered patterns
+func WithRegistry(r *regen.Registry) Option {
+	return func(l *loader) {
+		l.registry = r
+	}
+}
+
+// Load builds the patterns defined by a YAML document, returning them by name. Errors identify the
+// line of the document that they occur on.
+func Load(data []byte, opts ...Option) (map[string]regen.Regexp, error) {
+	l := &loader{
+		definitions: make(map[string]*yaml.Node),
+		patterns:    make(map[string]regen.Regexp),
+		building:    make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	var doc struct {
+		Patterns yaml.Node `yaml:"patterns"`
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("yamlregen: %v", err)
+	}
+	if doc.Patterns.Kind == 0 {
+		return nil, fmt.Errorf("yamlregen: the document doesn't define any patterns")
+	}
+	if doc.Patterns.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("yamlregen: line %d: patterns must be a mapping of names to patterns", doc.Patterns.Line)
+	}
+	var names []string
+	for i := 0; i < len(doc.Patterns.Content); i += 2 {
+		name := doc.Patterns.Content[i].Value
+		if _, ok := l.definitions[name]; ok {
+			return nil, fmt.Errorf("yamlregen: line %d: %q is defined more than once", doc.Patterns.Content[i].Line, name)
+		}
+		l.definitions[name] = doc.Patterns.Content[i+1]
+		names = append(names, name)
+	}
+	for _, name := range names {
+		if _, err := l.resolve(name, l.definitions[name]); err != nil {
+			return nil, err
+		}
+	}
+	return l.patterns, nil
+}
+
+// Register loads the patterns defined by a YAML document (see Load), resolving references to patterns
+// that the document doesn't define using r, and registers them in r in order of their names
+func Register(r *regen.Registry, data []byte) error {
+	patterns, err := Load(data, WithRegistry(r))
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(patterns))
+	for name := range patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := r.Register(name, patterns[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type loader struct {
+	definitions map[string]*yaml.Node
+	patterns    map[string]regen.Regexp
+	// building contains the patterns that are being built, to detect cycles
+	building map[string]bool
+	registry *regen.Registry
+}
+
+// resolve returns the pattern with the given name, where ref is the node that refers to it
+func (l *loader) resolve(name string, ref *yaml.Node) (regen.Regexp, error) {
+	if re, ok := l.patterns[name]; ok {
+		return re, nil
+	}
+	definition, ok := l.definitions[name]
+	if !ok {
+		if l.registry != nil {
+			if entry, ok := l.registry.Lookup(name); ok {
+				return entry.Regexp, nil
+			}
+		}
+		return nil, errorf(ref, "there is no pattern named %q", name)
+	}
+	if l.building[name] {
+		return nil, errorf(ref, "pattern %q refers to itself", name)
+	}
+	l.building[name] = true
+	re, err := l.build(definition)
+	delete(l.building, name)
+	if err != nil {
+		return nil, err
+	}
+	l.patterns[name] = re
+	return re, nil
+}
+
+// build builds the pattern described by n
+func (l *loader) build(n *yaml.Node) (regen.Regexp, error) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if re, ok := builtins[n.Value]; ok {
+			return re, nil
+		}
+		return nil, errorf(n, "unknown pattern %q (literal strings are written as string: %s)", n.Value, n.Value)
+	case yaml.MappingNode:
+	default:
+		return nil, errorf(n, "a pattern must be a name or a mapping")
+	}
+
+	var re regen.Regexp
+	var construct string
+	var modifiers []*yaml.Node
+	for i := 0; i < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if modifierKeys[key.Value] {
+			modifiers = append(modifiers, key, value)
+			continue
+		}
+		if construct != "" {
+			return nil, errorf(key, "a pattern can only have one of %s and %s", construct, key.Value)
+		}
+		construct = key.Value
+		var err error
+		if re, err = l.construct(key, value); err != nil {
+			return nil, err
+		}
+	}
+	if construct == "" {
+		return nil, errorf(n, "a pattern must have one of string, raw, ref, class, sequence, oneOf, oneOfStrings, chars, range, ascii, unicode or union")
+	}
+	return applyModifiers(re, modifiers)
+}
+
+// construct builds the pattern for the given key of a mapping
+func (l *loader) construct(key, value *yaml.Node) (regen.Regexp, error) {
+	switch key.Value {
+	case "string", "raw", "ref", "class", "chars", "range", "ascii", "unicode":
+		if value.Kind != yaml.ScalarNode {
+			return nil, errorf(value, "%s must be a string", key.Value)
+		}
+	case "sequence", "oneOf", "oneOfStrings", "union":
+		if value.Kind != yaml.SequenceNode {
+			return nil, errorf(value, "%s must be a list", key.Value)
+		}
+	}
+	switch key.Value {
+	case "string":
+		return regen.String(value.Value), nil
+	case "raw":
+		return regen.Raw(value.Value), nil
+	case "ref":
+		return l.resolve(value.Value, value)
+	case "class":
+		if re, ok := builtins[value.Value]; ok {
+			return re, nil
+		}
+		return nil, errorf(value, "unknown class %q", value.Value)
+	case "sequence", "oneOf", "union":
+		res := make([]regen.Regexp, len(value.Content))
+		for i, item := range value.Content {
+			re, err := l.build(item)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = re
+		}
+		switch key.Value {
+		case "sequence":
+			return regen.Sequence(res...), nil
+		case "oneOf":
+			return regen.OneOf(res...).Group().NoCapture(), nil
+		}
+		classes := make([]regen.CharClass, len(res))
+		for i, re := range res {
+			class, ok := re.(regen.CharClass)
+			if !ok {
+				return nil, errorf(value.Content[i], "only character classes can be in a union")
+			}
+			classes[i] = class
+		}
+		return regen.Union(classes...), nil
+	case "oneOfStrings":
+		choices := make([]string, len(value.Content))
+		for i, item := range value.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, errorf(item, "oneOfStrings must be a list of strings")
+			}
+			choices[i] = item.Value
+		}
+		return regen.OneOfStrings(choices...), nil
+	case "chars":
+		if value.Value == "" {
+			return nil, errorf(value, "chars must not be empty")
+		}
+		return regen.CharSet([]rune(value.Value)...), nil
+	case "range":
+		runes := []rune(value.Value)
+		if len(runes) != 3 || runes[1] != '-' || runes[0] > runes[2] {
+			return nil, errorf(value, "range must be written as start-end, e.g. a-z")
+		}
+		return regen.CharRange(runes[0], runes[2]), nil
+	case "ascii":
+		return regen.ASCIICharClass(value.Value), nil
+	case "unicode":
+		return regen.UnicodeCharClass(value.Value), nil
+	}
+	return nil, errorf(key, "unknown key %q", key.Value)
+}
+
+// modifierKeys are the keys that modify a pattern
+var modifierKeys = map[string]bool{
+	"negate":   true,
+	"min":      true,
+	"max":      true,
+	"exactly":  true,
+	"optional": true,
+	"ungreedy": true,
+	"name":     true,
+	"capture":  true,
+	"flags":    true,
+	"comment":  true,
+}
+
+// modifiers are the decoded values of the modifiers of a pattern
+type modifiers struct {
+	Negate   bool   `yaml:"negate"`
+	Min      *uint  `yaml:"min"`
+	Max      *uint  `yaml:"max"`
+	Exactly  *uint  `yaml:"exactly"`
+	Optional bool   `yaml:"optional"`
+	Ungreedy bool   `yaml:"ungreedy"`
+	Name     string `yaml:"name"`
+	Capture  bool   `yaml:"capture"`
+	Flags    string `yaml:"flags"`
+	Comment  string `yaml:"comment"`
+}
+
+// applyModifiers applies the modifiers (pairs of keys and values) to re
+func applyModifiers(re regen.Regexp, pairs []*yaml.Node) (regen.Regexp, error) {
+	if len(pairs) == 0 {
+		return re, nil
+	}
+	var m modifiers
+	if err := (&yaml.Node{Kind: yaml.MappingNode, Content: pairs}).Decode(&m); err != nil {
+		return nil, fmt.Errorf("yamlregen: %v", err)
+	}
+	at := pairs[0]
+
+	if m.Negate {
+		class, ok := re.(regen.CharClass)
+		if !ok {
+			return nil, errorf(at, "only character classes can be negated")
+		}
+		re = class.Negate()
+	}
+
+	if m.Min != nil || m.Max != nil || m.Exactly != nil {
+		if m.Optional {
+			return nil, errorf(at, "optional can't be combined with min, max or exactly")
+		}
+		repeated := re.Repeat()
+		if m.Exactly != nil {
+			if m.Min != nil || m.Max != nil {
+				return nil, errorf(at, "exactly can't be combined with min or max")
+			}
+			repeated = repeated.Exactly(*m.Exactly)
+		}
+		if m.Min != nil {
+			repeated = repeated.Min(*m.Min)
+		}
+		if m.Max != nil {
+			if m.Min != nil && *m.Max < *m.Min {
+				return nil, errorf(at, "max must be at least min")
+			}
+			repeated = repeated.Max(*m.Max)
+		}
+		if m.Ungreedy {
+			repeated = repeated.Ungreedy()
+		}
+		re = repeated
+	} else if m.Ungreedy {
+		return nil, errorf(at, "ungreedy requires min, max or exactly")
+	} else if m.Optional {
+		re = re.Optional()
+	}
+
+	if m.Name != "" || m.Capture || m.Flags != "" {
+		flags, err := parseFlags(m.Flags)
+		if err != nil {
+			return nil, errorf(at, "%v", err)
+		}
+		group := re.Group()
+		switch {
+		case m.Name != "":
+			group = group.CaptureAs(m.Name)
+		case m.Capture:
+			group = group.Capture()
+		default:
+			group = group.NoCapture()
+		}
+		if flags != 0 {
+			group = group.SetFlags(flags)
+		}
+		re = group
+	}
+
+	if m.Comment != "" {
+		re = regen.Annotate(re, m.Comment)
+	}
+	return re, nil
+}
+
+// parseFlags parses the letters of flags, as returned by regen.Flag.String
+func parseFlags(letters string) (regen.Flag, error) {
+	var flags regen.Flag
+	for _, letter := range letters {
+		switch letter {
+		case 'i':
+			flags |= regen.FlagCaseInsensitive
+		case 'm':
+			flags |= regen.FlagMultiLine
+		case 's':
+			flags |= regen.FlagMatchNewLine
+		case 'U':
+			flags |= regen.FlagUngreedy
+		default:
+			return 0, fmt.Errorf("unknown flag %q", letter)
+		}
+	}
+	return flags, nil
+}
+
+func errorf(n *yaml.Node, format string, args ...interface{}) error {
+	return fmt.Errorf("yamlregen: line %d: %s", n.Line, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,191 @@
+package yamlregen_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/aoldershaw/regen"
+	"github.com/aoldershaw/regen/yamlregen"
+)
+
+const logDocument = `
+patterns:
+  level:
+    oneOfStrings: [DEBUG, INFO, WARN, ERROR]
+  timestamp:
+    sequence:
+      - {raw: '\d{4}-\d{2}-\d{2}'}
+      - string: T
+      - {raw: '\d{2}:\d{2}:\d{2}'}
+  line:
+    sequence:
+      - lineStart
+      - {ref: timestamp, name: time}
+      - string: " "
+      - {ref: level, name: level}
+      - string: " "
+      - {ref: message, name: message}
+  message:
+    class: any
+    min: 0
+`
+
+func TestLoad(t *testing.T) {
+	patterns, err := yamlregen.Load([]byte(logDocument))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	line := patterns["line"]
+	expected := `^(?P<time>\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}) (?P<level>DEBUG|ERROR|INFO|WARN) (?P<message>.*)`
+	if line == nil {
+		t.Fatalf("expected a pattern named line")
+	}
+	if line.Regexp() != expected {
+		t.Fatalf("expected %s, got %s", expected, line.Regexp())
+	}
+	match := regexp.MustCompile(line.Regexp()).FindStringSubmatch("2024-05-01T12:00:00 WARN disk is full")
+	if match == nil || match[2] != "WARN" || match[3] != "disk is full" {
+		t.Errorf("unexpected match %q", match)
+	}
+	if len(patterns) != 4 {
+		t.Errorf("expected 4 patterns, got %d", len(patterns))
+	}
+}
+
+func TestLoad_Patterns(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		yaml        string
+		expected    string
+	}{
+		{
+			description: "predefined expressions",
+			yaml:        `[textStart, digit, whitespace, word, hexDigit, boundary, textEnd]`,
+			expected:    `\A\d\s\w[0-9A-Fa-f]\b\z`,
+		},
+		{
+			description: "classes",
+			yaml:        `[{chars: ab}, {range: 0-9, negate: true}, {ascii: alpha}, {unicode: Greek}, {union: [{chars: _}, word]}]`,
+			expected:    `[ab][^0-9][[:alpha:]]\p{Greek}[_\w]`,
+		},
+		{
+			description: "alternations are non-capturing unless named",
+			yaml:        `[{oneOf: [{string: a.b}, digit]}, {oneOf: [lineStart, lineEnd], name: edge}]`,
+			expected:    `(?:a\.b|\d)(?P<edge>^|$)`,
+		},
+		{
+			description: "repetitions",
+			yaml:        `[{class: digit, min: 2, max: 4}, {class: word, exactly: 3}, {class: any, min: 1, ungreedy: true}, {string: x, optional: true}]`,
+			expected:    `\d{2,4}\w{3}.+?x?`,
+		},
+		{
+			description: "groups and flags",
+			yaml:        `[{string: ab, capture: true}, {string: cd, flags: i}, {string: ef, name: x, flags: is}]`,
+			expected:    `(ab)(?i:cd)(?P<x>(?is)ef)`,
+		},
+		{
+			description: "comments",
+			yaml:        `[{string: id, comment: the prefix}]`,
+			expected:    `id`,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			patterns, err := yamlregen.Load([]byte("patterns:\n  p:\n    sequence: " + tt.yaml))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual := patterns["p"].Regexp(); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		yaml        string
+		expected    string
+	}{
+		{
+			description: "missing patterns",
+			yaml:        "rules: {}",
+			expected:    "yamlregen: yaml: unmarshal errors:\n  line 1: field rules not found in type struct { Patterns yaml.Node \"yaml:\\\"patterns\\\"\" }",
+		},
+		{
+			description: "empty documents",
+			yaml:        "",
+			expected:    "yamlregen: the document doesn't define any patterns",
+		},
+		{
+			description: "unknown names",
+			yaml:        "patterns:\n  a: hello",
+			expected:    "yamlregen: line 2: unknown pattern \"hello\" (literal strings are written as string: hello)",
+		},
+		{
+			description: "unknown references",
+			yaml:        "patterns:\n  a:\n    ref: b",
+			expected:    "yamlregen: line 3: there is no pattern named \"b\"",
+		},
+		{
+			description: "cycles",
+			yaml:        "patterns:\n  a:\n    sequence: [{ref: b}]\n  b:\n    ref: a",
+			expected:    "yamlregen: line 5: pattern \"a\" refers to itself",
+		},
+		{
+			description: "several constructs",
+			yaml:        "patterns:\n  a:\n    string: x\n    raw: y",
+			expected:    "yamlregen: line 4: a pattern can only have one of string and raw",
+		},
+		{
+			description: "no constructs",
+			yaml:        "patterns:\n  a:\n    min: 1",
+			expected:    "yamlregen: line 3: a pattern must have one of string, raw, ref, class, sequence, oneOf, oneOfStrings, chars, range, ascii, unicode or union",
+		},
+		{
+			description: "negating expressions other than classes",
+			yaml:        "patterns:\n  a:\n    string: x\n    negate: true",
+			expected:    "yamlregen: line 4: only character classes can be negated",
+		},
+		{
+			description: "invalid ranges",
+			yaml:        "patterns:\n  a:\n    range: z-a",
+			expected:    "yamlregen: line 3: range must be written as start-end, e.g. a-z",
+		},
+		{
+			description: "invalid flags",
+			yaml:        "patterns:\n  a:\n    string: x\n    flags: x",
+			expected:    "yamlregen: line 4: unknown flag 'x'",
+		},
+		{
+			description: "invalid counts",
+			yaml:        "patterns:\n  a:\n    string: x\n    min: -1",
+			expected:    "yamlregen: yaml: unmarshal errors:\n  line 4: cannot unmarshal !!int `-1` into uint",
+		},
+		{
+			description: "duplicate names",
+			yaml:        "patterns:\n  a: digit\n  a: word",
+			expected:    "yamlregen: line 3: \"a\" is defined more than once",
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			_, err := yamlregen.Load([]byte(tt.yaml))
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	r := regen.NewRegistry()
+	r.MustRegister("number", regen.Digit.Repeat().Min(1))
+	err := yamlregen.Register(r, []byte("patterns:\n  version:\n    sequence: [{ref: number}, {string: .}, {ref: number}]"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entry, ok := r.Lookup("version")
+	if !ok || entry.Regexp.Regexp() != `\d+\.\d+` {
+		t.Errorf("expected version to be registered as \\d+\\.\\d+, got %v", entry.Regexp)
+	}
+}